@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/elmiringos/indexer/allinone/internal/broker"
+
+	coreapp "github.com/elmiringos/indexer/indexer-core/app"
+	coreconfig "github.com/elmiringos/indexer/indexer-core/config"
+	corelogger "github.com/elmiringos/indexer/indexer-core/pkg/logger"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+
+	explorerapp "github.com/elmiringos/indexer/explorer/app"
+	explorerconfig "github.com/elmiringos/indexer/explorer/config"
+	explorerlogger "github.com/elmiringos/indexer/explorer/pkg/logger"
+
+	producerapp "github.com/elmiringos/indexer/producer/app"
+	producerconfig "github.com/elmiringos/indexer/producer/config"
+	producerlogger "github.com/elmiringos/indexer/producer/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const usage = `usage: indexer <command> [flags]
+
+commands:
+  all-in-one   run producer, core and explorer in one process with an in-process broker and store
+`
+
+// inProcessEnv fills the broker URL the service configs require, nothing dials it here
+var inProcessEnv = map[string]string{
+	"RMQ_URL": "in-process",
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "all-in-one" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("all-in-one", flag.ExitOnError)
+	coreConfigPath := flags.String("core-config", "./config/core.yml", "core config file")
+	producerConfigPath := flags.String("producer-config", "./config/producer.yml", "producer config file")
+	explorerConfigPath := flags.String("explorer-config", "./config/explorer.yml", "explorer config file")
+	envPath := flags.String("env", "./.env", "env file shared by the services")
+	queueSize := flags.Int("queue-size", 1024, "messages buffered per in-process queue")
+	startTimeout := flags.Duration("start-timeout", 30*time.Second, "how long to wait for core before starting the producer")
+	flags.Parse(os.Args[2:])
+
+	for key, value := range inProcessEnv {
+		if os.Getenv(key) == "" {
+			os.Setenv(key, value)
+		}
+	}
+
+	coreCfg, err := coreconfig.NewConfig(*coreConfigPath, *envPath)
+	if err != nil {
+		panic("Error in loading core config: " + err.Error())
+	}
+
+	// the producer reaches core over gRPC, by default on the port core listens on in this process
+	if os.Getenv("CORE_SERVICE_URL") == "" {
+		os.Setenv("CORE_SERVICE_URL", "localhost:"+coreCfg.HTTP.Port)
+	}
+
+	producerCfg, err := producerconfig.NewConfig(*producerConfigPath, *envPath)
+	if err != nil {
+		panic("Error in loading producer config: " + err.Error())
+	}
+
+	explorerCfg, err := explorerconfig.NewConfig(*explorerConfigPath, *envPath)
+	if err != nil {
+		panic("Error in loading explorer config: " + err.Error())
+	}
+
+	coreLog := corelogger.New(coreCfg)
+	producerLog := producerlogger.New(producerCfg)
+	explorerLog := explorerlogger.New(explorerCfg)
+	defer coreLog.Sync()
+	defer producerLog.Sync()
+	defer explorerLog.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	b := broker.New(*queueSize)
+
+	go coreapp.Run(ctx, coreCfg, coreLog, coreapp.Options{
+		Store:    memstore.New(),
+		Consumer: broker.NewConsumer(b),
+	})
+
+	// the producer asks core where to start from and the explorer checks the schema core migrates,
+	// so core has to accept connections first
+	if err := waitForListener(ctx, producerCfg.Server.CoreServiceURL, *startTimeout); err != nil {
+		coreLog.Fatal("Core did not start", zap.Error(err))
+	}
+
+	go func() {
+		if err := explorerapp.Run(ctx, explorerCfg, explorerLog); err != nil {
+			explorerLog.Fatal("Explorer failed", zap.Error(err))
+		}
+	}()
+
+	go producerapp.Run(ctx, producerCfg, producerLog, producerapp.Options{
+		Publisher: broker.NewPublisher(b),
+	})
+
+	<-ctx.Done()
+	coreLog.Info("Shutdown signal received")
+}
+
+func waitForListener(ctx context.Context, address string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s not reachable after %s: %w", address, timeout, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package broker routes the pipeline messages from the producer to core through Go channels,
+// it stands in for RabbitMQ when every service runs in one process
+package broker
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type binding struct {
+	exchange   string
+	routingKey string
+}
+
+// Broker keeps one buffered channel per declared queue. Like a direct exchange it delivers a message
+// to the queues bound with its exchange and routing key, a full queue blocks the publisher.
+// Messages are kept until they are acked, a nack with requeue delivers them again.
+type Broker struct {
+	mu        sync.Mutex
+	queueSize int
+	queues    map[string]*queue
+	bindings  map[binding][]*queue
+}
+
+func New(queueSize int) *Broker {
+	return &Broker{
+		queueSize: queueSize,
+		queues:    make(map[string]*queue),
+		bindings:  make(map[binding][]*queue),
+	}
+}
+
+// declare creates the queue if needed and binds it, the producer and core both declare the same queues
+func (b *Broker) declare(queueName, exchange, routingKey string) *queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		q = &queue{
+			name:       queueName,
+			deliveries: make(chan amqp.Delivery, b.queueSize),
+			unacked:    make(map[uint64]amqp.Delivery),
+		}
+		b.queues[queueName] = q
+	}
+
+	key := binding{exchange: exchange, routingKey: routingKey}
+	for _, bound := range b.bindings[key] {
+		if bound == q {
+			return q
+		}
+	}
+	b.bindings[key] = append(b.bindings[key], q)
+
+	return q
+}
+
+func (b *Broker) publish(exchange, routingKey string, headers amqp.Table, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	queues := b.bindings[binding{exchange: exchange, routingKey: routingKey}]
+	b.mu.Unlock()
+
+	for _, q := range queues {
+		q.push(amqp.Delivery{
+			ContentType: "application/json",
+			Timestamp:   time.Now(),
+			Headers:     headers,
+			Body:        body,
+		})
+	}
+
+	return nil
+}
+
+func (b *Broker) consume(queueName string) <-chan amqp.Delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil
+	}
+	q.consumed = true
+
+	return q.deliveries
+}
+
+func (b *Broker) purge(queueNames ...string) int {
+	purged := 0
+	for _, name := range queueNames {
+		b.mu.Lock()
+		q, ok := b.queues[name]
+		b.mu.Unlock()
+
+		if ok {
+			purged += q.purge()
+		}
+	}
+
+	return purged
+}
+
+// pending counts the messages of the queues that are not acked yet, buffered or handed to a worker
+func (b *Broker) pending(queueNames ...string) int {
+	pending := 0
+	for _, name := range queueNames {
+		b.mu.Lock()
+		q, ok := b.queues[name]
+		b.mu.Unlock()
+
+		if ok {
+			q.mu.Lock()
+			pending += len(q.unacked)
+			q.mu.Unlock()
+		}
+	}
+
+	return pending
+}
+
+type queue struct {
+	name       string
+	deliveries chan amqp.Delivery
+
+	mu       sync.Mutex
+	consumed bool
+	nextTag  uint64
+	unacked  map[uint64]amqp.Delivery
+}
+
+// push hands the delivery to the consumer. Nothing reads a queue core does not consume (internal
+// transactions and transaction actions), their messages are dropped instead of filling the buffer.
+func (q *queue) push(delivery amqp.Delivery) {
+	q.mu.Lock()
+	if !q.consumed {
+		q.mu.Unlock()
+		return
+	}
+	q.nextTag++
+	delivery.Acknowledger = q
+	delivery.DeliveryTag = q.nextTag
+	q.unacked[delivery.DeliveryTag] = delivery
+	q.mu.Unlock()
+
+	q.deliveries <- delivery
+}
+
+// purge drops the messages waiting in the buffer, deliveries already handed to a worker are left to it
+func (q *queue) purge() int {
+	purged := 0
+	for {
+		select {
+		case delivery := <-q.deliveries:
+			q.mu.Lock()
+			delete(q.unacked, delivery.DeliveryTag)
+			q.mu.Unlock()
+			purged++
+		default:
+			return purged
+		}
+	}
+}
+
+// settle removes the acknowledged deliveries, tag and every earlier one when multiple is set
+func (q *queue) settle(tag uint64, multiple bool) []amqp.Delivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var settled []amqp.Delivery
+	for deliveryTag, delivery := range q.unacked {
+		if deliveryTag == tag || (multiple && deliveryTag < tag) {
+			settled = append(settled, delivery)
+			delete(q.unacked, deliveryTag)
+		}
+	}
+
+	return settled
+}
+
+func (q *queue) Ack(tag uint64, multiple bool) error {
+	q.settle(tag, multiple)
+	return nil
+}
+
+func (q *queue) Nack(tag uint64, multiple bool, requeue bool) error {
+	settled := q.settle(tag, multiple)
+	if requeue {
+		// the worker nacking is the one reading the channel, requeue without waiting on it.
+		// The headers are kept, workers compare the reset generation they carry with the last reset.
+		go func() {
+			for _, delivery := range settled {
+				delivery.Redelivered = true
+				q.push(delivery)
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (q *queue) Reject(tag uint64, requeue bool) error {
+	return q.Nack(tag, false, requeue)
+}
@@ -0,0 +1,183 @@
+package broker
+
+import (
+	"testing"
+	"time"
+
+	corerabbitmq "github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
+	"github.com/elmiringos/indexer/producer/pkg/rabbitmq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockMessage struct {
+	Number int `json:"number"`
+}
+
+// newPipeline declares the block queue on both sides like the producer and core do at startup
+func newPipeline(t *testing.T, queueSize int) (*Broker, *Publisher, *Consumer, <-chan amqp.Delivery) {
+	t.Helper()
+
+	b := New(queueSize)
+	publisher := NewPublisher(b)
+	consumer := NewConsumer(b)
+
+	_, err := publisher.MakeNewQueueAndExchange(rabbitmq.BlockExchange, rabbitmq.BlockRoute, rabbitmq.BlockQueue)
+	require.NoError(t, err)
+	_, err = consumer.MakeNewQueueAndExchange(corerabbitmq.BlockQueue, corerabbitmq.BlockExchange, corerabbitmq.BlockRoute)
+	require.NoError(t, err)
+
+	deliveries := consumer.Consume(corerabbitmq.BlockQueue)
+	require.NotNil(t, deliveries)
+
+	return b, publisher, consumer, deliveries
+}
+
+func publishBlock(t *testing.T, publisher *Publisher, number int) {
+	t.Helper()
+
+	headers := amqp.Table{rabbitmq.ResetGenerationHeader: int64(2)}
+	require.NoError(t, publisher.PublishMessage(nil, rabbitmq.BlockExchange, rabbitmq.BlockRoute, headers, blockMessage{Number: number}))
+}
+
+func receive(t *testing.T, deliveries <-chan amqp.Delivery) amqp.Delivery {
+	t.Helper()
+
+	select {
+	case delivery := <-deliveries:
+		return delivery
+	case <-time.After(time.Second):
+		t.Fatal("no delivery")
+		return amqp.Delivery{}
+	}
+}
+
+func pending(t *testing.T, consumer *Consumer) int {
+	t.Helper()
+
+	count, err := consumer.PendingMessages(corerabbitmq.BlockQueue)
+	require.NoError(t, err)
+	return count
+}
+
+func TestBroker_Ack(t *testing.T) {
+	_, publisher, consumer, deliveries := newPipeline(t, 4)
+
+	publishBlock(t, publisher, 1)
+	publishBlock(t, publisher, 2)
+	assert.Equal(t, 2, pending(t, consumer))
+
+	first := receive(t, deliveries)
+	assert.JSONEq(t, `{"number":1}`, string(first.Body))
+	assert.Equal(t, "application/json", first.ContentType)
+	assert.Equal(t, int64(2), first.Headers[corerabbitmq.ResetGenerationHeader])
+	assert.False(t, first.Redelivered)
+
+	// a delivery handed to a worker stays pending until it is acked
+	assert.Equal(t, 2, pending(t, consumer))
+	require.NoError(t, first.Ack(false))
+	assert.Equal(t, 1, pending(t, consumer))
+
+	second := receive(t, deliveries)
+	assert.JSONEq(t, `{"number":2}`, string(second.Body))
+	require.NoError(t, second.Ack(false))
+	assert.Equal(t, 0, pending(t, consumer))
+}
+
+func TestBroker_AckMultiple(t *testing.T) {
+	_, publisher, consumer, deliveries := newPipeline(t, 4)
+
+	for number := 1; number <= 3; number++ {
+		publishBlock(t, publisher, number)
+	}
+	receive(t, deliveries)
+	second := receive(t, deliveries)
+
+	require.NoError(t, second.Ack(true))
+	assert.Equal(t, 1, pending(t, consumer))
+}
+
+func TestBroker_NackRequeue(t *testing.T) {
+	_, publisher, consumer, deliveries := newPipeline(t, 4)
+
+	publishBlock(t, publisher, 1)
+	delivery := receive(t, deliveries)
+	require.NoError(t, delivery.Nack(false, true))
+
+	redelivered := receive(t, deliveries)
+	assert.True(t, redelivered.Redelivered)
+	assert.JSONEq(t, `{"number":1}`, string(redelivered.Body))
+	// workers compare the generation of a requeued message with the last reset
+	assert.Equal(t, int64(2), redelivered.Headers[corerabbitmq.ResetGenerationHeader])
+	assert.NotEqual(t, delivery.DeliveryTag, redelivered.DeliveryTag)
+	assert.Equal(t, 1, pending(t, consumer))
+
+	require.NoError(t, redelivered.Ack(false))
+	assert.Equal(t, 0, pending(t, consumer))
+}
+
+func TestBroker_NackDrop(t *testing.T) {
+	_, publisher, consumer, deliveries := newPipeline(t, 4)
+
+	publishBlock(t, publisher, 1)
+	require.NoError(t, receive(t, deliveries).Reject(false))
+	assert.Equal(t, 0, pending(t, consumer))
+
+	select {
+	case delivery := <-deliveries:
+		t.Fatalf("rejected delivery came back: %s", delivery.Body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBroker_Purge(t *testing.T) {
+	_, publisher, consumer, deliveries := newPipeline(t, 4)
+
+	for number := 1; number <= 3; number++ {
+		publishBlock(t, publisher, number)
+	}
+	inFlight := receive(t, deliveries)
+
+	// the buffered messages are dropped, the one a worker holds is left to it
+	purged, err := consumer.PurgeQueues(corerabbitmq.BlockQueue, corerabbitmq.TransactionQueue)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.Equal(t, 1, pending(t, consumer))
+	assert.Empty(t, deliveries)
+
+	require.NoError(t, inFlight.Ack(false))
+	assert.Equal(t, 0, pending(t, consumer))
+
+	publishBlock(t, publisher, 4)
+	assert.JSONEq(t, `{"number":4}`, string(receive(t, deliveries).Body))
+}
+
+func TestBroker_UnconsumedQueue(t *testing.T) {
+	b, publisher, _, _ := newPipeline(t, 1)
+
+	// core never consumes internal transactions, publishing to them must not block the producer
+	_, err := publisher.MakeNewQueueAndExchange(rabbitmq.InternalTransactionExchange, rabbitmq.InternalTransactionRoute, rabbitmq.InternalTransactionQueue)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, publisher.PublishMessage(nil, rabbitmq.InternalTransactionExchange, rabbitmq.InternalTransactionRoute, amqp.Table{}, blockMessage{Number: i}))
+	}
+
+	assert.Equal(t, 0, b.pending(string(rabbitmq.InternalTransactionQueue)))
+	assert.Nil(t, b.consume("unknown"))
+}
+
+func TestBroker_RoutesByBinding(t *testing.T) {
+	b, publisher, consumer, deliveries := newPipeline(t, 4)
+
+	// a routing key nothing is bound with reaches no queue
+	require.NoError(t, publisher.PublishMessage(nil, rabbitmq.BlockExchange, rabbitmq.TransactionRoute, amqp.Table{}, blockMessage{Number: 1}))
+	assert.Equal(t, 0, pending(t, consumer))
+
+	// declaring the queue again does not bind it twice
+	b.declare(string(rabbitmq.BlockQueue), string(rabbitmq.BlockExchange), string(rabbitmq.BlockRoute))
+	publishBlock(t, publisher, 2)
+	receive(t, deliveries)
+	assert.Empty(t, deliveries)
+}
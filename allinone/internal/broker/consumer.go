@@ -0,0 +1,47 @@
+package broker
+
+import (
+	"github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Consumer is the core side of the broker, it implements rabbitmq.MessageConsumer of core
+type Consumer struct {
+	broker *Broker
+}
+
+func NewConsumer(broker *Broker) *Consumer {
+	return &Consumer{broker: broker}
+}
+
+func (c *Consumer) MakeNewQueueAndExchange(queueType rabbitmq.QueueType, exchange rabbitmq.ExchangeName, routingKey rabbitmq.RoutingKey) (*amqp.Queue, error) {
+	q := c.broker.declare(string(queueType), string(exchange), string(routingKey))
+	return &amqp.Queue{Name: q.name}, nil
+}
+
+// Consume returns the deliveries of a declared queue, nil for an unknown one
+func (c *Consumer) Consume(queue rabbitmq.QueueType) <-chan amqp.Delivery {
+	return c.broker.consume(string(queue))
+}
+
+func (c *Consumer) Ping() error {
+	return nil
+}
+
+func (c *Consumer) PendingMessages(queues ...rabbitmq.QueueType) (int, error) {
+	return c.broker.pending(queueNames(queues)...), nil
+}
+
+func (c *Consumer) PurgeQueues(queues ...rabbitmq.QueueType) (int, error) {
+	return c.broker.purge(queueNames(queues)...), nil
+}
+
+func queueNames(queues []rabbitmq.QueueType) []string {
+	names := make([]string, len(queues))
+	for i, queue := range queues {
+		names[i] = string(queue)
+	}
+
+	return names
+}
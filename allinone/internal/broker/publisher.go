@@ -0,0 +1,30 @@
+package broker
+
+import (
+	"github.com/elmiringos/indexer/producer/pkg/rabbitmq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Publisher is the producer side of the broker, it implements rabbitmq.MessagePublisher of the producer
+type Publisher struct {
+	broker *Broker
+}
+
+func NewPublisher(broker *Broker) *Publisher {
+	return &Publisher{broker: broker}
+}
+
+// CreateChannel returns nil, messages do not go through channels in process
+func (p *Publisher) CreateChannel() *amqp.Channel {
+	return nil
+}
+
+func (p *Publisher) MakeNewQueueAndExchange(exchange rabbitmq.ExchangeName, routingKey rabbitmq.RoutingKey, queueType rabbitmq.QueueType) (*amqp.Queue, error) {
+	q := p.broker.declare(string(queueType), string(exchange), string(routingKey))
+	return &amqp.Queue{Name: q.name}, nil
+}
+
+func (p *Publisher) PublishMessage(_ *amqp.Channel, exchange rabbitmq.ExchangeName, routingKey rabbitmq.RoutingKey, headers amqp.Table, message interface{}) error {
+	return p.broker.publish(string(exchange), string(routingKey), headers, message)
+}
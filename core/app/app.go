@@ -0,0 +1,68 @@
+// Package app starts the core service, cmd/api runs it with the store and broker from its config and
+// the all-in-one binary hands it in-process replacements for both
+package app
+
+import (
+	"context"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+	"github.com/elmiringos/indexer/indexer-core/internal/api"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+	"github.com/elmiringos/indexer/indexer-core/pkg/migrator"
+	"github.com/elmiringos/indexer/indexer-core/pkg/pgstore"
+	"github.com/elmiringos/indexer/indexer-core/pkg/postgres"
+	"github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
+	"github.com/elmiringos/indexer/indexer-core/pkg/redis"
+	"github.com/elmiringos/indexer/indexer-core/pkg/sqlite"
+
+	"go.uber.org/zap"
+)
+
+// Options replaces the connections core opens from its config, nil fields are created from it
+type Options struct {
+	Store    api.Store
+	Consumer rabbitmq.MessageConsumer
+}
+
+// Run serves core until ctx is cancelled
+func Run(ctx context.Context, cfg *config.Config, log *zap.Logger, opts Options) {
+	if cfg.Database.AutoMigrate {
+		migrate(cfg, log)
+	}
+
+	var db api.Database
+	if cfg.Database.Driver == config.DriverSQLite {
+		db = sqlite.NewSQLiteConnection(cfg, log)
+	} else {
+		db = postgres.NewPostgresConnection(cfg, log)
+	}
+
+	if opts.Store == nil {
+		switch cfg.Store.Driver {
+		case config.StoreMemory:
+			opts.Store = memstore.New()
+		case config.StorePostgres:
+			opts.Store = pgstore.New(db.GetDb())
+		default:
+			opts.Store = redis.NewClient(cfg, log)
+		}
+	}
+	if opts.Consumer == nil {
+		opts.Consumer = rabbitmq.NewConsumer(cfg.RMQ.URL)
+	}
+
+	server := api.NewServer(cfg, db, opts.Store, opts.Consumer, log)
+	server.Start(ctx)
+}
+
+func migrate(cfg *config.Config, log *zap.Logger) {
+	m, err := migrator.New(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to open migrations", zap.Error(err))
+	}
+	defer m.Close()
+
+	if err := m.Up(0); err != nil {
+		log.Fatal("Failed to apply migrations", zap.Error(err))
+	}
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/elmiringos/indexer/indexer-core/app"
 	"github.com/elmiringos/indexer/indexer-core/config"
-	"github.com/elmiringos/indexer/indexer-core/internal/api"
 	"github.com/elmiringos/indexer/indexer-core/pkg/logger"
-	"github.com/elmiringos/indexer/indexer-core/pkg/postgres"
-	"github.com/elmiringos/indexer/indexer-core/pkg/redis"
 )
 
 func main() {
@@ -16,9 +19,13 @@ func main() {
 
 	logger := logger.New(cfg)
 
-	db := postgres.NewPostgresConnection(cfg, logger)
-	redis := redis.NewClient(cfg, logger)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, logger, os.Args[2:])
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	server := api.NewServer(cfg, db, redis, logger)
-	server.Start()
+	app.Run(ctx, cfg, logger, app.Options{})
 }
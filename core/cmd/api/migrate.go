@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+	"github.com/elmiringos/indexer/indexer-core/pkg/migrator"
+
+	"go.uber.org/zap"
+)
+
+const migrateUsage = `usage: core migrate <command>
+
+commands:
+  up [N]          apply N pending migrations, all of them by default
+  down [N]        revert N migrations, 1 by default
+  status          print the applied and the latest embedded version
+  force VERSION   set the version without migrating, to recover from a failed migration
+`
+
+// runMigrate runs the migrate subcommand and exits
+func runMigrate(cfg *config.Config, log *zap.Logger, args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	m, err := migrator.New(cfg, log)
+	if err != nil {
+		log.Fatal("Failed to open migrations", zap.Error(err))
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up(parseCount(args[1:], 0))
+	case "down":
+		err = m.Down(parseCount(args[1:], 1))
+	case "force":
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, migrateUsage)
+			os.Exit(2)
+		}
+		err = m.Force(parseCount(args[1:], 0))
+	case "status":
+		var status *migrator.Status
+		if status, err = m.Status(); err == nil {
+			fmt.Printf("database: %s\nversion:  %d\ndirty:    %t\nlatest:   %d\n", cfg.Database.Driver, status.Version, status.Dirty, status.Latest)
+		}
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		m.Close()
+		log.Fatal("Migration failed", zap.Error(err))
+	}
+}
+
+func parseCount(args []string, fallback int) int {
+	if len(args) == 0 {
+		return fallback
+	}
+
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count < 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	return count
+}
@@ -1,20 +1,46 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
 
+// Database drivers selectable in the config
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Coordination stores selectable in the config
+const (
+	StoreRedis    = "redis"
+	StoreMemory   = "memory"
+	StorePostgres = "postgres"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
 type (
 	Config struct {
-		Server `yaml:"server"`
-		HTTP   `yaml:"http"`
-		Logger `yaml:"logger"`
+		Server     `yaml:"server"`
+		HTTP       `yaml:"http"`
+		Monitoring `yaml:"monitoring"`
+		Health     `yaml:"health"`
+		GapRepair  `yaml:"gap_repair"`
+		Verifier   `yaml:"verifier"`
+		Batch      `yaml:"batch"`
+		Logger     `yaml:"logger"`
+		Database   `yaml:"database"`
+		Store      `yaml:"store"`
 		PG
 		Redis
 		JWT
+		Admin
 		RMQ `yaml:"rabbitmq"`
 	}
 
@@ -29,22 +55,69 @@ type (
 		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
 	}
 
+	Monitoring struct {
+		Port string `env-required:"true" yaml:"port" env:"MONITORING_PORT"`
+	}
+
+	Health struct {
+		CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" env-default:"2s"`
+	}
+
+	GapRepair struct {
+		Enabled         bool          `yaml:"enabled"            env:"GAP_REPAIR_ENABLED"`
+		ProducerURL     string        `yaml:"producer_url"       env:"PRODUCER_CONTROL_URL"`
+		Interval        time.Duration `yaml:"interval"           env:"GAP_REPAIR_INTERVAL"           env-default:"1m"`
+		Cooldown        time.Duration `yaml:"cooldown"           env:"GAP_REPAIR_COOLDOWN"           env-default:"10m"`
+		SettleBlocks    uint64        `yaml:"settle_blocks"      env:"GAP_REPAIR_SETTLE_BLOCKS"      env-default:"64"`
+		MaxRange        uint64        `yaml:"max_range"          env:"GAP_REPAIR_MAX_RANGE"          env-default:"1000"`
+		MaxBlocksPerRun uint64        `yaml:"max_blocks_per_run" env:"GAP_REPAIR_MAX_BLOCKS_PER_RUN" env-default:"5000"`
+	}
+
+	Verifier struct {
+		MaxRange uint64 `yaml:"max_range" env:"VERIFIER_MAX_RANGE" env-default:"1000"`
+	}
+
+	// Batch makes workers write up to Size messages in one database transaction, waiting at most
+	// Linger for a batch to fill. A Size of 1 writes every message on its own.
+	Batch struct {
+		Size   int           `yaml:"size"   env:"BATCH_SIZE"   env-default:"1"`
+		Linger time.Duration `yaml:"linger" env:"BATCH_LINGER" env-default:"50ms"`
+	}
+
 	Logger struct {
 		File string `env-required:"false" yaml:"file" env:"LOG_FILE"`
 	}
 
+	// Database selects the storage backend, SQLite keeps everything in one file for small deployments and tests.
+	// AutoMigrate applies the pending embedded migrations on startup.
+	Database struct {
+		Driver      string `yaml:"driver"       env:"DB_DRIVER"       env-default:"postgres"`
+		SQLitePath  string `yaml:"sqlite_path"  env:"SQLITE_PATH"     env-default:"indexer.db"`
+		AutoMigrate bool   `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	}
+
+	// Store selects where processors keep the counters of the children they wait for, memory is
+	// only safe with a single core instance
+	Store struct {
+		Driver string `yaml:"driver" env:"STORE_DRIVER" env-default:"redis"`
+	}
+
 	PG struct {
-		URL string `env-required:"true" env:"PG_URL"`
+		URL string `env:"PG_URL"`
 	}
 
 	Redis struct {
-		URL string `env-required:"true" env:"REDIS_URL"`
+		URL string `env:"REDIS_URL"`
 	}
 
 	JWT struct {
 		SECRET string `env-required:"true" env:"JWT_SECRET"`
 	}
 
+	Admin struct {
+		Token string `env:"ADMIN_TOKEN"`
+	}
+
 	RMQ struct {
 		ServerExchange string `env-required:"true" yaml:"rpc_server_exchange" env:"RMQ_RPC_SERVER"`
 		ClientExchange string `env-required:"true" yaml:"rpc_client_exchange" env:"RMQ_RPC_CLIENT"`
@@ -71,14 +144,37 @@ func NewConfig(configPath, envPath string) (*Config, error) {
 		}
 	}
 
-	err := godotenv.Load(envPath)
+	err := cleanenv.ReadConfig(configPath, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	err = cleanenv.ReadConfig(configPath, cfg)
-	if err != nil {
-		return nil, err
+	switch cfg.Database.Driver {
+	case DriverPostgres:
+		if cfg.PG.URL == "" {
+			return nil, fmt.Errorf("%w: PG_URL is required for the %s driver", ErrInvalidConfig, DriverPostgres)
+		}
+	case DriverSQLite:
+	default:
+		return nil, fmt.Errorf("%w: unknown database driver %q", ErrInvalidConfig, cfg.Database.Driver)
+	}
+
+	if cfg.Batch.Size < 1 {
+		return nil, fmt.Errorf("%w: batch size must be at least 1", ErrInvalidConfig)
+	}
+
+	switch cfg.Store.Driver {
+	case StoreRedis:
+		if cfg.Redis.URL == "" {
+			return nil, fmt.Errorf("%w: REDIS_URL is required for the %s store", ErrInvalidConfig, StoreRedis)
+		}
+	case StoreMemory:
+	case StorePostgres:
+		if cfg.Database.Driver != DriverPostgres {
+			return nil, fmt.Errorf("%w: the %s store needs the %s database driver", ErrInvalidConfig, StorePostgres, DriverPostgres)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown store driver %q", ErrInvalidConfig, cfg.Store.Driver)
 	}
 
 	return cfg, nil
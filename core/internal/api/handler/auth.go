@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/api/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	actorHeader         = "x-admin-actor"
+	bearerPrefix        = "Bearer "
+)
+
+// adminMethods are the RPCs that change or destroy indexed state
+var adminMethods = map[string]bool{
+	pb.CoreService_ResetState_FullMethodName:  true,
+	pb.CoreService_VerifyRange_FullMethodName: true,
+	pb.CoreService_SetBulkMode_FullMethodName: true,
+}
+
+// AdminAuthInterceptor rejects admin RPCs that do not carry the configured admin token.
+// Admin RPCs are disabled altogether when no token is configured.
+func AdminAuthInterceptor(adminToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !adminMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		if adminToken == "" {
+			return nil, status.Error(codes.PermissionDenied, "admin operations are disabled, ADMIN_TOKEN is not configured")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(authorizationHeader)
+		if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+			return nil, status.Error(codes.Unauthenticated, "missing admin token")
+		}
+
+		token := strings.TrimPrefix(values[0], bearerPrefix)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "invalid admin token")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// actorFromContext identifies the caller of an admin RPC for the audit log
+func actorFromContext(ctx context.Context) string {
+	actor := "unknown"
+	if p, ok := peer.FromContext(ctx); ok {
+		actor = p.Addr.String()
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(actorHeader); len(values) > 0 && values[0] != "" {
+			actor = values[0] + "@" + actor
+		}
+	}
+
+	return actor
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	pb "github.com/elmiringos/indexer/indexer-core/internal/api/pb"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/admin"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/bulk"
 )
 
 func MapBlockToCurrentBlockResponse(block *block.Block) *pb.GetCurrentBlockResponse {
@@ -15,3 +18,153 @@ func MapBlockToCurrentBlockResponse(block *block.Block) *pb.GetCurrentBlockRespo
 		BlockHash:   block.Hash.Hex(),
 	}
 }
+
+func MapIndexingStatusToResponse(status *block.IndexingStatus) *pb.GetIndexingStatusResponse {
+	if status == nil {
+		return &pb.GetIndexingStatusResponse{}
+	}
+
+	response := &pb.GetIndexingStatusResponse{
+		HasBlocks:        true,
+		LowestNumber:     status.LowestNumber.Bytes(),
+		HighestNumber:    status.HighestNumber.Bytes(),
+		TotalBlocks:      uint64(status.TotalBlocks),
+		CompleteBlocks:   uint64(status.CompleteBlocks),
+		IncompleteBlocks: uint64(status.IncompleteBlocks),
+		MissingBlocks:    uint64(status.MissingBlocks),
+		LastUpdatedAt:    status.LastUpdatedAt.Unix(),
+	}
+
+	if status.NextNumber.Cmp(status.LowestNumber) > 0 {
+		contiguousNumber := status.NextNumber.Sub(domain.BigIntOne())
+		response.HasContiguous = true
+		response.ContiguousNumber = contiguousNumber.Bytes()
+	}
+
+	return response
+}
+
+func MapGapsToResponse(gaps []*block.Gap, nextPageToken string) *pb.ListGapsResponse {
+	response := &pb.ListGapsResponse{
+		Gaps:          make([]*pb.Gap, 0, len(gaps)),
+		NextPageToken: nextPageToken,
+	}
+
+	for _, gap := range gaps {
+		kind := pb.GapKind_GAP_KIND_UNSPECIFIED
+		switch gap.Kind {
+		case block.GapMissing:
+			kind = pb.GapKind_GAP_KIND_MISSING
+		case block.GapIncomplete:
+			kind = pb.GapKind_GAP_KIND_INCOMPLETE
+		}
+
+		response.Gaps = append(response.Gaps, &pb.Gap{
+			Kind:       kind,
+			FromNumber: gap.FromNumber.Bytes(),
+			ToNumber:   gap.ToNumber.Bytes(),
+		})
+	}
+
+	return response
+}
+
+func MapBlockStatusToResponse(status *block.BlockStatus) *pb.GetBlockStatusResponse {
+	response := &pb.GetBlockStatusResponse{
+		BlockHash:           status.Hash.Hex(),
+		BlockNumber:         status.Number.Bytes(),
+		Complete:            status.Complete(),
+		PendingTransactions: uint64(status.PendingTransactions),
+		PendingWithdrawals:  uint64(status.PendingWithdrawals),
+		PendingRewards:      uint64(status.PendingRewards),
+	}
+
+	for _, transactionStatus := range status.PendingTransactionChildren {
+		response.PendingTransactionChildren = append(response.PendingTransactionChildren, &pb.TransactionStatus{
+			Hash:           transactionStatus.Hash.Hex(),
+			PendingLogs:    uint64(transactionStatus.PendingLogs),
+			PendingActions: uint64(transactionStatus.PendingActions),
+		})
+	}
+
+	return response
+}
+
+func MapResetResultToResponse(result *admin.ResetResult) *pb.ResetStateResponse {
+	return &pb.ResetStateResponse{
+		Success:       true,
+		DeletedBlocks: uint64(result.DeletedBlocks),
+		Generation:    result.Generation,
+	}
+}
+
+func MapIndexedRangeToResponse(indexedRange *block.IndexedRange) *pb.GetIndexedRangeResponse {
+	if indexedRange == nil {
+		return &pb.GetIndexedRangeResponse{}
+	}
+
+	return &pb.GetIndexedRangeResponse{
+		HasBlocks:    true,
+		LowestNumber: indexedRange.LowestNumber.Bytes(),
+		NextNumber:   indexedRange.NextNumber.Bytes(),
+	}
+}
+
+var mismatchKinds = map[block.MismatchKind]pb.MismatchKind{
+	block.MismatchTransactionsRoot:   pb.MismatchKind_MISMATCH_KIND_TRANSACTIONS_ROOT,
+	block.MismatchReceiptsRoot:       pb.MismatchKind_MISMATCH_KIND_RECEIPTS_ROOT,
+	block.MismatchLogsBloom:          pb.MismatchKind_MISMATCH_KIND_LOGS_BLOOM,
+	block.MismatchTransactionsCount:  pb.MismatchKind_MISMATCH_KIND_TRANSACTIONS_COUNT,
+	block.MismatchWithdrawalsCount:   pb.MismatchKind_MISMATCH_KIND_WITHDRAWALS_COUNT,
+	block.MismatchInvalidTransaction: pb.MismatchKind_MISMATCH_KIND_INVALID_TRANSACTION,
+}
+
+func MapVerificationReportToResponse(report *block.VerificationReport) *pb.VerifyRangeResponse {
+	response := &pb.VerifyRangeResponse{
+		CheckedBlocks: uint64(report.CheckedBlocks),
+		SkippedBlocks: uint64(report.SkippedBlocks),
+	}
+
+	for _, mismatch := range report.Mismatches {
+		response.Mismatches = append(response.Mismatches, &pb.BlockMismatch{
+			Kind:        mismatchKinds[mismatch.Kind],
+			BlockNumber: mismatch.BlockNumber.Bytes(),
+			BlockHash:   mismatch.BlockHash.Hex(),
+			Expected:    mismatch.Expected,
+			Actual:      mismatch.Actual,
+		})
+	}
+
+	return response
+}
+
+var bulkModeStates = map[bulk.State]pb.BulkModeState{
+	bulk.StateOff:     pb.BulkModeState_BULK_MODE_STATE_OFF,
+	bulk.StateLoading: pb.BulkModeState_BULK_MODE_STATE_LOADING,
+	bulk.StateMerging: pb.BulkModeState_BULK_MODE_STATE_MERGING,
+}
+
+var bulkMergePhases = map[bulk.Phase]pb.BulkMergePhase{
+	bulk.PhasePrepare:             pb.BulkMergePhase_BULK_MERGE_PHASE_PREPARE,
+	bulk.PhaseMergeTables:         pb.BulkMergePhase_BULK_MERGE_PHASE_MERGE_TABLES,
+	bulk.PhaseRebuildIndexes:      pb.BulkMergePhase_BULK_MERGE_PHASE_REBUILD_INDEXES,
+	bulk.PhaseValidateConstraints: pb.BulkMergePhase_BULK_MERGE_PHASE_VALIDATE_CONSTRAINTS,
+	bulk.PhaseCleanup:             pb.BulkMergePhase_BULK_MERGE_PHASE_CLEANUP,
+}
+
+func MapBulkStatusToProto(status *bulk.Status) *pb.BulkModeStatus {
+	response := &pb.BulkModeStatus{
+		State:      bulkModeStates[status.State],
+		Phase:      bulkMergePhases[status.Progress.Phase],
+		Step:       status.Progress.Step,
+		StepsDone:  uint32(status.Progress.Done),
+		StepsTotal: uint32(status.Progress.Total),
+		MergedRows: uint64(status.Progress.MergedRows),
+		LastError:  status.LastError,
+	}
+	if !status.StartedAt.IsZero() {
+		response.MergeStartedAt = status.StartedAt.Unix()
+	}
+
+	return response
+}
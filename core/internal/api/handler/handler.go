@@ -1,26 +1,45 @@
-//go:generate protoc --go_out=. --go-grpc_out=. ../proto/core.proto
+//go:generate protoc --go_out=. --go-grpc_out=. --proto_path=../proto ../proto/core.proto ../proto/producer.proto
 
 package handler
 
 import (
 	"context"
+	"errors"
+	"math/big"
 
 	"github.com/elmiringos/indexer/indexer-core/internal/api/pb"
 	"github.com/elmiringos/indexer/indexer-core/internal/api/service"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CoreHandler struct {
-	coreService *service.CoreService
-	logger      *zap.Logger
+	coreService     *service.CoreService
+	resetService    *service.ResetService
+	verifierService *service.VerifierService
+	bulkService     *service.BulkLoadService
+	logger          *zap.Logger
 	pb.UnimplementedCoreServiceServer
 }
 
-func NewCoreHandler(coreService *service.CoreService, logger *zap.Logger) *CoreHandler {
+func NewCoreHandler(
+	coreService *service.CoreService,
+	resetService *service.ResetService,
+	verifierService *service.VerifierService,
+	bulkService *service.BulkLoadService,
+	logger *zap.Logger,
+) *CoreHandler {
 	return &CoreHandler{
-		coreService: coreService,
-		logger:      logger,
+		coreService:     coreService,
+		resetService:    resetService,
+		verifierService: verifierService,
+		bulkService:     bulkService,
+		logger:          logger,
 	}
 }
 
@@ -33,9 +52,142 @@ func (h *CoreHandler) GetCurrentBlock(ctx context.Context, req *pb.GetCurrentBlo
 	return MapBlockToCurrentBlockResponse(block), nil
 }
 
+func (h *CoreHandler) GetIndexedRange(ctx context.Context, req *pb.GetIndexedRangeRequest) (*pb.GetIndexedRangeResponse, error) {
+	indexedRange, err := h.coreService.GetIndexedRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return MapIndexedRangeToResponse(indexedRange), nil
+}
+
+const (
+	defaultGapsPageSize = 100
+	maxGapsPageSize     = 1000
+)
+
+func (h *CoreHandler) GetIndexingStatus(ctx context.Context, req *pb.GetIndexingStatusRequest) (*pb.GetIndexingStatusResponse, error) {
+	status, err := h.coreService.GetIndexingStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return MapIndexingStatusToResponse(status), nil
+}
+
+// ListGaps pages through gaps by their first block, the page token is the first block of the last gap returned
+func (h *CoreHandler) ListGaps(ctx context.Context, req *pb.ListGapsRequest) (*pb.ListGapsResponse, error) {
+	pageSize := int(req.PageSize)
+	if pageSize == 0 {
+		pageSize = defaultGapsPageSize
+	}
+	if pageSize > maxGapsPageSize {
+		pageSize = maxGapsPageSize
+	}
+
+	var after *domain.BigInt
+	if req.PageToken != "" {
+		number, ok := new(big.Int).SetString(req.PageToken, 10)
+		if !ok || number.Sign() < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		after = (*domain.BigInt)(number)
+	}
+
+	// fetch one extra gap to know whether another page exists
+	gaps, err := h.coreService.ListGaps(ctx, after, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	nextPageToken := ""
+	if len(gaps) > pageSize {
+		gaps = gaps[:pageSize]
+		nextPageToken = gaps[pageSize-1].FromNumber.String()
+	}
+
+	return MapGapsToResponse(gaps, nextPageToken), nil
+}
+
+func (h *CoreHandler) GetBlockStatus(ctx context.Context, req *pb.GetBlockStatusRequest) (*pb.GetBlockStatusResponse, error) {
+	if !isHexHash(req.BlockHash) {
+		return nil, status.Error(codes.InvalidArgument, "invalid block hash")
+	}
+
+	blockStatus, err := h.coreService.GetBlockStatus(ctx, common.HexToHash(req.BlockHash))
+	if err != nil {
+		return nil, err
+	}
+
+	if blockStatus == nil {
+		return nil, status.Error(codes.NotFound, "block is not indexed")
+	}
+
+	return MapBlockStatusToResponse(blockStatus), nil
+}
+
+func isHexHash(s string) bool {
+	data, err := hexutil.Decode(s)
+	return err == nil && len(data) == common.HashLength
+}
+
 func (h *CoreHandler) ResetState(ctx context.Context, req *pb.ResetStateRequest) (*pb.ResetStateResponse, error) {
-	state := true
-	return &pb.ResetStateResponse{
-		Success: state,
-	}, nil
+	var fromBlock *domain.BigInt
+	if req.HasFromBlock {
+		fromBlock = domain.FromBytesToBigInt(req.FromBlockNumber)
+	}
+
+	result, err := h.resetService.ResetState(ctx, fromBlock, actorFromContext(ctx), req.Reason)
+	if err != nil {
+		if errors.Is(err, service.ErrBulkModeActive) || errors.Is(err, service.ErrPipelineNotDrained) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return MapResetResultToResponse(result), nil
+}
+
+func (h *CoreHandler) GetResetGeneration(ctx context.Context, req *pb.GetResetGenerationRequest) (*pb.GetResetGenerationResponse, error) {
+	return &pb.GetResetGenerationResponse{Generation: h.resetService.Generation()}, nil
+}
+
+func (h *CoreHandler) VerifyRange(ctx context.Context, req *pb.VerifyRangeRequest) (*pb.VerifyRangeResponse, error) {
+	from := domain.FromBytesToBigInt(req.FromNumber)
+	to := domain.FromBytesToBigInt(req.ToNumber)
+
+	report, err := h.verifierService.VerifyRange(ctx, from, to)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidVerifyRange) || errors.Is(err, service.ErrVerifyRangeTooLarge) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return MapVerificationReportToResponse(report), nil
+}
+
+func (h *CoreHandler) SetBulkMode(ctx context.Context, req *pb.SetBulkModeRequest) (*pb.SetBulkModeResponse, error) {
+	setBulkMode := h.bulkService.Disable
+	if req.Enabled {
+		setBulkMode = h.bulkService.Enable
+	}
+
+	bulkStatus, err := setBulkMode(ctx, actorFromContext(ctx), req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBulkModeUnsupported):
+			return nil, status.Error(codes.Unimplemented, err.Error())
+		case errors.Is(err, service.ErrBulkModeActive), errors.Is(err, service.ErrBulkModeInactive), errors.Is(err, service.ErrBulkMergeRunning):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.SetBulkModeResponse{Status: MapBulkStatusToProto(bulkStatus)}, nil
+}
+
+func (h *CoreHandler) GetBulkModeStatus(ctx context.Context, req *pb.GetBulkModeStatusRequest) (*pb.GetBulkModeStatusResponse, error) {
+	bulkStatus := h.bulkService.Status()
+	return &pb.GetBulkModeStatusResponse{Status: MapBulkStatusToProto(&bulkStatus)}, nil
 }
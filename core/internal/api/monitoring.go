@@ -0,0 +1,31 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/elmiringos/indexer/indexer-core/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+// startMonitoringServer serves the operational HTTP endpoints next to the gRPC server
+func (s *Server) startMonitoringServer() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", s.checker.LivenessHandler())
+	mux.Handle("/readyz", s.checker.ReadinessHandler())
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%s", s.cfg.Monitoring.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	s.log.Info("Monitoring server started on " + server.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.Fatal("monitoring server failed", zap.Error(err))
+	}
+}
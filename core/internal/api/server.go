@@ -1,56 +1,95 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net"
-	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
 	"github.com/elmiringos/indexer/indexer-core/config"
 	"github.com/elmiringos/indexer/indexer-core/internal/api/handler"
 	"github.com/elmiringos/indexer/indexer-core/internal/api/pb"
 	"github.com/elmiringos/indexer/indexer-core/internal/api/service"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/admin"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/bulk"
+	smartcontract "github.com/elmiringos/indexer/indexer-core/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/token"
 	"github.com/elmiringos/indexer/indexer-core/internal/infrastructure/repository"
-	"github.com/elmiringos/indexer/indexer-core/pkg/postgres"
+	grpcproducerclient "github.com/elmiringos/indexer/indexer-core/pkg/grpc_producer_client"
+	"github.com/elmiringos/indexer/indexer-core/pkg/health"
 	"github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
-	"github.com/elmiringos/indexer/indexer-core/pkg/redis"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
+// Store keeps the counters processors use to wait for the children of a block,
+// redis.Client, memstore.Store and pgstore.Store implement it
+type Store interface {
+	repository.KVStorage
+	Reset() error
+	Ping(ctx context.Context) error
+}
+
+// Database is the connection the repositories run on, postgres.Connection and sqlite.Connection implement it
+type Database interface {
+	GetDb() *sql.DB
+	Ping(ctx context.Context) error
+}
+
 type Server struct {
 	cfg      *config.Config
-	db       *postgres.Connection
+	db       Database
 	log      *zap.Logger
 	handler  *handler.CoreHandler
-	consumer *rabbitmq.Consumer
+	consumer rabbitmq.MessageConsumer
+	checker  *health.Checker
 }
 
 // NewServer initializes the gRPC server
-func NewServer(cfg *config.Config, db *postgres.Connection, redis *redis.Client, logger *zap.Logger) *Server {
+func NewServer(cfg *config.Config, db Database, store Store, consumer rabbitmq.MessageConsumer, logger *zap.Logger) *Server {
 	// Initialize repositories
-	blockRepository := repository.NewBlockRepository(db.GetDb(), redis, logger)
-	internalTransactionRepository := repository.NewInternalTransactionRepository(db.GetDb(), redis)
-	rewardRepository := repository.NewRewardRepository(db.GetDb(), redis)
-	smartContractRepository := repository.NewSmartContractRepository(db.GetDb(), redis)
-	tokenRepository := repository.NewTokenRepository(db.GetDb(), redis)
-	transactionRepository := repository.NewTransactionRepository(db.GetDb(), redis, logger)
-	withdrawalRepository := repository.NewWithdrawalRepository(db.GetDb())
-
-	// Initialize consumer to message broker
-	consumer := rabbitmq.NewConsumer(cfg.RMQ.URL)
+	var (
+		blockRepository         block.Repository
+		smartContractRepository smartcontract.Repository
+		tokenRepository         token.Repository
+		adminRepository         admin.Repository
+		transactionRepository   *repository.TransactionRepository
+		withdrawalRepository    *repository.WithdrawalRepository
+	)
+	// SQLite variants override the queries that use Postgres only syntax
+	if cfg.Database.Driver == config.DriverSQLite {
+		blockRepository = repository.NewSQLiteBlockRepository(db.GetDb(), store, logger)
+		smartContractRepository = repository.NewSQLiteSmartContractRepository(db.GetDb(), store)
+		tokenRepository = repository.NewSQLiteTokenRepository(db.GetDb(), store)
+		adminRepository = repository.NewSQLiteAdminRepository(db.GetDb(), store)
+		transactionRepository = repository.NewSQLiteTransactionRepository(db.GetDb(), store, logger)
+		withdrawalRepository = repository.NewSQLiteWithdrawalRepository(db.GetDb())
+	} else {
+		blockRepository = repository.NewBlockRepository(db.GetDb(), store, logger)
+		smartContractRepository = repository.NewSmartContractRepository(db.GetDb(), store)
+		tokenRepository = repository.NewTokenRepository(db.GetDb(), store)
+		adminRepository = repository.NewAdminRepository(db.GetDb(), store)
+		transactionRepository = repository.NewTransactionRepository(db.GetDb(), store, logger)
+		withdrawalRepository = repository.NewWithdrawalRepository(db.GetDb())
+	}
+	internalTransactionRepository := repository.NewInternalTransactionRepository(db.GetDb(), store)
+	rewardRepository := repository.NewRewardRepository(db.GetDb(), store)
+
+	// Initialize queues on the message broker
 	initializeQueues(consumer, logger)
 
 	// Initialize queue channels and get messages
-	blockMessages := consumer.Consume(consumer.CreateChannel(), rabbitmq.BlockQueue)
-	transactionMessages := consumer.Consume(consumer.CreateChannel(), rabbitmq.TransactionQueue)
-	transactionLogMessages := consumer.Consume(consumer.CreateChannel(), rabbitmq.TransactionLogQueue)
-	rewardMessages := consumer.Consume(consumer.CreateChannel(), rabbitmq.RewardQueue)
-	withdrawalMessages := consumer.Consume(consumer.CreateChannel(), rabbitmq.WithdrawalQueue)
-	tokenEventMessages := consumer.Consume(consumer.CreateChannel(), rabbitmq.TokenEventQueue)
+	blockMessages := consumer.Consume(rabbitmq.BlockQueue)
+	transactionMessages := consumer.Consume(rabbitmq.TransactionQueue)
+	transactionLogMessages := consumer.Consume(rabbitmq.TransactionLogQueue)
+	rewardMessages := consumer.Consume(rabbitmq.RewardQueue)
+	withdrawalMessages := consumer.Consume(rabbitmq.WithdrawalQueue)
+	tokenEventMessages := consumer.Consume(rabbitmq.TokenEventQueue)
 
 	// Initialize service
 	coreService := service.NewCoreService(
@@ -64,51 +103,105 @@ func NewServer(cfg *config.Config, db *postgres.Connection, redis *redis.Client,
 		withdrawalRepository,
 	)
 
+	// Workers pause on the gate while an admin operation resets state
+	gate := service.NewPipelineGate()
+	generation, err := adminRepository.GetResetGeneration(context.Background())
+	if err != nil {
+		logger.Fatal("failed to load reset generation", zap.Error(err))
+	}
+	gate.SetGeneration(generation)
+
+	// Bulk mode stages writes in Postgres schemas, the SQLite connection cannot switch the search path
+	var (
+		bulkRepository bulk.Repository
+		searchPath     service.SearchPath
+	)
+	if conn, ok := db.(service.SearchPath); ok {
+		bulkRepository = repository.NewBulkLoadRepository(db.GetDb())
+		searchPath = conn
+	}
+	bulkService := service.NewBulkLoadService(gate, bulkRepository, adminRepository, searchPath, logger)
+	if err := bulkService.Restore(context.Background()); err != nil {
+		logger.Fatal("failed to restore bulk mode", zap.Error(err))
+	}
+
+	resetService := service.NewResetService(gate, adminRepository, store, consumer, bulkService, logger)
+
+	verifierService := service.NewVerifierService(blockRepository, transactionRepository, withdrawalRepository, cfg.Verifier.MaxRange, logger)
+
 	// Initialize handler
-	coreHandler := handler.NewCoreHandler(coreService, logger)
+	coreHandler := handler.NewCoreHandler(coreService, resetService, verifierService, bulkService, logger)
+
+	// Initialize worker pools and processors, processors supporting it write messages in batches
+	batch := service.BatchConfig{Size: cfg.Batch.Size, Linger: cfg.Batch.Linger}
 
-	// Initialize worker pools and processors
 	// Block processor
 	blockProcessor := service.NewBlockProcessor(blockRepository, logger)
-	blockWorkerPool := service.NewWorkerPool(blockProcessor, logger, cfg.Server.Worker)
+	blockWorkerPool := service.NewWorkerPool(string(rabbitmq.BlockQueue), blockProcessor, gate, logger, cfg.Server.Worker, batch)
 	go blockWorkerPool.Start(blockMessages)
 
 	// Transaction processor
 	transactionProcessor := service.NewTransactionProcessor(blockRepository, transactionRepository, logger)
-	transactionWorkerPool := service.NewWorkerPool(transactionProcessor, logger, cfg.Server.Worker)
+	transactionWorkerPool := service.NewWorkerPool(string(rabbitmq.TransactionQueue), transactionProcessor, gate, logger, cfg.Server.Worker, batch)
 	go transactionWorkerPool.Start(transactionMessages)
 
 	// // Transaction Log processor
 	transactionLogProcessor := service.NewTransactionLogProcessor(transactionRepository, logger)
-	transactionLogWorkerPool := service.NewWorkerPool(transactionLogProcessor, logger, cfg.Server.Worker)
+	transactionLogWorkerPool := service.NewWorkerPool(string(rabbitmq.TransactionLogQueue), transactionLogProcessor, gate, logger, cfg.Server.Worker, batch)
 	go transactionLogWorkerPool.Start(transactionLogMessages)
 
 	// Reward processor
 	rewardProcessor := service.NewRewardProcessor(blockRepository, rewardRepository, logger)
-	rewardWorkerPool := service.NewWorkerPool(rewardProcessor, logger, cfg.Server.Worker)
+	rewardWorkerPool := service.NewWorkerPool(string(rabbitmq.RewardQueue), rewardProcessor, gate, logger, cfg.Server.Worker, batch)
 	go rewardWorkerPool.Start(rewardMessages)
 
 	// Withdrawal processor
 	withdrawalProcessor := service.NewWithdrawalProcessor(blockRepository, withdrawalRepository, logger)
-	withdrawalWorkerPool := service.NewWorkerPool(withdrawalProcessor, logger, cfg.Server.Worker)
+	withdrawalWorkerPool := service.NewWorkerPool(string(rabbitmq.WithdrawalQueue), withdrawalProcessor, gate, logger, cfg.Server.Worker, batch)
 	go withdrawalWorkerPool.Start(withdrawalMessages)
 
 	// Token event processor
 	tokenEventProcessor := service.NewTokenProccesor(tokenRepository, smartContractRepository, logger)
-	tokenEventWorkerPool := service.NewWorkerPool(tokenEventProcessor, logger, cfg.Server.Worker)
+	tokenEventWorkerPool := service.NewWorkerPool(string(rabbitmq.TokenEventQueue), tokenEventProcessor, gate, logger, cfg.Server.Worker, batch)
 	go tokenEventWorkerPool.Start(tokenEventMessages)
 
+	// Gap detector asking the producer to reindex missing or incomplete blocks
+	if cfg.GapRepair.Enabled {
+		producerClient, err := grpcproducerclient.NewProducerClient(cfg.GapRepair.ProducerURL)
+		if err != nil {
+			logger.Fatal("failed to create producer client", zap.Error(err))
+		}
+
+		gapDetector := service.NewGapDetector(blockRepository, producerClient, service.GapDetectorConfig{
+			Interval:        cfg.GapRepair.Interval,
+			Cooldown:        cfg.GapRepair.Cooldown,
+			SettleBlocks:    cfg.GapRepair.SettleBlocks,
+			MaxRange:        cfg.GapRepair.MaxRange,
+			MaxBlocksPerRun: cfg.GapRepair.MaxBlocksPerRun,
+		}, logger)
+		go gapDetector.Run(context.Background())
+	}
+
+	// Initialize readiness checks
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	checker.Register(cfg.Database.Driver, db.Ping)
+	checker.Register("store", store.Ping)
+	checker.Register("broker", func(context.Context) error {
+		return consumer.Ping()
+	})
+
 	return &Server{
 		cfg:      cfg,
 		db:       db,
 		log:      logger,
 		handler:  coreHandler,
 		consumer: consumer,
+		checker:  checker,
 	}
 }
 
 // InitializeQueues initializes the queues for the gRPC server if they don't exist
-func initializeQueues(c *rabbitmq.Consumer, log *zap.Logger) {
+func initializeQueues(c rabbitmq.MessageConsumer, log *zap.Logger) {
 	if c == nil {
 		panic("subscriber is not initialized")
 	}
@@ -156,28 +249,26 @@ func initializeQueues(c *rabbitmq.Consumer, log *zap.Logger) {
 	log.Info("queues and exchanges initialized")
 }
 
-// Start the gRPC server
-func (s *Server) Start() {
-	address := fmt.Sprintf(":%s", s.cfg.Port)
+// Start serves the gRPC API until ctx is cancelled, then stops it gracefully
+func (s *Server) Start(ctx context.Context) {
+	go s.startMonitoringServer()
+
+	address := fmt.Sprintf(":%s", s.cfg.HTTP.Port)
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		s.log.Fatal("failed to listen", zap.Error(err))
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(handler.AdminAuthInterceptor(s.cfg.Admin.Token)))
 	pb.RegisterCoreServiceServer(grpcServer, s.handler)
 	reflection.Register(grpcServer)
 
-	s.log.Info("gRPC Server started on " + address)
-	if err := grpcServer.Serve(listener); err != nil {
-		s.log.Fatal("failed to serve", zap.Error(err))
-	}
+	healthServer := health.NewGRPCServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go s.checker.WatchGRPC(ctx, healthServer, 10*time.Second, pb.CoreService_ServiceDesc.ServiceName)
 
-	// Handle OS shutdown signals
 	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-		<-sigChan
+		<-ctx.Done()
 		s.log.Info("Shutting down gRPC server...")
 		grpcServer.GracefulStop()
 	}()
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/pkg/metrics"
 	"go.uber.org/zap"
 )
 
@@ -40,8 +41,46 @@ func (p *BlockProcessor) Process(ctx context.Context, data []byte) error {
 	}
 
 	p.log.Info("Block saved successfully", zap.Any("block_number", block.Number))
+	metrics.ObserveIndexedBlock(block.Number.Uint64())
+
+	return p.saveBlockHashes(ctx, block)
+}
+
+// ProcessBatch saves the blocks in one database transaction, then registers their children like Process
+func (p *BlockProcessor) ProcessBatch(ctx context.Context, batch [][]byte) error {
+	blocks, err := decodeBatch[block.Block](batch)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToUnmarshalBlock, err)
+	}
+
+	if err := p.blockRepository.SaveBlocks(ctx, blocks); err != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToSaveBlock, err)
+	}
+
+	p.log.Info("Blocks saved successfully", zap.Int("count", len(blocks)))
+
+	for _, b := range blocks {
+		metrics.ObserveIndexedBlock(b.Number.Uint64())
+		if err := p.saveBlockHashes(ctx, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveBlockHashes saves block hash for transaction, withdrawal and reward for sync queues.
+// A redelivered block keeps the counters it has, a reindexed one restarts them.
+func (p *BlockProcessor) saveBlockHashes(ctx context.Context, block *block.Block) error {
+	if block.Reindexed {
+		if err := p.blockRepository.ResetBlockHashCounts(ctx, block.Hash, block.TransactionsCount, block.WithdrawalsCount, 1); err != nil {
+			return fmt.Errorf("%s: %w", ErrFailedToSaveBlockHash, err)
+		}
+
+		p.log.Info("Block hash counts reset for reindex", zap.Any("block_hash", block.Hash))
+		return nil
+	}
 
-	// Save block hash for transaction, withdrawal and reward for sync queues
 	if err := p.blockRepository.SaveBlockHashForTransaction(ctx, block.Hash, block.TransactionsCount); err != nil {
 		return fmt.Errorf("%s: %w", ErrFailedToSaveBlockHash, err)
 	}
@@ -55,5 +94,6 @@ func (p *BlockProcessor) Process(ctx context.Context, data []byte) error {
 	}
 
 	p.log.Info("Block hash saved successfully", zap.Any("block_hash", block.Hash))
+
 	return nil
 }
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// counterBlockRepository keeps the counters of a real repository in memory and skips the database
+type counterBlockRepository struct {
+	*repository.BlockRepository
+}
+
+func (counterBlockRepository) SaveBlock(context.Context, *block.Block) error {
+	return nil
+}
+
+func (counterBlockRepository) SaveBlocks(context.Context, []*block.Block) error {
+	return nil
+}
+
+func blockMessage(t *testing.T, hash common.Hash, transactionCount int, reindexed bool) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(&block.Block{
+		Hash:              hash,
+		Number:            *domain.FromBytesToBigInt(big.NewInt(1).Bytes()),
+		TransactionsCount: transactionCount,
+		Reindexed:         reindexed,
+	})
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestBlockProcessor_RedeliveryKeepsCounters(t *testing.T) {
+	ctx := context.Background()
+	blocks := counterBlockRepository{repository.NewBlockRepository(nil, memstore.New(), zap.NewNop())}
+	processor := NewBlockProcessor(blocks, zap.NewNop())
+	hash := common.HexToHash("0x01")
+	first, second := common.HexToHash("0x0a"), common.HexToHash("0x0b")
+
+	require.NoError(t, processor.Process(ctx, blockMessage(t, hash, 2, false)))
+	require.NoError(t, blocks.DecrementBlockHashTransactionCount(ctx, hash, first))
+
+	// the redelivered block leaves one transaction to wait for, not two
+	require.NoError(t, processor.ProcessBatch(ctx, [][]byte{blockMessage(t, hash, 2, false)}))
+	require.NoError(t, blocks.DecrementBlockHashTransactionCount(ctx, hash, second))
+
+	exists, err := blocks.CheckBlockExistsForTransaction(ctx, hash)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestBlockProcessor_ReindexRestartsCounters(t *testing.T) {
+	ctx := context.Background()
+	blocks := counterBlockRepository{repository.NewBlockRepository(nil, memstore.New(), zap.NewNop())}
+	processor := NewBlockProcessor(blocks, zap.NewNop())
+	hash := common.HexToHash("0x01")
+	first, second := common.HexToHash("0x0a"), common.HexToHash("0x0b")
+	miner := common.HexToAddress("0x0c")
+
+	require.NoError(t, processor.Process(ctx, blockMessage(t, hash, 2, false)))
+	require.NoError(t, blocks.DecrementBlockHashTransactionCount(ctx, hash, first))
+	require.NoError(t, blocks.DecrementBlockHashRewardCount(ctx, hash, miner))
+
+	// a reindex publishes both transactions and the reward again, the first transaction counts again
+	require.NoError(t, processor.Process(ctx, blockMessage(t, hash, 2, true)))
+
+	for _, transactionHash := range []common.Hash{first, second} {
+		exists, err := blocks.CheckBlockExistsForTransaction(ctx, hash)
+		require.NoError(t, err)
+		assert.True(t, exists)
+		require.NoError(t, blocks.DecrementBlockHashTransactionCount(ctx, hash, transactionHash))
+	}
+
+	exists, err := blocks.CheckBlockExistsForTransaction(ctx, hash)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = blocks.CheckBlockExistsForReward(ctx, hash)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
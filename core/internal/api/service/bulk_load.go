@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/admin"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/bulk"
+	"github.com/elmiringos/indexer/indexer-core/internal/infrastructure/repository"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrBulkModeUnsupported   = errors.New("bulk mode needs the postgres database driver")
+	ErrBulkModeActive        = errors.New("bulk mode is active")
+	ErrBulkModeInactive      = errors.New("bulk mode is not active")
+	ErrBulkMergeRunning      = errors.New("bulk merge is running")
+	ErrFailedToCreateStaging = errors.New("failed to create staging tables")
+	ErrFailedToMergeStaging  = errors.New("failed to merge staging tables")
+)
+
+// SearchPath routes unqualified table names, postgres.Connection implements it
+type SearchPath interface {
+	SetSearchPath(schemas ...string)
+}
+
+// BulkLoadService switches the pipeline between the main tables and unlogged staging tables without
+// indexes, foreign keys or triggers, for initial backfills where those dominate the write cost
+type BulkLoadService struct {
+	gate            *PipelineGate
+	bulkRepository  bulk.Repository
+	adminRepository admin.Repository
+	searchPath      SearchPath
+	log             *zap.Logger
+
+	mu     sync.Mutex
+	status bulk.Status
+}
+
+// NewBulkLoadService returns a service refusing to enable bulk mode when bulkRepository or searchPath is nil
+func NewBulkLoadService(
+	gate *PipelineGate,
+	bulkRepository bulk.Repository,
+	adminRepository admin.Repository,
+	searchPath SearchPath,
+	log *zap.Logger,
+) *BulkLoadService {
+	return &BulkLoadService{
+		gate:            gate,
+		bulkRepository:  bulkRepository,
+		adminRepository: adminRepository,
+		searchPath:      searchPath,
+		log:             log,
+		status:          bulk.Status{State: bulk.StateOff},
+	}
+}
+
+func (s *BulkLoadService) supported() bool {
+	return s.bulkRepository != nil && s.searchPath != nil
+}
+
+// Restore resumes loading into the staging tables left by a previous run, it must run before the workers start
+func (s *BulkLoadService) Restore(ctx context.Context) error {
+	if !s.supported() {
+		return nil
+	}
+
+	exists, err := s.bulkRepository.StagingExists(ctx)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		s.searchPath.SetSearchPath(repository.StagingSchema, "public")
+		s.setState(bulk.StateLoading)
+		s.log.Warn("Resuming bulk mode, writes go to the staging tables")
+	}
+
+	return nil
+}
+
+// Active reports whether writes go to the staging tables or are being merged from them
+func (s *BulkLoadService) Active() bool {
+	return s.Status().State != bulk.StateOff
+}
+
+func (s *BulkLoadService) Status() bulk.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *BulkLoadService) setState(state bulk.State) {
+	s.mu.Lock()
+	s.status.State = state
+	s.mu.Unlock()
+}
+
+// Enable holds the workers while it creates the staging tables and routes the pipeline writes to them
+func (s *BulkLoadService) Enable(ctx context.Context, actor string, reason string) (*bulk.Status, error) {
+	if !s.supported() {
+		return nil, ErrBulkModeUnsupported
+	}
+
+	s.mu.Lock()
+	if s.status.State != bulk.StateOff {
+		s.mu.Unlock()
+		return nil, ErrBulkModeActive
+	}
+	s.status.State = bulk.StateLoading
+	s.mu.Unlock()
+
+	s.log.Warn("Enabling bulk mode", zap.String("actor", actor), zap.String("reason", reason))
+
+	release := s.gate.Hold()
+	err := s.bulkRepository.CreateStaging(ctx)
+	if err == nil {
+		s.searchPath.SetSearchPath(repository.StagingSchema, "public")
+	}
+	release()
+
+	s.audit(ctx, admin.ActionEnableBulkMode, actor, map[string]interface{}{"reason": reason}, err)
+
+	if err != nil {
+		s.setState(bulk.StateOff)
+		s.log.Error("Enabling bulk mode failed", zap.Error(err))
+		return nil, fmt.Errorf("%w: %w", ErrFailedToCreateStaging, err)
+	}
+
+	status := s.Status()
+	return &status, nil
+}
+
+// Disable starts merging the staging tables into the main tables and returns at once, the workers are
+// held until the merge ends. A failed merge keeps bulk mode on so it can be retried.
+func (s *BulkLoadService) Disable(ctx context.Context, actor string, reason string) (*bulk.Status, error) {
+	if !s.supported() {
+		return nil, ErrBulkModeUnsupported
+	}
+
+	s.mu.Lock()
+	switch s.status.State {
+	case bulk.StateOff:
+		s.mu.Unlock()
+		return nil, ErrBulkModeInactive
+	case bulk.StateMerging:
+		s.mu.Unlock()
+		return nil, ErrBulkMergeRunning
+	}
+	s.status = bulk.Status{State: bulk.StateMerging, StartedAt: time.Now()}
+	status := s.status
+	s.mu.Unlock()
+
+	s.log.Warn("Disabling bulk mode", zap.String("actor", actor), zap.String("reason", reason))
+
+	// the merge outlives the request that started it
+	go s.merge(context.WithoutCancel(ctx), actor, reason)
+
+	return &status, nil
+}
+
+func (s *BulkLoadService) merge(ctx context.Context, actor string, reason string) {
+	release := s.gate.Hold()
+	err := s.bulkRepository.Merge(ctx, func(progress bulk.Progress) {
+		s.mu.Lock()
+		s.status.Progress = progress
+		s.mu.Unlock()
+
+		s.log.Info("Bulk merge progress",
+			zap.String("phase", string(progress.Phase)),
+			zap.String("step", progress.Step),
+			zap.Int("done", progress.Done),
+			zap.Int("total", progress.Total),
+			zap.Int64("merged_rows", progress.MergedRows))
+	})
+	if err == nil {
+		s.searchPath.SetSearchPath()
+	}
+	release()
+
+	s.mu.Lock()
+	if err != nil {
+		s.status.State = bulk.StateLoading
+		s.status.LastError = err.Error()
+	} else {
+		s.status.State = bulk.StateOff
+	}
+	status := s.status
+	s.mu.Unlock()
+
+	params := map[string]interface{}{
+		"reason":      reason,
+		"merged_rows": status.Progress.MergedRows,
+		"duration":    time.Since(status.StartedAt).String(),
+	}
+	s.audit(ctx, admin.ActionDisableBulkMode, actor, params, err)
+
+	if err != nil {
+		s.log.Error("Bulk merge failed, bulk mode stays on", zap.Error(fmt.Errorf("%w: %w", ErrFailedToMergeStaging, err)))
+		return
+	}
+
+	s.log.Warn("Bulk mode disabled", zap.Any("params", params))
+}
+
+// audit writes the attempt to the audit log, a lost row is only logged since the change already happened
+func (s *BulkLoadService) audit(ctx context.Context, action string, actor string, params map[string]interface{}, err error) {
+	entry := &admin.AuditEntry{
+		Action:  action,
+		Actor:   actor,
+		Params:  params,
+		Success: err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if auditErr := s.adminRepository.SaveAuditEntry(ctx, entry); auditErr != nil {
+		s.log.Error("Failed to write audit entry", zap.Error(fmt.Errorf("%w: %w", ErrFailedToSaveAuditEntry, auditErr)), zap.Any("entry", entry))
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/api/pb"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/indexer-core/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+const gapsPageSize = 100
+
+// ReindexRequester asks the producer to publish a block range again
+type ReindexRequester interface {
+	ReindexRange(ctx context.Context, from, to *big.Int, reason string) (*pb.ReindexRangeResponse, error)
+}
+
+type GapDetectorConfig struct {
+	// Interval between two scans
+	Interval time.Duration
+	// Cooldown before the same range is requested again
+	Cooldown time.Duration
+	// SettleBlocks below the highest block are left alone, their children may still be in flight
+	SettleBlocks uint64
+	// MaxRange is the largest range sent in one request
+	MaxRange uint64
+	// MaxBlocksPerRun caps the blocks requested in one scan
+	MaxBlocksPerRun uint64
+}
+
+// GapDetector periodically looks for missing or incomplete blocks and asks the producer to reindex them
+type GapDetector struct {
+	blockRepository block.Repository
+	producer        ReindexRequester
+	cfg             GapDetectorConfig
+	issued          map[string]time.Time
+	log             *zap.Logger
+}
+
+func NewGapDetector(blockRepository block.Repository, producer ReindexRequester, cfg GapDetectorConfig, log *zap.Logger) *GapDetector {
+	return &GapDetector{
+		blockRepository: blockRepository,
+		producer:        producer,
+		cfg:             cfg,
+		issued:          make(map[string]time.Time),
+		log:             log,
+	}
+}
+
+// Run scans for gaps every interval until ctx is done
+func (d *GapDetector) Run(ctx context.Context) {
+	d.log.Info("Starting gap detector", zap.Duration("interval", d.cfg.Interval))
+
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.detect(ctx); err != nil {
+				d.log.Error("Gap detection failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (d *GapDetector) detect(ctx context.Context) error {
+	status, err := d.blockRepository.GetIndexingStatus(ctx)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	// gaps close to the head are usually blocks whose children are still being processed
+	horizon := new(big.Int).Sub((*big.Int)(&status.HighestNumber), new(big.Int).SetUint64(d.cfg.SettleBlocks))
+	budget := d.cfg.MaxBlocksPerRun
+	openGaps := 0
+
+	d.forgetExpired()
+
+	var after *domain.BigInt
+	for budget > 0 {
+		gaps, err := d.blockRepository.ListGaps(ctx, after, gapsPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, gap := range gaps {
+			from := new(big.Int).Set((*big.Int)(&gap.FromNumber))
+			if from.Cmp(horizon) > 0 {
+				metrics.OpenGaps.Set(float64(openGaps))
+				return nil
+			}
+			openGaps++
+
+			to := new(big.Int).Set((*big.Int)(&gap.ToNumber))
+			if to.Cmp(horizon) > 0 {
+				to.Set(horizon)
+			}
+
+			budget = d.requestRange(ctx, gap.Kind, from, to, budget)
+			if budget == 0 {
+				break
+			}
+		}
+
+		if len(gaps) < gapsPageSize {
+			break
+		}
+		after = &gaps[len(gaps)-1].FromNumber
+	}
+
+	metrics.OpenGaps.Set(float64(openGaps))
+	return nil
+}
+
+// requestRange sends the range to the producer in chunks of at most MaxRange blocks and returns the budget left
+func (d *GapDetector) requestRange(ctx context.Context, kind block.GapKind, from, to *big.Int, budget uint64) uint64 {
+	reason := "missing blocks"
+	if kind == block.GapIncomplete {
+		reason = "incomplete blocks"
+	}
+
+	for from.Cmp(to) <= 0 && budget > 0 {
+		size := min(d.cfg.MaxRange, budget)
+		if remaining := new(big.Int).Sub(to, from); remaining.IsUint64() && remaining.Uint64() < size {
+			size = remaining.Uint64() + 1
+		}
+		chunkTo := new(big.Int).Add(from, new(big.Int).SetUint64(size-1))
+
+		key := fmt.Sprintf("%s-%s", from, chunkTo)
+		if _, ok := d.issued[key]; !ok {
+			response, err := d.producer.ReindexRange(ctx, from, chunkTo, reason)
+			if err != nil {
+				d.log.Warn("Reindex request failed", zap.String("from", from.String()), zap.String("to", chunkTo.String()), zap.Error(err))
+				metrics.ReindexRequests.WithLabelValues("error").Inc()
+				return 0
+			}
+
+			d.issued[key] = time.Now()
+			metrics.ReindexRequests.WithLabelValues("ok").Inc()
+			d.log.Info(
+				"Requested reindex",
+				zap.String("from", from.String()),
+				zap.String("to", chunkTo.String()),
+				zap.String("reason", reason),
+				zap.Uint64("queued_blocks", response.QueuedBlocks),
+			)
+			budget -= size
+		}
+
+		from = chunkTo.Add(chunkTo, big.NewInt(1))
+	}
+
+	return budget
+}
+
+func (d *GapDetector) forgetExpired() {
+	for key, issuedAt := range d.issued {
+		if time.Since(issuedAt) > d.cfg.Cooldown {
+			delete(d.issued, key)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// MessageStamp describes a message to the gate: the epoch when the worker pool received it and
+// the reset generation the producer published it in. Messages of older producers carry no generation.
+type MessageStamp struct {
+	Epoch         uint64
+	Generation    uint64
+	HasGeneration bool
+}
+
+// PipelineGate lets an administrative operation pause every worker pool.
+// Workers hold the gate shared while processing a message, Pause takes it exclusively
+// and bumps the epoch so messages received before the pause can be told apart.
+type PipelineGate struct {
+	mu         sync.RWMutex
+	epoch      atomic.Uint64
+	generation atomic.Uint64
+}
+
+func NewPipelineGate() *PipelineGate {
+	return &PipelineGate{}
+}
+
+// Epoch returns the number of pauses completed so far
+func (g *PipelineGate) Epoch() uint64 {
+	return g.epoch.Load()
+}
+
+// Generation returns the reset generation messages must carry to be processed
+func (g *PipelineGate) Generation() uint64 {
+	return g.generation.Load()
+}
+
+// SetGeneration records the generation of the last state reset, it is loaded at startup and
+// set by a reset while the gate is paused
+func (g *PipelineGate) SetGeneration(generation uint64) {
+	g.generation.Store(generation)
+}
+
+// Enter blocks while the pipeline is paused and reports whether the message is stale: a pause
+// happened since it was received, or it was published in a generation before the last reset.
+func (g *PipelineGate) Enter(stamp MessageStamp) (stale bool) {
+	g.mu.RLock()
+	return g.isStale(stamp)
+}
+
+// EnterBatch takes the gate once for a batch, stamps describe each message.
+// A reader must not take the gate twice, a pending Pause would block the second RLock forever.
+func (g *PipelineGate) EnterBatch(stamps []MessageStamp) (stale []bool) {
+	g.mu.RLock()
+	stale = make([]bool, len(stamps))
+	for i := range stamps {
+		stale[i] = g.isStale(stamps[i])
+	}
+	return stale
+}
+
+func (g *PipelineGate) isStale(stamp MessageStamp) bool {
+	if g.epoch.Load() != stamp.Epoch {
+		return true
+	}
+
+	return stamp.HasGeneration && stamp.Generation < g.generation.Load()
+}
+
+// Leave releases the gate taken by Enter or EnterBatch
+func (g *PipelineGate) Leave() {
+	g.mu.RUnlock()
+}
+
+// Pause waits for in-flight messages to finish and stops workers until the returned resume is called
+func (g *PipelineGate) Pause() (resume func()) {
+	g.mu.Lock()
+	return func() {
+		g.epoch.Add(1)
+		g.mu.Unlock()
+	}
+}
+
+// Hold waits for in-flight messages to finish and stops workers until the returned release is called.
+// Unlike Pause it leaves the epoch alone, messages received meanwhile are processed once the gate opens.
+func (g *PipelineGate) Hold() (release func()) {
+	g.mu.Lock()
+	return g.mu.Unlock
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineGate_Stale(t *testing.T) {
+	gate := NewPipelineGate()
+	gate.SetGeneration(2)
+
+	tests := []struct {
+		name     string
+		stamp    MessageStamp
+		expected bool
+	}{
+		{name: "current generation", stamp: MessageStamp{Generation: 2, HasGeneration: true}},
+		{name: "newer generation", stamp: MessageStamp{Generation: 3, HasGeneration: true}},
+		{name: "older generation", stamp: MessageStamp{Generation: 1, HasGeneration: true}, expected: true},
+		{name: "no generation", stamp: MessageStamp{}},
+		{name: "received before a pause", stamp: MessageStamp{Epoch: 1, Generation: 2, HasGeneration: true}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stale := gate.Enter(tt.stamp)
+			gate.Leave()
+			assert.Equal(t, tt.expected, stale)
+		})
+	}
+}
+
+func TestPipelineGate_EnterBatch(t *testing.T) {
+	gate := NewPipelineGate()
+	gate.SetGeneration(1)
+
+	stale := gate.EnterBatch([]MessageStamp{
+		{Generation: 1, HasGeneration: true},
+		{Generation: 0, HasGeneration: true},
+		{},
+	})
+	gate.Leave()
+
+	assert.Equal(t, []bool{false, true, false}, stale)
+}
+
+func TestPipelineGate_PauseBumpsEpoch(t *testing.T) {
+	gate := NewPipelineGate()
+	stamp := MessageStamp{Epoch: gate.Epoch()}
+
+	gate.Hold()()
+	assert.Equal(t, uint64(0), gate.Epoch(), "hold keeps the epoch")
+
+	gate.Pause()()
+	assert.Equal(t, uint64(1), gate.Epoch())
+
+	stale := gate.Enter(stamp)
+	gate.Leave()
+	assert.True(t, stale, "a message received before the pause is stale")
+}
+
+func TestPipelineGate_PauseBlocksWorkers(t *testing.T) {
+	gate := NewPipelineGate()
+	resume := gate.Pause()
+
+	entered := make(chan bool)
+	go func() {
+		stale := gate.Enter(MessageStamp{Epoch: 1})
+		gate.Leave()
+		entered <- stale
+	}()
+
+	select {
+	case <-entered:
+		t.Fatal("a worker entered a paused gate")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	resume()
+	assert.False(t, <-entered, "a message received with the new epoch is processed")
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/admin"
+	"github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFailedToPurgeQueues      = errors.New("failed to purge queues")
+	ErrFailedToFlushCoordinator = errors.New("failed to flush coordination keys")
+	ErrFailedToResetState       = errors.New("failed to reset state")
+	ErrFailedToSaveAuditEntry   = errors.New("failed to save audit entry")
+	ErrFailedToCountMessages    = errors.New("failed to count pending messages")
+	ErrPipelineNotDrained       = errors.New("pipeline queues hold pending messages, stop the producer before a partial reset")
+)
+
+// CoordinationStore holds the counters processors use to wait for the children of a block
+type CoordinationStore interface {
+	Reset() error
+}
+
+// QueuePurger counts and drops pending messages of pipeline queues
+type QueuePurger interface {
+	PurgeQueues(queues ...rabbitmq.QueueType) (int, error)
+	PendingMessages(queues ...rabbitmq.QueueType) (int, error)
+}
+
+// BulkMode reports whether the pipeline writes to staging tables, a reset would only clear those
+type BulkMode interface {
+	Active() bool
+}
+
+// ResetService wipes indexed state while the pipeline is paused
+type ResetService struct {
+	gate            *PipelineGate
+	adminRepository admin.Repository
+	store           CoordinationStore
+	purger          QueuePurger
+	bulkMode        BulkMode
+	log             *zap.Logger
+}
+
+func NewResetService(
+	gate *PipelineGate,
+	adminRepository admin.Repository,
+	store CoordinationStore,
+	purger QueuePurger,
+	bulkMode BulkMode,
+	log *zap.Logger,
+) *ResetService {
+	return &ResetService{
+		gate:            gate,
+		adminRepository: adminRepository,
+		store:           store,
+		purger:          purger,
+		bulkMode:        bulkMode,
+		log:             log,
+	}
+}
+
+// Generation returns the reset generation the producer has to stamp on its messages
+func (s *ResetService) Generation() uint64 {
+	return s.gate.Generation()
+}
+
+// ResetState pauses the workers, then removes the indexed data (above fromBlock when it is set) and bumps
+// the reset generation, so messages published before the reset are dropped once the workers resume.
+// A full reset purges the pipeline queues and flushes every coordination key. The queues also carry
+// messages of the blocks a partial reset keeps, so a partial reset is refused unless they are empty and
+// only drops the keys of the removed blocks.
+// The attempt is written to the audit log whatever its outcome. A reset is refused while bulk mode is active.
+func (s *ResetService) ResetState(ctx context.Context, fromBlock *domain.BigInt, actor string, reason string) (*admin.ResetResult, error) {
+	if s.bulkMode.Active() {
+		return nil, ErrBulkModeActive
+	}
+
+	params := map[string]interface{}{"reason": reason}
+	if fromBlock != nil {
+		params["from_block"] = fromBlock.String()
+	}
+
+	s.log.Warn("Resetting state", zap.String("actor", actor), zap.Any("params", params))
+
+	resume := s.gate.Pause()
+	result, err := s.resetState(ctx, fromBlock, params)
+	if err == nil {
+		s.gate.SetGeneration(result.Generation)
+	}
+	resume()
+
+	entry := &admin.AuditEntry{
+		Action:  admin.ActionResetState,
+		Actor:   actor,
+		Params:  params,
+		Success: err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		params["deleted_blocks"] = result.DeletedBlocks
+		params["generation"] = result.Generation
+	}
+
+	// the reset already happened at this point, a lost audit row must not report it as failed
+	if auditErr := s.adminRepository.SaveAuditEntry(ctx, entry); auditErr != nil {
+		s.log.Error("Failed to write audit entry", zap.Error(fmt.Errorf("%w: %w", ErrFailedToSaveAuditEntry, auditErr)), zap.Any("entry", entry))
+	}
+
+	if err != nil {
+		s.log.Error("State reset failed", zap.Error(err))
+		return nil, fmt.Errorf("%w: %w", ErrFailedToResetState, err)
+	}
+
+	s.log.Warn("State reset completed", zap.Int64("deleted_blocks", result.DeletedBlocks), zap.Any("params", params))
+	return result, nil
+}
+
+// resetState runs with the gate paused, no message is being processed
+func (s *ResetService) resetState(ctx context.Context, fromBlock *domain.BigInt, params map[string]interface{}) (*admin.ResetResult, error) {
+	if fromBlock != nil {
+		pending, err := s.purger.PendingMessages(rabbitmq.PipelineQueues...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToCountMessages, err)
+		}
+		if pending > 0 {
+			params["pending_messages"] = pending
+			return nil, fmt.Errorf("%w: %d messages", ErrPipelineNotDrained, pending)
+		}
+
+		return s.adminRepository.ResetState(ctx, fromBlock, func(context.Context) error { return nil })
+	}
+
+	return s.adminRepository.ResetState(ctx, nil, func(ctx context.Context) error {
+		purged, err := s.purger.PurgeQueues(rabbitmq.PipelineQueues...)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToPurgeQueues, err)
+		}
+		params["purged_messages"] = purged
+
+		if err := s.store.Reset(); err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToFlushCoordinator, err)
+		}
+
+		return nil
+	})
+}
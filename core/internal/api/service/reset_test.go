@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/admin"
+	"github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeAdminRepository struct {
+	generation uint64
+	resets     []*domain.BigInt
+	audit      []*admin.AuditEntry
+}
+
+func (r *fakeAdminRepository) ResetState(ctx context.Context, fromBlock *domain.BigInt, beforeCommit func(ctx context.Context) error) (*admin.ResetResult, error) {
+	if err := beforeCommit(ctx); err != nil {
+		return nil, err
+	}
+
+	r.resets = append(r.resets, fromBlock)
+	r.generation++
+	return &admin.ResetResult{FromBlock: fromBlock, DeletedBlocks: 1, Generation: r.generation}, nil
+}
+
+func (r *fakeAdminRepository) GetResetGeneration(context.Context) (uint64, error) {
+	return r.generation, nil
+}
+
+func (r *fakeAdminRepository) SaveAuditEntry(_ context.Context, entry *admin.AuditEntry) error {
+	r.audit = append(r.audit, entry)
+	return nil
+}
+
+type fakeQueues struct {
+	pending int
+	purged  int
+}
+
+func (q *fakeQueues) PurgeQueues(queues ...rabbitmq.QueueType) (int, error) {
+	q.purged += len(queues)
+	return q.pending, nil
+}
+
+func (q *fakeQueues) PendingMessages(...rabbitmq.QueueType) (int, error) {
+	return q.pending, nil
+}
+
+type fakeStore struct {
+	resets int
+}
+
+func (s *fakeStore) Reset() error {
+	s.resets++
+	return nil
+}
+
+type fakeBulkMode bool
+
+func (b fakeBulkMode) Active() bool {
+	return bool(b)
+}
+
+func newTestResetService(bulk bool, pending int) (*ResetService, *PipelineGate, *fakeAdminRepository, *fakeQueues, *fakeStore) {
+	gate := NewPipelineGate()
+	repository := &fakeAdminRepository{}
+	queues := &fakeQueues{pending: pending}
+	store := &fakeStore{}
+	return NewResetService(gate, repository, store, queues, fakeBulkMode(bulk), zap.NewNop()), gate, repository, queues, store
+}
+
+func TestResetService_FullReset(t *testing.T) {
+	service, gate, repository, queues, store := newTestResetService(false, 3)
+
+	result, err := service.ResetState(context.Background(), nil, "admin", "test")
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), result.Generation)
+	assert.Equal(t, uint64(1), gate.Generation())
+	assert.Equal(t, uint64(1), gate.Epoch())
+	assert.Len(t, repository.resets, 1)
+	assert.Equal(t, len(rabbitmq.PipelineQueues), queues.purged)
+	assert.Equal(t, 1, store.resets)
+
+	require.Len(t, repository.audit, 1)
+	assert.True(t, repository.audit[0].Success)
+	assert.Equal(t, 3, repository.audit[0].Params["purged_messages"])
+}
+
+func TestResetService_PartialReset(t *testing.T) {
+	service, gate, repository, queues, store := newTestResetService(false, 0)
+	fromBlock := domain.FromBytesToBigInt(big.NewInt(100).Bytes())
+
+	result, err := service.ResetState(context.Background(), fromBlock, "admin", "test")
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), gate.Generation())
+	assert.Equal(t, uint64(1), result.Generation)
+	require.Len(t, repository.resets, 1)
+	assert.Equal(t, "100", repository.resets[0].String())
+	assert.Zero(t, queues.purged, "the queues hold messages of the kept blocks")
+	assert.Zero(t, store.resets, "the keys of the kept blocks stay")
+}
+
+func TestResetService_PartialResetRefusedWithPendingMessages(t *testing.T) {
+	service, gate, repository, queues, store := newTestResetService(false, 5)
+	fromBlock := domain.FromBytesToBigInt(big.NewInt(100).Bytes())
+
+	_, err := service.ResetState(context.Background(), fromBlock, "admin", "test")
+	require.ErrorIs(t, err, ErrPipelineNotDrained)
+
+	assert.Zero(t, gate.Generation())
+	assert.Empty(t, repository.resets)
+	assert.Zero(t, queues.purged)
+	assert.Zero(t, store.resets)
+
+	require.Len(t, repository.audit, 1)
+	assert.False(t, repository.audit[0].Success)
+	assert.Equal(t, 5, repository.audit[0].Params["pending_messages"])
+}
+
+func TestResetService_RefusedInBulkMode(t *testing.T) {
+	service, _, repository, _, _ := newTestResetService(true, 0)
+
+	_, err := service.ResetState(context.Background(), nil, "admin", "test")
+	require.ErrorIs(t, err, ErrBulkModeActive)
+	assert.Empty(t, repository.resets)
+}
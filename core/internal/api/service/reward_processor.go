@@ -6,14 +6,15 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/reward"
-	"github.com/elmiringos/indexer/indexer-core/internal/infrastructure/repository"
+	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
 )
 
 type RewardProcessor struct {
-	blockRepository  *repository.BlockRepository
-	rewardRepository *repository.RewardRepository
+	blockRepository  block.Repository
+	rewardRepository reward.Repository
 	log              *zap.Logger
 }
 
@@ -25,7 +26,7 @@ var (
 	ErrFailedToCheckBlockExistsForReward = errors.New("failed to check if block exists for reward")
 )
 
-func NewRewardProcessor(blockRepository *repository.BlockRepository, rewardRepository *repository.RewardRepository, log *zap.Logger) *RewardProcessor {
+func NewRewardProcessor(blockRepository block.Repository, rewardRepository reward.Repository, log *zap.Logger) *RewardProcessor {
 	return &RewardProcessor{blockRepository: blockRepository, rewardRepository: rewardRepository, log: log}
 }
 
@@ -50,3 +51,33 @@ func (p *RewardProcessor) Process(ctx context.Context, data []byte) error {
 
 	return nil
 }
+
+// ProcessBatch saves the rewards in one database transaction once every block they belong to is registered
+func (p *RewardProcessor) ProcessBatch(ctx context.Context, batch [][]byte) error {
+	rewards, err := decodeBatch[reward.Reward](batch)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUnmarshalReward, err)
+	}
+
+	checked := make(map[common.Hash]bool)
+	for _, rw := range rewards {
+		if checked[rw.BlockHash] {
+			continue
+		}
+
+		blockExists, err := p.blockRepository.CheckBlockExistsForReward(ctx, rw.BlockHash)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToCheckBlockExistsForReward, err)
+		}
+		if !blockExists {
+			return fmt.Errorf("%w: %s", ErrBlockDoesNotExistForReward, rw.BlockHash)
+		}
+		checked[rw.BlockHash] = true
+	}
+
+	if err := p.rewardRepository.SaveRewards(ctx, rewards); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSaveReward, err)
+	}
+
+	return nil
+}
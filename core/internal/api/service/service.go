@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/internal_transaction"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/reward"
@@ -12,6 +13,7 @@ import (
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/withdrawal"
 	"github.com/elmiringos/indexer/indexer-core/internal/infrastructure/repository"
+	"github.com/ethereum/go-ethereum/common"
 
 	"go.uber.org/zap"
 )
@@ -69,3 +71,70 @@ func (s *CoreService) GetCurrentBlock(ctx context.Context) (*block.Block, error)
 
 	return currentBlock, nil
 }
+
+// GetIndexingStatus returns the indexing summary, or nil when no block is stored yet
+func (s *CoreService) GetIndexingStatus(ctx context.Context) (*block.IndexingStatus, error) {
+	s.logger.Info("Getting indexing status")
+
+	status, err := s.BlockRepository.GetIndexingStatus(ctx)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, nil
+		default:
+			s.logger.Error("error in getting indexing status", zap.Error(err))
+			return nil, ErrUnexpectedError
+		}
+	}
+
+	return status, nil
+}
+
+// ListGaps returns up to limit missing or incomplete block ranges starting after the given block number
+func (s *CoreService) ListGaps(ctx context.Context, after *domain.BigInt, limit int) ([]*block.Gap, error) {
+	s.logger.Info("Listing gaps", zap.Int("limit", limit))
+
+	gaps, err := s.BlockRepository.ListGaps(ctx, after, limit)
+	if err != nil {
+		s.logger.Error("error in listing gaps", zap.Error(err))
+		return nil, ErrUnexpectedError
+	}
+
+	return gaps, nil
+}
+
+// GetBlockStatus returns the pending children of a block, or nil when the block is not stored
+func (s *CoreService) GetBlockStatus(ctx context.Context, hash common.Hash) (*block.BlockStatus, error) {
+	s.logger.Info("Getting block status", zap.String("hash", hash.Hex()))
+
+	status, err := s.BlockRepository.GetBlockStatus(ctx, hash)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, nil
+		default:
+			s.logger.Error("error in getting block status", zap.Error(err))
+			return nil, ErrUnexpectedError
+		}
+	}
+
+	return status, nil
+}
+
+// GetIndexedRange returns the contiguous fully indexed range, or nil when no block is stored yet
+func (s *CoreService) GetIndexedRange(ctx context.Context) (*block.IndexedRange, error) {
+	s.logger.Info("Getting indexed range")
+
+	indexedRange, err := s.BlockRepository.GetIndexedRange(ctx)
+	if err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return nil, nil
+		default:
+			s.logger.Error("error in getting indexed range", zap.Error(err))
+			return nil, ErrUnexpectedError
+		}
+	}
+
+	return indexedRange, nil
+}
@@ -50,6 +50,7 @@ func (p *TokenProcessor) Process(ctx context.Context, data []byte) error {
 		// Process Token Entity
 		tokenEntity := &token.Token{
 			Address:     tokenEvent.Address,
+			Type:        tokenType(tokenEvent),
 			Name:        metadata["name"].(string),
 			Symbol:      metadata["symbol"].(string),
 			TotalSupply: tokenEvent.Value,
@@ -98,9 +99,12 @@ func (p *TokenProcessor) Process(ctx context.Context, data []byte) error {
 		LogIndex:             tokenEvent.LogIndex,
 		From:                 tokenEvent.From,
 		To:                   tokenEvent.To,
-		TokenContractAddress: tokenEvent.From,
+		TokenContractAddress: tokenEvent.Address,
 		Amount:               tokenEvent.Value,
 	}
+	if tokenType(tokenEvent) != token.TypeERC20 {
+		tokenTransfer.TokenId = &tokenEvent.TokenId
+	}
 
 	err := p.tokenRepository.SaveTokenTransfer(ctx, tokenTransfer)
 	if err != nil {
@@ -109,3 +113,16 @@ func (p *TokenProcessor) Process(ctx context.Context, data []byte) error {
 
 	return nil
 }
+
+// tokenType infers the standard from the transfer, ERC-721 moves a single token id and ERC-1155
+// moves an amount of one
+func tokenType(event *token.TokenEvent) string {
+	switch {
+	case event.TokenId.String() == "0":
+		return token.TypeERC20
+	case event.Value.String() == "0":
+		return token.TypeERC721
+	default:
+		return token.TypeERC1155
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
 
 	"go.uber.org/zap"
 )
@@ -67,13 +68,13 @@ func (p *TransactionProcessor) Process(ctx context.Context, data []byte) error {
 
 	p.log.Info("Transaction saved successfully", zap.Any("transaction_hash", transaction.Hash))
 
-	if err := p.blockRepository.DecrementBlockHashTransactionCount(ctx, transaction.BlockHash); err != nil {
+	if err := p.blockRepository.DecrementBlockHashTransactionCount(ctx, transaction.BlockHash, transaction.Hash); err != nil {
 		return fmt.Errorf("%w: %w", ErrFailedToDecrementBlockHashTransaction, err)
 	}
 
 	p.log.Info("Successful decremented transaction count for block", zap.Any("block_hash", transaction.BlockHash))
 
-	if err := p.transactionRepository.SaveTransactionHashForLog(ctx, transaction.Hash, transaction.LogsCount); err != nil {
+	if err := p.saveTransactionHash(ctx, transaction); err != nil {
 		return fmt.Errorf("%w: %w", ErrFailedToSaveTransactionHash, err)
 	}
 
@@ -82,6 +83,59 @@ func (p *TransactionProcessor) Process(ctx context.Context, data []byte) error {
 	return nil
 }
 
+// ProcessBatch saves the transactions in one database transaction once every block they belong to
+// is registered, then updates the counters like Process does for each of them
+func (p *TransactionProcessor) ProcessBatch(ctx context.Context, batch [][]byte) error {
+	transactions, err := decodeBatch[transaction.Transaction](batch)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUnmarshalTransaction, err)
+	}
+
+	checked := make(map[common.Hash]bool)
+	for _, tx := range transactions {
+		if checked[tx.BlockHash] {
+			continue
+		}
+
+		blockExists, err := p.blockRepository.CheckBlockExistsForTransaction(ctx, tx.BlockHash)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToCheckBlockExistsForTransaction, err)
+		}
+		if !blockExists {
+			return fmt.Errorf("%w: %s", ErrBlockDoesNotExistForTransaction, tx.BlockHash)
+		}
+		checked[tx.BlockHash] = true
+	}
+
+	if err := p.transactionRepository.SaveTransactions(ctx, transactions); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSaveTransaction, err)
+	}
+
+	p.log.Info("Transactions saved successfully", zap.Int("count", len(transactions)))
+
+	for _, tx := range transactions {
+		if err := p.blockRepository.DecrementBlockHashTransactionCount(ctx, tx.BlockHash, tx.Hash); err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToDecrementBlockHashTransaction, err)
+		}
+
+		if err := p.saveTransactionHash(ctx, tx); err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToSaveTransactionHash, err)
+		}
+	}
+
+	return nil
+}
+
+// saveTransactionHash registers the logs the transaction waits for, a redelivered transaction keeps
+// its counter while one of a reindexed block restarts it
+func (p *TransactionProcessor) saveTransactionHash(ctx context.Context, tx *transaction.Transaction) error {
+	if tx.Reindexed {
+		return p.transactionRepository.ResetTransactionHashForLog(ctx, tx.Hash, tx.LogsCount)
+	}
+
+	return p.transactionRepository.SaveTransactionHashForLog(ctx, tx.Hash, tx.LogsCount)
+}
+
 type TransactionLogProcessor struct {
 	transactionRepository transaction.Repository
 	log                   *zap.Logger
@@ -120,7 +174,7 @@ func (p *TransactionLogProcessor) Process(ctx context.Context, data []byte) erro
 
 	p.log.Info("Transaction Log saved successfully", zap.Uint("transaction_log_index", transactionLog.Index))
 
-	if err := p.transactionRepository.DecrementTransactionLogCount(ctx, transactionLog.TransactionHash); err != nil {
+	if err := p.transactionRepository.DecrementTransactionLogCount(ctx, transactionLog.TransactionHash, transactionLog.Index); err != nil {
 		return fmt.Errorf("%w: %s", ErrFailedToDecrementTransactionHash, err)
 	}
 
@@ -128,3 +182,42 @@ func (p *TransactionLogProcessor) Process(ctx context.Context, data []byte) erro
 
 	return nil
 }
+
+// ProcessBatch saves the logs and their topics in one database transaction once every transaction
+// they belong to is registered, then decrements the log counters
+func (p *TransactionLogProcessor) ProcessBatch(ctx context.Context, batch [][]byte) error {
+	transactionLogs, err := decodeBatch[transaction.TransactionLog](batch)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUnmarshalTransactionLog, err)
+	}
+
+	checked := make(map[common.Hash]bool)
+	for _, transactionLog := range transactionLogs {
+		if checked[transactionLog.TransactionHash] {
+			continue
+		}
+
+		transactionExist, err := p.transactionRepository.CheckTransactionExistForLog(ctx, transactionLog.TransactionHash)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToCheckTransactionExistsForLog, err)
+		}
+		if !transactionExist {
+			return fmt.Errorf("%w: %s", ErrTransactionDoesNotExistForLog, transactionLog.TransactionHash)
+		}
+		checked[transactionLog.TransactionHash] = true
+	}
+
+	if err := p.transactionRepository.SaveTransactionLogs(ctx, transactionLogs); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSaveTransactionLog, err)
+	}
+
+	p.log.Info("Transaction Logs saved successfully", zap.Int("count", len(transactionLogs)))
+
+	for _, transactionLog := range transactionLogs {
+		if err := p.transactionRepository.DecrementTransactionLogCount(ctx, transactionLog.TransactionHash, transactionLog.Index); err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToDecrementTransactionHash, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/elmiringos/indexer/indexer-core/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var errLogCounter = errors.New("log counter unavailable")
+
+// counterTransactionRepository keeps the counters of a real repository in memory and skips the database.
+// The log counter of failHash cannot be registered the first time, which fails the batch after some of
+// its transactions were already counted.
+type counterTransactionRepository struct {
+	*repository.TransactionRepository
+	failHash common.Hash
+	failed   bool
+}
+
+func (*counterTransactionRepository) SaveTransaction(context.Context, *transaction.Transaction) error {
+	return nil
+}
+
+func (*counterTransactionRepository) SaveTransactions(context.Context, []*transaction.Transaction) error {
+	return nil
+}
+
+func (*counterTransactionRepository) SaveTransactionLog(context.Context, *transaction.TransactionLog) error {
+	return nil
+}
+
+func (*counterTransactionRepository) SaveTransactionLogs(context.Context, []*transaction.TransactionLog) error {
+	return nil
+}
+
+func (r *counterTransactionRepository) SaveTransactionHashForLog(ctx context.Context, hash common.Hash, count int) error {
+	if hash == r.failHash && !r.failed {
+		r.failed = true
+		return errLogCounter
+	}
+
+	return r.TransactionRepository.SaveTransactionHashForLog(ctx, hash, count)
+}
+
+type counterPipeline struct {
+	store        *memstore.Store
+	blocks       counterBlockRepository
+	transactions *counterTransactionRepository
+}
+
+func newCounterPipeline() *counterPipeline {
+	store := memstore.New()
+	return &counterPipeline{
+		store:        store,
+		blocks:       counterBlockRepository{repository.NewBlockRepository(nil, store, zap.NewNop())},
+		transactions: &counterTransactionRepository{TransactionRepository: repository.NewTransactionRepository(nil, store, zap.NewNop())},
+	}
+}
+
+func (p *counterPipeline) counter(t *testing.T, key string) int {
+	t.Helper()
+
+	value, err := p.store.GetInt(context.Background(), key)
+	require.NoError(t, err)
+	return value
+}
+
+func transactionMessage(t *testing.T, blockHash, hash common.Hash) string {
+	t.Helper()
+
+	data, err := json.Marshal(&transaction.Transaction{Hash: hash, BlockHash: blockHash, LogsCount: 1})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func logMessage(t *testing.T, transactionHash common.Hash, index uint) string {
+	t.Helper()
+
+	data, err := json.Marshal(&transaction.TransactionLog{TransactionHash: transactionHash, Index: index})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func runBatch(processor MessageProcessor, size int, deliveries ...amqp091.Delivery) {
+	msgs := make(chan amqp091.Delivery, len(deliveries))
+	for _, d := range deliveries {
+		msgs <- d
+	}
+	close(msgs)
+
+	NewWorkerPool("test", processor, NewPipelineGate(), zap.NewNop(), 1, BatchConfig{Size: size, Linger: time.Second}).Start(msgs)
+}
+
+// TestTransactionProcessor_BatchFallbackCountsOnce fails a batch after its first transaction was counted,
+// the per-message retry of the pool must not count it a second time
+func TestTransactionProcessor_BatchFallbackCountsOnce(t *testing.T) {
+	ctx := context.Background()
+	pipeline := newCounterPipeline()
+	blockHash := common.HexToHash("0x01")
+	first, second := common.HexToHash("0x0a"), common.HexToHash("0x0b")
+	pipeline.transactions.failHash = second
+
+	require.NoError(t, pipeline.blocks.SaveBlockHashForTransaction(ctx, blockHash, 3))
+
+	acknowledger := newRecordingAcknowledger()
+	processor := NewTransactionProcessor(pipeline.blocks, pipeline.transactions, zap.NewNop())
+	runBatch(processor, 2,
+		acknowledger.delivery(1, transactionMessage(t, blockHash, first), nil),
+		acknowledger.delivery(2, transactionMessage(t, blockHash, second), nil),
+	)
+
+	assert.True(t, pipeline.transactions.failed, "the batch failed")
+	assert.Equal(t, acked, acknowledger.get(1))
+	assert.Equal(t, acked, acknowledger.get(2))
+
+	// the third transaction of the block still finds it waiting
+	key := "block:" + blockHash.Hex() + ":transaction"
+	assert.Equal(t, 1, pipeline.counter(t, key))
+	exists, err := pipeline.blocks.CheckBlockExistsForTransaction(ctx, blockHash)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	assert.Equal(t, 1, pipeline.counter(t, "transaction:"+first.Hex()+":logs"))
+	assert.Equal(t, 1, pipeline.counter(t, "transaction:"+second.Hex()+":logs"))
+}
+
+func TestTransactionProcessor_RedeliveredCountsOnce(t *testing.T) {
+	ctx := context.Background()
+	pipeline := newCounterPipeline()
+	blockHash := common.HexToHash("0x01")
+	first, second := common.HexToHash("0x0a"), common.HexToHash("0x0b")
+
+	require.NoError(t, pipeline.blocks.SaveBlockHashForTransaction(ctx, blockHash, 2))
+
+	acknowledger := newRecordingAcknowledger()
+	processor := NewTransactionProcessor(pipeline.blocks, pipeline.transactions, zap.NewNop())
+	redelivered := acknowledger.delivery(2, transactionMessage(t, blockHash, first), nil)
+	redelivered.Redelivered = true
+
+	// the copies land in one batch and in separate messages
+	runBatch(processor, 2, acknowledger.delivery(1, transactionMessage(t, blockHash, first), nil), redelivered)
+	runBatch(processor, 1, acknowledger.delivery(3, transactionMessage(t, blockHash, first), nil))
+	assert.Equal(t, 1, pipeline.counter(t, "block:"+blockHash.Hex()+":transaction"))
+
+	runBatch(processor, 1, acknowledger.delivery(4, transactionMessage(t, blockHash, second), nil))
+	exists, err := pipeline.blocks.CheckBlockExistsForTransaction(ctx, blockHash)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestTransactionLogProcessor_CountsEveryLogOnce(t *testing.T) {
+	ctx := context.Background()
+	pipeline := newCounterPipeline()
+	transactionHash := common.HexToHash("0x0a")
+	key := "transaction:" + transactionHash.Hex() + ":logs"
+
+	require.NoError(t, pipeline.transactions.SaveTransactionHashForLog(ctx, transactionHash, 3))
+
+	acknowledger := newRecordingAcknowledger()
+	processor := NewTransactionLogProcessor(pipeline.transactions, zap.NewNop())
+	runBatch(processor, 3,
+		acknowledger.delivery(1, logMessage(t, transactionHash, 0), nil),
+		acknowledger.delivery(2, logMessage(t, transactionHash, 0), nil),
+		acknowledger.delivery(3, logMessage(t, transactionHash, 1), nil),
+	)
+	assert.Equal(t, 1, pipeline.counter(t, key))
+
+	runBatch(processor, 1, acknowledger.delivery(4, logMessage(t, transactionHash, 1), nil))
+	assert.Equal(t, 1, pipeline.counter(t, key))
+
+	runBatch(processor, 1, acknowledger.delivery(5, logMessage(t, transactionHash, 2), nil))
+	assert.Zero(t, pipeline.counter(t, key))
+	for tag := uint64(1); tag <= 5; tag++ {
+		assert.Equal(t, acked, acknowledger.get(tag))
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/withdrawal"
+	"github.com/elmiringos/indexer/indexer-core/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidVerifyRange  = errors.New("invalid verification range")
+	ErrVerifyRangeTooLarge = errors.New("verification range too large")
+	ErrFailedToVerifyBlock = errors.New("failed to verify block")
+)
+
+var mismatchKindLabels = map[block.MismatchKind]string{
+	block.MismatchTransactionsRoot:   "transactions_root",
+	block.MismatchReceiptsRoot:       "receipts_root",
+	block.MismatchLogsBloom:          "logs_bloom",
+	block.MismatchTransactionsCount:  "transactions_count",
+	block.MismatchWithdrawalsCount:   "withdrawals_count",
+	block.MismatchInvalidTransaction: "invalid_transaction",
+}
+
+// VerifierService checks stored blocks against the commitments of their headers
+type VerifierService struct {
+	blockRepository       block.Repository
+	transactionRepository transaction.Repository
+	withdrawalRepository  withdrawal.Repository
+	maxRange              uint64
+	log                   *zap.Logger
+}
+
+func NewVerifierService(
+	blockRepository block.Repository,
+	transactionRepository transaction.Repository,
+	withdrawalRepository withdrawal.Repository,
+	maxRange uint64,
+	log *zap.Logger,
+) *VerifierService {
+	return &VerifierService{
+		blockRepository:       blockRepository,
+		transactionRepository: transactionRepository,
+		withdrawalRepository:  withdrawalRepository,
+		maxRange:              maxRange,
+		log:                   log,
+	}
+}
+
+// VerifyRange reloads the blocks numbered from..to inclusive and recomputes their transactions root,
+// receipts root and logs bloom from the stored transactions and logs, and counts their transactions
+// and withdrawals. Blocks still being indexed report count mismatches, so only settled ranges should be verified.
+func (s *VerifierService) VerifyRange(ctx context.Context, from, to *domain.BigInt) (*block.VerificationReport, error) {
+	if from.Cmp(*to) > 0 || (*big.Int)(from).Sign() < 0 {
+		return nil, ErrInvalidVerifyRange
+	}
+	size := to.Sub(*from)
+	if s.maxRange > 0 && size.Uint64() >= s.maxRange {
+		return nil, fmt.Errorf("%w: at most %d blocks", ErrVerifyRangeTooLarge, s.maxRange)
+	}
+
+	blocks, err := s.blockRepository.GetBlocksInRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &block.VerificationReport{FromNumber: *from, ToNumber: *to}
+	for _, b := range blocks {
+		mismatches, checked, err := s.verifyBlock(ctx, b)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrFailedToVerifyBlock, b.Hash.Hex(), err)
+		}
+
+		result := "ok"
+		if checked {
+			report.CheckedBlocks++
+		} else {
+			report.SkippedBlocks++
+			result = "skipped"
+		}
+		if len(mismatches) > 0 {
+			result = "mismatch"
+		}
+		metrics.VerifiedBlocks.WithLabelValues(result).Inc()
+
+		for _, mismatch := range mismatches {
+			metrics.VerificationMismatches.WithLabelValues(mismatchKindLabels[mismatch.Kind]).Inc()
+			s.log.Warn("Block verification mismatch",
+				zap.String("number", mismatch.BlockNumber.String()),
+				zap.String("hash", mismatch.BlockHash.Hex()),
+				zap.String("kind", mismatchKindLabels[mismatch.Kind]),
+				zap.String("expected", mismatch.Expected),
+				zap.String("actual", mismatch.Actual),
+			)
+		}
+		report.Mismatches = append(report.Mismatches, mismatches...)
+	}
+
+	s.log.Info("Verified block range",
+		zap.String("from", from.String()),
+		zap.String("to", to.String()),
+		zap.Int64("checked", report.CheckedBlocks),
+		zap.Int64("skipped", report.SkippedBlocks),
+		zap.Int("mismatches", len(report.Mismatches)),
+	)
+
+	return report, nil
+}
+
+// verifyBlock returns the mismatches of a block and whether its roots could be recomputed
+func (s *VerifierService) verifyBlock(ctx context.Context, b *block.Block) ([]*block.Mismatch, bool, error) {
+	transactions, err := s.transactionRepository.GetBlockTransactions(ctx, b.Hash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	withdrawalsCount, err := s.withdrawalRepository.CountBlockWithdrawals(ctx, b.Hash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var mismatches []*block.Mismatch
+	mismatch := func(kind block.MismatchKind, expected, actual string) {
+		mismatches = append(mismatches, &block.Mismatch{
+			Kind:        kind,
+			BlockNumber: b.Number,
+			BlockHash:   b.Hash,
+			Expected:    expected,
+			Actual:      actual,
+		})
+	}
+
+	if len(transactions) != b.TransactionsCount {
+		mismatch(block.MismatchTransactionsCount, strconv.Itoa(b.TransactionsCount), strconv.Itoa(len(transactions)))
+	}
+	if withdrawalsCount != b.WithdrawalsCount {
+		mismatch(block.MismatchWithdrawalsCount, strconv.Itoa(b.WithdrawalsCount), strconv.Itoa(withdrawalsCount))
+	}
+
+	if !hasRoots(b, transactions) {
+		return mismatches, false, nil
+	}
+
+	logs, err := s.transactionRepository.GetBlockTransactionLogs(ctx, b.Hash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	txs := make(types.Transactions, 0, len(transactions))
+	for _, tx := range transactions {
+		var decoded types.Transaction
+		if err := decoded.UnmarshalBinary(tx.Raw); err != nil {
+			mismatch(block.MismatchInvalidTransaction, tx.Hash.Hex(), err.Error())
+			return mismatches, true, nil
+		}
+		if decoded.Hash() != tx.Hash {
+			mismatch(block.MismatchInvalidTransaction, tx.Hash.Hex(), decoded.Hash().Hex())
+			return mismatches, true, nil
+		}
+		txs = append(txs, &decoded)
+	}
+
+	transactionsRoot := types.DeriveSha(txs, trie.NewStackTrie(nil))
+	if transactionsRoot != b.TransactionsRoot {
+		mismatch(block.MismatchTransactionsRoot, b.TransactionsRoot.Hex(), transactionsRoot.Hex())
+	}
+
+	receipts := buildReceipts(transactions, logs)
+	receiptsRoot := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+	if receiptsRoot != b.ReceiptsRoot {
+		mismatch(block.MismatchReceiptsRoot, b.ReceiptsRoot.Hex(), receiptsRoot.Hex())
+	}
+
+	bloom := types.MergeBloom(receipts)
+	if bloom != types.BytesToBloom(b.LogsBloom) {
+		mismatch(block.MismatchLogsBloom, hexutil.Encode(b.LogsBloom), hexutil.Encode(bloom.Bytes()))
+	}
+
+	return mismatches, true, nil
+}
+
+// hasRoots reports whether the block was stored with its header commitments and every transaction with its encoding
+func hasRoots(b *block.Block, transactions []*transaction.Transaction) bool {
+	if len(b.LogsBloom) == 0 {
+		return false
+	}
+	for _, tx := range transactions {
+		if len(tx.Raw) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// buildReceipts rebuilds the consensus part of the receipts, the logs only carry the fields that are hashed
+func buildReceipts(transactions []*transaction.Transaction, logs []*transaction.TransactionLog) types.Receipts {
+	logsByIndex := make(map[uint][]*types.Log)
+	for _, txLog := range logs {
+		logsByIndex[txLog.TransactionIndex] = append(logsByIndex[txLog.TransactionIndex], &types.Log{
+			Address: txLog.Address,
+			Topics:  txLog.Topics,
+			Data:    txLog.Data,
+		})
+	}
+
+	receipts := make(types.Receipts, 0, len(transactions))
+	for _, tx := range transactions {
+		receipt := &types.Receipt{
+			Type:              tx.Type,
+			Status:            tx.Status,
+			PostState:         tx.PostState,
+			CumulativeGasUsed: tx.CumulativeGasUsed,
+			Logs:              logsByIndex[uint(tx.Index)],
+		}
+		if receipt.Logs == nil {
+			receipt.Logs = []*types.Log{}
+		}
+		receipt.Bloom = types.CreateBloom(receipt)
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/withdrawal"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type storedBlocks struct {
+	block.Repository
+	blocks []*block.Block
+}
+
+func (r *storedBlocks) GetBlocksInRange(context.Context, *domain.BigInt, *domain.BigInt) ([]*block.Block, error) {
+	return r.blocks, nil
+}
+
+type storedTransactions struct {
+	transaction.Repository
+	transactions map[common.Hash][]*transaction.Transaction
+	logs         map[common.Hash][]*transaction.TransactionLog
+}
+
+func (r *storedTransactions) GetBlockTransactions(_ context.Context, blockHash common.Hash) ([]*transaction.Transaction, error) {
+	return r.transactions[blockHash], nil
+}
+
+func (r *storedTransactions) GetBlockTransactionLogs(_ context.Context, blockHash common.Hash) ([]*transaction.TransactionLog, error) {
+	return r.logs[blockHash], nil
+}
+
+type storedWithdrawals struct {
+	withdrawal.Repository
+	counts map[common.Hash]int
+}
+
+func (r *storedWithdrawals) CountBlockWithdrawals(_ context.Context, blockHash common.Hash) (int, error) {
+	return r.counts[blockHash], nil
+}
+
+// storedChain holds blocks the way core stores them after indexing
+type storedChain struct {
+	blocks       *storedBlocks
+	transactions *storedTransactions
+	withdrawals  *storedWithdrawals
+}
+
+func newStoredChain() *storedChain {
+	return &storedChain{
+		blocks: &storedBlocks{},
+		transactions: &storedTransactions{
+			transactions: make(map[common.Hash][]*transaction.Transaction),
+			logs:         make(map[common.Hash][]*transaction.TransactionLog),
+		},
+		withdrawals: &storedWithdrawals{counts: make(map[common.Hash]int)},
+	}
+}
+
+// store converts a block and its receipts into the entities the producer publishes
+func (c *storedChain) store(t *testing.T, b *types.Block, receipts types.Receipts) {
+	t.Helper()
+
+	c.blocks.blocks = append(c.blocks.blocks, &block.Block{
+		Hash:              b.Hash(),
+		Number:            *domain.FromBytesToBigInt(b.Number().Bytes()),
+		TransactionsCount: len(b.Transactions()),
+		WithdrawalsCount:  len(b.Withdrawals()),
+		TransactionsRoot:  b.TxHash(),
+		ReceiptsRoot:      b.ReceiptHash(),
+		LogsBloom:         b.Bloom().Bytes(),
+	})
+	c.withdrawals.counts[b.Hash()] = len(b.Withdrawals())
+
+	for i, tx := range b.Transactions() {
+		raw, err := tx.MarshalBinary()
+		require.NoError(t, err)
+
+		receipt := receipts[i]
+		c.transactions.transactions[b.Hash()] = append(c.transactions.transactions[b.Hash()], &transaction.Transaction{
+			Hash:              tx.Hash(),
+			BlockHash:         b.Hash(),
+			Index:             i,
+			Status:            receipt.Status,
+			Type:              tx.Type(),
+			Raw:               raw,
+			CumulativeGasUsed: receipt.CumulativeGasUsed,
+			PostState:         receipt.PostState,
+		})
+
+		for _, txLog := range receipt.Logs {
+			c.transactions.logs[b.Hash()] = append(c.transactions.logs[b.Hash()], &transaction.TransactionLog{
+				Address:          txLog.Address,
+				Topics:           txLog.Topics,
+				TransactionHash:  tx.Hash(),
+				BlockHash:        b.Hash(),
+				TransactionIndex: uint(i),
+				Data:             txLog.Data,
+			})
+		}
+	}
+}
+
+func (c *storedChain) verify(t *testing.T) *block.VerificationReport {
+	t.Helper()
+
+	verifier := NewVerifierService(c.blocks, c.transactions, c.withdrawals, 0, zap.NewNop())
+	from := c.blocks.blocks[0].Number
+	to := c.blocks.blocks[len(c.blocks.blocks)-1].Number
+
+	report, err := verifier.VerifyRange(context.Background(), &from, &to)
+	require.NoError(t, err)
+
+	return report
+}
+
+// loadSepoliaBlock reads Sepolia block 176360, cut from the sepolia-00021 era1 archive. It holds
+// three EIP-1559 transfers.
+func loadSepoliaBlock(t *testing.T) (*types.Block, types.Receipts) {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/sepolia_176360.json")
+	require.NoError(t, err)
+
+	var fixture struct {
+		Header   hexutil.Bytes `json:"header"`
+		Body     hexutil.Bytes `json:"body"`
+		Receipts hexutil.Bytes `json:"receipts"`
+	}
+	require.NoError(t, json.Unmarshal(data, &fixture))
+
+	var (
+		header   types.Header
+		body     types.Body
+		receipts types.Receipts
+	)
+	require.NoError(t, rlp.DecodeBytes(fixture.Header, &header))
+	require.NoError(t, rlp.DecodeBytes(fixture.Body, &body))
+	require.NoError(t, rlp.DecodeBytes(fixture.Receipts, &receipts))
+
+	return types.NewBlockWithHeader(&header).WithBody(body), receipts
+}
+
+func TestVerifierService_SepoliaBlock(t *testing.T) {
+	b, receipts := loadSepoliaBlock(t)
+	require.Equal(t, common.HexToHash("0xc6a281877ba79b69420307d17e39765076165a6d6085d589d41412e99905b5d3"), b.Hash())
+	require.Equal(t, common.HexToHash("0x9a277b43029b4aa264920196f87681e5f5b87f069d5ea8a40a4c60385b9bea1d"), b.TxHash())
+	require.Equal(t, common.HexToHash("0x25e6b7af647c519a27cc13276a1e6abc46154b51414d174b072698df1f6c19df"), b.ReceiptHash())
+	for _, tx := range b.Transactions() {
+		require.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	}
+
+	chain := newStoredChain()
+	chain.store(t, b, receipts)
+
+	report := chain.verify(t)
+	assert.Equal(t, int64(1), report.CheckedBlocks)
+	assert.Empty(t, report.Mismatches)
+
+	// a wrong status only changes the receipts
+	chain.transactions.transactions[b.Hash()][1].Status = types.ReceiptStatusFailed
+	report = chain.verify(t)
+	require.Len(t, report.Mismatches, 1)
+	assert.Equal(t, block.MismatchReceiptsRoot, report.Mismatches[0].Kind)
+	assert.Equal(t, b.ReceiptHash().Hex(), report.Mismatches[0].Expected)
+}
+
+func TestVerifierService_MissingTransaction(t *testing.T) {
+	b, receipts := loadSepoliaBlock(t)
+
+	chain := newStoredChain()
+	chain.store(t, b, receipts)
+	stored := chain.transactions.transactions[b.Hash()]
+	chain.transactions.transactions[b.Hash()] = stored[:2]
+
+	report := chain.verify(t)
+	kinds := make([]block.MismatchKind, len(report.Mismatches))
+	for i, mismatch := range report.Mismatches {
+		kinds[i] = mismatch.Kind
+	}
+	assert.Equal(t, []block.MismatchKind{
+		block.MismatchTransactionsCount,
+		block.MismatchTransactionsRoot,
+		block.MismatchReceiptsRoot,
+	}, kinds)
+}
+
+// TestVerifierService_PreByzantiumPostState executes a block before Byzantium, its receipts commit to the
+// intermediate state root instead of a status and one of them carries a log
+func TestVerifierService_PreByzantiumPostState(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	// LOG1 of 32 zero bytes with topic 0xaa
+	emitter := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	config := &params.ChainConfig{
+		ChainID:        big.NewInt(1),
+		HomesteadBlock: big.NewInt(0),
+		EIP150Block:    big.NewInt(0),
+		EIP155Block:    big.NewInt(0),
+		EIP158Block:    big.NewInt(0),
+		Ethash:         new(params.EthashConfig),
+	}
+	genesis := &core.Genesis{
+		Config: config,
+		Alloc: types.GenesisAlloc{
+			sender:  {Balance: big.NewInt(params.Ether)},
+			emitter: {Code: common.FromHex("0x60aa60206000a100")},
+		},
+	}
+	signer := types.LatestSigner(config)
+
+	_, blocks, receipts := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 1, func(_ int, gen *core.BlockGen) {
+		recipient := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		for nonce, to := range []common.Address{recipient, emitter} {
+			tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    uint64(nonce),
+				GasPrice: big.NewInt(params.GWei),
+				Gas:      100_000,
+				To:       &to,
+				Value:    big.NewInt(1),
+			})
+			require.NoError(t, err)
+			gen.AddTx(tx)
+		}
+	})
+
+	b := blocks[0]
+	require.Len(t, receipts[0], 2)
+	for _, receipt := range receipts[0] {
+		require.Len(t, receipt.PostState, common.HashLength)
+	}
+	require.Len(t, receipts[0][1].Logs, 1)
+
+	chain := newStoredChain()
+	chain.store(t, b, receipts[0])
+
+	report := chain.verify(t)
+	assert.Equal(t, int64(1), report.CheckedBlocks)
+	assert.Empty(t, report.Mismatches)
+
+	// the post state is part of the receipt encoding
+	chain.transactions.transactions[b.Hash()][0].PostState = common.Hash{}.Bytes()
+	report = chain.verify(t)
+	require.Len(t, report.Mismatches, 1)
+	assert.Equal(t, block.MismatchReceiptsRoot, report.Mismatches[0].Kind)
+
+	// dropping the log changes the receipts root and the bloom
+	chain = newStoredChain()
+	chain.store(t, b, receipts[0])
+	chain.transactions.logs[b.Hash()] = nil
+	report = chain.verify(t)
+	kinds := make([]block.MismatchKind, len(report.Mismatches))
+	for i, mismatch := range report.Mismatches {
+		kinds[i] = mismatch.Kind
+	}
+	assert.Equal(t, []block.MismatchKind{block.MismatchReceiptsRoot, block.MismatchLogsBloom}, kinds)
+}
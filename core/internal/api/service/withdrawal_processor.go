@@ -8,6 +8,7 @@ import (
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
 )
 
@@ -52,3 +53,35 @@ func (p *WithdrawalProcessor) Process(ctx context.Context, data []byte) error {
 
 	return nil
 }
+
+// ProcessBatch saves the withdrawals in one database transaction once every block they belong to is registered
+func (p *WithdrawalProcessor) ProcessBatch(ctx context.Context, batch [][]byte) error {
+	withdrawals, err := decodeBatch[withdrawal.Withdrawal](batch)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToUnmarshalWithdrawal, err)
+	}
+
+	checked := make(map[common.Hash]bool)
+	for _, w := range withdrawals {
+		if checked[w.BlockHash] {
+			continue
+		}
+
+		blockExists, err := p.blockRepository.CheckBlockExistsForWithdrawal(ctx, w.BlockHash)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToCheckBlockExistsForWithdrawal, err)
+		}
+		if !blockExists {
+			return fmt.Errorf("%w: %s", ErrBlockDoesNotExistForWithdrawal, w.BlockHash)
+		}
+		checked[w.BlockHash] = true
+	}
+
+	if err := p.withdrawalRepository.SaveWithdrawals(ctx, withdrawals); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSaveWithdrawal, err)
+	}
+
+	p.log.Info("Withdrawals saved successfully", zap.Int("count", len(withdrawals)))
+
+	return nil
+}
@@ -2,7 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
+	"time"
+
+	"github.com/elmiringos/indexer/indexer-core/pkg/metrics"
+	"github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
 
 	"github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
@@ -13,66 +18,278 @@ type MessageProcessor interface {
 	Process(context.Context, []byte) error
 }
 
+// BatchProcessor is implemented by processors that can write several messages in one database transaction.
+// ProcessBatch either stores every message or returns an error, the pool then retries them one by one.
+type BatchProcessor interface {
+	MessageProcessor
+	ProcessBatch(context.Context, [][]byte) error
+}
+
+// decodeBatch unmarshals every message of a batch, a message that does not decode fails the batch
+func decodeBatch[T any](batch [][]byte) ([]*T, error) {
+	items := make([]*T, len(batch))
+	for i, data := range batch {
+		items[i] = new(T)
+		if err := json.Unmarshal(data, items[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// BatchConfig sets how many messages a worker collects before writing them. Linger caps how long
+// it waits for more messages after the first one, a Size of 1 processes every message on its own.
+type BatchConfig struct {
+	Size   int
+	Linger time.Duration
+}
+
+// delivery is a message together with what the gate needs to know about it
+type delivery struct {
+	msg   amqp091.Delivery
+	stamp MessageStamp
+}
+
 // WorkerPool represents a generic worker pool for processing messages
 type WorkerPool struct {
+	queue       string
 	processor   MessageProcessor
+	gate        *PipelineGate
 	log         *zap.Logger
 	workerCount int
+	batch       BatchConfig
 }
 
-// NewWorkerPool creates a new WorkerPool
-func NewWorkerPool(processor MessageProcessor, log *zap.Logger, workerCount int) *WorkerPool {
+// NewWorkerPool creates a new WorkerPool consuming the given queue, pausing whenever the gate is paused.
+// Messages are batched when the processor implements BatchProcessor and batch.Size is above 1.
+func NewWorkerPool(queue string, processor MessageProcessor, gate *PipelineGate, log *zap.Logger, workerCount int, batch BatchConfig) *WorkerPool {
 	return &WorkerPool{
+		queue:       queue,
 		processor:   processor,
+		gate:        gate,
 		log:         log,
 		workerCount: workerCount,
+		batch:       batch,
 	}
 }
 
 // Start starts the worker pool
 func (p *WorkerPool) Start(msgs <-chan amqp091.Delivery) {
-	p.log.Info("Starting worker pool", zap.Int("worker_count", p.workerCount))
+	batchProcessor, batched := p.processor.(BatchProcessor)
+	batched = batched && p.batch.Size > 1
+
+	p.log.Info("Starting worker pool", zap.String("queue", p.queue), zap.Int("worker_count", p.workerCount), zap.Bool("batched", batched))
+	metrics.WorkersTotal.WithLabelValues(p.queue).Set(float64(p.workerCount))
+
+	deliveries := p.receive(msgs, batched)
 
 	var wg sync.WaitGroup
 
 	for i := 0; i < p.workerCount; i++ {
 		wg.Add(1)
-		go p.worker(i, &wg, msgs)
+		if batched {
+			go p.batchWorker(i, &wg, deliveries, batchProcessor)
+		} else {
+			go p.worker(i, &wg, deliveries)
+		}
 	}
 
 	wg.Wait()
 }
 
+// receive stamps every message with the epoch at the moment it comes off the broker, before it
+// waits for a free worker, so a message received before a pause is stale even if a worker takes it later
+func (p *WorkerPool) receive(msgs <-chan amqp091.Delivery, batched bool) <-chan delivery {
+	size := p.workerCount
+	if batched {
+		size *= p.batch.Size
+	}
+
+	deliveries := make(chan delivery, size)
+	go func() {
+		defer close(deliveries)
+		for msg := range msgs {
+			deliveries <- p.stamp(msg)
+		}
+	}()
+
+	return deliveries
+}
+
+func (p *WorkerPool) stamp(msg amqp091.Delivery) delivery {
+	generation, ok := rabbitmq.ResetGeneration(msg.Headers)
+	return delivery{
+		msg: msg,
+		stamp: MessageStamp{
+			Epoch:         p.gate.Epoch(),
+			Generation:    generation,
+			HasGeneration: ok,
+		},
+	}
+}
+
 // worker is a worker function that processes messages
-func (p *WorkerPool) worker(id int, wg *sync.WaitGroup, msgs <-chan amqp091.Delivery) {
+func (p *WorkerPool) worker(id int, wg *sync.WaitGroup, deliveries <-chan delivery) {
 	defer wg.Done()
 
-	for msg := range msgs {
+	for d := range deliveries {
+		if stale := p.gate.Enter(d.stamp); stale {
+			p.gate.Leave()
+			p.dropStale(id, d.msg)
+			continue
+		}
+
 		p.log.Info("Worker processing message", zap.Int("worker_id", id))
+		p.handle(id, d.msg)
+		p.gate.Leave()
+	}
+}
+
+// batchWorker collects messages into batches and writes each batch in one call
+func (p *WorkerPool) batchWorker(id int, wg *sync.WaitGroup, deliveries <-chan delivery, processor BatchProcessor) {
+	defer wg.Done()
 
-		err := p.processor.Process(context.Background(), msg.Body)
-		if err != nil {
-			p.log.Error(
-				"Failed to process message",
-				zap.Error(err),
-				zap.Int("worker_id", id),
-			)
-
-			// Send nack with requeue=true to retry processing the message
-			if nackErr := msg.Nack(false, true); nackErr != nil {
-				p.log.Fatal("Error sending nack message", zap.Error(nackErr))
+	for {
+		batch, open := p.collect(deliveries)
+		if len(batch) > 0 {
+			p.handleBatch(id, processor, batch)
+		}
+		if !open {
+			return
+		}
+	}
+}
+
+// collect waits for a message, then takes more until the batch is full or Linger has passed
+func (p *WorkerPool) collect(deliveries <-chan delivery) (batch []delivery, open bool) {
+	d, ok := <-deliveries
+	if !ok {
+		return nil, false
+	}
+	batch = append(batch, d)
+
+	timer := time.NewTimer(p.batch.Linger)
+	defer timer.Stop()
+
+	for len(batch) < p.batch.Size {
+		select {
+		case d, ok := <-deliveries:
+			if !ok {
+				return batch, false
 			}
-			continue
+			batch = append(batch, d)
+		case <-timer.C:
+			return batch, true
 		}
+	}
+
+	return batch, true
+}
 
-		// Acknowledge the message after successful processing
-		if ackErr := msg.Ack(false); ackErr != nil {
-			p.log.Error("Failed to acknowledge message",
-				zap.Error(ackErr),
-				zap.Int("worker_id", id))
+// handleBatch writes the batch and acks every message once it is committed. When the batch fails
+// each message is processed on its own, so one bad message only requeues itself.
+func (p *WorkerPool) handleBatch(id int, processor BatchProcessor, batch []delivery) {
+	stamps := make([]MessageStamp, len(batch))
+	for i, d := range batch {
+		stamps[i] = d.stamp
+	}
+
+	stale := p.gate.EnterBatch(stamps)
+	defer p.gate.Leave()
+
+	fresh := make([]amqp091.Delivery, 0, len(batch))
+	bodies := make([][]byte, 0, len(batch))
+	for i, d := range batch {
+		if stale[i] {
+			p.dropStale(id, d.msg)
 			continue
 		}
+		fresh = append(fresh, d.msg)
+		bodies = append(bodies, d.msg.Body)
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	p.log.Info("Worker processing batch", zap.Int("worker_id", id), zap.Int("size", len(fresh)))
+	metrics.BatchSize.WithLabelValues(p.queue).Observe(float64(len(fresh)))
+
+	busy := metrics.WorkersBusy.WithLabelValues(p.queue)
+	busy.Inc()
+	start := time.Now()
+	err := processor.ProcessBatch(context.Background(), bodies)
+	busy.Dec()
+
+	if err != nil {
+		p.log.Warn("Failed to process batch, processing its messages one by one",
+			zap.Error(err),
+			zap.Int("worker_id", id),
+			zap.Int("size", len(fresh)),
+		)
+		metrics.BatchFallbacks.WithLabelValues(p.queue).Inc()
+
+		for _, msg := range fresh {
+			p.handle(id, msg)
+		}
+		return
+	}
+
+	// the histogram tracks single messages, a batch is accounted as its average message
+	perMessage := time.Since(start).Seconds() / float64(len(fresh))
+	for _, msg := range fresh {
+		metrics.ProcessingDuration.WithLabelValues(p.queue, "ok").Observe(perMessage)
+		p.ack(id, msg)
+	}
+}
+
+// handle processes a single message and acks it, or requeues it when processing fails
+func (p *WorkerPool) handle(id int, msg amqp091.Delivery) {
+	busy := metrics.WorkersBusy.WithLabelValues(p.queue)
+
+	busy.Inc()
+	start := time.Now()
+	err := p.processor.Process(context.Background(), msg.Body)
+	busy.Dec()
+
+	if err != nil {
+		p.log.Error(
+			"Failed to process message",
+			zap.Error(err),
+			zap.Int("worker_id", id),
+		)
+		metrics.ProcessingDuration.WithLabelValues(p.queue, "error").Observe(time.Since(start).Seconds())
+
+		// Send nack with requeue=true to retry processing the message
+		if nackErr := msg.Nack(false, true); nackErr != nil {
+			p.log.Fatal("Error sending nack message", zap.Error(nackErr))
+		}
+		metrics.MessagesNacked.WithLabelValues(p.queue).Inc()
+		return
+	}
+
+	metrics.ProcessingDuration.WithLabelValues(p.queue, "ok").Observe(time.Since(start).Seconds())
+	p.ack(id, msg)
+}
+
+// ack acknowledges a processed message
+func (p *WorkerPool) ack(id int, msg amqp091.Delivery) {
+	if ackErr := msg.Ack(false); ackErr != nil {
+		p.log.Error("Failed to acknowledge message",
+			zap.Error(ackErr),
+			zap.Int("worker_id", id))
+		return
+	}
+	metrics.MessagesAcked.WithLabelValues(p.queue).Inc()
+
+	p.log.Info("Message processed successfully", zap.Int("worker_id", id), zap.String("msg_id", string(msg.MessageId)))
+}
 
-		p.log.Info("Message processed successfully", zap.Int("worker_id", id), zap.String("msg_id", string(msg.MessageId)))
+// dropStale rejects a message received or published before a state reset, its data no longer belongs to the pipeline
+func (p *WorkerPool) dropStale(id int, msg amqp091.Delivery) {
+	p.log.Warn("Dropping message received before state reset", zap.Int("worker_id", id))
+	if nackErr := msg.Nack(false, false); nackErr != nil {
+		p.log.Error("Error sending nack message", zap.Error(nackErr))
 	}
+	metrics.MessagesNacked.WithLabelValues(p.queue).Inc()
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elmiringos/indexer/indexer-core/pkg/rabbitmq"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// settlement is how a delivery was settled: acked, nacked with requeue or dropped
+type settlement string
+
+const (
+	acked    settlement = "ack"
+	requeued settlement = "requeue"
+	dropped  settlement = "drop"
+)
+
+// recordingAcknowledger records how every delivery tag was settled
+type recordingAcknowledger struct {
+	mu          sync.Mutex
+	settlements map[uint64]settlement
+}
+
+func newRecordingAcknowledger() *recordingAcknowledger {
+	return &recordingAcknowledger{settlements: make(map[uint64]settlement)}
+}
+
+func (a *recordingAcknowledger) settle(tag uint64, s settlement) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.settlements[tag] = s
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, _ bool) error {
+	a.settle(tag, acked)
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, _ bool, requeue bool) error {
+	if requeue {
+		a.settle(tag, requeued)
+	} else {
+		a.settle(tag, dropped)
+	}
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func (a *recordingAcknowledger) get(tag uint64) settlement {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settlements[tag]
+}
+
+func (a *recordingAcknowledger) delivery(tag uint64, body string, headers amqp091.Table) amqp091.Delivery {
+	return amqp091.Delivery{Acknowledger: a, DeliveryTag: tag, Body: []byte(body), Headers: headers}
+}
+
+// funcProcessor processes messages with a function
+type funcProcessor func(ctx context.Context, data []byte) error
+
+func (f funcProcessor) Process(ctx context.Context, data []byte) error {
+	return f(ctx, data)
+}
+
+func generationHeader(generation int64) amqp091.Table {
+	return amqp091.Table{rabbitmq.ResetGenerationHeader: generation}
+}
+
+func TestWorkerPool_DropsOlderGeneration(t *testing.T) {
+	gate := NewPipelineGate()
+	gate.SetGeneration(2)
+	acknowledger := newRecordingAcknowledger()
+
+	var processed []string
+	processor := funcProcessor(func(_ context.Context, data []byte) error {
+		processed = append(processed, string(data))
+		return nil
+	})
+
+	msgs := make(chan amqp091.Delivery, 4)
+	msgs <- acknowledger.delivery(1, "old", generationHeader(1))
+	msgs <- acknowledger.delivery(2, "current", generationHeader(2))
+	msgs <- acknowledger.delivery(3, "unstamped", nil)
+	close(msgs)
+
+	NewWorkerPool("test", processor, gate, zap.NewNop(), 1, BatchConfig{Size: 1}).Start(msgs)
+
+	assert.Equal(t, []string{"current", "unstamped"}, processed)
+	assert.Equal(t, dropped, acknowledger.get(1))
+	assert.Equal(t, acked, acknowledger.get(2))
+	assert.Equal(t, acked, acknowledger.get(3))
+}
+
+// A message received while another one is processed waits for the worker, a pause in between makes it
+// stale even though the worker only takes it after the pause
+func TestWorkerPool_StampsEpochOnReceipt(t *testing.T) {
+	gate := NewPipelineGate()
+	acknowledger := newRecordingAcknowledger()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	processor := funcProcessor(func(_ context.Context, data []byte) error {
+		if string(data) == "first" {
+			close(started)
+			<-release
+		}
+		return nil
+	})
+
+	msgs := make(chan amqp091.Delivery)
+	done := make(chan struct{})
+	go func() {
+		NewWorkerPool("test", processor, gate, zap.NewNop(), 1, BatchConfig{Size: 1}).Start(msgs)
+		close(done)
+	}()
+
+	msgs <- acknowledger.delivery(1, "first", nil)
+	<-started
+	msgs <- acknowledger.delivery(2, "second", nil)
+	// the pool takes a third message only once the second one is stamped
+	msgs <- acknowledger.delivery(3, "third", nil)
+
+	paused := make(chan func())
+	go func() { paused <- gate.Pause() }()
+	// a pending Pause makes TryRLock fail, the worker cannot enter again before it
+	for gate.mu.TryRLock() {
+		gate.mu.RUnlock()
+		runtime.Gosched()
+	}
+	close(release)
+	resume := <-paused
+	resume()
+
+	close(msgs)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker pool did not stop")
+	}
+
+	assert.Equal(t, acked, acknowledger.get(1))
+	assert.Equal(t, dropped, acknowledger.get(2))
+}
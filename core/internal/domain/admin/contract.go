@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+)
+
+type Repository interface {
+	// ResetState removes indexed data, every table when fromBlock is nil or only blocks above it otherwise,
+	// and bumps the reset generation. A partial reset also drops the coordination keys of the removed blocks.
+	// beforeCommit runs inside the database transaction so the reset is rolled back when it fails.
+	ResetState(ctx context.Context, fromBlock *domain.BigInt, beforeCommit func(ctx context.Context) error) (*ResetResult, error)
+	// GetResetGeneration returns the number of resets done so far
+	GetResetGeneration(ctx context.Context) (uint64, error)
+	SaveAuditEntry(ctx context.Context, entry *AuditEntry) error
+}
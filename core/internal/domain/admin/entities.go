@@ -0,0 +1,27 @@
+package admin
+
+import "github.com/elmiringos/indexer/indexer-core/internal/domain"
+
+const (
+	ActionResetState      = "reset_state"
+	ActionEnableBulkMode  = "enable_bulk_mode"
+	ActionDisableBulkMode = "disable_bulk_mode"
+)
+
+// AuditEntry records a privileged operation and its outcome
+type AuditEntry struct {
+	Action  string
+	Actor   string
+	Params  map[string]interface{}
+	Success bool
+	Error   string
+}
+
+// ResetResult summarizes what a state reset removed
+type ResetResult struct {
+	// FromBlock is the height above which data was removed, nil for a full reset
+	FromBlock     *domain.BigInt
+	DeletedBlocks int64
+	// Generation is the reset generation after the reset, messages published before it are dropped
+	Generation uint64
+}
@@ -3,21 +3,30 @@ package block
 import (
 	"context"
 
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type Repository interface {
 	GetCurrentBlock(ctx context.Context) (*Block, error)
+	GetIndexedRange(ctx context.Context) (*IndexedRange, error)
+	GetIndexingStatus(ctx context.Context) (*IndexingStatus, error)
+	ListGaps(ctx context.Context, after *domain.BigInt, limit int) ([]*Gap, error)
+	GetBlockStatus(ctx context.Context, hash common.Hash) (*BlockStatus, error)
+	GetBlocksInRange(ctx context.Context, from, to *domain.BigInt) ([]*Block, error)
 	SaveBlock(ctx context.Context, b *Block) error
+	SaveBlocks(ctx context.Context, blocks []*Block) error
 	SaveBlockHashForTransaction(ctx context.Context, hash common.Hash, transactionCount int) error
 	SaveBlockHashForWithdrawal(ctx context.Context, hash common.Hash, withdrawalCount int) error
 	SaveBlockHashForReward(ctx context.Context, hash common.Hash, rewardCount int) error
+	ResetBlockHashCounts(ctx context.Context, hash common.Hash, transactionCount, withdrawalCount, rewardCount int) error
 	DeleteBlockHashForTransaction(ctx context.Context, hash common.Hash) error
 	DeleteBlockHashForWithdrawal(ctx context.Context, hash common.Hash) error
 	DeleteBlockHashForReward(ctx context.Context, hash common.Hash) error
-	DecrementBlockHashTransactionCount(ctx context.Context, hash common.Hash) error
-	DecrementBlockHashWithdrawalCount(ctx context.Context, hash common.Hash) error
-	DecrementBlockHashRewardCount(ctx context.Context, hash common.Hash) error
+	// the decrements count every transaction, withdrawal and reward of the block once
+	DecrementBlockHashTransactionCount(ctx context.Context, hash common.Hash, transactionHash common.Hash) error
+	DecrementBlockHashWithdrawalCount(ctx context.Context, hash common.Hash, index uint64) error
+	DecrementBlockHashRewardCount(ctx context.Context, hash common.Hash, address common.Address) error
 	CheckBlockExistsForTransaction(ctx context.Context, hash common.Hash) (bool, error)
 	CheckBlockExistsForWithdrawal(ctx context.Context, hash common.Hash) (bool, error)
 	CheckBlockExistsForReward(ctx context.Context, hash common.Hash) (bool, error)
@@ -1,6 +1,8 @@
 package block
 
 import (
+	"time"
+
 	"github.com/elmiringos/indexer/indexer-core/internal/domain"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -20,6 +22,22 @@ type Block struct {
 	TransactionsCount int            `json:"transactions_count"`
 	WithdrawalsCount  int            `json:"withdrawals_count"`
 	Timestamp         uint64         `json:"timestamp"`
+	TransactionsRoot  common.Hash    `json:"transactions_root"`
+	ReceiptsRoot      common.Hash    `json:"receipts_root"`
+	LogsBloom         []byte         `json:"logs_bloom"`
+	StateRoot         common.Hash    `json:"state_root"`
+	MixHash           common.Hash    `json:"mix_hash"`
+	// Reindexed is set by the producer on blocks published again on request
+	Reindexed bool `json:"reindexed,omitempty"`
+}
+
+// IndexedRange describes how far the block table is fully indexed without holes
+type IndexedRange struct {
+	// LowestNumber is the lowest block number present in the database
+	LowestNumber domain.BigInt
+	// NextNumber is the first block number at or above LowestNumber that is missing or incomplete,
+	// every block below it is stored together with all of its transactions, withdrawals and reward
+	NextNumber domain.BigInt
 }
 
 func (b *Block) ToMap() map[string]interface{} {
@@ -38,6 +56,11 @@ func (b *Block) ToMap() map[string]interface{} {
 		"transactions_count": b.TransactionsCount,
 		"withdrawals_count":  b.WithdrawalsCount,
 		"timestamp":          b.Timestamp,
+		"transactions_root":  b.TransactionsRoot,
+		"receipts_root":      b.ReceiptsRoot,
+		"logs_bloom":         b.LogsBloom,
+		"state_root":         b.StateRoot,
+		"mix_hash":           b.MixHash,
 	}
 }
 
@@ -48,3 +71,92 @@ func MakeSlice(blocks []*Block) []map[string]interface{} {
 	}
 	return slices
 }
+
+// IndexingStatus summarizes the indexed block range and how much of it is complete
+type IndexingStatus struct {
+	IndexedRange
+	HighestNumber    domain.BigInt
+	TotalBlocks      int64
+	CompleteBlocks   int64
+	IncompleteBlocks int64
+	// MissingBlocks counts numbers between the lowest and highest block that are not stored at all
+	MissingBlocks int64
+	LastUpdatedAt time.Time
+}
+
+type GapKind int
+
+const (
+	// GapMissing is a range of block numbers that are not stored
+	GapMissing GapKind = iota + 1
+	// GapIncomplete is a range of stored blocks still missing transactions, withdrawals or the reward
+	GapIncomplete
+)
+
+// Gap is an inclusive range of block numbers that is not fully indexed
+type Gap struct {
+	Kind       GapKind
+	FromNumber domain.BigInt
+	ToNumber   domain.BigInt
+}
+
+// TransactionStatus holds the children of a transaction that have not arrived yet
+type TransactionStatus struct {
+	Hash           common.Hash
+	PendingLogs    int
+	PendingActions int
+}
+
+// BlockStatus holds the children of a block that have not arrived yet according to the coordination counters
+type BlockStatus struct {
+	Hash                common.Hash
+	Number              domain.BigInt
+	PendingTransactions int
+	PendingWithdrawals  int
+	PendingRewards      int
+	// PendingTransactionChildren lists only the transactions that still wait for logs or actions
+	PendingTransactionChildren []*TransactionStatus
+}
+
+// Complete reports whether nothing is pending for the block
+func (s *BlockStatus) Complete() bool {
+	return s.PendingTransactions == 0 && s.PendingWithdrawals == 0 && s.PendingRewards == 0 && len(s.PendingTransactionChildren) == 0
+}
+
+type MismatchKind int
+
+const (
+	// MismatchTransactionsRoot means the stored transactions do not hash to the header transactions root
+	MismatchTransactionsRoot MismatchKind = iota + 1
+	// MismatchReceiptsRoot means the receipts rebuilt from the stored transactions and logs do not hash to the header receipts root
+	MismatchReceiptsRoot
+	// MismatchLogsBloom means the bloom of the stored logs differs from the header bloom
+	MismatchLogsBloom
+	// MismatchTransactionsCount means the stored transactions differ in number from transactions_count
+	MismatchTransactionsCount
+	// MismatchWithdrawalsCount means the stored withdrawals differ in number from withdrawals_count
+	MismatchWithdrawalsCount
+	// MismatchInvalidTransaction means a stored raw transaction does not decode to its own hash
+	MismatchInvalidTransaction
+)
+
+// Mismatch is a stored block whose data disagrees with its header or counts
+type Mismatch struct {
+	Kind        MismatchKind
+	BlockNumber domain.BigInt
+	BlockHash   common.Hash
+	Expected    string
+	Actual      string
+}
+
+// VerificationReport summarizes the verification of an inclusive block range
+type VerificationReport struct {
+	FromNumber domain.BigInt
+	ToNumber   domain.BigInt
+	// CheckedBlocks counts the blocks whose roots were recomputed
+	CheckedBlocks int64
+	// SkippedBlocks counts the blocks stored without the header roots or raw transactions,
+	// only their counts are checked
+	SkippedBlocks int64
+	Mismatches    []*Mismatch
+}
@@ -0,0 +1,13 @@
+package bulk
+
+import "context"
+
+type Repository interface {
+	// StagingExists reports whether the staging tables are present, bulk mode outlives a restart through them
+	StagingExists(ctx context.Context) (bool, error)
+	// CreateStaging creates an unlogged copy of every indexed table keeping only its primary key
+	CreateStaging(ctx context.Context) error
+	// Merge moves the staged rows into the main tables and drops the staging tables in one transaction,
+	// progress is called before every step
+	Merge(ctx context.Context, progress func(Progress)) error
+}
@@ -0,0 +1,43 @@
+package bulk
+
+import "time"
+
+type State string
+
+const (
+	// StateOff writes straight to the main tables
+	StateOff State = "off"
+	// StateLoading writes to the staging tables
+	StateLoading State = "loading"
+	// StateMerging moves the staging tables into the main tables, the pipeline is held meanwhile
+	StateMerging State = "merging"
+)
+
+type Phase string
+
+const (
+	// PhasePrepare drops the foreign keys and secondary indexes of the main tables and disables their triggers
+	PhasePrepare             Phase = "prepare"
+	PhaseMergeTables         Phase = "merge_tables"
+	PhaseRebuildIndexes      Phase = "rebuild_indexes"
+	PhaseValidateConstraints Phase = "validate_constraints"
+	// PhaseCleanup enables the triggers again, analyzes the main tables and drops the staging tables
+	PhaseCleanup Phase = "cleanup"
+)
+
+// Progress describes the merge step being run, Done of Total steps of its phase are finished
+type Progress struct {
+	Phase      Phase
+	Step       string
+	Done       int
+	Total      int
+	MergedRows int64
+}
+
+// Status is the bulk mode state, Progress and StartedAt describe the running or the last merge
+type Status struct {
+	State     State
+	Progress  Progress
+	StartedAt time.Time
+	LastError string
+}
@@ -4,4 +4,5 @@ import "context"
 
 type Repository interface {
 	SaveReward(ctx context.Context, reward *Reward) error
+	SaveRewards(ctx context.Context, rewards []*Reward) error
 }
@@ -25,8 +25,16 @@ type TokenEvent struct {
 	SmartContractDeployed bool           `json:"smart_contract_deployed"`
 }
 
+// Token standards, stored in token.type
+const (
+	TypeERC20   = "ERC-20"
+	TypeERC721  = "ERC-721"
+	TypeERC1155 = "ERC-1155"
+)
+
 type Token struct {
 	Address              common.Address
+	Type                 string
 	Name                 string
 	Symbol               string
 	TotalSupply          domain.BigInt
@@ -38,6 +46,7 @@ type Token struct {
 func (t *Token) ToMap() map[string]interface{} {
 	return map[string]interface{}{
 		"address":                t.Address,
+		"type":                   t.Type,
 		"name":                   t.Name,
 		"symbol":                 t.Symbol,
 		"total_supply":           t.TotalSupply,
@@ -63,6 +72,8 @@ type TokenTransfer struct {
 	To                   common.Address
 	TokenContractAddress common.Address
 	Amount               domain.BigInt
+	// TokenId is nil for ERC-20 transfers
+	TokenId *domain.BigInt
 }
 
 func (t *TokenTransfer) ToMap() map[string]interface{} {
@@ -73,6 +84,7 @@ func (t *TokenTransfer) ToMap() map[string]interface{} {
 		"to":                     t.To,
 		"token_contract_address": t.TokenContractAddress,
 		"amount":                 t.Amount,
+		"token_id":               t.TokenId,
 	}
 }
 
@@ -8,12 +8,18 @@ import (
 
 type Repository interface {
 	SaveTransaction(ctx context.Context, tx *Transaction) error
+	SaveTransactions(ctx context.Context, txs []*Transaction) error
 	SaveTransactionLog(ctx context.Context, txLog *TransactionLog) error
+	SaveTransactionLogs(ctx context.Context, txLogs []*TransactionLog) error
+	GetBlockTransactions(ctx context.Context, blockHash common.Hash) ([]*Transaction, error)
+	GetBlockTransactionLogs(ctx context.Context, blockHash common.Hash) ([]*TransactionLog, error)
 	SaveTransactionAction(ctx context.Context, txAction *TransactionAction) error
 	SaveTransactionHashForAction(ctx context.Context, hash common.Hash, count int) error
 	SaveTransactionHashForLog(ctx context.Context, hash common.Hash, count int) error
+	ResetTransactionHashForLog(ctx context.Context, hash common.Hash, count int) error
 	CheckTransactionExistForLog(ctx context.Context, hash common.Hash) (bool, error)
 	CheckTransactionExistForAction(ctx context.Context, hash common.Hash) (bool, error)
-	DecrementTransactionActionCount(ctx context.Context, hash common.Hash) error
-	DecrementTransactionLogCount(ctx context.Context, hash common.Hash) error
+	// the decrements count every log and action of the transaction once
+	DecrementTransactionActionCount(ctx context.Context, hash common.Hash, logIndex int) error
+	DecrementTransactionLogCount(ctx context.Context, hash common.Hash, logIndex uint) error
 }
@@ -19,6 +19,13 @@ type Transaction struct {
 	Nonce     uint64         `json:"nonce"`
 	Timestamp int64          `json:"timestamp"`
 	LogsCount int            `json:"logs_count"`
+	// Consensus encoding and receipt fields used to recompute the block roots
+	Type              uint8  `json:"type"`
+	Raw               []byte `json:"raw"`
+	CumulativeGasUsed uint64 `json:"cumulative_gas_used"`
+	PostState         []byte `json:"post_state"`
+	// Reindexed is copied by the producer from the reindexed block of the transaction
+	Reindexed bool `json:"reindexed,omitempty"`
 }
 
 func (t *Transaction) ToMap() map[string]interface{} {
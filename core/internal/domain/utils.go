@@ -39,6 +39,16 @@ func (i *BigInt) Bytes() []byte {
 	return (*big.Int)(i).Bytes()
 }
 
+// Uint64 returns the low 64 bits of the number
+func (i *BigInt) Uint64() uint64 {
+	return (*big.Int)(i).Uint64()
+}
+
+// Int64 returns the number as int64, the result is undefined when it does not fit
+func (i *BigInt) Int64() int64 {
+	return (*big.Int)(i).Int64()
+}
+
 func (i BigInt) String() string {
 	return (*big.Int)(&i).String()
 }
@@ -96,10 +106,18 @@ func (i BigInt) Sub(j BigInt) BigInt {
 	return fromBigInt(result)
 }
 
-// Convert SQL string to big.Int
+// Convert SQL numeric to big.Int, drivers return NUMERIC either as string or as its text bytes
 func (i *BigInt) Scan(value interface{}) error {
-	str, ok := value.(string)
-	if !ok {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	case int64:
+		*i = BigInt(*big.NewInt(v))
+		return nil
+	default:
 		return errors.New("failed to scan BigInt")
 	}
 	bi, ok := new(big.Int).SetString(str, 10)
@@ -114,6 +132,10 @@ func BigIntZero() BigInt {
 	return BigInt(*big.NewInt(0))
 }
 
+func BigIntOne() BigInt {
+	return BigInt(*big.NewInt(1))
+}
+
 func FromBytesToBigInt(data []byte) *BigInt {
 	return (*BigInt)(big.NewInt(0).SetBytes(data))
 }
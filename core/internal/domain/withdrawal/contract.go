@@ -1,7 +1,13 @@
 package withdrawal
 
-import "context"
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 type Repository interface {
 	SaveWithdrawal(ctx context.Context, withdrawal *Withdrawal) error
+	SaveWithdrawals(ctx context.Context, withdrawals []*Withdrawal) error
+	CountBlockWithdrawals(ctx context.Context, blockHash common.Hash) (int, error)
 }
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/admin"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// truncateIndexedTablesQuery empties every table filled from the chain. Verified contracts and audit reports are
+// submitted by users and survive a reset.
+const truncateIndexedTablesQuery = `
+	TRUNCATE TABLE
+		transaction_action,
+		transaction_log_topic,
+		transaction_log,
+		token_transfer,
+		token_instance,
+		token,
+		internal_transaction,
+		transaction,
+		withdrawal,
+		reward,
+		block`
+
+// bumpResetGenerationQuery runs in the reset transaction, so the generation only moves when the reset commits
+const bumpResetGenerationQuery = `update reset_generation set generation = generation + 1 where id = 1 returning generation`
+
+type AdminRepository struct {
+	db    *sql.DB
+	store KVStorage
+}
+
+func NewAdminRepository(db *sql.DB, store KVStorage) *AdminRepository {
+	return &AdminRepository{db: db, store: store}
+}
+
+func (r *AdminRepository) ResetState(ctx context.Context, fromBlock *domain.BigInt, beforeCommit func(ctx context.Context) error) (*admin.ResetResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &admin.ResetResult{FromBlock: fromBlock}
+
+	var blockHashes, transactionHashes []common.Hash
+	if fromBlock == nil {
+		if err := tx.QueryRowContext(ctx, `select count(*) from block`).Scan(&result.DeletedBlocks); err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, truncateIndexedTablesQuery); err != nil {
+			return nil, err
+		}
+	} else {
+		if blockHashes, transactionHashes, err = removedHashes(ctx, tx, fromBlock); err != nil {
+			return nil, err
+		}
+
+		// children of the removed blocks go with them through ON DELETE CASCADE
+		res, err := tx.ExecContext(ctx, `delete from block where number > $1`, fromBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.DeletedBlocks, err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.QueryRowContext(ctx, bumpResetGenerationQuery).Scan(&result.Generation); err != nil {
+		return nil, err
+	}
+
+	if err := beforeCommit(ctx); err != nil {
+		return nil, err
+	}
+
+	// the pipeline keeps running below fromBlock, a partial reset only drops the keys of the removed blocks
+	if err := r.deleteCoordinationKeys(ctx, blockHashes, transactionHashes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *AdminRepository) GetResetGeneration(ctx context.Context) (uint64, error) {
+	var generation uint64
+	err := r.db.QueryRowContext(ctx, `select generation from reset_generation where id = 1`).Scan(&generation)
+	return generation, err
+}
+
+// removedHashes lists the blocks above fromBlock and their transactions, the rows a partial reset deletes
+func removedHashes(ctx context.Context, tx *sql.Tx, fromBlock *domain.BigInt) (blockHashes, transactionHashes []common.Hash, err error) {
+	if blockHashes, err = queryHashes(ctx, tx, `select hash from block where number > $1`, fromBlock); err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+		select t.hash
+		from "transaction" t
+		join block b on b.hash = t.block_hash
+		where b.number > $1`
+	if transactionHashes, err = queryHashes(ctx, tx, query, fromBlock); err != nil {
+		return nil, nil, err
+	}
+
+	return blockHashes, transactionHashes, nil
+}
+
+func queryHashes(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]common.Hash, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []common.Hash
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, common.BytesToHash(hash))
+	}
+
+	return hashes, rows.Err()
+}
+
+func (r *AdminRepository) deleteCoordinationKeys(ctx context.Context, blockHashes, transactionHashes []common.Hash) error {
+	for _, hash := range blockHashes {
+		for _, key := range []string{makeTransactionKey(hash), makeWithdrawalKey(hash), makeRewardKey(hash)} {
+			if err := r.store.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, hash := range transactionHashes {
+		for _, key := range []string{makeTransactionLogKey(hash), makeTransactionActionKey(hash)} {
+			if err := r.store.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *AdminRepository) SaveAuditEntry(ctx context.Context, entry *admin.AuditEntry) error {
+	params, err := json.Marshal(entry.Params)
+	if err != nil {
+		return err
+	}
+
+	query := `insert into admin_audit_log (action, actor, params, success, error) values ($1, $2, $3, $4, $5)`
+	_, err = r.db.ExecContext(ctx, query, entry.Action, entry.Actor, params, entry.Success, sql.NullString{String: entry.Error, Valid: entry.Error != ""})
+	return err
+}
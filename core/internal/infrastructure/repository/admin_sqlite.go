@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/admin"
+)
+
+// sqliteIndexedTables are the tables emptied by a full reset, SQLite has no TRUNCATE so they are
+// deleted children first
+var sqliteIndexedTables = []string{
+	"transaction_action",
+	"transaction_log_topic",
+	"transaction_log",
+	"token_transfer",
+	"token_instance",
+	"token",
+	"internal_transaction",
+	`"transaction"`,
+	"withdrawal",
+	"reward",
+	"block",
+}
+
+// SQLiteAdminRepository replaces the TRUNCATE of a full reset, partial resets share the Postgres query
+type SQLiteAdminRepository struct {
+	*AdminRepository
+}
+
+func NewSQLiteAdminRepository(db *sql.DB, store KVStorage) *SQLiteAdminRepository {
+	return &SQLiteAdminRepository{AdminRepository: NewAdminRepository(db, store)}
+}
+
+func (r *SQLiteAdminRepository) ResetState(ctx context.Context, fromBlock *domain.BigInt, beforeCommit func(ctx context.Context) error) (*admin.ResetResult, error) {
+	if fromBlock != nil {
+		// a partial reset is a plain delete cascading to the children
+		return r.AdminRepository.ResetState(ctx, fromBlock, beforeCommit)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &admin.ResetResult{}
+	if err := tx.QueryRowContext(ctx, `select count(*) from block`).Scan(&result.DeletedBlocks); err != nil {
+		return nil, err
+	}
+
+	for _, table := range sqliteIndexedTables {
+		if _, err := tx.ExecContext(ctx, "delete from "+table); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.QueryRowContext(ctx, bumpResetGenerationQuery).Scan(&result.Generation); err != nil {
+		return nil, err
+	}
+
+	if err := beforeCommit(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
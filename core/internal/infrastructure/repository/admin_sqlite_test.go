@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noopBeforeCommit(context.Context) error { return nil }
+
+func TestSQLiteAdminRepository_PartialReset(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	store := memstore.New()
+	repository := NewSQLiteAdminRepository(db, store)
+
+	kept, removed := common.HexToHash("0x01"), common.HexToHash("0x02")
+	keptTransaction, removedTransaction := common.HexToHash("0x11"), common.HexToHash("0x12")
+	insertBlock(t, db, kept, 10)
+	insertBlock(t, db, removed, 11)
+	insertTransaction(t, db, keptTransaction, kept)
+	insertTransaction(t, db, removedTransaction, removed)
+
+	// both blocks still wait for children, only the keys of the removed one may go
+	for _, key := range []string{
+		makeTransactionKey(kept), makeRewardKey(kept), makeTransactionLogKey(keptTransaction),
+		makeTransactionKey(removed), makeRewardKey(removed), makeTransactionLogKey(removedTransaction),
+	} {
+		require.NoError(t, store.SetInt(ctx, key, 1))
+	}
+
+	result, err := repository.ResetState(ctx, domain.FromBytesToBigInt(big.NewInt(10).Bytes()), noopBeforeCommit)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.DeletedBlocks)
+	assert.Equal(t, uint64(1), result.Generation)
+
+	var blocks, transactions int
+	require.NoError(t, db.QueryRow(`select count(*) from block`).Scan(&blocks))
+	require.NoError(t, db.QueryRow(`select count(*) from "transaction"`).Scan(&transactions))
+	assert.Equal(t, 1, blocks)
+	assert.Equal(t, 1, transactions)
+
+	for key, expected := range map[string]int{
+		makeTransactionKey(kept):                  1,
+		makeRewardKey(kept):                       1,
+		makeTransactionLogKey(keptTransaction):    1,
+		makeTransactionKey(removed):               0,
+		makeRewardKey(removed):                    0,
+		makeTransactionLogKey(removedTransaction): 0,
+	} {
+		value, err := store.GetInt(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, key)
+	}
+
+	generation, err := repository.GetResetGeneration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), generation)
+}
+
+func TestSQLiteAdminRepository_FullReset(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repository := NewSQLiteAdminRepository(db, memstore.New())
+
+	insertBlock(t, db, common.HexToHash("0x01"), 1)
+	insertBlock(t, db, common.HexToHash("0x02"), 2)
+
+	result, err := repository.ResetState(ctx, nil, noopBeforeCommit)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.DeletedBlocks)
+	assert.Equal(t, uint64(1), result.Generation)
+
+	result, err = repository.ResetState(ctx, nil, noopBeforeCommit)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.DeletedBlocks)
+	assert.Equal(t, uint64(2), result.Generation)
+}
+
+func TestSQLiteAdminRepository_ResetRolledBack(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repository := NewSQLiteAdminRepository(db, memstore.New())
+
+	insertBlock(t, db, common.HexToHash("0x01"), 1)
+
+	_, err := repository.ResetState(ctx, nil, func(context.Context) error { return assert.AnError })
+	require.ErrorIs(t, err, assert.AnError)
+
+	var blocks int
+	require.NoError(t, db.QueryRow(`select count(*) from block`).Scan(&blocks))
+	assert.Equal(t, 1, blocks)
+
+	generation, err := repository.GetResetGeneration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), generation)
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// maxBindParams keeps multi-row statements under the bind parameter limit, SQLite allows 32766
+// and Postgres 65535
+const maxBindParams = 32766
+
+// execValues inserts the rows with multi-row statements, insert holds everything before VALUES and
+// conflict the clause after it. The rows are split so no statement exceeds maxBindParams.
+func execValues(ctx context.Context, tx *sql.Tx, insert, conflict string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	chunkSize := maxBindParams / len(rows[0])
+	for start := 0; start < len(rows); start += chunkSize {
+		chunk := rows[start:min(start+chunkSize, len(rows))]
+
+		var query strings.Builder
+		args := make([]interface{}, 0, len(chunk)*len(chunk[0]))
+
+		query.WriteString(insert)
+		query.WriteString(" values ")
+		for i, row := range chunk {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteByte('(')
+			for j, arg := range row {
+				if j > 0 {
+					query.WriteString(", ")
+				}
+				args = append(args, arg)
+				fmt.Fprintf(&query, "$%d", len(args))
+			}
+			query.WriteByte(')')
+		}
+		query.WriteByte(' ')
+		query.WriteString(conflict)
+
+		if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dedupe keeps the last item for every key. An upsert cannot touch the same row twice in one
+// statement, and a batch may hold a redelivered copy of a message.
+func dedupe[T any, K comparable](items []T, key func(T) K) []T {
+	last := make(map[K]int, len(items))
+	for i, item := range items {
+		last[key(item)] = i
+	}
+
+	unique := make([]T, 0, len(last))
+	for i, item := range items {
+		if last[key(item)] == i {
+			unique = append(unique, item)
+		}
+	}
+
+	return unique
+}
+
+// inTx runs fn in a database transaction, committing when it succeeds
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,27 +1,39 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
 	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
 )
 
 type BlockRepository struct {
-	db    *sql.DB
-	store KVStorage
-	log   *zap.Logger
+	db     *sql.DB
+	store  KVStorage
+	log    *zap.Logger
+	schema string
 }
 
 func NewBlockRepository(db *sql.DB, store KVStorage, log *zap.Logger) *BlockRepository {
-	return &BlockRepository{db: db, store: store, log: log}
+	return &BlockRepository{db: db, store: store, log: log, schema: postgresSchema}
+}
+
+// mainTable qualifies a table core reads its own progress from
+func (r *BlockRepository) mainTable(table string) string {
+	return qualifiedTable(r.schema, table)
 }
 
 func (r *BlockRepository) GetCurrentBlock(ctx context.Context) (*block.Block, error) {
-	query := `select hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp from block order by number desc limit 1`
+	query := fmt.Sprintf(`select hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp from %s order by number desc limit 1`,
+		r.mainTable("block"))
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -41,10 +53,118 @@ func (r *BlockRepository) GetCurrentBlock(ctx context.Context) (*block.Block, er
 	return nil, ErrNotFound
 }
 
+// GetIndexedRange returns the lowest stored block and the first block after it that breaks the
+// contiguous run of blocks stored with every transaction, withdrawal and reward
+func (r *BlockRepository) GetIndexedRange(ctx context.Context) (*block.IndexedRange, error) {
+	query := fmt.Sprintf(`
+		with ordered as (
+			select number, complete, lead(number) over (order by number) as next_number from %[1]s
+		)
+		select
+			(select min(number) from %[1]s),
+			case when complete then number + 1 else number end
+		from ordered
+		where not complete or next_number is null or next_number <> number + 1
+		order by number
+		limit 1`, r.mainTable("block"))
+
+	var indexedRange block.IndexedRange
+	err := r.db.QueryRowContext(ctx, query).Scan(&indexedRange.LowestNumber, &indexedRange.NextNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &indexedRange, nil
+}
+
+const (
+	blockInsert = `insert into block (hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp, transactions_count, withdrawals_count, transactions_root, receipts_root, logs_bloom, state_root, mix_hash)`
+
+	blockConflict = `on conflict (hash) do update set
+			number = excluded.number,
+			miner_hash = excluded.miner_hash,
+			parent_hash = excluded.parent_hash,
+			gas_limit = excluded.gas_limit,
+			gas_used = excluded.gas_used,
+			nonce = excluded.nonce,
+			size = excluded.size,
+			difficulty = excluded.difficulty,
+			is_pos = excluded.is_pos,
+			base_fee_per_gas = excluded.base_fee_per_gas,
+			timestamp = excluded.timestamp,
+			transactions_count = excluded.transactions_count,
+			withdrawals_count = excluded.withdrawals_count,
+			transactions_root = excluded.transactions_root,
+			receipts_root = excluded.receipts_root,
+			logs_bloom = excluded.logs_bloom,
+			state_root = excluded.state_root,
+			mix_hash = excluded.mix_hash`
+)
+
+func blockArgs(b *block.Block) []interface{} {
+	return []interface{}{b.Hash, b.Number, b.MinerHash, b.ParentHash, b.GasLimit, b.GasUsed, b.Nonce, b.Size, b.Difficulty, b.IsPos, b.BaseFeePerGas, b.Timestamp, b.TransactionsCount, b.WithdrawalsCount, b.TransactionsRoot, b.ReceiptsRoot, b.LogsBloom, b.StateRoot, b.MixHash}
+}
+
 func (r *BlockRepository) SaveBlock(ctx context.Context, b *block.Block) error {
-	query := `insert into block (hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
-	_, err := r.db.ExecContext(ctx, query, b.Hash, b.Number, b.MinerHash, b.ParentHash, b.GasLimit, b.GasUsed, b.Nonce, b.Size, b.Difficulty, b.IsPos, b.BaseFeePerGas, b.Timestamp)
-	return err
+	return r.SaveBlocks(ctx, []*block.Block{b})
+}
+
+// SaveBlocks upserts the blocks with multi-row inserts in one database transaction and refreshes their
+// complete flag, a block without transactions and withdrawals may already have its reward
+func (r *BlockRepository) SaveBlocks(ctx context.Context, blocks []*block.Block) error {
+	blocks = dedupe(blocks, func(b *block.Block) common.Hash { return b.Hash })
+	// rows are locked in hash order like lockBlocks does
+	sort.Slice(blocks, func(i, j int) bool { return bytes.Compare(blocks[i].Hash[:], blocks[j].Hash[:]) < 0 })
+
+	rows := make([][]interface{}, len(blocks))
+	hashes := make([]common.Hash, len(blocks))
+	for i, b := range blocks {
+		rows[i] = blockArgs(b)
+		hashes[i] = b.Hash
+	}
+
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := execValues(ctx, tx, blockInsert, blockConflict, rows); err != nil {
+			return err
+		}
+
+		return refreshBlocksComplete(ctx, tx, hashes)
+	})
+}
+
+// GetBlocksInRange returns the stored blocks numbered from..to inclusive ordered by number.
+// The roots and bloom are left empty for blocks indexed before they were recorded.
+func (r *BlockRepository) GetBlocksInRange(ctx context.Context, from, to *domain.BigInt) ([]*block.Block, error) {
+	query := fmt.Sprintf(`select hash, number, transactions_count, withdrawals_count, transactions_root, receipts_root, logs_bloom
+		from %s
+		where number between $1 and $2
+		order by number`, r.mainTable("block"))
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*block.Block
+	for rows.Next() {
+		var (
+			b                              block.Block
+			transactionsRoot, receiptsRoot []byte
+		)
+		err = rows.Scan(&b.Hash, &b.Number, &b.TransactionsCount, &b.WithdrawalsCount, &transactionsRoot, &receiptsRoot, &b.LogsBloom)
+		if err != nil {
+			return nil, err
+		}
+		b.TransactionsRoot = common.BytesToHash(transactionsRoot)
+		b.ReceiptsRoot = common.BytesToHash(receiptsRoot)
+		blocks = append(blocks, &b)
+	}
+
+	return blocks, rows.Err()
 }
 
 func makeTransactionKey(hash common.Hash) string {
@@ -74,6 +194,22 @@ func (r *BlockRepository) SaveBlockHashForReward(ctx context.Context, hash commo
 	return r.store.SetInt(ctx, makeRewardKey(hash), rewardCount)
 }
 
+// ResetBlockHashCounts restarts the counters of a reindexed block, its children are published again
+// while the counters may still hold what was left of the first indexing
+func (r *BlockRepository) ResetBlockHashCounts(ctx context.Context, hash common.Hash, transactionCount, withdrawalCount, rewardCount int) error {
+	r.log.Debug("Resetting block hash counts", zap.String("hash", hash.Hex()))
+
+	if err := r.store.OverwriteInt(ctx, makeTransactionKey(hash), transactionCount); err != nil {
+		return err
+	}
+
+	if err := r.store.OverwriteInt(ctx, makeWithdrawalKey(hash), withdrawalCount); err != nil {
+		return err
+	}
+
+	return r.store.OverwriteInt(ctx, makeRewardKey(hash), rewardCount)
+}
+
 func (r *BlockRepository) DeleteBlockHashForTransaction(ctx context.Context, hash common.Hash) error {
 	r.log.Debug("Deleting block hash for transaction", zap.String("hash", hash.Hex()))
 	return r.store.Delete(ctx, makeTransactionKey(hash))
@@ -116,14 +252,14 @@ func (r *BlockRepository) CheckBlockExistsForReward(ctx context.Context, hash co
 	return exists != 0, nil
 }
 
-func (r *BlockRepository) DecrementBlockHashRewardCount(ctx context.Context, hash common.Hash) error {
-	return r.store.DecrementAndMaybeDelete(ctx, makeRewardKey(hash))
+func (r *BlockRepository) DecrementBlockHashRewardCount(ctx context.Context, hash common.Hash, address common.Address) error {
+	return r.store.DecrementAndMaybeDelete(ctx, makeRewardKey(hash), address.Hex())
 }
 
-func (r *BlockRepository) DecrementBlockHashTransactionCount(ctx context.Context, hash common.Hash) error {
-	return r.store.DecrementAndMaybeDelete(ctx, makeTransactionKey(hash))
+func (r *BlockRepository) DecrementBlockHashTransactionCount(ctx context.Context, hash common.Hash, transactionHash common.Hash) error {
+	return r.store.DecrementAndMaybeDelete(ctx, makeTransactionKey(hash), transactionHash.Hex())
 }
 
-func (r *BlockRepository) DecrementBlockHashWithdrawalCount(ctx context.Context, hash common.Hash) error {
-	return r.store.DecrementAndMaybeDelete(ctx, makeWithdrawalKey(hash))
+func (r *BlockRepository) DecrementBlockHashWithdrawalCount(ctx context.Context, hash common.Hash, index uint64) error {
+	return r.store.DecrementAndMaybeDelete(ctx, makeWithdrawalKey(hash), strconv.FormatUint(index, 10))
 }
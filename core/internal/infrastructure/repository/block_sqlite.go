@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"go.uber.org/zap"
+)
+
+// sqliteTimestampLayout is how SQLite stores CURRENT_TIMESTAMP, aggregates over it come back as text
+const sqliteTimestampLayout = "2006-01-02 15:04:05"
+
+// SQLiteBlockRepository runs the block queries on SQLite, only the queries relying on Postgres casts
+// and typed aggregates are rewritten
+type SQLiteBlockRepository struct {
+	*BlockRepository
+}
+
+func NewSQLiteBlockRepository(db *sql.DB, store KVStorage, log *zap.Logger) *SQLiteBlockRepository {
+	r := NewBlockRepository(db, store, log)
+	r.schema = sqliteSchema
+	return &SQLiteBlockRepository{BlockRepository: r}
+}
+
+func (r *SQLiteBlockRepository) GetIndexingStatus(ctx context.Context) (*block.IndexingStatus, error) {
+	indexedRange, err := r.GetIndexedRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &block.IndexingStatus{IndexedRange: *indexedRange}
+
+	var completeBlocks, missingBlocks int64
+	var lastUpdatedAt string
+	err = r.db.QueryRowContext(ctx, fmt.Sprintf(indexingStatusQuery, r.mainTable("block"))).Scan(
+		&status.HighestNumber,
+		&status.TotalBlocks,
+		&completeBlocks,
+		&missingBlocks,
+		&lastUpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if status.LastUpdatedAt, err = time.Parse(sqliteTimestampLayout, lastUpdatedAt); err != nil {
+		return nil, err
+	}
+
+	status.CompleteBlocks = completeBlocks
+	status.IncompleteBlocks = status.TotalBlocks - completeBlocks
+	status.MissingBlocks = missingBlocks
+
+	return status, nil
+}
+
+// ListGaps binds the block number as an integer, computed numbers have no column affinity in SQLite
+// and would never compare equal to a text parameter
+func (r *SQLiteBlockRepository) ListGaps(ctx context.Context, after *domain.BigInt, limit int) ([]*block.Gap, error) {
+	query := fmt.Sprintf(listGapsQuery, "$3", "$1", "$2", r.mainTable("block"))
+
+	var afterNumber sql.NullInt64
+	if after != nil {
+		afterNumber = sql.NullInt64{Int64: after.Int64(), Valid: true}
+	}
+
+	return r.queryGaps(ctx, query, afterNumber, limit)
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// lockBlockQuery takes the row lock of a block before its children are written. Two transactions
+// storing children of the same block would otherwise each miss the rows of the other when they refresh
+// its complete flag.
+const lockBlockQuery = `update block set complete = complete where hash = $1`
+
+// refreshBlockCompleteQuery marks a block as complete when all of its transactions, withdrawals and
+// the reward are stored
+const refreshBlockCompleteQuery = `
+	update block as b set complete = (
+		(select count(*) from "transaction" t where t.block_hash = b.hash) = b.transactions_count
+		and (select count(*) from withdrawal w where w.block_hash = b.hash) = b.withdrawals_count
+		and exists (select 1 from reward rw where rw.block_hash = b.hash)
+	)
+	where b.hash = $1`
+
+// uniqueHashes returns the distinct hashes in ascending order, blocks are always locked in that order
+func uniqueHashes(hashes []common.Hash) []common.Hash {
+	seen := make(map[common.Hash]bool, len(hashes))
+	unique := make([]common.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		if !seen[hash] {
+			seen[hash] = true
+			unique = append(unique, hash)
+		}
+	}
+
+	sort.Slice(unique, func(i, j int) bool { return bytes.Compare(unique[i][:], unique[j][:]) < 0 })
+	return unique
+}
+
+func lockBlocks(ctx context.Context, tx *sql.Tx, blockHashes []common.Hash) error {
+	for _, hash := range uniqueHashes(blockHashes) {
+		if _, err := tx.ExecContext(ctx, lockBlockQuery, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func refreshBlocksComplete(ctx context.Context, tx *sql.Tx, blockHashes []common.Hash) error {
+	for _, hash := range uniqueHashes(blockHashes) {
+		if _, err := tx.ExecContext(ctx, refreshBlockCompleteQuery, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveBlockChildren writes the children of blocks and refreshes the complete flag of their blocks
+func saveBlockChildren(ctx context.Context, db *sql.DB, blockHashes []common.Hash, write func(tx *sql.Tx) error) error {
+	return inTx(ctx, db, func(tx *sql.Tx) error {
+		if err := lockBlocks(ctx, tx, blockHashes); err != nil {
+			return err
+		}
+
+		if err := write(tx); err != nil {
+			return err
+		}
+
+		return refreshBlocksComplete(ctx, tx, blockHashes)
+	})
+}
+
+// indexingStatusQuery reads every figure of the status in one pass over the block table
+const indexingStatusQuery = `
+	select
+		max(number),
+		count(*),
+		count(*) filter (where complete),
+		max(number) - min(number) + 1 - count(*),
+		max(updated_at)
+	from %s`
+
+func (r *BlockRepository) GetIndexingStatus(ctx context.Context) (*block.IndexingStatus, error) {
+	indexedRange, err := r.GetIndexedRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &block.IndexingStatus{IndexedRange: *indexedRange}
+
+	var completeBlocks int64
+	var missingBlocks domain.BigInt
+	err = r.db.QueryRowContext(ctx, fmt.Sprintf(indexingStatusQuery, r.mainTable("block"))).Scan(
+		&status.HighestNumber,
+		&status.TotalBlocks,
+		&completeBlocks,
+		&missingBlocks,
+		&status.LastUpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	status.CompleteBlocks = completeBlocks
+	status.IncompleteBlocks = status.TotalBlocks - completeBlocks
+	status.MissingBlocks = missingBlocks.Int64()
+
+	return status, nil
+}
+
+// listGapsQuery pairs the blocks from $3 on with the next stored number to find the missing ranges, and
+// groups the incomplete blocks above $3 into runs of consecutive numbers through idx_block_incomplete.
+// A run whose previous block is incomplete too started at or before $3 and was listed on an earlier page.
+const listGapsQuery = `
+	with ordered as (
+		select number, lead(number) over (order by number) as next_number
+		from %[4]s
+		where %[1]s is null or number >= %[1]s
+	), incomplete as (
+		select number, number - row_number() over (order by number) as island
+		from %[4]s
+		where not complete and (%[1]s is null or number > %[1]s)
+	), islands as (
+		select min(number) as from_number, max(number) as to_number
+		from incomplete
+		group by island
+	), gaps as (
+		select %[2]s as kind, number + 1 as from_number, next_number - 1 as to_number
+		from ordered
+		where next_number > number + 1
+		union all
+		select %[3]s, from_number, to_number
+		from islands i
+		where not exists (select 1 from %[4]s p where p.number = i.from_number - 1 and not p.complete)
+	)
+	select kind, from_number, to_number
+	from gaps
+	order by from_number
+	limit $4`
+
+// ListGaps returns up to limit ranges of missing or incomplete blocks between the lowest and highest
+// stored block, ordered by their first block and starting after the given block number when set
+func (r *BlockRepository) ListGaps(ctx context.Context, after *domain.BigInt, limit int) ([]*block.Gap, error) {
+	query := fmt.Sprintf(listGapsQuery, "$3::numeric", "$1::int", "$2::int", r.mainTable("block"))
+
+	var afterNumber sql.NullString
+	if after != nil {
+		afterNumber = sql.NullString{String: after.String(), Valid: true}
+	}
+
+	return r.queryGaps(ctx, query, afterNumber, limit)
+}
+
+func (r *BlockRepository) queryGaps(ctx context.Context, query string, after interface{}, limit int) ([]*block.Gap, error) {
+	rows, err := r.db.QueryContext(ctx, query, block.GapMissing, block.GapIncomplete, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gaps []*block.Gap
+	for rows.Next() {
+		var gap block.Gap
+		if err := rows.Scan(&gap.Kind, &gap.FromNumber, &gap.ToNumber); err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, &gap)
+	}
+
+	return gaps, rows.Err()
+}
+
+// GetBlockStatus reads the coordination counters of a stored block and of its transactions
+func (r *BlockRepository) GetBlockStatus(ctx context.Context, hash common.Hash) (*block.BlockStatus, error) {
+	status := &block.BlockStatus{Hash: hash}
+
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`select number from %s where hash = $1`, r.mainTable("block")), hash).Scan(&status.Number)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if status.PendingTransactions, err = r.store.GetInt(ctx, makeTransactionKey(hash)); err != nil {
+		return nil, err
+	}
+
+	if status.PendingWithdrawals, err = r.store.GetInt(ctx, makeWithdrawalKey(hash)); err != nil {
+		return nil, err
+	}
+
+	if status.PendingRewards, err = r.store.GetInt(ctx, makeRewardKey(hash)); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`select hash from %s where block_hash = $1 order by "index"`, r.mainTable("transaction"))
+	rows, err := r.db.QueryContext(ctx, query, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transactionStatus := &block.TransactionStatus{}
+		if err := rows.Scan(&transactionStatus.Hash); err != nil {
+			return nil, err
+		}
+
+		if transactionStatus.PendingLogs, err = r.store.GetInt(ctx, makeTransactionLogKey(transactionStatus.Hash)); err != nil {
+			return nil, err
+		}
+
+		if transactionStatus.PendingActions, err = r.store.GetInt(ctx, makeTransactionActionKey(transactionStatus.Hash)); err != nil {
+			return nil, err
+		}
+
+		if transactionStatus.PendingLogs > 0 || transactionStatus.PendingActions > 0 {
+			status.PendingTransactionChildren = append(status.PendingTransactionChildren, transactionStatus)
+		}
+	}
+
+	return status, rows.Err()
+}
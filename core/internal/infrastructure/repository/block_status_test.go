@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/reward"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testRepositories struct {
+	blocks       *SQLiteBlockRepository
+	transactions *TransactionRepository
+	rewards      *RewardRepository
+}
+
+func newTestRepositories(db *sql.DB) testRepositories {
+	store := memstore.New()
+	return testRepositories{
+		blocks:       NewSQLiteBlockRepository(db, store, zap.NewNop()),
+		transactions: NewSQLiteTransactionRepository(db, store, zap.NewNop()),
+		rewards:      NewRewardRepository(db, store),
+	}
+}
+
+// storeBlock saves block number with one transaction, the transaction and the reward are only
+// stored when withTransaction and withReward are set
+func (r testRepositories) storeBlock(t *testing.T, number int64, withTransaction, withReward bool) {
+	t.Helper()
+	ctx := context.Background()
+
+	require.NoError(t, r.blocks.SaveBlock(ctx, testBlock(number, 1)))
+	if withTransaction {
+		require.NoError(t, r.transactions.SaveTransactions(ctx, []*transaction.Transaction{testTransaction(number, 0)}))
+	}
+	if withReward {
+		require.NoError(t, r.rewards.SaveRewards(ctx, []*reward.Reward{testReward(number)}))
+	}
+}
+
+func isComplete(t *testing.T, db *sql.DB, hash common.Hash) bool {
+	t.Helper()
+
+	var complete bool
+	require.NoError(t, db.QueryRow(`select complete from block where hash = $1`, hash).Scan(&complete))
+	return complete
+}
+
+func TestBlockComplete_RefreshedOnWrite(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repositories := newTestRepositories(db)
+	hash := testBlockHash(1)
+
+	require.NoError(t, repositories.blocks.SaveBlock(ctx, testBlock(1, 2)))
+	assert.False(t, isComplete(t, db, hash))
+
+	require.NoError(t, repositories.rewards.SaveReward(ctx, testReward(1)))
+	require.NoError(t, repositories.transactions.SaveTransaction(ctx, testTransaction(1, 0)))
+	assert.False(t, isComplete(t, db, hash), "one transaction is still missing")
+
+	// a redelivered copy of a stored transaction must not count twice
+	require.NoError(t, repositories.transactions.SaveTransactions(ctx, []*transaction.Transaction{testTransaction(1, 0), testTransaction(1, 0)}))
+	assert.False(t, isComplete(t, db, hash))
+
+	require.NoError(t, repositories.transactions.SaveTransactions(ctx, []*transaction.Transaction{testTransaction(1, 1)}))
+	assert.True(t, isComplete(t, db, hash))
+
+	// a reindexed block announcing more transactions is incomplete again
+	require.NoError(t, repositories.blocks.SaveBlocks(ctx, []*block.Block{testBlock(1, 3)}))
+	assert.False(t, isComplete(t, db, hash))
+}
+
+// seedGaps stores blocks 1 to 10 without 6, block 3 misses its reward, block 4 its transaction
+// and block 8 its reward
+func seedGaps(t *testing.T, db *sql.DB) {
+	t.Helper()
+	repositories := newTestRepositories(db)
+
+	for number := int64(1); number <= 10; number++ {
+		switch number {
+		case 6:
+		case 3, 8:
+			repositories.storeBlock(t, number, true, false)
+		case 4:
+			repositories.storeBlock(t, number, false, true)
+		default:
+			repositories.storeBlock(t, number, true, true)
+		}
+	}
+}
+
+func TestSQLiteBlockRepository_IndexingStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	seedGaps(t, db)
+	repository := newTestRepositories(db).blocks
+
+	indexedRange, err := repository.GetIndexedRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", indexedRange.LowestNumber.String())
+	assert.Equal(t, "3", indexedRange.NextNumber.String())
+
+	status, err := repository.GetIndexingStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "10", status.HighestNumber.String())
+	assert.Equal(t, int64(9), status.TotalBlocks)
+	assert.Equal(t, int64(6), status.CompleteBlocks)
+	assert.Equal(t, int64(3), status.IncompleteBlocks)
+	assert.Equal(t, int64(1), status.MissingBlocks)
+	assert.False(t, status.LastUpdatedAt.IsZero())
+}
+
+type gapRange struct {
+	kind     block.GapKind
+	from, to string
+}
+
+func gapRanges(gaps []*block.Gap) []gapRange {
+	ranges := make([]gapRange, len(gaps))
+	for i, gap := range gaps {
+		ranges[i] = gapRange{kind: gap.Kind, from: gap.FromNumber.String(), to: gap.ToNumber.String()}
+	}
+	return ranges
+}
+
+func TestSQLiteBlockRepository_ListGaps(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	seedGaps(t, db)
+	repository := newTestRepositories(db).blocks
+
+	all := []gapRange{
+		{kind: block.GapIncomplete, from: "3", to: "4"},
+		{kind: block.GapMissing, from: "6", to: "6"},
+		{kind: block.GapIncomplete, from: "8", to: "8"},
+	}
+
+	gaps, err := repository.ListGaps(ctx, nil, 10)
+	require.NoError(t, err)
+	assert.Equal(t, all, gapRanges(gaps))
+
+	gaps, err = repository.ListGaps(ctx, nil, 2)
+	require.NoError(t, err)
+	assert.Equal(t, all[:2], gapRanges(gaps))
+
+	// pages start after the first block of the last gap, the rest of the 3-4 run was already listed
+	after := bigInt(3)
+	gaps, err = repository.ListGaps(ctx, &after, 10)
+	require.NoError(t, err)
+	assert.Equal(t, all[1:], gapRanges(gaps))
+
+	after = bigInt(6)
+	gaps, err = repository.ListGaps(ctx, &after, 10)
+	require.NoError(t, err)
+	assert.Equal(t, all[2:], gapRanges(gaps))
+}
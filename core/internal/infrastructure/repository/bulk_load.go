@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/bulk"
+	"github.com/lib/pq"
+)
+
+// StagingSchema holds the staging tables, put first on the search path it receives the pipeline writes
+const StagingSchema = "bulk"
+
+// postgresSchema and sqliteSchema hold the main tables. Core reads its own progress from them by qualified
+// names, unqualified names resolve to the staged rows only while bulk mode is on.
+const (
+	postgresSchema = "public"
+	sqliteSchema   = "main"
+)
+
+// stagedTables are the tables the pipeline writes, parents before their children
+var stagedTables = []string{
+	"block",
+	"transaction",
+	"withdrawal",
+	"reward",
+	"internal_transaction",
+	"transaction_log",
+	"transaction_log_topic",
+	"transaction_action",
+	"token",
+	"token_instance",
+	"token_transfer",
+}
+
+// foreignKeysQuery lists the foreign keys from or to the staged tables
+const foreignKeysQuery = `
+	select t.relname, c.conname, pg_get_constraintdef(c.oid)
+	from pg_constraint c
+	join pg_class t on t.oid = c.conrelid
+	join pg_namespace n on n.oid = t.relnamespace
+	join pg_class r on r.oid = c.confrelid
+	where c.contype = 'f' and n.nspname = 'public' and (t.relname = any($1) or r.relname = any($1))
+	order by t.relname, c.conname`
+
+// secondaryIndexesQuery lists the indexes of the staged tables that do not back a constraint
+const secondaryIndexesQuery = `
+	select i.relname, pg_get_indexdef(i.oid)
+	from pg_index x
+	join pg_class i on i.oid = x.indexrelid
+	join pg_class t on t.oid = x.indrelid
+	join pg_namespace n on n.oid = t.relnamespace
+	where n.nspname = 'public' and t.relname = any($1)
+		and not exists (select 1 from pg_constraint c where c.conindid = x.indexrelid)
+	order by i.relname`
+
+type foreignKey struct {
+	table      string
+	name       string
+	definition string
+}
+
+type secondaryIndex struct {
+	name       string
+	definition string
+}
+
+type BulkLoadRepository struct {
+	db *sql.DB
+}
+
+func NewBulkLoadRepository(db *sql.DB) *BulkLoadRepository {
+	return &BulkLoadRepository{db: db}
+}
+
+func (r *BulkLoadRepository) StagingExists(ctx context.Context) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `select exists (select 1 from pg_namespace where nspname = $1)`, StagingSchema).Scan(&exists)
+	return exists, err
+}
+
+func (r *BulkLoadRepository) CreateStaging(ctx context.Context) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `create schema `+pq.QuoteIdentifier(StagingSchema)); err != nil {
+			return err
+		}
+
+		for _, table := range stagedTables {
+			// LIKE copies neither indexes, foreign keys nor triggers, only the primary key is added back for upserts
+			query := fmt.Sprintf(`create unlogged table %s (like %s including defaults including identity including generated)`, stagingTable(table), mainTable(table))
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+
+			var primaryKey string
+			query = `select pg_get_constraintdef(oid) from pg_constraint where conrelid = $1::regclass and contype = 'p'`
+			if err := tx.QueryRowContext(ctx, query, mainTable(table)).Scan(&primaryKey); err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`alter table %s add %s`, stagingTable(table), primaryKey)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Merge holds an exclusive lock on the main tables until it commits, a failed merge leaves them and the
+// staging tables untouched
+func (r *BulkLoadRepository) Merge(ctx context.Context, progress func(bulk.Progress)) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		var mergedRows int64
+		report := func(phase bulk.Phase, step string, done, total int) {
+			progress(bulk.Progress{Phase: phase, Step: step, Done: done, Total: total, MergedRows: mergedRows})
+		}
+
+		report(bulk.PhasePrepare, "", 0, 0)
+		foreignKeys, indexes, err := r.dropReferences(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for i, table := range stagedTables {
+			report(bulk.PhaseMergeTables, table, i, len(stagedTables))
+			merged, err := r.mergeTable(ctx, tx, table)
+			if err != nil {
+				return fmt.Errorf("merge %s: %w", table, err)
+			}
+			mergedRows += merged
+		}
+
+		// the staged flags only counted the staged children, a block may have children on both sides
+		if _, err := tx.ExecContext(ctx, refreshMergedBlocksCompleteQuery()); err != nil {
+			return fmt.Errorf("refresh complete blocks: %w", err)
+		}
+
+		for i, index := range indexes {
+			report(bulk.PhaseRebuildIndexes, index.name, i, len(indexes))
+			if _, err := tx.ExecContext(ctx, index.definition); err != nil {
+				return fmt.Errorf("rebuild index %s: %w", index.name, err)
+			}
+		}
+
+		// adding the keys NOT VALID first lets every key be checked with one scan instead of a trigger per row
+		for i, fk := range foreignKeys {
+			report(bulk.PhaseValidateConstraints, fk.name, i, len(foreignKeys))
+			definition := strings.TrimSuffix(fk.definition, " NOT VALID")
+			query := fmt.Sprintf(`alter table %s add constraint %s %s not valid`, mainTable(fk.table), pq.QuoteIdentifier(fk.name), definition)
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return fmt.Errorf("add constraint %s: %w", fk.name, err)
+			}
+
+			query = fmt.Sprintf(`alter table %s validate constraint %s`, mainTable(fk.table), pq.QuoteIdentifier(fk.name))
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return fmt.Errorf("validate constraint %s: %w", fk.name, err)
+			}
+		}
+
+		for i, table := range stagedTables {
+			report(bulk.PhaseCleanup, table, i, len(stagedTables))
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`alter table %s enable trigger user`, mainTable(table))); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`analyze %s`, mainTable(table))); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `drop schema `+pq.QuoteIdentifier(StagingSchema)+` cascade`); err != nil {
+			return err
+		}
+
+		report(bulk.PhaseCleanup, "", len(stagedTables), len(stagedTables))
+		return nil
+	})
+}
+
+// dropReferences drops the foreign keys and secondary indexes of the main tables and disables their
+// triggers, returning what Merge has to create again
+func (r *BulkLoadRepository) dropReferences(ctx context.Context, tx *sql.Tx) ([]foreignKey, []secondaryIndex, error) {
+	rows, err := tx.QueryContext(ctx, foreignKeysQuery, pq.Array(stagedTables))
+	if err != nil {
+		return nil, nil, err
+	}
+	var foreignKeys []foreignKey
+	for rows.Next() {
+		var fk foreignKey
+		if err := rows.Scan(&fk.table, &fk.name, &fk.definition); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		foreignKeys = append(foreignKeys, fk)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx, secondaryIndexesQuery, pq.Array(stagedTables))
+	if err != nil {
+		return nil, nil, err
+	}
+	var indexes []secondaryIndex
+	for rows.Next() {
+		var index secondaryIndex
+		if err := rows.Scan(&index.name, &index.definition); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	for _, fk := range foreignKeys {
+		query := fmt.Sprintf(`alter table %s drop constraint %s`, mainTable(fk.table), pq.QuoteIdentifier(fk.name))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, index := range indexes {
+		if _, err := tx.ExecContext(ctx, `drop index `+mainTable(index.name)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, table := range stagedTables {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`alter table %s disable trigger user`, mainTable(table))); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return foreignKeys, indexes, nil
+}
+
+// mergeTable upserts the staged rows of table, staged rows replace the stored ones and are stamped
+// with the merge time since the staging tables have no trigger maintaining updated_at
+func (r *BulkLoadRepository) mergeTable(ctx context.Context, tx *sql.Tx, table string) (int64, error) {
+	columns, err := queryStrings(ctx, tx, `
+		select attname from pg_attribute
+		where attrelid = $1::regclass and attnum > 0 and not attisdropped and attgenerated = ''
+		order by attnum`, stagingTable(table))
+	if err != nil {
+		return 0, err
+	}
+
+	primaryKey, err := queryStrings(ctx, tx, `
+		select a.attname from pg_index x
+		join pg_attribute a on a.attrelid = x.indrelid and a.attnum = any(x.indkey)
+		where x.indrelid = $1::regclass and x.indisprimary
+		order by a.attnum`, stagingTable(table))
+	if err != nil {
+		return 0, err
+	}
+
+	isKey := make(map[string]bool, len(primaryKey))
+	for _, column := range primaryKey {
+		isKey[column] = true
+	}
+
+	var insertColumns, selectColumns, updates, conflict []string
+	for _, column := range columns {
+		quoted := pq.QuoteIdentifier(column)
+		insertColumns = append(insertColumns, quoted)
+
+		if column == "updated_at" {
+			selectColumns = append(selectColumns, "current_timestamp")
+		} else {
+			selectColumns = append(selectColumns, quoted)
+		}
+
+		if !isKey[column] && column != "created_at" {
+			updates = append(updates, quoted+" = excluded."+quoted)
+		}
+	}
+	for _, column := range primaryKey {
+		conflict = append(conflict, pq.QuoteIdentifier(column))
+	}
+
+	action := "do nothing"
+	if len(updates) > 0 {
+		action = "do update set " + strings.Join(updates, ", ")
+	}
+
+	query := fmt.Sprintf(`insert into %s (%s) select %s from %s on conflict (%s) %s`,
+		mainTable(table), strings.Join(insertColumns, ", "), strings.Join(selectColumns, ", "),
+		stagingTable(table), strings.Join(conflict, ", "), action)
+
+	result, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// refreshMergedBlocksCompleteQuery recomputes the complete flag of every block the merge wrote to
+func refreshMergedBlocksCompleteQuery() string {
+	return fmt.Sprintf(`
+		update %[1]s as b set complete = (
+			(select count(*) from %[2]s t where t.block_hash = b.hash) = b.transactions_count
+			and (select count(*) from %[3]s w where w.block_hash = b.hash) = b.withdrawals_count
+			and exists (select 1 from %[4]s rw where rw.block_hash = b.hash)
+		)
+		where b.hash in (
+			select hash from %[5]s
+			union select block_hash from %[6]s
+			union select block_hash from %[7]s
+			union select block_hash from %[8]s
+		)`,
+		mainTable("block"), mainTable("transaction"), mainTable("withdrawal"), mainTable("reward"),
+		stagingTable("block"), stagingTable("transaction"), stagingTable("withdrawal"), stagingTable("reward"))
+}
+
+func queryStrings(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// mainTable qualifies a relation of the public schema, unqualified names resolve to the staging tables in bulk mode
+func mainTable(table string) string {
+	return qualifiedTable(postgresSchema, table)
+}
+
+func qualifiedTable(schema, table string) string {
+	return pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(table)
+}
+
+func stagingTable(table string) string {
+	return pq.QuoteIdentifier(StagingSchema) + "." + pq.QuoteIdentifier(table)
+}
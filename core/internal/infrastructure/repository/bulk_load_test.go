@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/bulk"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+	"github.com/elmiringos/indexer/indexer-core/pkg/migrator"
+	"github.com/elmiringos/indexer/indexer-core/pkg/postgres"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// assertMainReads checks the reads core takes its progress from against main blocks 1..3, each stored
+// complete with one transaction
+func assertMainReads(t *testing.T, blocks block.Repository, transactions *TransactionRepository, withdrawals *WithdrawalRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	indexedRange, err := blocks.GetIndexedRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), indexedRange.LowestNumber.Int64())
+	assert.Equal(t, int64(4), indexedRange.NextNumber.Int64())
+
+	current, err := blocks.GetCurrentBlock(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, testBlockHash(3), current.Hash)
+
+	gaps, err := blocks.ListGaps(ctx, nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, gaps)
+
+	status, err := blocks.GetBlockStatus(ctx, testBlockHash(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), status.Number.Int64())
+
+	from, to := bigInt(1), bigInt(10)
+	stored, err := blocks.GetBlocksInRange(ctx, &from, &to)
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+
+	storedTransactions, err := transactions.GetBlockTransactions(ctx, testBlockHash(1))
+	require.NoError(t, err)
+	require.Len(t, storedTransactions, 1)
+	assert.Equal(t, testTransactionHash(1, 0), storedTransactions[0].Hash)
+
+	count, err := withdrawals.CountBlockWithdrawals(ctx, testBlockHash(1))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+// TestMainTableReads_IgnoreShadowingTables shadows the main tables with temporary ones, SQLite resolves
+// unqualified names to them first like Postgres does with the staging schema in bulk mode
+func TestMainTableReads_IgnoreShadowingTables(t *testing.T) {
+	db := openSQLite(t)
+	repositories := newTestRepositories(db)
+	for number := int64(1); number <= 3; number++ {
+		repositories.storeBlock(t, number, true, true)
+	}
+
+	for _, table := range []string{"block", "transaction", "withdrawal"} {
+		_, err := db.Exec(`create temp table ` + qualifiedTable("temp", table) + ` as select * from ` + qualifiedTable(sqliteSchema, table) + ` where false`)
+		require.NoError(t, err)
+	}
+	insertBlock(t, db, testBlockHash(10), 10)
+	insertTransaction(t, db, testTransactionHash(10, 0), testBlockHash(1))
+	_, err := db.Exec(`insert into withdrawal ("index", block_hash, address_hash, validator_index, amount) values (0, $1, $2, 0, '1')`,
+		testBlockHash(1).Bytes(), testBlockHash(1).Bytes()[:20])
+	require.NoError(t, err)
+
+	var shadowed int
+	require.NoError(t, db.QueryRow(`select count(*) from block`).Scan(&shadowed))
+	require.Equal(t, 1, shadowed, "unqualified names resolve to the temporary tables")
+
+	assertMainReads(t, repositories.blocks, repositories.transactions, NewSQLiteWithdrawalRepository(db))
+}
+
+// openPostgres migrates the scratch database PG_TEST_URL names, its public schema is dropped first
+func openPostgres(t *testing.T) *postgres.Connection {
+	t.Helper()
+
+	url := os.Getenv("PG_TEST_URL")
+	if url == "" {
+		t.Skip("PG_TEST_URL is not set")
+	}
+
+	cfg := &config.Config{}
+	cfg.Database.Driver = config.DriverPostgres
+	cfg.PG.URL = url
+
+	conn := postgres.NewPostgresConnection(cfg, zap.NewNop())
+	t.Cleanup(func() { conn.GetDb().Close() })
+
+	_, err := conn.GetDb().Exec(`drop schema if exists bulk cascade; drop schema public cascade; create schema public`)
+	require.NoError(t, err)
+
+	m, err := migrator.New(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, m.Up(0))
+	require.NoError(t, m.Close())
+
+	return conn
+}
+
+func countRows(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+
+	var count int
+	require.NoError(t, db.QueryRow(`select count(*) from `+table).Scan(&count))
+	return count
+}
+
+// constraintCounts returns the foreign keys of the main tables, those not validated yet and the
+// indexes of the main block table
+func constraintCounts(t *testing.T, db *sql.DB) (foreignKeys, notValidated, blockIndexes int) {
+	t.Helper()
+
+	require.NoError(t, db.QueryRow(`
+		select count(*), count(*) filter (where not c.convalidated)
+		from pg_constraint c join pg_namespace n on n.oid = c.connamespace
+		where c.contype = 'f' and n.nspname = 'public'`).Scan(&foreignKeys, &notValidated))
+	require.NoError(t, db.QueryRow(`select count(*) from pg_indexes where schemaname = 'public' and tablename = 'block'`).Scan(&blockIndexes))
+	return foreignKeys, notValidated, blockIndexes
+}
+
+func TestBulkLoad_StageMergeRevalidate(t *testing.T) {
+	ctx := context.Background()
+	conn := openPostgres(t)
+	db := conn.GetDb()
+	store := memstore.New()
+	blocks := NewBlockRepository(db, store, zap.NewNop())
+	transactions := NewTransactionRepository(db, store, zap.NewNop())
+	rewards := NewRewardRepository(db, store)
+	bulkLoad := NewBulkLoadRepository(db)
+
+	storeBlock := func(number int64) {
+		require.NoError(t, blocks.SaveBlock(ctx, testBlock(number, 1)))
+		require.NoError(t, transactions.SaveTransaction(ctx, testTransaction(number, 0)))
+		require.NoError(t, rewards.SaveReward(ctx, testReward(number)))
+	}
+	for number := int64(1); number <= 3; number++ {
+		storeBlock(number)
+	}
+	foreignKeys, _, blockIndexes := constraintCounts(t, db)
+	require.NotZero(t, foreignKeys)
+
+	require.NoError(t, bulkLoad.CreateStaging(ctx))
+	exists, err := bulkLoad.StagingExists(ctx)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	conn.SetSearchPath(StagingSchema, "public")
+
+	// the pipeline writes land in the staging tables while core keeps reading the main ones
+	for number := int64(4); number <= 5; number++ {
+		storeBlock(number)
+	}
+	assert.Equal(t, 2, countRows(t, db, stagingTable("block")))
+	assert.Equal(t, 3, countRows(t, db, mainTable("block")))
+	assertMainReads(t, blocks, transactions, NewWithdrawalRepository(db))
+
+	require.NoError(t, bulkLoad.Merge(ctx, func(bulk.Progress) {}))
+	conn.SetSearchPath()
+
+	exists, err = bulkLoad.StagingExists(ctx)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// the merged blocks are complete and the dropped keys and indexes are back and validated
+	indexedRange, err := blocks.GetIndexedRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), indexedRange.NextNumber.Int64())
+	assert.Equal(t, 5, countRows(t, db, mainTable("block")))
+
+	mergedForeignKeys, notValidated, mergedBlockIndexes := constraintCounts(t, db)
+	assert.Equal(t, foreignKeys, mergedForeignKeys)
+	assert.Zero(t, notValidated)
+	assert.Equal(t, blockIndexes, mergedBlockIndexes)
+}
+
+func TestBulkLoad_MergeRejectsOrphans(t *testing.T) {
+	ctx := context.Background()
+	conn := openPostgres(t)
+	db := conn.GetDb()
+	store := memstore.New()
+	blocks := NewBlockRepository(db, store, zap.NewNop())
+	transactions := NewTransactionRepository(db, store, zap.NewNop())
+	bulkLoad := NewBulkLoadRepository(db)
+
+	require.NoError(t, blocks.SaveBlock(ctx, testBlock(1, 1)))
+	foreignKeys, _, _ := constraintCounts(t, db)
+
+	require.NoError(t, bulkLoad.CreateStaging(ctx))
+	conn.SetSearchPath(StagingSchema, "public")
+
+	// the staging tables have no foreign keys, a transaction of an unknown block is only caught by revalidation
+	require.NoError(t, transactions.SaveTransactions(ctx, []*transaction.Transaction{testTransaction(1, 0), testTransaction(7, 0)}))
+	require.Error(t, bulkLoad.Merge(ctx, func(bulk.Progress) {}))
+	conn.SetSearchPath()
+
+	// the failed merge rolled back, the staged rows wait for a fixed load
+	exists, err := bulkLoad.StagingExists(ctx)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, 2, countRows(t, db, stagingTable("transaction")))
+	assert.Zero(t, countRows(t, db, mainTable("transaction")))
+
+	mergedForeignKeys, notValidated, _ := constraintCounts(t, db)
+	assert.Equal(t, foreignKeys, mergedForeignKeys)
+	assert.Zero(t, notValidated)
+}
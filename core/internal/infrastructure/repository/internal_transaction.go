@@ -5,16 +5,15 @@ import (
 	"database/sql"
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/internal_transaction"
-	"github.com/elmiringos/indexer/indexer-core/pkg/redis"
 )
 
 type InternalTransactionRepository struct {
 	db    *sql.DB
-	redis *redis.Client
+	store KVStorage
 }
 
-func NewInternalTransactionRepository(db *sql.DB, redis *redis.Client) *InternalTransactionRepository {
-	return &InternalTransactionRepository{db: db, redis: redis}
+func NewInternalTransactionRepository(db *sql.DB, store KVStorage) *InternalTransactionRepository {
+	return &InternalTransactionRepository{db: db, store: store}
 }
 
 func (r *InternalTransactionRepository) SaveInternalTransaction(ctx context.Context, tx *internal_transaction.InternalTransaction) (string, error) {
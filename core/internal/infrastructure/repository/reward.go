@@ -5,21 +5,46 @@ import (
 	"database/sql"
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/reward"
-	"github.com/elmiringos/indexer/indexer-core/pkg/redis"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type RewardRepository struct {
 	db    *sql.DB
-	redis *redis.Client
+	store KVStorage
 }
 
-func NewRewardRepository(db *sql.DB, redis *redis.Client) *RewardRepository {
-	return &RewardRepository{db: db, redis: redis}
+func NewRewardRepository(db *sql.DB, store KVStorage) *RewardRepository {
+	return &RewardRepository{db: db, store: store}
 }
 
-func (r *RewardRepository) SaveReward(ctx context.Context, reward *reward.Reward) error {
-	query := `insert into reward (block_hash, address, amount) values ($1, $2, $3)`
-	_, err := r.db.ExecContext(ctx, query, reward.BlockHash, reward.Address, reward.Amount)
+const (
+	rewardInsert   = `insert into reward (block_hash, address, amount)`
+	rewardConflict = `on conflict (block_hash, address) do update set amount = excluded.amount`
+)
+
+type rewardKey struct {
+	blockHash common.Hash
+	address   common.Address
+}
+
+func (r *RewardRepository) SaveReward(ctx context.Context, rw *reward.Reward) error {
+	return r.SaveRewards(ctx, []*reward.Reward{rw})
+}
+
+// SaveRewards upserts the rewards with multi-row inserts in one database transaction
+func (r *RewardRepository) SaveRewards(ctx context.Context, rewards []*reward.Reward) error {
+	rewards = dedupe(rewards, func(rw *reward.Reward) rewardKey {
+		return rewardKey{blockHash: rw.BlockHash, address: rw.Address}
+	})
+
+	rows := make([][]interface{}, len(rewards))
+	blockHashes := make([]common.Hash, len(rewards))
+	for i, rw := range rewards {
+		rows[i] = []interface{}{rw.BlockHash, rw.Address, rw.Amount}
+		blockHashes[i] = rw.BlockHash
+	}
 
-	return err
+	return saveBlockChildren(ctx, r.db, blockHashes, func(tx *sql.Tx) error {
+		return execValues(ctx, tx, rewardInsert, rewardConflict, rows)
+	})
 }
@@ -5,20 +5,22 @@ import (
 	"database/sql"
 
 	smartcontract "github.com/elmiringos/indexer/indexer-core/internal/domain/smart_contract"
-	"github.com/elmiringos/indexer/indexer-core/pkg/redis"
 )
 
 type SmartContractRepository struct {
 	db    *sql.DB
-	redis *redis.Client
+	store KVStorage
 }
 
-func NewSmartContractRepository(db *sql.DB, redis *redis.Client) *SmartContractRepository {
-	return &SmartContractRepository{db: db, redis: redis}
+func NewSmartContractRepository(db *sql.DB, store KVStorage) *SmartContractRepository {
+	return &SmartContractRepository{db: db, store: store}
 }
 
 func (r *SmartContractRepository) SaveSmartContract(ctx context.Context, smartContract *smartcontract.SmartContract) error {
-	query := `insert into smart_contract (hash, block_hash, from_address, to_address, value, gas, gas_price, input, nonce, timestamp) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	// contracts verified by users are kept as they are when the deployment is indexed again
+	query := `insert into smart_contract (address_hash, name, compiler_version, source_code, abi, compiler_settings, verified_by_eth, evm_version)
+		values ($1, $2, $3, $4, coalesce(nullif($5, '')::jsonb, '[]'::jsonb), nullif($6, '')::jsonb, $7, $8)
+		on conflict (address_hash) do nothing`
 	_, err := r.db.ExecContext(ctx, query, smartContract.AddressHash, smartContract.Name, smartContract.CompilerVersion, smartContract.SourceCode, smartContract.ABI, smartContract.CompilerSettings, smartContract.VerifiedByEth, smartContract.EvmVersion)
 
 	return err
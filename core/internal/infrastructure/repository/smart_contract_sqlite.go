@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	smartcontract "github.com/elmiringos/indexer/indexer-core/internal/domain/smart_contract"
+)
+
+// SQLiteSmartContractRepository stores the ABI and compiler settings as JSON text
+type SQLiteSmartContractRepository struct {
+	*SmartContractRepository
+}
+
+func NewSQLiteSmartContractRepository(db *sql.DB, store KVStorage) *SQLiteSmartContractRepository {
+	return &SQLiteSmartContractRepository{SmartContractRepository: NewSmartContractRepository(db, store)}
+}
+
+func (r *SQLiteSmartContractRepository) SaveSmartContract(ctx context.Context, smartContract *smartcontract.SmartContract) error {
+	// contracts verified by users are kept as they are when the deployment is indexed again
+	query := `insert into smart_contract (address_hash, name, compiler_version, source_code, abi, compiler_settings, verified_by_eth, evm_version)
+		values ($1, $2, $3, $4, coalesce(nullif($5, ''), '[]'), nullif($6, ''), $7, $8)
+		on conflict (address_hash) do nothing`
+	_, err := r.db.ExecContext(ctx, query, smartContract.AddressHash, smartContract.Name, smartContract.CompilerVersion, smartContract.SourceCode, smartContract.ABI, smartContract.CompilerSettings, smartContract.VerifiedByEth, smartContract.EvmVersion)
+
+	return err
+}
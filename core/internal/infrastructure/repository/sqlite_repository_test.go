@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/reward"
+	smartcontract "github.com/elmiringos/indexer/indexer-core/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/token"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/withdrawal"
+	"github.com/elmiringos/indexer/indexer-core/pkg/memstore"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// uint256 values past the 2^53 a REAL keeps exactly and the 2^63 of an INTEGER
+var (
+	maxUint256 = domain.BigInt(*math.MaxBig256)
+	above2e64  = domain.BigInt(*new(big.Int).Add(math.BigPow(2, 64), big.NewInt(1)))
+	above2e200 = domain.BigInt(*new(big.Int).Add(math.BigPow(2, 200), big.NewInt(7)))
+)
+
+func TestSQLiteBlockRepository_SaveBlocks(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	blocks := NewSQLiteBlockRepository(db, memstore.New(), zap.NewNop())
+
+	first := testBlock(10, 0)
+	first.Difficulty = maxUint256
+	first.BaseFeePerGas = above2e64
+	first.MinerHash = common.HexToAddress("0x2f14582947E292a2eCd20C430B46f2d27CFE213c")
+	first.TransactionsRoot = common.HexToHash("0x35ec65e8eb9fb5c1d05922960dfe266d17a766c16b19822e7f0c99c9eb843173")
+	first.ReceiptsRoot = common.HexToHash("0x09e41ef90db5a42e8a4d9a5ccdfe58c208534b3d45111bdcf92f969a3abb1581")
+	first.LogsBloom = make([]byte, 256)
+	first.LogsBloom[255] = 1
+	first.StateRoot = common.HexToHash("0x2ee3c4bc6d1dde7a7f85e0e9c5e2e1a1f9ae3cc4dbbfc5d2b4cb1d5de9dca2f1")
+	first.MixHash = common.HexToHash("0x6b2e7f5b3b8c9a1d0e4f2a7c5d9b3e1f0a2c4e6b8d0f1a3c5e7b9d1f3a5c7e9b")
+	second := testBlock(11, 0)
+
+	require.NoError(t, blocks.SaveBlocks(ctx, []*block.Block{first, second}))
+
+	current, err := blocks.GetCurrentBlock(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.Hash, current.Hash)
+	assert.Equal(t, "11", current.Number.String())
+
+	// a redelivered block in the same batch as its update keeps the last copy
+	updated := *first
+	updated.Difficulty = above2e200
+	updated.IsPos = true
+	require.NoError(t, blocks.SaveBlocks(ctx, []*block.Block{first, &updated}))
+
+	var difficulty, baseFee domain.BigInt
+	var isPos bool
+	require.NoError(t, db.QueryRow(`select difficulty, base_fee_per_gas, is_pos from block where hash = $1`, first.Hash).Scan(&difficulty, &baseFee, &isPos))
+	assert.Equal(t, above2e200.String(), difficulty.String())
+	assert.Equal(t, above2e64.String(), baseFee.String())
+	assert.True(t, isPos)
+
+	from, to := bigInt(10), bigInt(11)
+	stored, err := blocks.GetBlocksInRange(ctx, &from, &to)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, first.Hash, stored[0].Hash)
+	assert.Equal(t, first.TransactionsRoot, stored[0].TransactionsRoot)
+	assert.Equal(t, first.ReceiptsRoot, stored[0].ReceiptsRoot)
+	assert.Equal(t, first.LogsBloom, stored[0].LogsBloom)
+	assert.Equal(t, common.Hash{}, stored[1].TransactionsRoot)
+
+	var stateRoot, mixHash []byte
+	require.NoError(t, db.QueryRow(`select state_root, mix_hash from block where hash = $1`, first.Hash).Scan(&stateRoot, &mixHash))
+	assert.Equal(t, first.StateRoot.Bytes(), stateRoot)
+	assert.Equal(t, first.MixHash.Bytes(), mixHash)
+
+	// neither block has its reward yet
+	indexedRange, err := blocks.GetIndexedRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "10", indexedRange.LowestNumber.String())
+	assert.Equal(t, "10", indexedRange.NextNumber.String())
+}
+
+func TestSQLiteTransactionRepository_SaveTransactionsAndLogs(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repositories := newTestRepositories(db)
+	require.NoError(t, repositories.blocks.SaveBlock(ctx, testBlock(1, 2)))
+
+	first := testTransaction(1, 0)
+	first.Value = maxUint256
+	first.From = common.HexToAddress("0xeA1B261FB7Ec1C4F2BEeA2476f17017537b4B507")
+	first.Raw = []byte{0x02, 0xf8}
+	second := testTransaction(1, 1)
+	second.Value = above2e64
+	require.NoError(t, repositories.transactions.SaveTransactions(ctx, []*transaction.Transaction{second, first}))
+
+	var value domain.BigInt
+	require.NoError(t, db.QueryRow(`select value from "transaction" where hash = $1`, first.Hash).Scan(&value))
+	assert.Equal(t, maxUint256.String(), value.String())
+	require.NoError(t, db.QueryRow(`select value from "transaction" where hash = $1`, second.Hash).Scan(&value))
+	assert.Equal(t, above2e64.String(), value.String())
+
+	stored, err := repositories.transactions.GetBlockTransactions(ctx, testBlockHash(1))
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, first.Hash, stored[0].Hash)
+	assert.Equal(t, first.Raw, stored[0].Raw)
+	assert.Equal(t, second.Hash, stored[1].Hash)
+
+	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	logs := []*transaction.TransactionLog{
+		{
+			Address:         common.HexToAddress("0xf4eced2f682ce333f96f2d8966c613ded8fc95dd"),
+			Topics:          []common.Hash{transferTopic, common.BytesToHash(first.From.Bytes())},
+			TransactionHash: first.Hash,
+			BlockHash:       testBlockHash(1),
+			Index:           0,
+			Data:            maxUint256.Bytes(),
+		},
+		{
+			TransactionHash:  second.Hash,
+			BlockHash:        testBlockHash(1),
+			TransactionIndex: 1,
+			Index:            1,
+		},
+	}
+	require.NoError(t, repositories.transactions.SaveTransactionLogs(ctx, logs))
+	// a redelivered log is upserted
+	require.NoError(t, repositories.transactions.SaveTransactionLog(ctx, logs[0]))
+
+	storedLogs, err := repositories.transactions.GetBlockTransactionLogs(ctx, testBlockHash(1))
+	require.NoError(t, err)
+	require.Len(t, storedLogs, 2)
+	assert.Equal(t, logs[0].Address, storedLogs[0].Address)
+	assert.Equal(t, logs[0].Topics, storedLogs[0].Topics)
+	assert.Equal(t, logs[0].Data, storedLogs[0].Data)
+	assert.Empty(t, storedLogs[1].Topics)
+}
+
+func TestSQLiteRepositories_SaveWithdrawalsAndRewards(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repositories := newTestRepositories(db)
+	withdrawals := NewSQLiteWithdrawalRepository(db)
+	require.NoError(t, repositories.blocks.SaveBlock(ctx, testBlock(1, 0)))
+
+	address := common.HexToAddress("0xB9D7934878B5FB9610B3fE8A5e441e8fad7E293f")
+	batch := []*withdrawal.Withdrawal{
+		{Index: 18476769, BlockHash: testBlockHash(1), AddressHash: address, ValidatorIndex: 711858, Amount: 16008754},
+		{Index: 18476770, BlockHash: testBlockHash(1), AddressHash: address, ValidatorIndex: 711859, Amount: 1 << 62},
+		{Index: 18476769, BlockHash: testBlockHash(1), AddressHash: address, ValidatorIndex: 711858, Amount: 16008754},
+	}
+	require.NoError(t, withdrawals.SaveWithdrawals(ctx, batch))
+
+	count, err := withdrawals.CountBlockWithdrawals(ctx, testBlockHash(1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	var amount domain.BigInt
+	require.NoError(t, db.QueryRow(`select amount from withdrawal where "index" = 18476770`).Scan(&amount))
+	assert.Equal(t, "4611686018427387904", amount.String())
+
+	rewards := []*reward.Reward{
+		{BlockHash: testBlockHash(1), Address: address, Amount: 1 << 62},
+	}
+	require.NoError(t, repositories.rewards.SaveRewards(ctx, rewards))
+	require.NoError(t, repositories.rewards.SaveRewards(ctx, rewards))
+
+	var rewardCount int
+	require.NoError(t, db.QueryRow(`select count(*), max(amount) from reward where block_hash = $1`, testBlockHash(1)).Scan(&rewardCount, &amount))
+	assert.Equal(t, 1, rewardCount)
+	assert.Equal(t, "4611686018427387904", amount.String())
+}
+
+func TestSQLiteTokenRepository_Supply(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	tokens := NewSQLiteTokenRepository(db, memstore.New())
+	address := common.HexToAddress("0xf4eced2f682ce333f96f2d8966c613ded8fc95dd")
+
+	require.NoError(t, tokens.SaveToken(ctx, &token.Token{
+		Address:              address,
+		Type:                 "ERC-20",
+		Name:                 "Token",
+		Symbol:               "TKN",
+		Decimals:             18,
+		TotalSupply:          above2e200,
+		FiatValue:            bigInt(0),
+		CirculationMarketCap: bigInt(0),
+	}))
+
+	supply := func() string {
+		t.Helper()
+		var totalSupply domain.BigInt
+		require.NoError(t, db.QueryRow(`select total_supply from token where address_hash = $1`, address).Scan(&totalSupply))
+		return totalSupply.String()
+	}
+
+	require.NoError(t, tokens.IncreaseTokenSupply(ctx, address, above2e200))
+	assert.Equal(t, new(big.Int).Mul((*big.Int)(&above2e200), big.NewInt(2)).String(), supply())
+
+	require.NoError(t, tokens.DecreaseTokenSupply(ctx, address, above2e200))
+	assert.Equal(t, above2e200.String(), supply())
+
+	require.Error(t, tokens.DecreaseTokenSupply(ctx, address, maxUint256))
+	assert.Equal(t, above2e200.String(), supply())
+
+	// saving the token again keeps the supply the transfers built up
+	require.NoError(t, tokens.SaveToken(ctx, &token.Token{Address: address, Type: "ERC-20", Name: "Renamed", TotalSupply: bigInt(0)}))
+	assert.Equal(t, above2e200.String(), supply())
+}
+
+func TestSQLiteTokenRepository_SaveTransfersAndInstances(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	tokens := NewSQLiteTokenRepository(db, memstore.New())
+	contract := common.HexToAddress("0x06012c8cf97bead5deae237070f9587f8e7a266d")
+	from := common.HexToAddress("0xd1220a0cf47c7b9be7a2e6ba89f429762e7b9adb")
+	to := common.HexToAddress("0xdbf03b407c01e7cd3cbea99509d93f8dddc8c6fb")
+	insertBlock(t, db, testBlockHash(1), 1)
+	insertTransaction(t, db, testTransactionHash(1, 0), testBlockHash(1))
+	require.NoError(t, tokens.SaveToken(ctx, &token.Token{Address: contract, Type: "ERC-721", TotalSupply: bigInt(0)}))
+
+	require.NoError(t, tokens.SaveTokenTransfer(ctx, &token.TokenTransfer{
+		TransactionHash:      testTransactionHash(1, 0),
+		LogIndex:             0,
+		From:                 from,
+		To:                   to,
+		TokenContractAddress: contract,
+		Amount:               bigInt(1),
+		TokenId:              &maxUint256,
+	}))
+	require.NoError(t, tokens.SaveTokenTransfer(ctx, &token.TokenTransfer{
+		TransactionHash:      testTransactionHash(1, 0),
+		LogIndex:             1,
+		From:                 from,
+		To:                   to,
+		TokenContractAddress: common.HexToAddress("0xf4eced2f682ce333f96f2d8966c613ded8fc95dd"),
+		Amount:               above2e200,
+	}))
+
+	var amount domain.BigInt
+	var tokenId *string
+	require.NoError(t, db.QueryRow(`select amount, token_id from token_transfer where log_index = 0`).Scan(&amount, &tokenId))
+	assert.Equal(t, "1", amount.String())
+	require.NotNil(t, tokenId)
+	assert.Equal(t, maxUint256.String(), *tokenId)
+
+	require.NoError(t, db.QueryRow(`select amount, token_id from token_transfer where log_index = 1`).Scan(&amount, &tokenId))
+	assert.Equal(t, above2e200.String(), amount.String())
+	assert.Nil(t, tokenId, "ERC-20 transfers have no token id")
+
+	instance := &token.TokenInstance{TokenId: maxUint256, TokenContractAddress: contract, OwnerAddress: from}
+	require.NoError(t, tokens.SaveOrUpdateTokenInstance(ctx, instance))
+	instance.OwnerAddress = to
+	require.NoError(t, tokens.SaveOrUpdateTokenInstance(ctx, instance))
+
+	var owner []byte
+	var instances int
+	require.NoError(t, db.QueryRow(`select count(*), max(owner_address_hash) from token_instance where token_id = $1`, maxUint256).Scan(&instances, &owner))
+	assert.Equal(t, 1, instances)
+	assert.Equal(t, to, common.BytesToAddress(owner))
+}
+
+func TestSQLiteSmartContractRepository_SaveSmartContract(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	contracts := NewSQLiteSmartContractRepository(db, memstore.New())
+	address := common.HexToAddress("0x1B68284E6a2A0a1Dc3a4D7B2d1a6A5Bf5f83AEa5")
+
+	require.NoError(t, contracts.SaveSmartContract(ctx, &smartcontract.SmartContract{AddressHash: address}))
+
+	var abi string
+	var settings *string
+	require.NoError(t, db.QueryRow(`select abi, compiler_settings from smart_contract where address_hash = $1`, address).Scan(&abi, &settings))
+	assert.Equal(t, "[]", abi)
+	assert.Nil(t, settings)
+
+	// a contract verified by a user is kept when its deployment is indexed again
+	_, err := db.Exec(`update smart_contract set name = 'Verified', abi = '[{"type":"fallback"}]' where address_hash = $1`, address)
+	require.NoError(t, err)
+	require.NoError(t, contracts.SaveSmartContract(ctx, &smartcontract.SmartContract{AddressHash: address, Name: "Deployed"}))
+
+	var name string
+	require.NoError(t, db.QueryRow(`select name, abi from smart_contract where address_hash = $1`, address).Scan(&name, &abi))
+	assert.Equal(t, "Verified", name)
+	assert.Equal(t, `[{"type":"fallback"}]`, abi)
+}
+
+// TestSQLiteBlockRepository_StatusWithUint256Values runs the status and gap queries over blocks
+// whose uint256 columns hold values no INTEGER or REAL keeps exactly
+func TestSQLiteBlockRepository_StatusWithUint256Values(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	repositories := newTestRepositories(db)
+
+	for _, number := range []int64{5, 6, 9} {
+		b := testBlock(number, 1)
+		b.Difficulty = maxUint256
+		b.BaseFeePerGas = above2e64
+		require.NoError(t, repositories.blocks.SaveBlock(ctx, b))
+
+		tx := testTransaction(number, 0)
+		tx.Value = maxUint256
+		require.NoError(t, repositories.transactions.SaveTransactions(ctx, []*transaction.Transaction{tx}))
+		if number != 6 {
+			require.NoError(t, repositories.rewards.SaveRewards(ctx, []*reward.Reward{testReward(number)}))
+		}
+	}
+
+	status, err := repositories.blocks.GetIndexingStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "5", status.LowestNumber.String())
+	assert.Equal(t, "6", status.NextNumber.String())
+	assert.Equal(t, "9", status.HighestNumber.String())
+	assert.Equal(t, int64(3), status.TotalBlocks)
+	assert.Equal(t, int64(2), status.CompleteBlocks)
+	assert.Equal(t, int64(1), status.IncompleteBlocks)
+	assert.Equal(t, int64(2), status.MissingBlocks)
+
+	gaps, err := repositories.blocks.ListGaps(ctx, nil, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []gapRange{
+		{kind: block.GapIncomplete, from: "6", to: "6"},
+		{kind: block.GapMissing, from: "7", to: "8"},
+	}, gapRanges(gaps))
+
+	after := bigInt(6)
+	gaps, err = repositories.blocks.ListGaps(ctx, &after, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []gapRange{{kind: block.GapMissing, from: "7", to: "8"}}, gapRanges(gaps))
+}
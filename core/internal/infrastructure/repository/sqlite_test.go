@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/block"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/reward"
+	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
+	"github.com/elmiringos/indexer/indexer-core/pkg/migrator"
+	"github.com/elmiringos/indexer/indexer-core/pkg/sqlite"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// openSQLite migrates a fresh SQLite database in a temporary directory
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.SQLitePath = filepath.Join(t.TempDir(), "indexer.db")
+
+	m, err := migrator.New(cfg, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, m.Up(0))
+	require.NoError(t, m.Close())
+
+	db := sqlite.NewSQLiteConnection(cfg, zap.NewNop()).GetDb()
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// insertBlock stores a block row with only the columns the schema requires
+func insertBlock(t *testing.T, db *sql.DB, hash common.Hash, number int64) {
+	t.Helper()
+
+	query := `
+		insert into block (hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp)
+		values ($1, $2, $3, $4, 0, 0, 0, 0, '0', true, '0', 0)`
+	_, err := db.Exec(query, hash.Bytes(), number, common.Address{}.Bytes(), common.Hash{}.Bytes())
+	require.NoError(t, err)
+}
+
+// insertTransaction stores a transaction row of the block with only the columns the schema requires
+func insertTransaction(t *testing.T, db *sql.DB, hash common.Hash, blockHash common.Hash) {
+	t.Helper()
+
+	query := `
+		insert into "transaction" (hash, block_hash, "index", status, gas, gas_used, value, from_address, to_address, nonce, timestamp)
+		values ($1, $2, 0, 1, '0', '0', '0', $3, $3, 0, 0)`
+	_, err := db.Exec(query, hash.Bytes(), blockHash.Bytes(), common.Address{}.Bytes())
+	require.NoError(t, err)
+}
+
+func bigInt(n int64) domain.BigInt {
+	return domain.BigInt(*big.NewInt(n))
+}
+
+func testBlockHash(number int64) common.Hash {
+	return common.BigToHash(big.NewInt(number))
+}
+
+func testTransactionHash(number int64, index int) common.Hash {
+	return common.BigToHash(big.NewInt(number*1000 + int64(index) + 1))
+}
+
+// testBlock builds block number with transactionCount transactions and no withdrawals
+func testBlock(number int64, transactionCount int) *block.Block {
+	return &block.Block{
+		Hash:              testBlockHash(number),
+		Number:            bigInt(number),
+		Difficulty:        bigInt(0),
+		BaseFeePerGas:     bigInt(0),
+		TransactionsCount: transactionCount,
+		Timestamp:         uint64(number),
+	}
+}
+
+func testTransaction(number int64, index int) *transaction.Transaction {
+	return &transaction.Transaction{
+		Hash:      testTransactionHash(number, index),
+		BlockHash: testBlockHash(number),
+		Index:     index,
+		Status:    1,
+		Value:     bigInt(0),
+		Timestamp: number,
+	}
+}
+
+func testReward(number int64) *reward.Reward {
+	return &reward.Reward{BlockHash: testBlockHash(number), Amount: 1}
+}
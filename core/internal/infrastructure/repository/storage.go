@@ -4,7 +4,13 @@ import "context"
 
 type KVStorage interface {
 	GetInt(ctx context.Context, key string) (int, error)
+	// SetInt stores the value unless the key exists, a redelivered message must not restart a counter
 	SetInt(ctx context.Context, key string, value int) error
+	// OverwriteInt stores the value even if the key exists and forgets the members applied to it,
+	// only a reindex may restart a counter
+	OverwriteInt(ctx context.Context, key string, value int) error
 	Delete(ctx context.Context, key string) error
-	DecrementAndMaybeDelete(ctx context.Context, key string) error
+	// DecrementAndMaybeDelete decrements the counter once per member, a redelivered message or one
+	// retried after a failed batch leaves it unchanged. The counter is removed once it reaches zero.
+	DecrementAndMaybeDelete(ctx context.Context, key, member string) error
 }
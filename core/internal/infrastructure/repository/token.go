@@ -7,24 +7,28 @@ import (
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain"
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/token"
-	"github.com/elmiringos/indexer/indexer-core/pkg/redis"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type TokenRepository struct {
 	db    *sql.DB
-	redis *redis.Client
+	store KVStorage
 }
 
-func NewTokenRepository(db *sql.DB, redis *redis.Client) *TokenRepository {
-	return &TokenRepository{db: db, redis: redis}
+func NewTokenRepository(db *sql.DB, store KVStorage) *TokenRepository {
+	return &TokenRepository{db: db, store: store}
 }
 
 func (r *TokenRepository) SaveToken(ctx context.Context, token *token.Token) error {
 	query := `
-		INSERT INTO token (address_hash, name, symbol, decimals, total_supply, fiat_value, circulation_market_cap)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-	_, err := r.db.ExecContext(ctx, query, token.Address, token.Name, token.Symbol, token.Decimals, token.TotalSupply, token.FiatValue, token.CirculationMarketCap)
+		INSERT INTO token (address_hash, type, name, symbol, decimals, total_supply, fiat_value, circulation_market_cap)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (address_hash) DO UPDATE SET
+			type = EXCLUDED.type,
+			name = EXCLUDED.name,
+			symbol = EXCLUDED.symbol,
+			decimals = EXCLUDED.decimals`
+	_, err := r.db.ExecContext(ctx, query, token.Address, token.Type, token.Name, token.Symbol, token.Decimals, token.TotalSupply, token.FiatValue, token.CirculationMarketCap)
 
 	return err
 }
@@ -123,8 +127,14 @@ func (r *TokenRepository) SaveOrUpdateTokenInstance(ctx context.Context, token *
 
 func (r *TokenRepository) SaveTokenTransfer(ctx context.Context, token *token.TokenTransfer) error {
 	query := `
-		INSERT INTO token_transfer (transaction_hash, log_index, from_address, to_address, token_contract_address_hash, amount)
-		VALUES($1, $2, $3, $4, $5, $6)
+		INSERT INTO token_transfer (transaction_hash, log_index, from_address, to_address, token_contract_address_hash, amount, token_id)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (transaction_hash, log_index) DO UPDATE SET
+			from_address = EXCLUDED.from_address,
+			to_address = EXCLUDED.to_address,
+			token_contract_address_hash = EXCLUDED.token_contract_address_hash,
+			amount = EXCLUDED.amount,
+			token_id = EXCLUDED.token_id
 	`
 	_, err := r.db.ExecContext(
 		ctx, query,
@@ -134,6 +144,7 @@ func (r *TokenRepository) SaveTokenTransfer(ctx context.Context, token *token.To
 		token.To,
 		token.TokenContractAddress,
 		token.Amount,
+		token.TokenId,
 	)
 
 	return err
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/elmiringos/indexer/indexer-core/internal/domain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SQLiteTokenRepository drops the row lock of the supply update, SQLite serializes writers on the database
+type SQLiteTokenRepository struct {
+	*TokenRepository
+}
+
+func NewSQLiteTokenRepository(db *sql.DB, store KVStorage) *SQLiteTokenRepository {
+	return &SQLiteTokenRepository{TokenRepository: NewTokenRepository(db, store)}
+}
+
+func (r *SQLiteTokenRepository) IncreaseTokenSupply(ctx context.Context, addressHash common.Address, addSupply domain.BigInt) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var currentSupply domain.BigInt
+	err = tx.QueryRowContext(ctx, `SELECT total_supply FROM token WHERE address_hash = $1`, addressHash).Scan(&currentSupply)
+	if err != nil {
+		return err
+	}
+
+	newSupply := currentSupply.Sum(addSupply)
+	if _, err = tx.ExecContext(ctx, `UPDATE token SET total_supply = $1 WHERE address_hash = $2`, newSupply, addressHash); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
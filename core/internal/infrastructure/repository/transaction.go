@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/transaction"
 	"github.com/ethereum/go-ethereum/common"
@@ -11,20 +12,33 @@ import (
 )
 
 type TransactionRepository struct {
-	db    *sql.DB
-	store KVStorage
-	log   *zap.Logger
+	db     *sql.DB
+	store  KVStorage
+	log    *zap.Logger
+	schema string
 }
 
 func NewTransactionRepository(db *sql.DB, store KVStorage, log *zap.Logger) *TransactionRepository {
-	return &TransactionRepository{db: db, store: store, log: log}
+	return &TransactionRepository{db: db, store: store, log: log, schema: postgresSchema}
 }
 
-func (r *TransactionRepository) SaveTransaction(ctx context.Context, tx *transaction.Transaction) error {
-	query := `insert into transaction (
+// NewSQLiteTransactionRepository reads the transactions from the main database of SQLite
+func NewSQLiteTransactionRepository(db *sql.DB, store KVStorage, log *zap.Logger) *TransactionRepository {
+	r := NewTransactionRepository(db, store, log)
+	r.schema = sqliteSchema
+	return r
+}
+
+// mainTable qualifies a table core reads its own progress from
+func (r *TransactionRepository) mainTable(table string) string {
+	return qualifiedTable(r.schema, table)
+}
+
+const (
+	transactionInsert = `insert into "transaction" (
 		hash,
 		block_hash,
-		index,
+		"index",
 		status,
 		gas,
 		gas_used,
@@ -33,60 +47,177 @@ func (r *TransactionRepository) SaveTransaction(ctx context.Context, tx *transac
 		from_address,
 		to_address,
 		nonce,
-		timestamp
-	) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
-	_, err := r.db.ExecContext(ctx, query, tx.Hash, tx.BlockHash, tx.Index, tx.Status, tx.Gas, tx.GasUsed, tx.Input, tx.Value, tx.From, tx.To, tx.Nonce, tx.Timestamp)
+		timestamp,
+		type,
+		raw,
+		cumulative_gas_used,
+		post_state
+	)`
 
-	return err
+	transactionConflict = `on conflict (hash) do update set
+		block_hash = excluded.block_hash,
+		"index" = excluded."index",
+		status = excluded.status,
+		gas = excluded.gas,
+		gas_used = excluded.gas_used,
+		input = excluded.input,
+		value = excluded.value,
+		from_address = excluded.from_address,
+		to_address = excluded.to_address,
+		nonce = excluded.nonce,
+		timestamp = excluded.timestamp,
+		type = excluded.type,
+		raw = excluded.raw,
+		cumulative_gas_used = excluded.cumulative_gas_used,
+		post_state = excluded.post_state`
+)
+
+func transactionArgs(tx *transaction.Transaction) []interface{} {
+	return []interface{}{tx.Hash, tx.BlockHash, tx.Index, tx.Status, tx.Gas, tx.GasUsed, tx.Input, tx.Value, tx.From, tx.To, tx.Nonce, tx.Timestamp,
+		tx.Type, tx.Raw, tx.CumulativeGasUsed, tx.PostState}
 }
 
-func (r *TransactionRepository) SaveTransactionLog(ctx context.Context, txLog *transaction.TransactionLog) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+func (r *TransactionRepository) SaveTransaction(ctx context.Context, tx *transaction.Transaction) error {
+	return r.SaveTransactions(ctx, []*transaction.Transaction{tx})
+}
+
+// SaveTransactions upserts the transactions with multi-row inserts in one database transaction
+func (r *TransactionRepository) SaveTransactions(ctx context.Context, txs []*transaction.Transaction) error {
+	txs = dedupe(txs, func(tx *transaction.Transaction) common.Hash { return tx.Hash })
+
+	rows := make([][]interface{}, len(txs))
+	blockHashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		rows[i] = transactionArgs(tx)
+		blockHashes[i] = tx.BlockHash
+	}
+
+	return saveBlockChildren(ctx, r.db, blockHashes, func(tx *sql.Tx) error {
+		return execValues(ctx, tx, transactionInsert, transactionConflict, rows)
+	})
+}
+
+// GetBlockTransactions returns the transactions of a block ordered by index with the fields
+// needed to rebuild their consensus encoding and receipts
+func (r *TransactionRepository) GetBlockTransactions(ctx context.Context, blockHash common.Hash) ([]*transaction.Transaction, error) {
+	query := fmt.Sprintf(`select hash, "index", status, gas_used, type, raw, cumulative_gas_used, post_state
+		from %s
+		where block_hash = $1
+		order by "index"`, r.mainTable("transaction"))
+
+	rows, err := r.db.QueryContext(ctx, query, blockHash)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	logQuery := `
-		INSERT INTO transaction_log (
-			address, transaction_hash, block_hash, transaction_index, log_index, data  
-		) VALUES ($1, $2, $3, $4, $5, $6)`
-
-	_, err = tx.ExecContext(ctx, logQuery,
-		txLog.Address,
-		txLog.TransactionHash,
-		txLog.BlockHash,
-		txLog.TransactionIndex,
-		txLog.Index,
-		txLog.Data,
-	)
+	var transactions []*transaction.Transaction
+	for rows.Next() {
+		tx := transaction.Transaction{BlockHash: blockHash}
+		err = rows.Scan(&tx.Hash, &tx.Index, &tx.Status, &tx.GasUsed, &tx.Type, &tx.Raw, &tx.CumulativeGasUsed, &tx.PostState)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, &tx)
+	}
+
+	return transactions, rows.Err()
+}
+
+// GetBlockTransactionLogs returns the logs of a block with their topics, ordered by transaction and log index
+func (r *TransactionRepository) GetBlockTransactionLogs(ctx context.Context, blockHash common.Hash) ([]*transaction.TransactionLog, error) {
+	query := fmt.Sprintf(`select l.address, l.transaction_hash, l.transaction_index, l.log_index, l.data, t.topic_index, t.topic
+		from %s l
+		left join %s t on t.transaction_hash = l.transaction_hash and t.log_index = l.log_index
+		where l.block_hash = $1
+		order by l.transaction_index, l.log_index, t.topic_index`, r.mainTable("transaction_log"), r.mainTable("transaction_log_topic"))
+
+	rows, err := r.db.QueryContext(ctx, query, blockHash)
 	if err != nil {
-		tx.Rollback()
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	topicQuery := `
-		INSERT INTO transaction_log_topic (
-			transaction_hash, log_index, topic_index, topic
-		) VALUES ($1, $2, $3, $4)`
-
-	for i, topic := range txLog.Topics {
-		_, err := tx.ExecContext(ctx, topicQuery,
-			txLog.TransactionHash,
-			txLog.Index,
-			i,
-			topic,
+	var (
+		logs    []*transaction.TransactionLog
+		current *transaction.TransactionLog
+	)
+	for rows.Next() {
+		var (
+			txLog      = transaction.TransactionLog{BlockHash: blockHash}
+			topicIndex sql.NullInt64
+			topic      []byte
 		)
+		err = rows.Scan(&txLog.Address, &txLog.TransactionHash, &txLog.TransactionIndex, &txLog.Index, &txLog.Data, &topicIndex, &topic)
 		if err != nil {
-			tx.Rollback()
-			return err
+			return nil, err
+		}
+
+		// the join yields one row per topic, consecutive rows of the same log are folded together
+		if current == nil || current.TransactionHash != txLog.TransactionHash || current.Index != txLog.Index {
+			current = &txLog
+			logs = append(logs, current)
+		}
+		if topicIndex.Valid {
+			current.Topics = append(current.Topics, common.BytesToHash(topic))
 		}
 	}
 
-	return tx.Commit()
+	return logs, rows.Err()
+}
+
+const (
+	transactionLogInsert = `insert into transaction_log (address, transaction_hash, block_hash, transaction_index, log_index, data)`
+
+	transactionLogConflict = `on conflict (transaction_hash, log_index) do update set
+			address = excluded.address,
+			block_hash = excluded.block_hash,
+			transaction_index = excluded.transaction_index,
+			data = excluded.data`
+
+	transactionLogTopicInsert = `insert into transaction_log_topic (transaction_hash, log_index, topic_index, topic)`
+
+	transactionLogTopicConflict = `on conflict (transaction_hash, log_index, topic_index) do update set topic = excluded.topic`
+)
+
+type transactionLogKey struct {
+	hash  common.Hash
+	index uint
+}
+
+func (r *TransactionRepository) SaveTransactionLog(ctx context.Context, txLog *transaction.TransactionLog) error {
+	return r.SaveTransactionLogs(ctx, []*transaction.TransactionLog{txLog})
+}
+
+// SaveTransactionLogs upserts the logs and their topics in one database transaction,
+// one multi-row insert for the logs and one for all of their topics
+func (r *TransactionRepository) SaveTransactionLogs(ctx context.Context, txLogs []*transaction.TransactionLog) error {
+	txLogs = dedupe(txLogs, func(l *transaction.TransactionLog) transactionLogKey {
+		return transactionLogKey{hash: l.TransactionHash, index: l.Index}
+	})
+
+	var logRows, topicRows [][]interface{}
+	for _, l := range txLogs {
+		logRows = append(logRows, []interface{}{l.Address, l.TransactionHash, l.BlockHash, l.TransactionIndex, l.Index, l.Data})
+		for i, topic := range l.Topics {
+			topicRows = append(topicRows, []interface{}{l.TransactionHash, l.Index, i, topic})
+		}
+	}
+
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := execValues(ctx, tx, transactionLogInsert, transactionLogConflict, logRows); err != nil {
+			return err
+		}
+		return execValues(ctx, tx, transactionLogTopicInsert, transactionLogTopicConflict, topicRows)
+	})
 }
 
 func (r *TransactionRepository) SaveTransactionAction(ctx context.Context, txAction *transaction.TransactionAction) error {
-	query := `insert into transaction_action (transaction_hash, log_index, data, address, type) values ($1, $2, $3, $4, $5)`
+	query := `insert into transaction_action (transaction_hash, log_index, data, address_contract_hash, type) values ($1, $2, $3, $4, $5)
+		on conflict (transaction_hash, log_index) do update set
+			data = excluded.data,
+			address_contract_hash = excluded.address_contract_hash,
+			type = excluded.type`
 	_, err := r.db.ExecContext(ctx, query, txAction.TransactionHash, txAction.LogIndex, txAction.Data, txAction.AddressHash, txAction.Type)
 
 	return err
@@ -104,6 +235,11 @@ func (r *TransactionRepository) SaveTransactionHashForLog(ctx context.Context, h
 	return r.store.SetInt(ctx, makeTransactionLogKey(hash), count)
 }
 
+// ResetTransactionHashForLog restarts the log counter of a transaction of a reindexed block
+func (r *TransactionRepository) ResetTransactionHashForLog(ctx context.Context, hash common.Hash, count int) error {
+	return r.store.OverwriteInt(ctx, makeTransactionLogKey(hash), count)
+}
+
 func (r *TransactionRepository) CheckTransactionExistForLog(ctx context.Context, hash common.Hash) (bool, error) {
 	exists, err := r.store.GetInt(ctx, makeTransactionLogKey(hash))
 	if err != nil {
@@ -113,8 +249,8 @@ func (r *TransactionRepository) CheckTransactionExistForLog(ctx context.Context,
 	return exists != 0, nil
 }
 
-func (r *TransactionRepository) DecrementTransactionLogCount(ctx context.Context, hash common.Hash) error {
-	return r.store.DecrementAndMaybeDelete(ctx, makeTransactionLogKey(hash))
+func (r *TransactionRepository) DecrementTransactionLogCount(ctx context.Context, hash common.Hash, logIndex uint) error {
+	return r.store.DecrementAndMaybeDelete(ctx, makeTransactionLogKey(hash), strconv.FormatUint(uint64(logIndex), 10))
 }
 
 func (r *TransactionRepository) SaveTransactionHashForAction(ctx context.Context, hash common.Hash, count int) error {
@@ -130,6 +266,6 @@ func (r *TransactionRepository) CheckTransactionExistForAction(ctx context.Conte
 	return exists != 0, nil
 }
 
-func (r *TransactionRepository) DecrementTransactionActionCount(ctx context.Context, hash common.Hash) error {
-	return r.store.DecrementAndMaybeDelete(ctx, makeTransactionActionKey(hash))
+func (r *TransactionRepository) DecrementTransactionActionCount(ctx context.Context, hash common.Hash, logIndex int) error {
+	return r.store.DecrementAndMaybeDelete(ctx, makeTransactionActionKey(hash), strconv.Itoa(logIndex))
 }
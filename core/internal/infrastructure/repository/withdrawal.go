@@ -3,34 +3,69 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/elmiringos/indexer/indexer-core/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type WithdrawalRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	schema string
 }
 
 func NewWithdrawalRepository(db *sql.DB) *WithdrawalRepository {
-	return &WithdrawalRepository{db: db}
-}
-
-func (r *WithdrawalRepository) SaveWithdrawal(ctx context.Context, withdrawal *withdrawal.Withdrawal) error {
-	query := `
-		INSERT INTO withdrawal (
-			index,
-			block_hash,
-			address_hash,
-			validator_index,
-			amount
-		) VALUES (
-			$1,
-			$2,
-			$3,
-			$4,
-			$5
-		)
-	`
-	_, err := r.db.ExecContext(ctx, query, withdrawal.Index, withdrawal.BlockHash, withdrawal.AddressHash, withdrawal.ValidatorIndex, withdrawal.Amount)
-	return err
+	return &WithdrawalRepository{db: db, schema: postgresSchema}
+}
+
+// NewSQLiteWithdrawalRepository counts the withdrawals in the main database of SQLite
+func NewSQLiteWithdrawalRepository(db *sql.DB) *WithdrawalRepository {
+	return &WithdrawalRepository{db: db, schema: sqliteSchema}
+}
+
+const (
+	withdrawalInsert = `INSERT INTO withdrawal ("index", block_hash, address_hash, validator_index, amount)`
+
+	withdrawalConflict = `ON CONFLICT ("index", block_hash) DO UPDATE SET
+			address_hash = EXCLUDED.address_hash,
+			validator_index = EXCLUDED.validator_index,
+			amount = EXCLUDED.amount`
+)
+
+type withdrawalKey struct {
+	index     uint64
+	blockHash common.Hash
+}
+
+func withdrawalArgs(w *withdrawal.Withdrawal) []interface{} {
+	return []interface{}{w.Index, w.BlockHash, w.AddressHash, w.ValidatorIndex, w.Amount}
+}
+
+func (r *WithdrawalRepository) SaveWithdrawal(ctx context.Context, w *withdrawal.Withdrawal) error {
+	return r.SaveWithdrawals(ctx, []*withdrawal.Withdrawal{w})
+}
+
+// SaveWithdrawals upserts the withdrawals with multi-row inserts in one database transaction
+func (r *WithdrawalRepository) SaveWithdrawals(ctx context.Context, withdrawals []*withdrawal.Withdrawal) error {
+	withdrawals = dedupe(withdrawals, func(w *withdrawal.Withdrawal) withdrawalKey {
+		return withdrawalKey{index: w.Index, blockHash: w.BlockHash}
+	})
+
+	rows := make([][]interface{}, len(withdrawals))
+	blockHashes := make([]common.Hash, len(withdrawals))
+	for i, w := range withdrawals {
+		rows[i] = withdrawalArgs(w)
+		blockHashes[i] = w.BlockHash
+	}
+
+	return saveBlockChildren(ctx, r.db, blockHashes, func(tx *sql.Tx) error {
+		return execValues(ctx, tx, withdrawalInsert, withdrawalConflict, rows)
+	})
+}
+
+func (r *WithdrawalRepository) CountBlockWithdrawals(ctx context.Context, blockHash common.Hash) (int, error) {
+	var count int
+	query := fmt.Sprintf(`select count(*) from %s where block_hash = $1`, qualifiedTable(r.schema, "withdrawal"))
+	err := r.db.QueryRowContext(ctx, query, blockHash).Scan(&count)
+	return count, err
 }
@@ -0,0 +1,9 @@
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "transactions_count" INT NOT NULL DEFAULT 0;
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "withdrawals_count" INT NOT NULL DEFAULT 0;
+
+-- backfill from the rows already indexed, blocks ingested before this migration are treated as complete
+UPDATE "block" b SET
+    "transactions_count" = (SELECT count(*) FROM "transaction" t WHERE t.block_hash = b.hash),
+    "withdrawals_count" = (SELECT count(*) FROM "withdrawal" w WHERE w.block_hash = b.hash);
+
+CREATE INDEX IF NOT EXISTS idx_block_number ON block (number);
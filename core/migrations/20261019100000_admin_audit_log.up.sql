@@ -0,0 +1,12 @@
+-- admin_audit_log
+CREATE TABLE IF NOT EXISTS "admin_audit_log" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "action" VARCHAR NOT NULL,
+    "actor" VARCHAR NOT NULL,
+    "params" JSONB,
+    "success" BOOL NOT NULL,
+    "error" TEXT,
+    "created_at" TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at_brin ON admin_audit_log USING BRIN (created_at);
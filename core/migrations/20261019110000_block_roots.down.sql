@@ -0,0 +1,8 @@
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS "post_state";
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS "cumulative_gas_used";
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS "raw";
+ALTER TABLE "transaction" DROP COLUMN IF EXISTS "type";
+
+ALTER TABLE "block" DROP COLUMN IF EXISTS "logs_bloom";
+ALTER TABLE "block" DROP COLUMN IF EXISTS "receipts_root";
+ALTER TABLE "block" DROP COLUMN IF EXISTS "transactions_root";
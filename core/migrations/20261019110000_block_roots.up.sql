@@ -0,0 +1,10 @@
+-- header commitments published by the producer, NULL for blocks indexed before this migration
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "transactions_root" BYTEA;
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "receipts_root" BYTEA;
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "logs_bloom" BYTEA;
+
+-- consensus encoding and receipt fields needed to recompute the roots
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS "type" SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS "raw" BYTEA;
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS "cumulative_gas_used" BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE "transaction" ADD COLUMN IF NOT EXISTS "post_state" BYTEA;
@@ -0,0 +1,5 @@
+-- kv_store holds the coordination counters when core runs without Redis
+CREATE TABLE IF NOT EXISTS "kv_store" (
+    "key" VARCHAR PRIMARY KEY,
+    "value" BIGINT NOT NULL
+);
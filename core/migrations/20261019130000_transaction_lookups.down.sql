@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_transaction_to_address;
+DROP INDEX IF EXISTS idx_internal_tx_transaction_hash;
@@ -0,0 +1,9 @@
+CREATE INDEX IF NOT EXISTS idx_transaction_to_address ON "transaction" (to_address);
+
+DROP INDEX IF EXISTS idx_reward_address;
+DROP INDEX IF EXISTS idx_withdrawal_address_index;
+DROP INDEX IF EXISTS idx_token_transfer_to_address;
+DROP INDEX IF EXISTS idx_internal_tx_to_address_timestamp;
+DROP INDEX IF EXISTS idx_internal_tx_from_address_timestamp;
+DROP INDEX IF EXISTS idx_transaction_to_address_timestamp;
+DROP INDEX IF EXISTS idx_transaction_from_address_timestamp;
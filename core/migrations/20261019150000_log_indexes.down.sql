@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_tx_log_address ON transaction_log (address);
+
+DROP INDEX IF EXISTS idx_tx_log_address_block_hash;
+DROP INDEX IF EXISTS idx_tx_log_topic_topic;
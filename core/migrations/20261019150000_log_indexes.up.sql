@@ -0,0 +1,7 @@
+-- log search matches topics by value and position, then reads the log through the primary key
+CREATE INDEX IF NOT EXISTS idx_tx_log_topic_topic ON transaction_log_topic (topic, topic_index);
+-- log search by address also filters on the block, the block hash saves a heap read per log
+CREATE INDEX IF NOT EXISTS idx_tx_log_address_block_hash ON transaction_log (address, block_hash);
+
+-- superseded by idx_tx_log_address_block_hash
+DROP INDEX IF EXISTS idx_tx_log_address;
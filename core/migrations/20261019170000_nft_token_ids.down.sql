@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_token_transfer_token_id;
+
+ALTER TABLE token_transfer DROP COLUMN IF EXISTS token_id;
+
+-- fails while an instance holds an id past the BIGINT range
+ALTER TABLE token_instance ALTER COLUMN token_id TYPE BIGINT;
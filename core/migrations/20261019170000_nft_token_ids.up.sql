@@ -0,0 +1,7 @@
+-- token ids are uint256, BIGINT overflows past 2^63 - 1
+ALTER TABLE token_instance ALTER COLUMN token_id TYPE NUMERIC;
+
+-- token id of ERC-721 and ERC-1155 transfers, NULL for ERC-20 and for transfers indexed before this migration
+ALTER TABLE token_transfer ADD COLUMN IF NOT EXISTS token_id NUMERIC;
+
+CREATE INDEX IF NOT EXISTS idx_token_transfer_token_id ON token_transfer (token_contract_address_hash, token_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_smart_contract_name_trgm;
+DROP INDEX IF EXISTS idx_smart_contract_name_prefix;
+
+DROP INDEX IF EXISTS idx_token_name_trgm;
+DROP INDEX IF EXISTS idx_token_symbol_trgm;
+DROP INDEX IF EXISTS idx_token_name_prefix;
+DROP INDEX IF EXISTS idx_token_symbol_prefix;
+
+-- pg_trgm is left installed, other objects may depend on it
@@ -0,0 +1,10 @@
+-- search matches token and contract names case insensitively, by prefix or anywhere through trigrams
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_token_symbol_prefix ON token (lower(symbol) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_token_name_prefix ON token (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_token_symbol_trgm ON token USING GIN (lower(symbol) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_token_name_trgm ON token USING GIN (lower(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_smart_contract_name_prefix ON smart_contract (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_smart_contract_name_trgm ON smart_contract USING GIN (lower(name) gin_trgm_ops);
@@ -0,0 +1,2 @@
+-- blocks are looked up by the closest timestamp, the BRIN index only serves ranges
+CREATE INDEX IF NOT EXISTS idx_block_timestamp ON block (timestamp);
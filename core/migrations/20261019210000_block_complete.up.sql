@@ -0,0 +1,12 @@
+-- complete is set once every transaction, withdrawal and the reward of the block are stored,
+-- the repositories refresh it in the transaction writing the block or its children
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "complete" BOOL NOT NULL DEFAULT false;
+
+UPDATE "block" AS b SET "complete" = (
+    (SELECT count(*) FROM "transaction" t WHERE t.block_hash = b.hash) = b.transactions_count
+    AND (SELECT count(*) FROM withdrawal w WHERE w.block_hash = b.hash) = b.withdrawals_count
+    AND EXISTS (SELECT 1 FROM reward rw WHERE rw.block_hash = b.hash)
+);
+
+-- gap detection only walks the blocks still waiting for children
+CREATE INDEX IF NOT EXISTS idx_block_incomplete ON block (number) WHERE NOT complete;
@@ -0,0 +1,7 @@
+-- kv_store_applied records the members already decremented from a counter, redelivered messages
+-- and the retries of a failed batch decrement it only once
+CREATE TABLE IF NOT EXISTS "kv_store_applied" (
+    "key" VARCHAR NOT NULL,
+    "member" VARCHAR NOT NULL,
+    PRIMARY KEY ("key", "member")
+);
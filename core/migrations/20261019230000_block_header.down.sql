@@ -0,0 +1,2 @@
+ALTER TABLE "block" DROP COLUMN IF EXISTS "mix_hash";
+ALTER TABLE "block" DROP COLUMN IF EXISTS "state_root";
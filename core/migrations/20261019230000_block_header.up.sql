@@ -0,0 +1,3 @@
+-- the rest of the header nodes return, NULL for blocks indexed before this migration
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "state_root" BYTEA;
+ALTER TABLE "block" ADD COLUMN IF NOT EXISTS "mix_hash" BYTEA;
@@ -0,0 +1,8 @@
+// Package migrations embeds the schema migrations into the core binary, the Postgres migrations
+// are at the root and the SQLite ones under sqlite/
+package migrations
+
+import "embed"
+
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
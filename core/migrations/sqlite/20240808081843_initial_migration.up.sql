@@ -0,0 +1,2 @@
+-- SQLite has no trigger functions, every table in the baseline gets its own updated_at trigger
+SELECT 1;
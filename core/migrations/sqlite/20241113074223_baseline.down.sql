@@ -0,0 +1,38 @@
+DROP TRIGGER IF EXISTS update_audit_report_modtime;
+DROP TABLE IF EXISTS "audit_report";
+
+DROP TRIGGER IF EXISTS update_token_instance_modtime;
+DROP TABLE IF EXISTS "token_instance";
+
+DROP TRIGGER IF EXISTS update_token_transfer_modtime;
+DROP TABLE IF EXISTS "token_transfer";
+
+DROP TRIGGER IF EXISTS update_token_modtime;
+DROP TABLE IF EXISTS "token";
+
+DROP TRIGGER IF EXISTS update_transaction_action_modtime;
+DROP TABLE IF EXISTS "transaction_action";
+
+DROP TRIGGER IF EXISTS update_transaction_log_topic_modtime;
+DROP TABLE IF EXISTS "transaction_log_topic";
+
+DROP TRIGGER IF EXISTS update_transaction_log_modtime;
+DROP TABLE IF EXISTS "transaction_log";
+
+DROP TRIGGER IF EXISTS update_reward_modtime;
+DROP TABLE IF EXISTS "reward";
+
+DROP TRIGGER IF EXISTS update_transaction_modtime;
+DROP TABLE IF EXISTS "transaction";
+
+DROP TRIGGER IF EXISTS update_withdrawal_modtime;
+DROP TABLE IF EXISTS "withdrawal";
+
+DROP TRIGGER IF EXISTS update_internal_transaction_modtime;
+DROP TABLE IF EXISTS "internal_transaction";
+
+DROP TRIGGER IF EXISTS update_smart_contract_modtime;
+DROP TABLE IF EXISTS "smart_contract";
+
+DROP TRIGGER IF EXISTS update_block_modtime;
+DROP TABLE IF EXISTS "block";
@@ -0,0 +1,327 @@
+-- uint256 values are stored as decimal TEXT, block numbers fit in INTEGER and stay comparable
+
+-- block
+CREATE TABLE IF NOT EXISTS "block" (
+    "hash" BLOB PRIMARY KEY,
+    "number" INTEGER NOT NULL,
+    "miner_hash" BLOB NOT NULL,
+    "parent_hash" BLOB NOT NULL,
+    "gas_limit" INTEGER NOT NULL,
+    "gas_used" INTEGER NOT NULL,
+    "nonce" INTEGER NOT NULL,
+    "size" INTEGER NOT NULL,
+    "difficulty" TEXT NOT NULL,
+    "is_pos" BOOLEAN NOT NULL,
+    "base_fee_per_gas" TEXT NOT NULL,
+    "timestamp" INTEGER NOT NULL,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_block_timestamp ON block (timestamp);
+
+CREATE TRIGGER IF NOT EXISTS update_block_modtime
+AFTER UPDATE ON "block"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "block" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- smart_contract
+CREATE TABLE IF NOT EXISTS "smart_contract" (
+    "address_hash" BLOB PRIMARY KEY,
+    "name" TEXT NOT NULL,
+    "compiler_version" TEXT NOT NULL,
+    "source_code" TEXT NOT NULL,
+    "abi" TEXT NOT NULL,
+    "compiler_settings" TEXT,
+    "verified_by_eth" BOOLEAN DEFAULT FALSE,
+    "evm_version" TEXT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_smart_contract_name ON smart_contract (name);
+CREATE INDEX IF NOT EXISTS idx_smart_contract_verified ON smart_contract (verified_by_eth);
+
+CREATE TRIGGER IF NOT EXISTS update_smart_contract_modtime
+AFTER UPDATE ON "smart_contract"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "smart_contract" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- internal_transaction
+CREATE TABLE IF NOT EXISTS "internal_transaction" (
+    "block_hash" BLOB NOT NULL,
+    "index" INTEGER NOT NULL,
+    "transaction_hash" BLOB NOT NULL,
+    "status" INTEGER NOT NULL,
+    "gas" TEXT NOT NULL,
+    "gas_used" TEXT NOT NULL,
+    "input" BLOB,
+    "output" BLOB,
+    "amount" TEXT NOT NULL,
+    "from_address" BLOB NOT NULL,
+    "to_address" BLOB NOT NULL,
+    "create_contract_address_hash" BLOB NOT NULL,
+    "timestamp" INTEGER NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("block_hash", "index"),
+    FOREIGN KEY ("block_hash") REFERENCES "block"("hash") ON DELETE CASCADE,
+    FOREIGN KEY ("create_contract_address_hash") REFERENCES "smart_contract"("address_hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_internal_tx_block_hash ON internal_transaction (block_hash);
+CREATE INDEX IF NOT EXISTS idx_internal_tx_timestamp ON internal_transaction (timestamp);
+CREATE INDEX IF NOT EXISTS idx_internal_tx_from_to ON internal_transaction (from_address, to_address);
+
+CREATE TRIGGER IF NOT EXISTS update_internal_transaction_modtime
+AFTER UPDATE ON "internal_transaction"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "internal_transaction" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- withdrawal
+CREATE TABLE IF NOT EXISTS "withdrawal" (
+    "index" INTEGER,
+    "block_hash" BLOB,
+    "address_hash" BLOB NOT NULL,
+    "validator_index" INTEGER NOT NULL,
+    "amount" TEXT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("index", "block_hash"),
+    FOREIGN KEY ("block_hash") REFERENCES "block"("hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawal_block_hash ON withdrawal (block_hash);
+
+CREATE TRIGGER IF NOT EXISTS update_withdrawal_modtime
+AFTER UPDATE ON "withdrawal"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "withdrawal" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- transaction
+CREATE TABLE IF NOT EXISTS "transaction" (
+    "hash" BLOB,
+    "block_hash" BLOB NOT NULL,
+    "index" INTEGER NOT NULL,
+    "status" INTEGER NOT NULL,
+    "gas" TEXT NOT NULL,
+    "gas_used" TEXT NOT NULL,
+    "input" BLOB,
+    "value" TEXT NOT NULL,
+    "from_address" BLOB NOT NULL,
+    "to_address" BLOB NOT NULL,
+    "nonce" INTEGER NOT NULL,
+    "timestamp" INTEGER NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("hash"),
+    FOREIGN KEY ("block_hash") REFERENCES "block"("hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_block_hash ON "transaction" (block_hash);
+CREATE INDEX IF NOT EXISTS idx_transaction_timestamp ON "transaction" (timestamp);
+CREATE INDEX IF NOT EXISTS idx_transaction_from_to ON "transaction" (from_address, to_address);
+
+CREATE TRIGGER IF NOT EXISTS update_transaction_modtime
+AFTER UPDATE ON "transaction"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "transaction" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- reward
+CREATE TABLE IF NOT EXISTS "reward" (
+    "block_hash" BLOB NOT NULL,
+    "address" BLOB NOT NULL,
+    "amount" TEXT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("block_hash", "address"),
+    FOREIGN KEY ("block_hash") REFERENCES "block"("hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_reward_block_hash ON reward (block_hash);
+
+CREATE TRIGGER IF NOT EXISTS update_reward_modtime
+AFTER UPDATE ON "reward"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "reward" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- transaction_log
+CREATE TABLE IF NOT EXISTS "transaction_log" (
+    "address" BLOB NOT NULL,
+    "transaction_hash" BLOB NOT NULL,
+    "block_hash" BLOB NOT NULL,
+    "transaction_index" INTEGER NOT NULL,
+    "log_index" INTEGER NOT NULL,
+    "data" BLOB,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("transaction_hash", "log_index"),
+    FOREIGN KEY ("transaction_hash") REFERENCES "transaction"("hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tx_log_block_hash ON transaction_log (block_hash);
+CREATE INDEX IF NOT EXISTS idx_tx_log_address ON transaction_log (address);
+
+CREATE TRIGGER IF NOT EXISTS update_transaction_log_modtime
+AFTER UPDATE ON "transaction_log"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "transaction_log" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- transaction_log_topic
+CREATE TABLE IF NOT EXISTS "transaction_log_topic" (
+    "transaction_hash" BLOB NOT NULL,
+    "log_index" INTEGER NOT NULL,
+    "topic_index" INTEGER NOT NULL,
+    "topic" BLOB NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("transaction_hash", "log_index", "topic_index"),
+    FOREIGN KEY ("transaction_hash", "log_index") REFERENCES "transaction_log"("transaction_hash", "log_index") ON DELETE CASCADE
+);
+
+CREATE TRIGGER IF NOT EXISTS update_transaction_log_topic_modtime
+AFTER UPDATE ON "transaction_log_topic"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "transaction_log_topic" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- transaction_action
+CREATE TABLE IF NOT EXISTS "transaction_action" (
+    "transaction_hash" BLOB,
+    "log_index" INTEGER,
+    "data" TEXT,
+    "address_contract_hash" BLOB NOT NULL,
+    "type" INTEGER NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("transaction_hash", "log_index"),
+    FOREIGN KEY ("transaction_hash", "log_index") REFERENCES "transaction_log"("transaction_hash", "log_index") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tx_action_address_hash ON transaction_action (address_contract_hash);
+
+CREATE TRIGGER IF NOT EXISTS update_transaction_action_modtime
+AFTER UPDATE ON "transaction_action"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "transaction_action" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- token
+CREATE TABLE IF NOT EXISTS "token" (
+    "address_hash" BLOB PRIMARY KEY,
+    "symbol" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "total_supply" TEXT,
+    "decimals" INTEGER NOT NULL,
+    "fiat_value" TEXT,
+    "circulation_market_cap" TEXT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_symbol_name ON token (symbol, name);
+
+CREATE TRIGGER IF NOT EXISTS update_token_modtime
+AFTER UPDATE ON "token"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "token" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- token_transfer
+CREATE TABLE IF NOT EXISTS "token_transfer" (
+    "transaction_hash" BLOB,
+    "log_index" INTEGER,
+    "from_address" BLOB NOT NULL,
+    "to_address" BLOB NOT NULL,
+    "token_contract_address_hash" BLOB NOT NULL,
+    "amount" TEXT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("transaction_hash", "log_index"),
+    FOREIGN KEY ("transaction_hash") REFERENCES "transaction"("hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_transfer_from_to ON token_transfer (from_address, to_address);
+CREATE INDEX IF NOT EXISTS idx_token_transfer_token ON token_transfer (token_contract_address_hash);
+CREATE INDEX IF NOT EXISTS idx_token_transfer_created_at ON token_transfer (created_at);
+
+CREATE TRIGGER IF NOT EXISTS update_token_transfer_modtime
+AFTER UPDATE ON "token_transfer"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "token_transfer" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- token_instance
+CREATE TABLE IF NOT EXISTS "token_instance" (
+    "token_contract_address_hash" BLOB,
+    "token_id" TEXT,
+    "owner_address_hash" BLOB NOT NULL,
+    "metadata" TEXT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("token_id", "token_contract_address_hash"),
+    FOREIGN KEY ("token_contract_address_hash") REFERENCES "token"("address_hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_token_instance_owner ON token_instance (owner_address_hash);
+
+CREATE TRIGGER IF NOT EXISTS update_token_instance_modtime
+AFTER UPDATE ON "token_instance"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "token_instance" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+
+
+-- audit_report
+CREATE TABLE IF NOT EXISTS "audit_report" (
+    "id" INTEGER PRIMARY KEY,
+    "address_hash" BLOB NOT NULL,
+    "is_approved" BOOLEAN DEFAULT FALSE,
+    "submitter_name" TEXT NOT NULL,
+    "submitter_email" TEXT NOT NULL,
+    "audit_company_name" TEXT NOT NULL,
+    "audit_report_url" TEXT NOT NULL,
+    "project_url" TEXT NOT NULL,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY ("address_hash") REFERENCES "smart_contract"("address_hash") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_report_address_hash ON audit_report (address_hash);
+
+CREATE TRIGGER IF NOT EXISTS update_audit_report_modtime
+AFTER UPDATE ON "audit_report"
+FOR EACH ROW WHEN NEW."updated_at" IS OLD."updated_at"
+BEGIN
+    UPDATE "audit_report" SET "updated_at" = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
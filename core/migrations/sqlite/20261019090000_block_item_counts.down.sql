@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_block_number;
+
+ALTER TABLE "block" DROP COLUMN "withdrawals_count";
+ALTER TABLE "block" DROP COLUMN "transactions_count";
@@ -0,0 +1,9 @@
+ALTER TABLE "block" ADD COLUMN "transactions_count" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "block" ADD COLUMN "withdrawals_count" INTEGER NOT NULL DEFAULT 0;
+
+-- backfill from the rows already indexed, blocks ingested before this migration are treated as complete
+UPDATE "block" SET
+    "transactions_count" = (SELECT count(*) FROM "transaction" t WHERE t.block_hash = "block".hash),
+    "withdrawals_count" = (SELECT count(*) FROM "withdrawal" w WHERE w.block_hash = "block".hash);
+
+CREATE INDEX IF NOT EXISTS idx_block_number ON block (number);
@@ -0,0 +1,12 @@
+-- admin_audit_log
+CREATE TABLE IF NOT EXISTS "admin_audit_log" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "action" TEXT NOT NULL,
+    "actor" TEXT NOT NULL,
+    "params" TEXT,
+    "success" BOOLEAN NOT NULL,
+    "error" TEXT,
+    "created_at" TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created_at ON admin_audit_log (created_at);
@@ -0,0 +1,8 @@
+ALTER TABLE "transaction" DROP COLUMN "post_state";
+ALTER TABLE "transaction" DROP COLUMN "cumulative_gas_used";
+ALTER TABLE "transaction" DROP COLUMN "raw";
+ALTER TABLE "transaction" DROP COLUMN "type";
+
+ALTER TABLE "block" DROP COLUMN "logs_bloom";
+ALTER TABLE "block" DROP COLUMN "receipts_root";
+ALTER TABLE "block" DROP COLUMN "transactions_root";
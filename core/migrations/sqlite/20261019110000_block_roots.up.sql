@@ -0,0 +1,10 @@
+-- header commitments published by the producer, NULL for blocks indexed before this migration
+ALTER TABLE "block" ADD COLUMN "transactions_root" BLOB;
+ALTER TABLE "block" ADD COLUMN "receipts_root" BLOB;
+ALTER TABLE "block" ADD COLUMN "logs_bloom" BLOB;
+
+-- consensus encoding and receipt fields needed to recompute the roots
+ALTER TABLE "transaction" ADD COLUMN "type" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "transaction" ADD COLUMN "raw" BLOB;
+ALTER TABLE "transaction" ADD COLUMN "cumulative_gas_used" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "transaction" ADD COLUMN "post_state" BLOB;
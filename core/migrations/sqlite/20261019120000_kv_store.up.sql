@@ -0,0 +1,2 @@
+-- the table backed coordination store needs Postgres, this keeps the versions aligned
+SELECT 1;
@@ -0,0 +1,3 @@
+-- the explorer's transaction page reads internal transactions by transaction and its listing filters by recipient
+CREATE INDEX IF NOT EXISTS idx_internal_tx_transaction_hash ON internal_transaction (transaction_hash);
+CREATE INDEX IF NOT EXISTS idx_transaction_to_address ON "transaction" (to_address);
@@ -0,0 +1,11 @@
+-- address history reads each side of a transfer newest first, the (from_address, to_address) indexes only serve the sender side
+CREATE INDEX IF NOT EXISTS idx_transaction_from_address_timestamp ON "transaction" (from_address, timestamp, "index");
+CREATE INDEX IF NOT EXISTS idx_transaction_to_address_timestamp ON "transaction" (to_address, timestamp, "index");
+CREATE INDEX IF NOT EXISTS idx_internal_tx_from_address_timestamp ON internal_transaction (from_address, timestamp, "index");
+CREATE INDEX IF NOT EXISTS idx_internal_tx_to_address_timestamp ON internal_transaction (to_address, timestamp, "index");
+CREATE INDEX IF NOT EXISTS idx_token_transfer_to_address ON token_transfer (to_address);
+CREATE INDEX IF NOT EXISTS idx_withdrawal_address_index ON withdrawal (address_hash, "index");
+CREATE INDEX IF NOT EXISTS idx_reward_address ON reward (address);
+
+-- superseded by idx_transaction_to_address_timestamp
+DROP INDEX IF EXISTS idx_transaction_to_address;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_token_instance_token_owner;
+DROP INDEX IF EXISTS idx_token_type;
+
+ALTER TABLE "token" DROP COLUMN "type";
@@ -0,0 +1,9 @@
+-- token standard, tokens indexed before this migration are ERC-721 when they have instances
+ALTER TABLE "token" ADD COLUMN "type" TEXT NOT NULL DEFAULT 'ERC-20';
+
+UPDATE "token" SET "type" = 'ERC-721'
+WHERE EXISTS (SELECT 1 FROM token_instance ti WHERE ti.token_contract_address_hash = "token".address_hash);
+
+CREATE INDEX IF NOT EXISTS idx_token_type ON token (type);
+-- token holders of instance based tokens are read by contract
+CREATE INDEX IF NOT EXISTS idx_token_instance_token_owner ON token_instance (token_contract_address_hash, owner_address_hash);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_token_transfer_token_id;
+
+ALTER TABLE token_transfer DROP COLUMN token_id;
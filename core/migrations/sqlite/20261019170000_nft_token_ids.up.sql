@@ -0,0 +1,6 @@
+-- token_instance.token_id is already TEXT here
+
+-- token id of ERC-721 and ERC-1155 transfers, NULL for ERC-20 and for transfers indexed before this migration
+ALTER TABLE token_transfer ADD COLUMN token_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_token_transfer_token_id ON token_transfer (token_contract_address_hash, token_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_smart_contract_name_lower;
+
+DROP INDEX IF EXISTS idx_token_name_lower;
+DROP INDEX IF EXISTS idx_token_symbol_lower;
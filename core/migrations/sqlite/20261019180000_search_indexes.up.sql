@@ -0,0 +1,5 @@
+-- SQLite has no trigram or pattern indexes, these serve exact name and symbol matches and the other matches scan
+CREATE INDEX IF NOT EXISTS idx_token_symbol_lower ON token (lower(symbol));
+CREATE INDEX IF NOT EXISTS idx_token_name_lower ON token (lower(name));
+
+CREATE INDEX IF NOT EXISTS idx_smart_contract_name_lower ON smart_contract (lower(name));
@@ -0,0 +1 @@
+-- idx_block_timestamp belongs to the SQLite baseline
@@ -0,0 +1 @@
+-- the SQLite baseline already has idx_block_timestamp, PostgreSQL only had a BRIN index
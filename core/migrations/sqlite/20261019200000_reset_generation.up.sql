@@ -0,0 +1,8 @@
+-- reset_generation counts the state resets, the producer stamps it on every message so core
+-- can drop the messages published before the last reset
+CREATE TABLE IF NOT EXISTS "reset_generation" (
+    "id" INTEGER PRIMARY KEY CHECK ("id" = 1),
+    "generation" INTEGER NOT NULL
+);
+
+INSERT INTO reset_generation (id, generation) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;
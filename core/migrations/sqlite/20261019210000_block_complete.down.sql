@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_block_incomplete;
+
+ALTER TABLE "block" DROP COLUMN "complete";
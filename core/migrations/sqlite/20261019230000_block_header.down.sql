@@ -0,0 +1,2 @@
+ALTER TABLE "block" DROP COLUMN "mix_hash";
+ALTER TABLE "block" DROP COLUMN "state_root";
@@ -0,0 +1,3 @@
+-- the rest of the header nodes return, NULL for blocks indexed before this migration
+ALTER TABLE "block" ADD COLUMN "state_root" BLOB;
+ALTER TABLE "block" ADD COLUMN "mix_hash" BLOB;
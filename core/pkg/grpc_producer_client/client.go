@@ -0,0 +1,43 @@
+package grpcproducerclient
+
+import (
+	"context"
+	"math/big"
+
+	pb "github.com/elmiringos/indexer/indexer-core/internal/api/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type ProducerClient struct {
+	conn       *grpc.ClientConn
+	grpcClient pb.ProducerControlServiceClient
+}
+
+func NewProducerClient(address string) (*ProducerClient, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewProducerControlServiceClient(conn)
+	return &ProducerClient{conn: conn, grpcClient: client}, nil
+}
+
+func (c *ProducerClient) Close() error {
+	return c.conn.Close()
+}
+
+// ReindexRange asks the producer to fetch and publish the inclusive block range again
+func (c *ProducerClient) ReindexRange(ctx context.Context, from, to *big.Int, reason string) (*pb.ReindexRangeResponse, error) {
+	response, err := c.grpcClient.ReindexRange(ctx, &pb.ReindexRangeRequest{
+		FromNumber: from.Bytes(),
+		ToNumber:   to.Bytes(),
+		Reason:     reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,184 @@
+// Package health implements liveness and readiness reporting over HTTP and the standard gRPC health service
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// CheckFunc reports whether a dependency is usable, returning nil when it is
+type CheckFunc func(ctx context.Context) error
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// CheckResult is the outcome of a single dependency check
+type CheckResult struct {
+	Status   Status `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the aggregated outcome of all registered checks
+type Report struct {
+	Status    Status                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker runs the registered dependency checks
+type Checker struct {
+	mu      sync.RWMutex
+	checks  map[string]CheckFunc
+	timeout time.Duration
+}
+
+// NewChecker creates a Checker that bounds every check by timeout
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		checks:  make(map[string]CheckFunc),
+		timeout: timeout,
+	}
+}
+
+// Register adds a named dependency check, replacing any check with the same name
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+}
+
+// Check runs all registered checks concurrently and aggregates the results
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	c.mu.RUnlock()
+	sort.Strings(names)
+
+	results := make([]CheckResult, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		c.mu.RLock()
+		check := c.checks[name]
+		c.mu.RUnlock()
+
+		wg.Add(1)
+		go func(i int, check CheckFunc) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusUp,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make(map[string]CheckResult, len(names)),
+	}
+
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check CheckFunc) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+
+	result := CheckResult{
+		Status:   StatusUp,
+		Duration: time.Since(start).String(),
+	}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// LivenessHandler reports that the process is running without touching dependencies
+func (c *Checker) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, Report{Status: StatusUp, CheckedAt: time.Now().UTC()})
+	}
+}
+
+// ReadinessHandler runs all checks and answers 503 when any dependency is down
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, c.Check(r.Context()))
+	}
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if report.Status == StatusUp {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// NewGRPCServer creates the standard gRPC health server, initially not serving
+func NewGRPCServer() *health.Server {
+	server := health.NewServer()
+	server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return server
+}
+
+// WatchGRPC periodically runs the checks and mirrors the result into the gRPC health server
+// for the overall ("") service and every given service name, until ctx is done
+func (c *Checker) WatchGRPC(ctx context.Context, server *health.Server, interval time.Duration, services ...string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	services = append([]string{""}, services...)
+
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if c.Check(ctx).Status != StatusUp {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+
+		for _, service := range services {
+			server.SetServingStatus(service, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
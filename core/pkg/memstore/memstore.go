@@ -0,0 +1,100 @@
+// Package memstore keeps the coordination counters in process memory, it replaces Redis for a
+// single core instance such as the one inside the all-in-one binary
+package memstore
+
+import (
+	"context"
+	"sync"
+)
+
+type Store struct {
+	mu     sync.Mutex
+	values map[string]int
+	// applied holds the members already decremented from each counter
+	applied map[string]map[string]struct{}
+}
+
+func New() *Store {
+	return &Store{values: make(map[string]int), applied: make(map[string]map[string]struct{})}
+}
+
+// GetInt returns 0 for a missing key like the Redis client does
+func (s *Store) GetInt(_ context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key], nil
+}
+
+// SetInt keeps an existing counter like SETNX does
+func (s *Store) SetInt(_ context.Context, key string, value int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; !ok {
+		s.values[key] = value
+	}
+	return nil
+}
+
+func (s *Store) OverwriteInt(_ context.Context, key string, value int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	delete(s.applied, key)
+	return nil
+}
+
+func (s *Store) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key)
+	delete(s.applied, key)
+	return nil
+}
+
+// DecrementAndMaybeDelete decrements the counter once per member and removes it once it reaches zero, in one step
+func (s *Store) DecrementAndMaybeDelete(_ context.Context, key, member string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		return nil
+	}
+
+	applied := s.applied[key]
+	if _, done := applied[member]; done {
+		return nil
+	}
+
+	value--
+	if value <= 0 {
+		delete(s.values, key)
+		delete(s.applied, key)
+		return nil
+	}
+
+	if applied == nil {
+		applied = make(map[string]struct{})
+		s.applied[key] = applied
+	}
+	applied[member] = struct{}{}
+	s.values[key] = value
+	return nil
+}
+
+func (s *Store) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = make(map[string]int)
+	s.applied = make(map[string]map[string]struct{})
+	return nil
+}
+
+func (s *Store) Ping(context.Context) error {
+	return nil
+}
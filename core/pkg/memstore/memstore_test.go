@@ -0,0 +1,122 @@
+package memstore
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SetInt(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	require.NoError(t, store.SetInt(ctx, "counter", 3))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+
+	// a redelivered message must not restart a counter already decremented
+	require.NoError(t, store.SetInt(ctx, "counter", 3))
+	value, err := store.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+
+	require.NoError(t, store.OverwriteInt(ctx, "counter", 3))
+	value, err = store.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestStore_DecrementAndMaybeDelete(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	require.NoError(t, store.SetInt(ctx, "counter", 2))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "c"))
+
+	assert.NotContains(t, store.values, "counter")
+
+	// a decrement of a missing counter leaves nothing behind
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.NotContains(t, store.values, "counter")
+}
+
+// TestStore_DecrementAndMaybeDelete_Concurrent decrements counters from several workers at once, every
+// member is applied once and the decrement reaching zero deletes the counter. Run it with -race.
+func TestStore_DecrementAndMaybeDelete_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+	const workers = 64
+
+	require.NoError(t, store.SetInt(ctx, "partial", workers+5))
+	require.NoError(t, store.SetInt(ctx, "complete", workers))
+	require.NoError(t, store.SetInt(ctx, "duplicated", 2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			member := strconv.Itoa(i)
+			assert.NoError(t, store.DecrementAndMaybeDelete(ctx, "partial", member))
+			assert.NoError(t, store.DecrementAndMaybeDelete(ctx, "complete", member))
+			assert.NoError(t, store.DecrementAndMaybeDelete(ctx, "duplicated", "same"))
+		}()
+	}
+	wg.Wait()
+
+	value, err := store.GetInt(ctx, "duplicated")
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	value, err = store.GetInt(ctx, "partial")
+	require.NoError(t, err)
+	assert.Equal(t, 5, value)
+	assert.NotContains(t, store.values, "complete")
+}
+
+// TestStore_DecrementOncePerMember replays decrements the way a redelivered message or the per-message
+// retry of a failed batch does, each member is counted once until the counter restarts
+func TestStore_DecrementOncePerMember(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	value := func() int {
+		t.Helper()
+		v, err := store.GetInt(ctx, "counter")
+		require.NoError(t, err)
+		return v
+	}
+
+	require.NoError(t, store.SetInt(ctx, "counter", 3))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 2, value())
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Equal(t, 1, value())
+
+	// a reindex restarts the counter and every child counts again
+	require.NoError(t, store.OverwriteInt(ctx, "counter", 2))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 1, value())
+
+	require.NoError(t, store.Delete(ctx, "counter"))
+	require.NoError(t, store.SetInt(ctx, "counter", 2))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 1, value())
+
+	// the members go with the counter once it reaches zero
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Zero(t, value())
+	require.NoError(t, store.SetInt(ctx, "counter", 1))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Zero(t, value())
+
+	// a decrement of a missing counter does not create it
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "c"))
+	require.NoError(t, store.SetInt(ctx, "counter", 1))
+	assert.Equal(t, 1, value())
+}
@@ -0,0 +1,123 @@
+// Package metrics defines the Prometheus collectors exported by the core service
+package metrics
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "core"
+
+var (
+	// IndexedHeight is the highest block number saved by the block processor
+	IndexedHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "indexed_block",
+		Help:      "Highest block number saved to the database.",
+	})
+
+	// OpenGaps is the number of gaps below the settle horizon found by the last gap detector scan
+	OpenGaps = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "open_gaps",
+		Help:      "Missing or incomplete block ranges found by the last gap detector scan.",
+	})
+
+	// ReindexRequests counts reindex requests sent to the producer, by result
+	ReindexRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reindex_requests_total",
+		Help:      "Number of reindex requests sent to the producer, by result.",
+	}, []string{"result"})
+
+	// VerifiedBlocks counts blocks checked by the verifier, by result
+	VerifiedBlocks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verified_blocks_total",
+		Help:      "Number of blocks checked by the verifier, by result.",
+	}, []string{"result"})
+
+	// VerificationMismatches counts mismatches found by the verifier, by kind
+	VerificationMismatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "verification_mismatches_total",
+		Help:      "Number of mismatches between stored data and block headers found by the verifier, by kind.",
+	}, []string{"kind"})
+
+	// ProcessingDuration tracks how long a message takes to process, by queue and result
+	ProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "message_processing_duration_seconds",
+		Help:      "Time spent processing a single message, by queue and result.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"queue", "result"})
+
+	// MessagesAcked counts acknowledged messages by queue
+	MessagesAcked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_acked_total",
+		Help:      "Number of messages acknowledged after successful processing, by queue.",
+	}, []string{"queue"})
+
+	// MessagesNacked counts messages returned to the broker by queue
+	MessagesNacked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_nacked_total",
+		Help:      "Number of messages negatively acknowledged and requeued, by queue.",
+	}, []string{"queue"})
+
+	// WorkersTotal is the size of each worker pool
+	WorkersTotal = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workers_total",
+		Help:      "Number of workers started for a queue.",
+	}, []string{"queue"})
+
+	// BatchSize tracks how many messages a worker writes together, by queue
+	BatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "batch_size",
+		Help:      "Number of messages written in one batch, by queue.",
+		Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+	}, []string{"queue"})
+
+	// BatchFallbacks counts failed batches whose messages were processed one by one
+	BatchFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "batch_fallbacks_total",
+		Help:      "Number of failed batches retried message by message, by queue.",
+	}, []string{"queue"})
+
+	// WorkersBusy is the number of workers currently processing a message
+	WorkersBusy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workers_busy",
+		Help:      "Number of workers currently processing a message, by queue.",
+	}, []string{"queue"})
+)
+
+// indexed mirrors IndexedHeight so concurrent workers only ever raise it
+var indexed atomic.Uint64
+
+// ObserveIndexedBlock raises the indexed height if number is above it
+func ObserveIndexedBlock(number uint64) {
+	for {
+		current := indexed.Load()
+		if number <= current {
+			return
+		}
+		if indexed.CompareAndSwap(current, number) {
+			IndexedHeight.Set(float64(number))
+			return
+		}
+	}
+}
+
+// Handler returns the HTTP handler serving the metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,165 @@
+// Package migrator applies the embedded migrations. It keeps the version in schema_migrations
+// like the migrate CLI, so databases migrated by hand are picked up where they are.
+package migrator
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+	"github.com/elmiringos/indexer/indexer-core/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrFailedToOpenMigrations = errors.New("failed to open migrations")
+	ErrFailedToMigrate        = errors.New("failed to migrate")
+)
+
+// Status describes the schema version of the database against the embedded migrations
+type Status struct {
+	// Version is the last applied migration, 0 when none is
+	Version uint
+	// Dirty is set when a migration failed halfway, the schema has to be fixed and the version forced
+	Dirty bool
+	// Latest is the newest embedded migration
+	Latest uint
+}
+
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+	db     *sql.DB
+}
+
+// New opens its own connection to the configured database, Close releases it
+func New(cfg *config.Config, log *zap.Logger) (*Migrator, error) {
+	var (
+		dir      = "."
+		db       *sql.DB
+		driver   database.Driver
+		dbDriver string
+		err      error
+	)
+
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		dir = "sqlite"
+		dbDriver = config.DriverSQLite
+		if db, err = sql.Open("sqlite", cfg.Database.SQLitePath); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToOpenMigrations, err)
+		}
+		driver, err = migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	default:
+		dbDriver = config.DriverPostgres
+		if db, err = sql.Open("postgres", cfg.PG.URL); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToOpenMigrations, err)
+		}
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToOpenMigrations, err)
+	}
+
+	files, err := fs.Sub(migrations.FS, dir)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToOpenMigrations, err)
+	}
+	src, err := iofs.New(files, ".")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToOpenMigrations, err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, dbDriver, driver)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToOpenMigrations, err)
+	}
+	m.Log = &logAdapter{log: log}
+
+	return &Migrator{m: m, source: src, db: db}, nil
+}
+
+// Up applies the given number of migrations, every pending one when steps is 0
+func (m *Migrator) Up(steps int) error {
+	var err error
+	if steps == 0 {
+		err = m.m.Up()
+	} else {
+		err = m.m.Steps(steps)
+	}
+
+	return wrap(err)
+}
+
+// Down reverts the given number of migrations
+func (m *Migrator) Down(steps int) error {
+	return wrap(m.m.Steps(-steps))
+}
+
+// Force sets the version without running migrations and clears the dirty flag
+func (m *Migrator) Force(version int) error {
+	return wrap(m.m.Force(version))
+}
+
+func (m *Migrator) Status() (*Status, error) {
+	status := &Status{}
+
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMigrate, err)
+	}
+	status.Version, status.Dirty = version, dirty
+
+	latest, err := m.source.First()
+	for err == nil {
+		status.Latest = latest
+		latest, err = m.source.Next(latest)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMigrate, err)
+	}
+
+	return status, nil
+}
+
+// Close releases the connection, the Postgres driver only returns it to the pool
+func (m *Migrator) Close() error {
+	sourceErr, driverErr := m.m.Close()
+	return errors.Join(sourceErr, driverErr, m.db.Close())
+}
+
+// wrap treats an up to date schema as success
+func wrap(err error) error {
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %w", ErrFailedToMigrate, err)
+}
+
+type logAdapter struct {
+	log *zap.Logger
+}
+
+func (l *logAdapter) Printf(format string, v ...interface{}) {
+	l.log.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (l *logAdapter) Verbose() bool {
+	return false
+}
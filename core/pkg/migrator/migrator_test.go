@@ -0,0 +1,140 @@
+package migrator
+
+import (
+	"database/sql"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+	"github.com/elmiringos/indexer/indexer-core/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// versions lists the embedded migration versions of dir in order
+func versions(t *testing.T, dir string) []uint {
+	t.Helper()
+
+	names, err := fs.Glob(migrations.FS, path.Join(dir, "*.up.sql"))
+	require.NoError(t, err)
+
+	var result []uint
+	for _, name := range names {
+		version, err := strconv.ParseUint(strings.SplitN(path.Base(name), "_", 2)[0], 10, 64)
+		require.NoError(t, err)
+		result = append(result, uint(version))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+func newSQLiteMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.SQLitePath = filepath.Join(t.TempDir(), "indexer.db")
+
+	m, err := New(cfg, zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { m.Close() })
+
+	db, err := sql.Open("sqlite", cfg.Database.SQLitePath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return m, db
+}
+
+func status(t *testing.T, m *Migrator) Status {
+	t.Helper()
+
+	s, err := m.Status()
+	require.NoError(t, err)
+	return *s
+}
+
+func hasColumn(t *testing.T, db *sql.DB, table, column string) bool {
+	t.Helper()
+
+	var count int
+	require.NoError(t, db.QueryRow(`select count(*) from pragma_table_info($1) where name = $2`, table, column).Scan(&count))
+	return count > 0
+}
+
+// TestMigrations_SameVersions keeps the Postgres and SQLite migrations in step, the explorer checks
+// one schema version for both
+func TestMigrations_SameVersions(t *testing.T) {
+	assert.Equal(t, versions(t, "."), versions(t, "sqlite"))
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	m, db := newSQLiteMigrator(t)
+	all := versions(t, "sqlite")
+	latest := all[len(all)-1]
+
+	assert.Equal(t, Status{Latest: latest}, status(t, m))
+
+	require.NoError(t, m.Up(2))
+	assert.Equal(t, Status{Version: all[1], Latest: latest}, status(t, m))
+
+	require.NoError(t, m.Up(0))
+	assert.Equal(t, Status{Version: latest, Latest: latest}, status(t, m))
+	assert.True(t, hasColumn(t, db, "block", "complete"))
+
+	// an up to date schema is not an error, core migrates on every start with auto-migrate
+	require.NoError(t, m.Up(0))
+
+	require.NoError(t, m.Down(1))
+	assert.Equal(t, Status{Version: all[len(all)-2], Latest: latest}, status(t, m))
+
+	// every down migration reverts its up migration on SQLite
+	require.NoError(t, m.Down(len(all)-1))
+	assert.Equal(t, Status{Latest: latest}, status(t, m))
+	var tables int
+	require.NoError(t, db.QueryRow(`select count(*) from sqlite_master where type = 'table' and name = 'block'`).Scan(&tables))
+	assert.Zero(t, tables)
+
+	require.NoError(t, m.Up(0))
+	assert.Equal(t, Status{Version: latest, Latest: latest}, status(t, m))
+}
+
+// blockComplete is the migration adding block.complete
+const blockComplete = 20261019210000
+
+func TestMigrator_DirtyAndForce(t *testing.T) {
+	m, db := newSQLiteMigrator(t)
+	all := versions(t, "sqlite")
+	latest := all[len(all)-1]
+
+	step := 0
+	for all[step] != blockComplete {
+		step++
+	}
+	previous := all[step-1]
+	require.NoError(t, m.Up(step))
+
+	// the migration adds a column that is already there and fails halfway
+	_, err := db.Exec(`alter table block add column complete boolean not null default false`)
+	require.NoError(t, err)
+	err = m.Up(0)
+	require.ErrorIs(t, err, ErrFailedToMigrate)
+	assert.Equal(t, Status{Version: blockComplete, Dirty: true, Latest: latest}, status(t, m))
+
+	// the schema is fixed by hand and the version forced back before migrating again
+	_, err = db.Exec(`alter table block drop column complete`)
+	require.NoError(t, err)
+	require.NoError(t, m.Force(int(previous)))
+	assert.Equal(t, Status{Version: previous, Latest: latest}, status(t, m))
+
+	require.NoError(t, m.Up(0))
+	assert.Equal(t, Status{Version: latest, Latest: latest}, status(t, m))
+}
@@ -0,0 +1,123 @@
+// Package pgstore keeps the coordination counters in a Postgres table, it replaces Redis when
+// core should depend on the database only
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func New(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// GetInt returns 0 for a missing key like the Redis client does
+func (s *Store) GetInt(ctx context.Context, key string) (int, error) {
+	var value int
+	err := s.db.QueryRowContext(ctx, `select value from kv_store where key = $1`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return value, err
+}
+
+// SetInt keeps an existing counter like SETNX does
+func (s *Store) SetInt(ctx context.Context, key string, value int) error {
+	query := `insert into kv_store (key, value) values ($1, $2)
+		on conflict (key) do nothing`
+	_, err := s.db.ExecContext(ctx, query, key, value)
+	return err
+}
+
+func (s *Store) OverwriteInt(ctx context.Context, key string, value int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `insert into kv_store (key, value) values ($1, $2)
+		on conflict (key) do update set value = excluded.value`
+	if _, err := tx.ExecContext(ctx, query, key, value); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `delete from kv_store_applied where key = $1`, key); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteCounter(ctx, tx, key); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func deleteCounter(ctx context.Context, tx *sql.Tx, key string) error {
+	if _, err := tx.ExecContext(ctx, `delete from kv_store where key = $1`, key); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `delete from kv_store_applied where key = $1`, key)
+	return err
+}
+
+// DecrementAndMaybeDelete decrements the counter once per member and removes it once it reaches zero.
+// The update locks the row until commit, so concurrent decrements of the same key are applied one
+// after another and a member recorded by one of them is seen by the next.
+func (s *Store) DecrementAndMaybeDelete(ctx context.Context, key, member string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var value int
+	err = tx.QueryRowContext(ctx, `update kv_store set value = value - 1 where key = $1 returning value`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `insert into kv_store_applied (key, member) values ($1, $2) on conflict do nothing`, key, member)
+	if err != nil {
+		return err
+	}
+	// the member was applied before, the rollback undoes the decrement
+	if inserted, err := result.RowsAffected(); err != nil || inserted == 0 {
+		return err
+	}
+
+	if value <= 0 {
+		if err := deleteCounter(ctx, tx, key); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) Reset() error {
+	_, err := s.db.Exec(`truncate kv_store, kv_store_applied`)
+	return err
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
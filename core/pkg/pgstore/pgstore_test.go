@@ -0,0 +1,159 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// newTestStore runs the store on SQLite with the tables of the Postgres migrations,
+// the queries of the store are plain enough for both. Writers wait for each other like core's
+// SQLite connection does.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", filepath.Join(t.TempDir(), "kv.db"))
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	for _, migration := range []string{"20261019120000_kv_store", "20261019220000_kv_store_applied"} {
+		schema, err := os.ReadFile("../../migrations/" + migration + ".up.sql")
+		require.NoError(t, err)
+		_, err = db.Exec(string(schema))
+		require.NoError(t, err)
+	}
+
+	return New(db)
+}
+
+func TestStore_SetInt(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	require.NoError(t, store.SetInt(ctx, "counter", 3))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+
+	// a redelivered message must not restart a counter already decremented
+	require.NoError(t, store.SetInt(ctx, "counter", 3))
+	value, err := store.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+
+	require.NoError(t, store.OverwriteInt(ctx, "counter", 3))
+	value, err = store.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestStore_DecrementAndMaybeDelete(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	require.NoError(t, store.SetInt(ctx, "counter", 2))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "c"))
+
+	var count int
+	require.NoError(t, store.db.QueryRow(`select count(*) from kv_store`).Scan(&count))
+	assert.Zero(t, count)
+
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	require.NoError(t, store.db.QueryRow(`select count(*) from kv_store`).Scan(&count))
+	assert.Zero(t, count)
+}
+
+// TestStore_DecrementAndMaybeDelete_Concurrent decrements counters from several connections at once,
+// every member is applied once and the last decrement removes the row
+func TestStore_DecrementAndMaybeDelete_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	const workers = 16
+
+	require.NoError(t, store.SetInt(ctx, "partial", workers+5))
+	require.NoError(t, store.SetInt(ctx, "complete", workers))
+	require.NoError(t, store.SetInt(ctx, "duplicated", 2))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			member := strconv.Itoa(i)
+			errs <- store.DecrementAndMaybeDelete(ctx, "partial", member)
+			errs <- store.DecrementAndMaybeDelete(ctx, "complete", member)
+			errs <- store.DecrementAndMaybeDelete(ctx, "duplicated", "same")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	value, err := store.GetInt(ctx, "partial")
+	require.NoError(t, err)
+	assert.Equal(t, 5, value)
+	value, err = store.GetInt(ctx, "duplicated")
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	var count int
+	require.NoError(t, store.db.QueryRow(`select count(*) from kv_store where key = 'complete'`).Scan(&count))
+	assert.Zero(t, count)
+	require.NoError(t, store.db.QueryRow(`select count(*) from kv_store_applied where key = 'complete'`).Scan(&count))
+	assert.Zero(t, count)
+}
+
+// TestStore_DecrementOncePerMember replays decrements the way a redelivered message or the per-message
+// retry of a failed batch does, each member is counted once until the counter restarts
+func TestStore_DecrementOncePerMember(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	value := func() int {
+		t.Helper()
+		v, err := store.GetInt(ctx, "counter")
+		require.NoError(t, err)
+		return v
+	}
+
+	require.NoError(t, store.SetInt(ctx, "counter", 3))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 2, value())
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Equal(t, 1, value())
+
+	// a reindex restarts the counter and every child counts again
+	require.NoError(t, store.OverwriteInt(ctx, "counter", 2))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 1, value())
+
+	require.NoError(t, store.Delete(ctx, "counter"))
+	require.NoError(t, store.SetInt(ctx, "counter", 2))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 1, value())
+
+	// the members go with the counter once it reaches zero
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Zero(t, value())
+	require.NoError(t, store.SetInt(ctx, "counter", 1))
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Zero(t, value())
+
+	// a decrement of a missing counter does not create it
+	require.NoError(t, store.DecrementAndMaybeDelete(ctx, "counter", "c"))
+	require.NoError(t, store.SetInt(ctx, "counter", 1))
+	assert.Equal(t, 1, value())
+}
@@ -7,25 +7,26 @@ import (
 
 	"github.com/elmiringos/indexer/indexer-core/config"
 
-	// Register the PostgreSQL driver
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
 type Connection struct {
-	db  *sql.DB
-	log *zap.Logger
+	db        *sql.DB
+	connector *searchPathConnector
+	log       *zap.Logger
 }
 
 func NewPostgresConnection(cfg *config.Config, log *zap.Logger) *Connection {
 	log.Debug("Connect to database", zap.String("url", cfg.PG.URL))
-	db, err := sql.Open("postgres", cfg.PG.URL)
+	base, err := pq.NewConnector(cfg.PG.URL)
 
 	if err != nil {
 		log.Fatal("Error in opening db", zap.Error(err))
 	}
 
-	pc := &Connection{db: db, log: log}
+	connector := &searchPathConnector{base: base}
+	pc := &Connection{db: sql.OpenDB(connector), connector: connector, log: log}
 
 	pc.Health()
 
@@ -44,6 +45,17 @@ func (pc *Connection) Health() bool {
 	return true
 }
 
+// Ping verifies that the database is reachable
+func (pc *Connection) Ping(ctx context.Context) error {
+	return pc.db.PingContext(ctx)
+}
+
+// SetSearchPath changes the schemas unqualified table names resolve to, pooled sessions opened with
+// the previous path are closed once released. No schemas restores the server default.
+func (pc *Connection) SetSearchPath(schemas ...string) {
+	pc.connector.setSearchPath(schemas...)
+}
+
 func (pc *Connection) GetDb() *sql.DB {
 	return pc.db
 }
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lib/pq"
+)
+
+var ErrUnsupportedConn = errors.New("driver connection does not support session reset")
+
+// session is the part of the lib/pq connection the pool relies on
+type session interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+// searchPathConnector sets the search path of every session it opens. Changing the path bumps the
+// generation, sessions of an older generation are discarded instead of going back to the pool.
+type searchPathConnector struct {
+	base       driver.Connector
+	mu         sync.RWMutex
+	searchPath string
+	generation atomic.Uint64
+}
+
+func (c *searchPathConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.RLock()
+	searchPath, generation := c.searchPath, c.generation.Load()
+	c.mu.RUnlock()
+
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := conn.(session)
+	if !ok {
+		conn.Close()
+		return nil, ErrUnsupportedConn
+	}
+
+	if searchPath != "" {
+		if _, err := s.ExecContext(ctx, "set search_path to "+searchPath, nil); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return &searchPathConn{session: s, connector: c, generation: generation}, nil
+}
+
+func (c *searchPathConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// setSearchPath applies to sessions opened from now on, the server default is used when schemas is empty
+func (c *searchPathConnector) setSearchPath(schemas ...string) {
+	quoted := make([]string, len(schemas))
+	for i, schema := range schemas {
+		quoted[i] = pq.QuoteIdentifier(schema)
+	}
+
+	c.mu.Lock()
+	c.searchPath = strings.Join(quoted, ", ")
+	c.generation.Add(1)
+	c.mu.Unlock()
+}
+
+type searchPathConn struct {
+	session
+	connector  *searchPathConnector
+	generation uint64
+}
+
+func (c *searchPathConn) current() bool {
+	return c.generation == c.connector.generation.Load()
+}
+
+// ResetSession runs before a pooled session is reused
+func (c *searchPathConn) ResetSession(ctx context.Context) error {
+	if !c.current() {
+		return driver.ErrBadConn
+	}
+	return c.session.ResetSession(ctx)
+}
+
+// IsValid runs before a session goes back to the pool
+func (c *searchPathConn) IsValid() bool {
+	return c.current() && c.session.IsValid()
+}
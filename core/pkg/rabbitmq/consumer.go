@@ -1,12 +1,45 @@
 package rabbitmq
 
 import (
+	"errors"
+
 	"github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 
 	"github.com/elmiringos/indexer/indexer-core/pkg/logger"
 )
 
+// MessageConsumer is what the core pipeline needs from a broker, Consumer implements it on top of
+// RabbitMQ and the all-in-one binary with in-process queues
+type MessageConsumer interface {
+	MakeNewQueueAndExchange(queueType QueueType, exchange ExchangeName, routingKey RoutingKey) (*amqp091.Queue, error)
+	Consume(queue QueueType) <-chan amqp091.Delivery
+	Ping() error
+	PurgeQueues(queues ...QueueType) (int, error)
+	PendingMessages(queues ...QueueType) (int, error)
+}
+
+// ResetGeneration reads the reset generation header of a message, ok is false when the producer did not set it
+func ResetGeneration(headers amqp091.Table) (generation uint64, ok bool) {
+	var value int64
+	switch v := headers[ResetGenerationHeader].(type) {
+	case int64:
+		value = v
+	case int32:
+		value = int64(v)
+	case int:
+		value = int64(v)
+	default:
+		return 0, false
+	}
+
+	if value < 0 {
+		return 0, false
+	}
+
+	return uint64(value), true
+}
+
 type Consumer struct {
 	conn *amqp091.Connection
 	log  *zap.Logger
@@ -80,9 +113,9 @@ func (c *Consumer) MakeNewQueueAndExchange(queueType QueueType, exchange Exchang
 	return &queue, nil
 }
 
-func (c *Consumer) Consume(channel *amqp091.Channel, queue QueueType) <-chan amqp091.Delivery {
-
-	msgs, err := channel.Consume(
+// Consume opens a channel of its own for the queue so every queue is consumed independently
+func (c *Consumer) Consume(queue QueueType) <-chan amqp091.Delivery {
+	msgs, err := c.CreateChannel().Consume(
 		string(queue),
 		"",
 		false,
@@ -99,6 +132,63 @@ func (c *Consumer) Consume(channel *amqp091.Channel, queue QueueType) <-chan amq
 	return msgs
 }
 
+// Ping reports whether the broker connection is open and accepts new channels
+func (c *Consumer) Ping() error {
+	if c.conn.IsClosed() {
+		return errors.New("amqp connection is closed")
+	}
+
+	channel, err := c.conn.Channel()
+	if err != nil {
+		return err
+	}
+
+	return channel.Close()
+}
+
+// PurgeQueues drops every ready message from the given queues and returns how many were removed
+func (c *Consumer) PurgeQueues(queues ...QueueType) (int, error) {
+	channel, err := c.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer channel.Close()
+
+	purged := 0
+	for _, queue := range queues {
+		count, err := channel.QueuePurge(string(queue), false)
+		if err != nil {
+			return purged, err
+		}
+
+		c.log.Info("Queue purged", zap.String("queue", string(queue)), zap.Int("messages", count))
+		purged += count
+	}
+
+	return purged, nil
+}
+
+// PendingMessages counts the ready messages of the given queues, messages delivered but not acked are not included
+func (c *Consumer) PendingMessages(queues ...QueueType) (int, error) {
+	channel, err := c.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer channel.Close()
+
+	pending := 0
+	for _, queue := range queues {
+		// the flags must match the declaration in MakeNewQueueAndExchange
+		state, err := channel.QueueDeclarePassive(string(queue), false, false, false, false, nil)
+		if err != nil {
+			return pending, err
+		}
+		pending += state.Messages
+	}
+
+	return pending, nil
+}
+
 func (c *Consumer) CloseConnection() error {
 	return c.conn.Close()
 }
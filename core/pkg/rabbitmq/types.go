@@ -42,6 +42,21 @@ const (
 	TransactionActionQueue   QueueType = "transaction_action"
 )
 
+// PipelineQueues lists every queue the indexing pipeline consumes from
+var PipelineQueues = []QueueType{
+	BlockQueue,
+	TransactionQueue,
+	TransactionLogQueue,
+	TransactionActionQueue,
+	InternalTransactionQueue,
+	WithdrawalQueue,
+	RewardQueue,
+	TokenEventQueue,
+}
+
+// ResetGenerationHeader carries the reset generation the producer published a message in
+const ResetGenerationHeader = "x-reset-generation"
+
 type BlockStatus int
 
 const (
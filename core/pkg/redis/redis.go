@@ -53,21 +53,44 @@ func (r *Client) SetInt(ctx context.Context, key string, count int) error {
 	return r.client.SetNX(ctx, key, count, 0).Err()
 }
 
+// OverwriteInt restarts the counter, a reindexed block publishes all of its children again
+// so its counters have to start from the full count
+func (r *Client) OverwriteInt(ctx context.Context, key string, count int) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, count, 0)
+		pipe.Del(ctx, appliedKey(key))
+		return nil
+	})
+	return err
+}
+
+// appliedKey holds the set of members already decremented from a counter
+func appliedKey(key string) string {
+	return key + ":applied"
+}
+
+// decrAndMaybeDeleteScript leaves a missing counter alone, a decrement would create it at -1
 var decrAndMaybeDeleteScript = redis.NewScript(`
+    if redis.call("EXISTS", KEYS[1]) == 0 then
+        return 0
+    end
+    if redis.call("SADD", KEYS[2], ARGV[1]) == 0 then
+        return redis.call("GET", KEYS[1])
+    end
     local val = redis.call("DECR", KEYS[1])
     if val <= 0 then
-        redis.call("DEL", KEYS[1])
+        redis.call("DEL", KEYS[1], KEYS[2])
     end
     return val
 `)
 
-func (r *Client) DecrementAndMaybeDelete(ctx context.Context, key string) error {
-	_, err := decrAndMaybeDeleteScript.Run(ctx, r.client, []string{key}).Result()
+func (r *Client) DecrementAndMaybeDelete(ctx context.Context, key, member string) error {
+	_, err := decrAndMaybeDeleteScript.Run(ctx, r.client, []string{key, appliedKey(key)}, member).Result()
 	return err
 }
 
 func (r *Client) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+	return r.client.Del(ctx, key, appliedKey(key)).Err()
 }
 
 func (r *Client) Reset() error {
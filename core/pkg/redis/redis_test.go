@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	cfg := &config.Config{}
+	cfg.Redis.URL = server.Addr()
+
+	client := NewClient(cfg, zap.NewNop())
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestClient_SetInt(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	require.NoError(t, client.SetInt(ctx, "counter", 3))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "a"))
+
+	// a redelivered message must not restart a counter already decremented
+	require.NoError(t, client.SetInt(ctx, "counter", 3))
+	value, err := client.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+
+	require.NoError(t, client.OverwriteInt(ctx, "counter", 3))
+	value, err = client.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestClient_DecrementAndMaybeDelete(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	require.NoError(t, client.SetInt(ctx, "counter", 2))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "c"))
+
+	value, err := client.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Zero(t, value)
+
+	// the counter is gone, a new SetInt starts it again
+	require.NoError(t, client.SetInt(ctx, "counter", 1))
+	value, err = client.GetInt(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+}
+
+// TestClient_DecrementOncePerMember replays decrements the way a redelivered message or the per-message
+// retry of a failed batch does, each member is counted once until the counter restarts
+func TestClient_DecrementOncePerMember(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+
+	value := func() int {
+		t.Helper()
+		v, err := client.GetInt(ctx, "counter")
+		require.NoError(t, err)
+		return v
+	}
+
+	require.NoError(t, client.SetInt(ctx, "counter", 3))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 2, value())
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Equal(t, 1, value())
+
+	// a reindex restarts the counter and every child counts again
+	require.NoError(t, client.OverwriteInt(ctx, "counter", 2))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 1, value())
+
+	require.NoError(t, client.Delete(ctx, "counter"))
+	require.NoError(t, client.SetInt(ctx, "counter", 2))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "a"))
+	assert.Equal(t, 1, value())
+
+	// the members go with the counter once it reaches zero
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Zero(t, value())
+	require.NoError(t, client.SetInt(ctx, "counter", 1))
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "b"))
+	assert.Zero(t, value())
+
+	// a decrement of a missing counter does not create it
+	require.NoError(t, client.DecrementAndMaybeDelete(ctx, "counter", "c"))
+	require.NoError(t, client.SetInt(ctx, "counter", 1))
+	assert.Equal(t, 1, value())
+}
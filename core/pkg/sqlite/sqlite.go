@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/elmiringos/indexer/indexer-core/config"
+
+	"go.uber.org/zap"
+	// Register the pure Go SQLite driver
+	_ "modernc.org/sqlite"
+)
+
+type Connection struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+// NewSQLiteConnection opens the database file, the explorer reads it while core writes so the
+// journal is in WAL mode, and cascading deletes need foreign keys switched on
+func NewSQLiteConnection(cfg *config.Config, log *zap.Logger) *Connection {
+	log.Debug("Open sqlite database", zap.String("path", cfg.Database.SQLitePath))
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", cfg.Database.SQLitePath)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		log.Fatal("Error in opening db", zap.Error(err))
+	}
+
+	// SQLite allows one writer at a time, a single connection keeps the workers from failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		log.Fatal("db down", zap.Error(err))
+	}
+
+	return &Connection{db: db, log: log}
+}
+
+// Ping verifies that the database file can be read
+func (c *Connection) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+func (c *Connection) GetDb() *sql.DB {
+	return c.db
+}
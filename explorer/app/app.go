@@ -0,0 +1,16 @@
+// Package app starts the explorer, it is run by cmd/api and embedded in the all-in-one binary
+package app
+
+import (
+	"context"
+
+	"github.com/elmiringos/indexer/explorer/config"
+	"github.com/elmiringos/indexer/explorer/internal/api"
+
+	"go.uber.org/zap"
+)
+
+// Run serves the explorer APIs until ctx is cancelled
+func Run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
+	return api.Run(ctx, cfg, log)
+}
@@ -1,9 +1,31 @@
 package main
 
 import (
-	"github.com/elmiringos/indexer/explorer/internal/api"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/elmiringos/indexer/explorer/app"
+	"github.com/elmiringos/indexer/explorer/config"
+	"github.com/elmiringos/indexer/explorer/pkg/logger"
 )
 
 func main() {
-	api.Run()
+	cfg, err := config.NewDefaultConfig()
+	if err != nil {
+		panic("Error in loading config: " + err.Error())
+	}
+
+	// Initialize logger
+	log := logger.New(cfg)
+	defer log.Sync()
+
+	// Graceful shutdown setup
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Run(ctx, cfg, log); err != nil {
+		log.Fatal("Explorer failed: " + err.Error())
+	}
 }
@@ -1,17 +1,31 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
 
+// Database drivers selectable in the config
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
 type (
 	Config struct {
-		Server `yaml:"server"`
-		HTTP   `yaml:"http"`
-		Logger `yaml:"logger"`
+		Server   `yaml:"server"`
+		HTTP     `yaml:"http"`
+		Health   `yaml:"health"`
+		Logger   `yaml:"logger"`
+		Database `yaml:"database"`
+		JSONRPC  `yaml:"jsonrpc"`
 		PG
 	}
 
@@ -25,12 +39,31 @@ type (
 		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
 	}
 
+	Health struct {
+		CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" env-default:"2s"`
+		MaxBlockAge  time.Duration `yaml:"max_block_age" env:"HEALTH_MAX_BLOCK_AGE" env-default:"5m"`
+	}
+
 	Logger struct {
 		File string `env-required:"false" yaml:"file" env:"LOG_FILE"`
 	}
 
+	// Database selects the storage backend core writes to, the SQLite file is opened read only
+	Database struct {
+		Driver     string `yaml:"driver"      env:"DB_DRIVER"   env-default:"postgres"`
+		SQLitePath string `yaml:"sqlite_path" env:"SQLITE_PATH" env-default:"indexer.db"`
+	}
+
+	// JSONRPC configures the JSON-RPC facade. Without a chain id eth_chainId asks the upstream node, and
+	// state methods are only served when an upstream node is set.
+	JSONRPC struct {
+		ChainID     uint64   `yaml:"chain_id"     env:"JSONRPC_CHAIN_ID"`
+		UpstreamURL string   `yaml:"upstream_url" env:"JSONRPC_UPSTREAM_URL"`
+		WSOrigins   []string `yaml:"ws_origins"   env:"JSONRPC_WS_ORIGINS" env-default:"*"`
+	}
+
 	PG struct {
-		URL string `env-required:"true" env:"PG_URL"`
+		URL string `env:"PG_URL"`
 	}
 )
 
@@ -53,14 +86,19 @@ func NewConfig(configPath, envPath string) (*Config, error) {
 		}
 	}
 
-	err := godotenv.Load(envPath)
+	err := cleanenv.ReadConfig(configPath, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	err = cleanenv.ReadConfig(configPath, cfg)
-	if err != nil {
-		return nil, err
+	switch cfg.Database.Driver {
+	case DriverPostgres:
+		if cfg.PG.URL == "" {
+			return nil, fmt.Errorf("%w: PG_URL is required for the %s driver", ErrInvalidConfig, DriverPostgres)
+		}
+	case DriverSQLite:
+	default:
+		return nil, fmt.Errorf("%w: unknown database driver %q", ErrInvalidConfig, cfg.Database.Driver)
 	}
 
 	return cfg, nil
@@ -2,44 +2,133 @@ package api
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
 	"github.com/elmiringos/indexer/explorer/config"
+	graphqlhandler "github.com/elmiringos/indexer/explorer/internal/api/handler/graphql"
+	jsonrpchandler "github.com/elmiringos/indexer/explorer/internal/api/handler/jsonrpc"
+	"github.com/elmiringos/indexer/explorer/internal/api/pb"
 	"github.com/elmiringos/indexer/explorer/internal/api/server"
 	"github.com/elmiringos/indexer/explorer/internal/api/service"
 	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
-	"github.com/elmiringos/indexer/explorer/pkg/logger"
+	"github.com/elmiringos/indexer/explorer/pkg/health"
 	"github.com/elmiringos/indexer/explorer/pkg/postgres"
+	"github.com/elmiringos/indexer/explorer/pkg/sqlite"
+	"github.com/ethereum/go-ethereum/rpc"
 	"go.uber.org/zap"
 )
 
-func Run() error {
-	cfg, err := config.NewDefaultConfig()
-	if err != nil {
-		panic("Error in loading config: " + err.Error())
-	}
+// Database is the connection the repositories read from, postgres.Connection and sqlite.Connection implement it
+type Database interface {
+	GetDb() *sql.DB
+	Ping(ctx context.Context) error
+}
 
-	// Initialize logger
-	log := logger.New(cfg)
-	defer log.Sync()
+// Run serves the explorer REST, JSON-RPC, GraphQL and gRPC APIs until ctx is cancelled
+func Run(ctx context.Context, cfg *config.Config, log *zap.Logger) error {
+	var db Database
+	if cfg.Database.Driver == config.DriverSQLite {
+		db = sqlite.NewSQLiteConnection(cfg, log)
+	} else {
+		db = postgres.NewPostgresConnection(cfg, log)
+	}
 
-	db := postgres.NewPostgresConnection(cfg, log)
+	// core owns the schema, serving an older one would fail on the first query that needs a newer column
+	if err := repository.CheckSchemaVersion(ctx, db.GetDb()); err != nil {
+		return err
+	}
 
 	// Initialize Repositories
 	blockRepository := repository.NewBlockRepository(db.GetDb(), log)
+	transactionRepository := repository.NewTransactionRepository(db.GetDb(), log)
+	withdrawalRepository := repository.NewWithdrawalRepository(db.GetDb())
+	internalTransactionRepository := repository.NewInternalTransactionRepository(db.GetDb())
+	tokenRepository := repository.NewTokenRepository(db.GetDb())
+	smartContractRepository := repository.NewSmartContractRepository(db.GetDb())
+	addressRepository := repository.NewAddressRepository(db.GetDb())
+	if cfg.Database.Driver == config.DriverSQLite {
+		tokenRepository = repository.NewSQLiteTokenRepository(db.GetDb())
+		addressRepository = repository.NewSQLiteAddressRepository(db.GetDb())
+	}
+	rewardRepository := repository.NewRewardRepository(db.GetDb())
 
 	// Initialize services
 	blockService := service.NewBlockService(
 		blockRepository,
+		transactionRepository,
+		withdrawalRepository,
+		log,
+	)
+	transactionService := service.NewTransactionService(
+		transactionRepository,
+		internalTransactionRepository,
+		tokenRepository,
+		smartContractRepository,
+		log,
+	)
+	logService := service.NewLogService(transactionRepository, smartContractRepository, log)
+	tokenService := service.NewTokenService(tokenRepository, log)
+	nftService := service.NewNFTService(tokenRepository, log)
+	addressService := service.NewAddressService(
+		addressRepository,
+		transactionRepository,
+		internalTransactionRepository,
+		tokenRepository,
+		withdrawalRepository,
+		rewardRepository,
+		smartContractRepository,
+		log,
+	)
+	searchService := service.NewSearchService(
+		blockRepository,
+		transactionRepository,
+		addressRepository,
+		tokenRepository,
+		smartContractRepository,
+		log,
+	)
+	etherscanService := service.NewEtherscanService(blockRepository, transactionRepository, tokenRepository, smartContractRepository, log)
+	rpcService := service.NewRPCService(blockRepository, transactionRepository, withdrawalRepository, log)
+	graphqlService := service.NewGraphQLService(
+		blockRepository,
+		transactionRepository,
+		withdrawalRepository,
+		tokenRepository,
+		addressRepository,
+		smartContractRepository,
 		log,
 	)
 
+	// the upstream node is optional, without it state methods are not served
+	var upstream *rpc.Client
+	if cfg.JSONRPC.UpstreamURL != "" {
+		client, err := rpc.DialContext(ctx, cfg.JSONRPC.UpstreamURL)
+		if err != nil {
+			return fmt.Errorf("failed to dial JSON-RPC upstream: %w", err)
+		}
+		defer client.Close()
+		upstream = client
+	}
+	rpcServer, err := jsonrpchandler.NewServer(rpcService, upstream, cfg.JSONRPC.ChainID, cfg.JSONRPC.WSOrigins, log)
+	if err != nil {
+		return err
+	}
+	graphqlHandler, err := graphqlhandler.NewHandler(blockService, transactionService, addressService, logService, tokenService, graphqlService, log)
+	if err != nil {
+		return err
+	}
+
+	// Initialize readiness checks
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+	registerHealthChecks(checker, db, blockRepository, cfg)
+	healthServer := health.NewGRPCServer()
+
 	// Initialize REST and gRPC servers
-	httpServer := server.NewRESTServer(blockService, log)
-	grpcServer := server.NewGRPCServer(blockService, log)
+	httpServer := server.NewRESTServer(blockService, transactionService, addressService, logService, tokenService, nftService, searchService, etherscanService, rpcServer, graphqlHandler, checker, log)
+	grpcServer := server.NewGRPCServer(blockService, transactionService, logService, healthServer, log)
 
 	// Initialize listeners
 	grpcL, httpL, muxer, err := server.SetupListeners(cfg.Port)
@@ -47,11 +136,9 @@ func Run() error {
 		log.Fatal("Failed to setup listeners", zap.Error(err))
 	}
 
-	// Graceful shutdown setup
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
-
 	// Start servers
+	go checker.WatchGRPC(ctx, healthServer, 10*time.Second, pb.ExplorerService_ServiceDesc.ServiceName)
+
 	go func() {
 		log.Info("Starting gRPC server...")
 		if err := grpcServer.Serve(grpcL); err != nil {
@@ -0,0 +1,254 @@
+package etherscan
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TransactionResult is a txlist item, every number is a decimal string
+type TransactionResult struct {
+	BlockNumber       string `json:"blockNumber"`
+	TimeStamp         string `json:"timeStamp"`
+	Hash              string `json:"hash"`
+	Nonce             string `json:"nonce"`
+	BlockHash         string `json:"blockHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Value             string `json:"value"`
+	Gas               string `json:"gas"`
+	GasPrice          string `json:"gasPrice"`
+	IsError           string `json:"isError"`
+	TxReceiptStatus   string `json:"txreceipt_status"`
+	Input             string `json:"input"`
+	ContractAddress   string `json:"contractAddress"`
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+	GasUsed           string `json:"gasUsed"`
+	Confirmations     string `json:"confirmations"`
+	MethodId          string `json:"methodId"`
+	FunctionName      string `json:"functionName"`
+}
+
+// TokenTransferResult is a tokentx item, Etherscan no longer returns the input of the transaction
+type TokenTransferResult struct {
+	BlockNumber       string `json:"blockNumber"`
+	TimeStamp         string `json:"timeStamp"`
+	Hash              string `json:"hash"`
+	Nonce             string `json:"nonce"`
+	BlockHash         string `json:"blockHash"`
+	From              string `json:"from"`
+	ContractAddress   string `json:"contractAddress"`
+	To                string `json:"to"`
+	Value             string `json:"value"`
+	TokenName         string `json:"tokenName"`
+	TokenSymbol       string `json:"tokenSymbol"`
+	TokenDecimal      string `json:"tokenDecimal"`
+	TransactionIndex  string `json:"transactionIndex"`
+	Gas               string `json:"gas"`
+	GasPrice          string `json:"gasPrice"`
+	GasUsed           string `json:"gasUsed"`
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+	Input             string `json:"input"`
+	Confirmations     string `json:"confirmations"`
+}
+
+// txList serves module=account&action=txlist, the transactions sent or received by address between
+// startblock and endblock
+func (h *Router) txList(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAccountFilter(r)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get transactions", err)
+		return
+	}
+
+	transactions, err := h.etherscanService.GetAccountTransactions(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get transactions", err)
+		return
+	}
+
+	latest, err := h.etherscanService.GetLatestBlockNumber(r.Context())
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get transactions", err)
+		return
+	}
+
+	result := make([]*TransactionResult, len(transactions))
+	for i, tx := range transactions {
+		result[i] = mapTransaction(tx, latest)
+	}
+
+	writeList(w, h.log, result, "No transactions found")
+}
+
+func parseAccountFilter(r *http.Request) (transaction.AccountFilter, error) {
+	var (
+		filter transaction.AccountFilter
+		err    error
+	)
+	if filter.Address, err = formAddress(r, "address"); err != nil {
+		return filter, err
+	}
+	if filter.FromBlock, err = formBlock(r, "startblock"); err != nil {
+		return filter, err
+	}
+	if filter.ToBlock, err = formBlock(r, "endblock"); err != nil {
+		return filter, err
+	}
+	if filter.Descending, err = formSort(r); err != nil {
+		return filter, err
+	}
+	filter.Offset, filter.Limit, err = formPage(r, MaxResults)
+
+	return filter, err
+}
+
+func mapTransaction(tx *service.TransactionWithFee, latest domain.BigInt) *TransactionResult {
+	result := &TransactionResult{
+		BlockNumber:       tx.BlockNumber.String(),
+		TimeStamp:         strconv.FormatInt(tx.Timestamp, 10),
+		Hash:              tx.Hash.Hex(),
+		Nonce:             strconv.FormatUint(tx.Nonce, 10),
+		BlockHash:         tx.BlockHash.Hex(),
+		TransactionIndex:  strconv.Itoa(tx.Index),
+		From:              hexAddress(tx.From),
+		To:                hexAddress(tx.To),
+		Value:             tx.Value.String(),
+		Gas:               strconv.FormatUint(tx.Gas, 10),
+		GasPrice:          gasPrice(tx).String(),
+		IsError:           "0",
+		TxReceiptStatus:   strconv.FormatUint(tx.Status, 10),
+		Input:             hexutil.Encode(tx.Input),
+		CumulativeGasUsed: strconv.FormatUint(tx.CumulativeGasUsed, 10),
+		GasUsed:           strconv.FormatUint(tx.GasUsed, 10),
+		Confirmations:     confirmations(latest, tx.BlockNumber),
+		MethodId:          "0x",
+	}
+	if tx.Status == 0 {
+		result.IsError = "1"
+	}
+	// core stores the zero address as the recipient of a contract creation
+	if tx.To == (common.Address{}) {
+		result.To = ""
+		result.ContractAddress = hexAddress(crypto.CreateAddress(tx.From, tx.Nonce))
+	}
+	if len(tx.Input) >= 4 {
+		result.MethodId = hexutil.Encode(tx.Input[:4])
+	}
+
+	return result
+}
+
+// tokenTx serves module=account&action=tokentx, the ERC-20 transfers of address, of contractaddress or
+// of address in contractaddress between startblock and endblock
+func (h *Router) tokenTx(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransferFilter(r)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get token transfers", err)
+		return
+	}
+
+	transfers, err := h.etherscanService.GetAccountTokenTransfers(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get token transfers", err)
+		return
+	}
+
+	latest, err := h.etherscanService.GetLatestBlockNumber(r.Context())
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get token transfers", err)
+		return
+	}
+
+	result := make([]*TokenTransferResult, len(transfers))
+	for i, transfer := range transfers {
+		result[i] = mapTokenTransfer(transfer, latest)
+	}
+
+	writeList(w, h.log, result, "No transactions found")
+}
+
+func parseTransferFilter(r *http.Request) (token.AccountTransferFilter, error) {
+	filter := token.AccountTransferFilter{Type: token.TypeERC20}
+
+	var err error
+	if filter.Address, err = formOptionalAddress(r, "address"); err != nil {
+		return filter, err
+	}
+	if filter.Token, err = formOptionalAddress(r, "contractaddress"); err != nil {
+		return filter, err
+	}
+	if filter.FromBlock, err = formBlock(r, "startblock"); err != nil {
+		return filter, err
+	}
+	if filter.ToBlock, err = formBlock(r, "endblock"); err != nil {
+		return filter, err
+	}
+	if filter.Descending, err = formSort(r); err != nil {
+		return filter, err
+	}
+	filter.Offset, filter.Limit, err = formPage(r, MaxResults)
+
+	return filter, err
+}
+
+func mapTokenTransfer(t *service.TransferWithTransaction, latest domain.BigInt) *TokenTransferResult {
+	result := &TokenTransferResult{
+		BlockNumber:      t.BlockNumber.String(),
+		TimeStamp:        strconv.FormatInt(t.Timestamp, 10),
+		Hash:             t.TransactionHash.Hex(),
+		From:             hexAddress(t.From),
+		ContractAddress:  hexAddress(t.TokenContractAddress),
+		To:               hexAddress(t.To),
+		Value:            t.Amount.String(),
+		TransactionIndex: strconv.Itoa(t.TransactionIndex),
+		Input:            "deprecated",
+		Confirmations:    confirmations(latest, t.BlockNumber),
+	}
+	if t.Token != nil {
+		result.TokenName, result.TokenSymbol, result.TokenDecimal = t.Token.Name, t.Token.Symbol, strconv.Itoa(t.Token.Decimals)
+	}
+	if tx := t.Transaction; tx != nil {
+		result.Nonce = strconv.FormatUint(tx.Nonce, 10)
+		result.BlockHash = tx.BlockHash.Hex()
+		result.Gas = strconv.FormatUint(tx.Gas, 10)
+		result.GasPrice = gasPrice(tx).String()
+		result.GasUsed = strconv.FormatUint(tx.GasUsed, 10)
+		result.CumulativeGasUsed = strconv.FormatUint(tx.CumulativeGasUsed, 10)
+	}
+
+	return result
+}
+
+// hexAddress formats addresses in lower case like Etherscan does
+func hexAddress(addr common.Address) string {
+	return strings.ToLower(addr.Hex())
+}
+
+// gasPrice is what the sender paid per gas, 0 when the transaction was indexed without its encoding
+func gasPrice(tx *service.TransactionWithFee) *big.Int {
+	if tx.Fee == nil {
+		return new(big.Int)
+	}
+
+	return (*big.Int)(&tx.Fee.GasPrice)
+}
+
+// confirmations counts the blocks from number to latest, number included
+func confirmations(latest domain.BigInt, number domain.BigInt) string {
+	if latest.Cmp(number) < 0 {
+		return "0"
+	}
+
+	return latest.Sub(number).Sum(domain.BigInt(*big.NewInt(1))).String()
+}
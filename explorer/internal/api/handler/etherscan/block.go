@@ -0,0 +1,40 @@
+package etherscan
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+)
+
+// getBlockNoByTime serves module=block&action=getblocknobytime, the number of the last block at or before
+// timestamp, or with closest=after of the first block at or after it
+func (h *Router) getBlockNoByTime(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("timestamp") == "" {
+		writeError(w, h.log, "Error! Invalid timestamp")
+		return
+	}
+	timestamp, err := formUint(r, "timestamp", 0)
+	if err != nil {
+		writeError(w, h.log, "Error! Invalid timestamp")
+		return
+	}
+
+	var after bool
+	switch r.FormValue("closest") {
+	case "", "before":
+	case "after":
+		after = true
+	default:
+		writeServiceError(w, h.log, "Failed to get block", fmt.Errorf("%w: closest must be before or after", service.ErrInvalidQuery))
+		return
+	}
+
+	b, err := h.etherscanService.GetBlockByTimestamp(r.Context(), timestamp, after)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get block", err)
+		return
+	}
+
+	writeResult(w, h.log, b.Number.String())
+}
@@ -0,0 +1,22 @@
+package etherscan
+
+import (
+	"net/http"
+)
+
+// getABI serves module=contract&action=getabi, the JSON ABI of a verified contract as a string
+func (h *Router) getABI(w http.ResponseWriter, r *http.Request) {
+	addr, err := formAddress(r, "address")
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get contract ABI", err)
+		return
+	}
+
+	abi, err := h.etherscanService.GetABI(r.Context(), addr)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get contract ABI", err)
+		return
+	}
+
+	writeResult(w, h.log, abi)
+}
@@ -0,0 +1,121 @@
+package etherscan
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// LogResult is a getLogs item, numbers are hex encoded like in eth_getLogs
+type LogResult struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      string   `json:"blockNumber"`
+	BlockHash        string   `json:"blockHash"`
+	TimeStamp        string   `json:"timeStamp"`
+	GasPrice         string   `json:"gasPrice"`
+	GasUsed          string   `json:"gasUsed"`
+	LogIndex         string   `json:"logIndex"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex string   `json:"transactionIndex"`
+}
+
+// getLogs serves module=logs&action=getLogs, the logs of address matching topic0 to topic3 between
+// fromBlock and toBlock. Topics are combined with and, the or operator is not supported.
+func (h *Router) getLogs(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLogFilter(r)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get logs", err)
+		return
+	}
+
+	logs, err := h.etherscanService.GetLogs(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get logs", err)
+		return
+	}
+
+	result := make([]*LogResult, len(logs))
+	for i, l := range logs {
+		result[i] = mapLog(l)
+	}
+
+	writeList(w, h.log, result, "No records found")
+}
+
+func parseLogFilter(r *http.Request) (transaction.LogFilter, error) {
+	var (
+		filter transaction.LogFilter
+		err    error
+	)
+
+	addr, err := formOptionalAddress(r, "address")
+	if err != nil {
+		return filter, err
+	}
+	if addr != nil {
+		filter.Addresses = []common.Address{*addr}
+	}
+
+	for position := 0; position < service.MaxLogTopicPositions; position++ {
+		for other := position + 1; other < service.MaxLogTopicPositions; other++ {
+			operator := r.FormValue(fmt.Sprintf("topic%d_%d_opr", position, other))
+			if operator != "" && operator != "and" {
+				return filter, fmt.Errorf("%w: only the and topic operator is supported", service.ErrInvalidQuery)
+			}
+		}
+
+		raw := r.FormValue(fmt.Sprintf("topic%d", position))
+		if raw == "" {
+			continue
+		}
+		data, err := hexutil.Decode(raw)
+		if err != nil || len(data) != common.HashLength {
+			return filter, fmt.Errorf("%w: topic%d must be a 0x prefixed 32 byte hash", service.ErrInvalidQuery, position)
+		}
+
+		for len(filter.Topics) <= position {
+			filter.Topics = append(filter.Topics, nil)
+		}
+		filter.Topics[position] = []common.Hash{common.BytesToHash(data)}
+	}
+
+	if filter.FromBlock, err = formBlock(r, "fromBlock"); err != nil {
+		return filter, err
+	}
+	if filter.ToBlock, err = formBlock(r, "toBlock"); err != nil {
+		return filter, err
+	}
+	filter.Offset, filter.Limit, err = formPage(r, MaxLogResults)
+
+	return filter, err
+}
+
+func mapLog(l *service.LogWithTransaction) *LogResult {
+	result := &LogResult{
+		Address:          hexAddress(l.Address),
+		Topics:           make([]string, len(l.Topics)),
+		Data:             hexutil.Encode(l.Data),
+		BlockNumber:      hexutil.EncodeBig((*big.Int)(&l.BlockNumber)),
+		BlockHash:        l.BlockHash.Hex(),
+		LogIndex:         hexutil.EncodeUint64(uint64(l.Index)),
+		TransactionHash:  l.TransactionHash.Hex(),
+		TransactionIndex: hexutil.EncodeUint64(uint64(l.TransactionIndex)),
+	}
+	for i, topic := range l.Topics {
+		result.Topics[i] = topic.Hex()
+	}
+	if tx := l.Transaction; tx != nil {
+		result.TimeStamp = hexutil.EncodeUint64(uint64(tx.Timestamp))
+		result.GasPrice = hexutil.EncodeBig(gasPrice(tx))
+		result.GasUsed = hexutil.EncodeUint64(tx.GasUsed)
+	}
+
+	return result
+}
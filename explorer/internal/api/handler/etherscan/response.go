@@ -0,0 +1,157 @@
+package etherscan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// Etherscan caps the results a listing reaches, page x offset may not go past them
+const (
+	MaxResults    = 10000
+	MaxLogResults = 1000
+)
+
+// Response is the Etherscan envelope. Status is "1" with message "OK" on success, failures are "0" with
+// the error in Result, and an empty listing is "0" with an empty Result.
+type Response struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Result  interface{} `json:"result"`
+}
+
+// Etherscan answers every request with 200, the status of the envelope tells failures apart
+func writeResponse(w http.ResponseWriter, log *zap.Logger, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+func writeResult(w http.ResponseWriter, log *zap.Logger, result interface{}) {
+	writeResponse(w, log, Response{Status: "1", Message: "OK", Result: result})
+}
+
+// writeList writes a listing, emptyMessage is what Etherscan says when nothing matched
+func writeList[T any](w http.ResponseWriter, log *zap.Logger, items []T, emptyMessage string) {
+	if len(items) == 0 {
+		writeResponse(w, log, Response{Status: "0", Message: emptyMessage, Result: []T{}})
+		return
+	}
+
+	writeResult(w, log, items)
+}
+
+func writeError(w http.ResponseWriter, log *zap.Logger, message string) {
+	writeResponse(w, log, Response{Status: "0", Message: "NOTOK", Result: message})
+}
+
+// writeServiceError words the service errors like Etherscan does and hides the rest
+func writeServiceError(w http.ResponseWriter, log *zap.Logger, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery), errors.Is(err, service.ErrInvalidAddress):
+		// Etherscan prints the reason alone, without the error it wraps
+		writeError(w, log, "Error! "+strings.TrimPrefix(err.Error(), service.ErrInvalidQuery.Error()+": "))
+	case errors.Is(err, service.ErrBlockNotFound):
+		writeError(w, log, "Error! No closest block found")
+	case errors.Is(err, service.ErrContractNotVerified):
+		writeError(w, log, "Contract source code not verified")
+	default:
+		log.Error(message, zap.Error(err))
+		writeError(w, log, "Error! "+message)
+	}
+}
+
+// formAddress reads a required address parameter
+func formAddress(r *http.Request, name string) (common.Address, error) {
+	addr, err := service.ParseAddress(r.FormValue(name))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: Invalid address format", service.ErrInvalidQuery)
+	}
+
+	return addr, nil
+}
+
+// formOptionalAddress reads an address parameter that may be left out
+func formOptionalAddress(r *http.Request, name string) (*common.Address, error) {
+	if r.FormValue(name) == "" {
+		return nil, nil
+	}
+
+	addr, err := formAddress(r, name)
+	return &addr, err
+}
+
+// formBlock reads an optional block number, latest and an empty value leave the range open
+func formBlock(r *http.Request, name string) (*domain.BigInt, error) {
+	raw := r.FormValue(name)
+	if raw == "" || raw == "latest" {
+		return nil, nil
+	}
+
+	number, ok := new(big.Int).SetString(raw, 10)
+	if !ok || number.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %s must be a block number", service.ErrInvalidQuery, name)
+	}
+
+	return (*domain.BigInt)(number), nil
+}
+
+// formSort reads the sort parameter, asc by default, and reports whether it is descending
+func formSort(r *http.Request) (bool, error) {
+	switch r.FormValue("sort") {
+	case "", "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w: sort must be asc or desc", service.ErrInvalidQuery)
+	}
+}
+
+// formPage reads page and offset, the page number from 1 and its size, into the number of results to
+// skip and to return. Without offset, or with 0, the listing returns up to maxResults.
+func formPage(r *http.Request, maxResults int) (skip int, limit int, err error) {
+	page, err := formUint(r, "page", 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := formUint(r, "offset", uint64(maxResults))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	page = max(page, 1)
+	if offset == 0 {
+		offset = uint64(maxResults)
+	}
+	if page > uint64(maxResults) || offset > uint64(maxResults) || page*offset > uint64(maxResults) {
+		return 0, 0, fmt.Errorf("%w: Result window is too large, PageNo x Offset size must be less than or equal to %d", service.ErrInvalidQuery, maxResults)
+	}
+
+	return int((page - 1) * offset), int(offset), nil
+}
+
+func formUint(r *http.Request, name string, fallback uint64) (uint64, error) {
+	raw := r.FormValue(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an unsigned integer", service.ErrInvalidQuery, name)
+	}
+
+	return value, nil
+}
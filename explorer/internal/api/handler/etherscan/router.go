@@ -0,0 +1,62 @@
+package etherscan
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// Router serves the Etherscan API subset existing clients use, picking the action from the module and
+// action parameters of the query string or of a posted form. The apikey parameter is ignored.
+type Router struct {
+	etherscanService *service.EtherscanService
+	actions          map[string]map[string]http.HandlerFunc
+	log              *zap.Logger
+}
+
+func NewRouter(etherscanService *service.EtherscanService, log *zap.Logger) *Router {
+	h := &Router{
+		etherscanService: etherscanService,
+		log:              log,
+	}
+	h.actions = map[string]map[string]http.HandlerFunc{
+		"account":  {"txlist": h.txList, "tokentx": h.tokenTx},
+		"logs":     {"getLogs": h.getLogs},
+		"block":    {"getblocknobytime": h.getBlockNoByTime},
+		"contract": {"getabi": h.getABI},
+	}
+
+	return h
+}
+
+func (h *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	module, action := r.FormValue("module"), r.FormValue("action")
+
+	actions, ok := h.actions[module]
+	if !ok {
+		writeError(w, h.log, "Error! Missing Or invalid Module name")
+		h.observe("/api", r, start)
+		return
+	}
+	handle, ok := actions[action]
+	if !ok {
+		writeError(w, h.log, "Error! Missing Or invalid Action name")
+		h.observe("/api", r, start)
+		return
+	}
+
+	handle(w, r)
+	h.observe("/api?module="+module+"&action="+action, r, start)
+}
+
+// observe records the request latency labelled by the action, every response is a 200
+func (h *Router) observe(route string, r *http.Request, start time.Time) {
+	metrics.RequestDuration.
+		WithLabelValues(route, r.Method, strconv.Itoa(http.StatusOK)).
+		Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,411 @@
+package etherscan
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// envelope is a Response with its result left undecoded
+type envelope struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+type client struct {
+	t      *testing.T
+	router *Router
+}
+
+func newTestClient(t *testing.T) (*client, *sql.DB) {
+	db := testdb.Open(t)
+	router := NewRouter(service.NewEtherscanService(
+		repository.NewBlockRepository(db, zap.NewNop()),
+		repository.NewTransactionRepository(db, zap.NewNop()),
+		repository.NewSQLiteTokenRepository(db),
+		repository.NewSmartContractRepository(db),
+		zap.NewNop(),
+	), zap.NewNop())
+
+	return &client{t: t, router: router}, db
+}
+
+// get calls the router with params in the query string, every response has to be a 200 envelope
+func (c *client) get(params url.Values) envelope {
+	c.t.Helper()
+
+	recorder := httptest.NewRecorder()
+	c.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api?"+params.Encode(), nil))
+
+	return c.decode(recorder)
+}
+
+// post calls the router with params in a posted form
+func (c *client) post(params url.Values) envelope {
+	c.t.Helper()
+
+	request := httptest.NewRequest(http.MethodPost, "/api", strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	c.router.ServeHTTP(recorder, request)
+
+	return c.decode(recorder)
+}
+
+func (c *client) decode(recorder *httptest.ResponseRecorder) envelope {
+	c.t.Helper()
+
+	require.Equal(c.t, http.StatusOK, recorder.Code)
+	assert.Equal(c.t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var response envelope
+	require.NoError(c.t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	return response
+}
+
+// result decodes the result of a successful response
+func result[T any](t *testing.T, response envelope) T {
+	t.Helper()
+
+	require.Equal(t, "1", response.Status, string(response.Result))
+	require.Equal(t, "OK", response.Message)
+
+	var value T
+	require.NoError(t, json.Unmarshal(response.Result, &value))
+	return value
+}
+
+// failure returns the error message of a failed response
+func failure(t *testing.T, response envelope) string {
+	t.Helper()
+
+	require.Equal(t, "0", response.Status, string(response.Result))
+	require.Equal(t, "NOTOK", response.Message)
+
+	var message string
+	require.NoError(t, json.Unmarshal(response.Result, &message))
+	return message
+}
+
+func params(pairs ...string) url.Values {
+	values := url.Values{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		values.Set(pairs[i], pairs[i+1])
+	}
+	return values
+}
+
+func TestRouter_Envelope(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	assert.Equal(t, "Error! Missing Or invalid Module name", failure(t, c.get(params())))
+	assert.Equal(t, "Error! Missing Or invalid Module name", failure(t, c.get(params("module", "stats", "action", "ethprice"))))
+	assert.Equal(t, "Error! Missing Or invalid Action name", failure(t, c.get(params("module", "account", "action", "balance"))))
+	assert.Equal(t, "Error! Missing Or invalid Action name", failure(t, c.post(params("module", "account"))))
+
+	// an empty listing is a failed status with an empty array, not an error
+	for _, response := range []envelope{
+		c.get(params("module", "account", "action", "txlist", "address", common.HexToAddress("0x01").Hex(), "apikey", "ignored")),
+		c.post(params("module", "account", "action", "txlist", "address", common.HexToAddress("0x01").Hex())),
+	} {
+		assert.Equal(t, envelope{Status: "0", Message: "No transactions found", Result: json.RawMessage(`[]`)}, response)
+	}
+	assert.Equal(t, envelope{Status: "0", Message: "No records found", Result: json.RawMessage(`[]`)},
+		c.get(params("module", "logs", "action", "getLogs")))
+}
+
+func TestFormPage(t *testing.T) {
+	for _, test := range []struct {
+		query string
+		max   int
+		skip  int
+		limit int
+		err   bool
+	}{
+		{query: "", max: MaxResults, skip: 0, limit: MaxResults},
+		{query: "page=0&offset=0", max: MaxResults, skip: 0, limit: MaxResults},
+		{query: "page=3&offset=10", max: MaxResults, skip: 20, limit: 10},
+		{query: "offset=25", max: MaxLogResults, skip: 0, limit: 25},
+		{query: "page=100&offset=100", max: MaxResults, skip: 9900, limit: 100},
+		{query: "page=10&offset=100", max: MaxLogResults, skip: 900, limit: 100},
+		{query: "page=101&offset=100", max: MaxResults, err: true},
+		{query: "page=11&offset=100", max: MaxLogResults, err: true},
+		{query: "offset=10001", max: MaxResults, err: true},
+		{query: "page=10001", max: MaxResults, err: true},
+		// a product overflowing uint64 is still too large
+		{query: "page=4294967297&offset=4294967297", max: MaxResults, err: true},
+		{query: "page=-1", max: MaxResults, err: true},
+		{query: "offset=ten", max: MaxResults, err: true},
+	} {
+		skip, limit, err := formPage(httptest.NewRequest(http.MethodGet, "/api?"+test.query, nil), test.max)
+		if test.err {
+			assert.ErrorIs(t, err, service.ErrInvalidQuery, test.query)
+			continue
+		}
+		require.NoError(t, err, test.query)
+		assert.Equal(t, test.skip, skip, test.query)
+		assert.Equal(t, test.limit, limit, test.query)
+	}
+}
+
+func TestRouter_TxList(t *testing.T) {
+	c, db := newTestClient(t)
+	account, other := common.HexToAddress("0xAB"), common.HexToAddress("0xCD")
+	failed := uint64(0)
+	for number := int64(1); number <= 4; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number, TransactionsCount: 2})
+		testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: number, Index: 0, From: account, To: other, Value: "5", Input: []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01}})
+		testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: number, Index: 1, From: other, To: other})
+	}
+	testdb.InsertBlock(t, db, testdb.Block{Number: 5, TransactionsCount: 2})
+	testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 5, Index: 0, From: other, To: account, Status: &failed})
+	creation := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 5, Index: 1, From: account})
+
+	positions := func(query url.Values) []string {
+		t.Helper()
+
+		query.Set("module", "account")
+		query.Set("action", "txlist")
+		query.Set("address", strings.ToLower(account.Hex()))
+		var items []string
+		for _, tx := range result[[]TransactionResult](t, c.get(query)) {
+			items = append(items, tx.BlockNumber+":"+tx.TransactionIndex)
+		}
+		return items
+	}
+
+	assert.Equal(t, []string{"1:0", "2:0", "3:0", "4:0", "5:0", "5:1"}, positions(params()))
+	assert.Equal(t, []string{"5:1", "5:0", "4:0", "3:0", "2:0", "1:0"}, positions(params("sort", "desc")))
+	assert.Equal(t, []string{"2:0", "3:0", "4:0"}, positions(params("startblock", "2", "endblock", "4")))
+	assert.Equal(t, []string{"3:0", "4:0", "5:0", "5:1"}, positions(params("startblock", "3", "endblock", "latest")))
+
+	// page x offset skips whole pages, the last page is short
+	assert.Equal(t, []string{"1:0", "2:0", "3:0", "4:0"}, positions(params("page", "1", "offset", "4")))
+	assert.Equal(t, []string{"5:0", "5:1"}, positions(params("page", "2", "offset", "4")))
+	assert.Equal(t, []string{"4:0", "3:0"}, positions(params("sort", "desc", "page", "2", "offset", "2")))
+	assert.Equal(t, envelope{Status: "0", Message: "No transactions found", Result: json.RawMessage(`[]`)},
+		c.get(params("module", "account", "action", "txlist", "address", account.Hex(), "page", "4", "offset", "2")))
+
+	transactions := result[[]TransactionResult](t, c.get(params("module", "account", "action", "txlist", "address", account.Hex(), "startblock", "5")))
+	require.Len(t, transactions, 2)
+	assert.Equal(t, TransactionResult{
+		BlockNumber:       "5",
+		TimeStamp:         big.NewInt(testdb.Timestamp(5)).String(),
+		Hash:              testdb.TransactionHash(5, 0).Hex(),
+		Nonce:             "0",
+		BlockHash:         testdb.BlockHash(5).Hex(),
+		TransactionIndex:  "0",
+		From:              strings.ToLower(other.Hex()),
+		To:                strings.ToLower(account.Hex()),
+		Value:             "0",
+		Gas:               "21000",
+		GasPrice:          "0",
+		IsError:           "1",
+		TxReceiptStatus:   "0",
+		Input:             "0x",
+		CumulativeGasUsed: "0",
+		GasUsed:           "0",
+		Confirmations:     "1",
+		MethodId:          "0x",
+	}, transactions[0])
+	// a contract creation has no recipient, the created address follows from the sender and nonce
+	assert.Equal(t, creation.Hex(), transactions[1].Hash)
+	assert.Empty(t, transactions[1].To)
+	assert.Equal(t, strings.ToLower(crypto.CreateAddress(account, 1).Hex()), transactions[1].ContractAddress)
+
+	first := result[[]TransactionResult](t, c.get(params("module", "account", "action", "txlist", "address", account.Hex(), "offset", "1")))
+	require.Len(t, first, 1)
+	assert.Equal(t, "0xa9059cbb", first[0].MethodId)
+	assert.Equal(t, "0xa9059cbb01", first[0].Input)
+	assert.Equal(t, "5", first[0].Confirmations)
+
+	for query, message := range map[string]string{
+		"address=0x1234": "Error! Invalid address format",
+		"address=" + account.Hex() + "&startblock=3&endblock=2": "Error! the start block is after the end block",
+		"address=" + account.Hex() + "&startblock=-1":           "Error! startblock must be a block number",
+		"address=" + account.Hex() + "&sort=newest":             "Error! sort must be asc or desc",
+		"address=" + account.Hex() + "&page=2&offset=5001":      "Error! Result window is too large, PageNo x Offset size must be less than or equal to 10000",
+		"address=" + account.Hex() + "&offset=many":             "Error! offset must be an unsigned integer",
+	} {
+		response := c.get(mustParseQuery(t, "module=account&action=txlist&"+query))
+		assert.Equal(t, message, failure(t, response), query)
+	}
+}
+
+func mustParseQuery(t *testing.T, query string) url.Values {
+	t.Helper()
+
+	values, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	return values
+}
+
+func TestRouter_TokenTx(t *testing.T) {
+	c, db := newTestClient(t)
+	account, other := common.HexToAddress("0xAB"), common.HexToAddress("0xCD")
+	usdc, dai, nft := common.HexToAddress("0xA1"), common.HexToAddress("0xA2"), common.HexToAddress("0xA3")
+	testdb.InsertToken(t, db, testdb.Token{Address: usdc, Name: "USD Coin", Symbol: "USDC", Decimals: 6})
+	testdb.InsertToken(t, db, testdb.Token{Address: dai, Name: "Dai", Symbol: "DAI", Decimals: 18})
+	testdb.InsertToken(t, db, testdb.Token{Address: nft, Name: "Punks", Symbol: "PUNK", Type: "ERC-721"})
+	large := new(big.Int).Lsh(big.NewInt(1), 200).String()
+	for number := int64(1); number <= 3; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number, TransactionsCount: 1})
+		testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: number, From: account, To: usdc})
+		testdb.InsertTokenTransfer(t, db, testdb.TokenTransfer{BlockNumber: number, LogIndex: 0, Token: usdc, From: account, To: other, Amount: large})
+		testdb.InsertTokenTransfer(t, db, testdb.TokenTransfer{BlockNumber: number, LogIndex: 1, Token: dai, From: other, To: account, Amount: "7"})
+		testdb.InsertTokenTransfer(t, db, testdb.TokenTransfer{BlockNumber: number, LogIndex: 2, Token: nft, From: other, To: account, Amount: "1", TokenID: "9"})
+	}
+
+	transfers := func(query string) []string {
+		t.Helper()
+
+		var items []string
+		for _, transfer := range result[[]TokenTransferResult](t, c.get(mustParseQuery(t, "module=account&action=tokentx&"+query))) {
+			items = append(items, transfer.BlockNumber+" "+transfer.TokenSymbol)
+		}
+		return items
+	}
+
+	// only ERC-20 transfers are listed
+	assert.Equal(t, []string{"1 USDC", "1 DAI", "2 USDC", "2 DAI", "3 USDC", "3 DAI"}, transfers("address="+account.Hex()))
+	assert.Equal(t, []string{"3 USDC", "2 USDC", "1 USDC"}, transfers("contractaddress="+usdc.Hex()+"&sort=desc"))
+	assert.Equal(t, []string{"2 DAI", "3 DAI"}, transfers("address="+account.Hex()+"&contractaddress="+dai.Hex()+"&startblock=2"))
+	assert.Equal(t, []string{"2 USDC", "2 DAI"}, transfers("address="+account.Hex()+"&page=2&offset=2"))
+
+	all := result[[]TokenTransferResult](t, c.get(params("module", "account", "action", "tokentx", "address", account.Hex(), "offset", "1")))
+	require.Len(t, all, 1)
+	assert.Equal(t, TokenTransferResult{
+		BlockNumber:       "1",
+		TimeStamp:         big.NewInt(testdb.Timestamp(1)).String(),
+		Hash:              testdb.TransactionHash(1, 0).Hex(),
+		Nonce:             "0",
+		BlockHash:         testdb.BlockHash(1).Hex(),
+		From:              strings.ToLower(account.Hex()),
+		ContractAddress:   strings.ToLower(usdc.Hex()),
+		To:                strings.ToLower(other.Hex()),
+		Value:             large,
+		TokenName:         "USD Coin",
+		TokenSymbol:       "USDC",
+		TokenDecimal:      "6",
+		TransactionIndex:  "0",
+		Gas:               "21000",
+		GasPrice:          "0",
+		GasUsed:           "0",
+		CumulativeGasUsed: "0",
+		Input:             "deprecated",
+		Confirmations:     "3",
+	}, all[0])
+
+	assert.Equal(t, "Error! an address or a token is required", failure(t, c.get(params("module", "account", "action", "tokentx"))))
+	assert.Equal(t, "Error! Invalid address format", failure(t, c.get(params("module", "account", "action", "tokentx", "contractaddress", "usdc"))))
+}
+
+func TestRouter_GetLogs(t *testing.T) {
+	c, db := newTestClient(t)
+	token, other := common.HexToAddress("0xA1"), common.HexToAddress("0xA2")
+	transfer := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	approval := crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	from := common.BytesToHash(common.HexToAddress("0x01").Bytes())
+	for number := int64(1); number <= 3; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number, TransactionsCount: 1})
+		testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: number, GasUsed: 50000})
+		testdb.InsertLog(t, db, testdb.Log{BlockNumber: number, Index: 0, Address: token, Topics: []common.Hash{transfer, from}, Data: []byte{0x01}})
+		testdb.InsertLog(t, db, testdb.Log{BlockNumber: number, Index: 1, Address: token, Topics: []common.Hash{approval, from}})
+		testdb.InsertLog(t, db, testdb.Log{BlockNumber: number, Index: 2, Address: other, Topics: []common.Hash{transfer}})
+	}
+
+	logs := func(query string) []string {
+		t.Helper()
+
+		var items []string
+		for _, l := range result[[]LogResult](t, c.get(mustParseQuery(t, "module=logs&action=getLogs&"+query))) {
+			items = append(items, l.BlockNumber+":"+l.LogIndex)
+		}
+		return items
+	}
+
+	assert.Equal(t, []string{"0x1:0x0", "0x1:0x1", "0x2:0x0", "0x2:0x1", "0x3:0x0", "0x3:0x1"}, logs("address="+token.Hex()))
+	assert.Equal(t, []string{"0x1:0x0", "0x1:0x2", "0x2:0x0", "0x2:0x2", "0x3:0x0", "0x3:0x2"}, logs("topic0="+transfer.Hex()))
+	assert.Equal(t, []string{"0x2:0x0", "0x3:0x0"}, logs("topic0="+transfer.Hex()+"&topic1="+from.Hex()+"&topic0_1_opr=and&fromBlock=2&toBlock=latest"))
+	assert.Equal(t, []string{"0x1:0x1", "0x2:0x1", "0x3:0x1"}, logs("address="+token.Hex()+"&topic1="+from.Hex()+"&topic0="+approval.Hex()))
+	assert.Equal(t, []string{"0x2:0x0", "0x2:0x1"}, logs("address="+token.Hex()+"&page=2&offset=2"))
+
+	first := result[[]LogResult](t, c.get(params("module", "logs", "action", "getLogs", "address", token.Hex(), "offset", "1")))
+	require.Len(t, first, 1)
+	assert.Equal(t, LogResult{
+		Address:          strings.ToLower(token.Hex()),
+		Topics:           []string{transfer.Hex(), from.Hex()},
+		Data:             "0x01",
+		BlockNumber:      "0x1",
+		BlockHash:        testdb.BlockHash(1).Hex(),
+		TimeStamp:        "0x" + big.NewInt(testdb.Timestamp(1)).Text(16),
+		GasPrice:         "0x0",
+		GasUsed:          "0xc350",
+		LogIndex:         "0x0",
+		TransactionHash:  testdb.TransactionHash(1, 0).Hex(),
+		TransactionIndex: "0x0",
+	}, first[0])
+
+	for query, message := range map[string]string{
+		"topic0=0x1234":         "Error! topic0 must be a 0x prefixed 32 byte hash",
+		"topic0_1_opr=or":       "Error! only the and topic operator is supported",
+		"fromBlock=3&toBlock=2": "Error! from_block is after to_block",
+		"address=token":         "Error! Invalid address format",
+		"page=2&offset=501":     "Error! Result window is too large, PageNo x Offset size must be less than or equal to 1000",
+		"offset=1001":           "Error! Result window is too large, PageNo x Offset size must be less than or equal to 1000",
+	} {
+		assert.Equal(t, message, failure(t, c.get(mustParseQuery(t, "module=logs&action=getLogs&"+query))), query)
+	}
+}
+
+func TestRouter_GetBlockNoByTime(t *testing.T) {
+	c, db := newTestClient(t)
+	for number := int64(1); number <= 3; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number})
+	}
+
+	blockNumber := func(timestamp int64, closest string) envelope {
+		t.Helper()
+		return c.get(params("module", "block", "action", "getblocknobytime", "timestamp", big.NewInt(timestamp).String(), "closest", closest))
+	}
+
+	assert.Equal(t, "2", result[string](t, blockNumber(testdb.Timestamp(2), "before")))
+	assert.Equal(t, "2", result[string](t, blockNumber(testdb.Timestamp(2), "after")))
+	assert.Equal(t, "2", result[string](t, blockNumber(testdb.Timestamp(2)+1, "")))
+	assert.Equal(t, "3", result[string](t, blockNumber(testdb.Timestamp(2)+1, "after")))
+	assert.Equal(t, "3", result[string](t, blockNumber(testdb.Timestamp(3)+100, "before")))
+
+	assert.Equal(t, "Error! No closest block found", failure(t, blockNumber(testdb.Timestamp(1)-1, "before")))
+	assert.Equal(t, "Error! No closest block found", failure(t, blockNumber(testdb.Timestamp(3)+1, "after")))
+	assert.Equal(t, "Error! closest must be before or after", failure(t, blockNumber(testdb.Timestamp(1), "nearest")))
+	assert.Equal(t, "Error! Invalid timestamp", failure(t, c.get(params("module", "block", "action", "getblocknobytime"))))
+	assert.Equal(t, "Error! Invalid timestamp", failure(t, c.get(params("module", "block", "action", "getblocknobytime", "timestamp", "yesterday"))))
+}
+
+func TestRouter_GetABI(t *testing.T) {
+	c, db := newTestClient(t)
+	verified := common.HexToAddress("0xA1")
+	abi := `[{"type":"function","name":"totalSupply","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`
+	testdb.InsertSmartContract(t, db, testdb.SmartContract{Address: verified, Name: "Token", ABI: abi})
+
+	assert.Equal(t, abi, result[string](t, c.get(params("module", "contract", "action", "getabi", "address", verified.Hex()))))
+	assert.Equal(t, "Contract source code not verified",
+		failure(t, c.get(params("module", "contract", "action", "getabi", "address", common.HexToAddress("0xA2").Hex()))))
+	assert.Equal(t, "Error! Invalid address format", failure(t, c.get(params("module", "contract", "action", "getabi"))))
+}
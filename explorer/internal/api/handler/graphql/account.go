@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/graphql-go/graphql"
+)
+
+var directionEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "Direction",
+	Description: "The side of a transfer the account is on.",
+	Values: graphql.EnumValueConfigMap{
+		"IN":  &graphql.EnumValueConfig{Value: address.DirectionIn},
+		"OUT": &graphql.EnumValueConfig{Value: address.DirectionOut},
+	},
+})
+
+func (r *resolver) accountFields(t *types) graphql.Fields {
+	historyArgs := pageArgs(graphql.FieldConfigArgument{
+		"direction": &graphql.ArgumentConfig{Type: directionEnum, Description: "Both sides when unset."},
+	})
+
+	return graphql.Fields{
+		"address": field(nonNull(addressScalar), "", func(addr common.Address) interface{} { return addr }),
+		"transactionsCount": r.summaryField(nonNull(longScalar), "", func(s *address.Summary) interface{} {
+			return s.TransactionsCount
+		}),
+		"firstSeen": r.summaryField(longScalar, "The timestamp of the first transaction, null without any.", func(s *address.Summary) interface{} {
+			if s.FirstSeen == nil {
+				return nil
+			}
+			return *s.FirstSeen
+		}),
+		"lastSeen": r.summaryField(longScalar, "The timestamp of the last transaction, null without any.", func(s *address.Summary) interface{} {
+			if s.LastSeen == nil {
+				return nil
+			}
+			return *s.LastSeen
+		}),
+		"isContract": r.summaryField(nonNull(graphql.Boolean), "", func(s *address.Summary) interface{} {
+			return s.IsContract
+		}),
+		"tokenHoldingsCount": r.summaryField(nonNull(longScalar), "", func(s *address.Summary) interface{} {
+			return s.TokenHoldingsCount
+		}),
+		"token": &graphql.Field{
+			Type:        t.token,
+			Description: "The token deployed at the address, null when it is not a token.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).tokens.load(p.Context, p.Source.(common.Address))), nil
+			},
+		},
+		"transactions": &graphql.Field{
+			Type:        nonNull(t.transactionConnection),
+			Description: "The transactions sent or received, newest first.",
+			Args:        historyArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				first, after, err := page(p)
+				if err != nil {
+					return nil, err
+				}
+				direction, _ := p.Args["direction"].(address.Direction)
+
+				transactions, next, err := r.addressService.GetTransactions(p.Context, p.Source.(common.Address), direction, after, first)
+				if err != nil {
+					return nil, r.fail("Failed to get address transactions", err)
+				}
+				return &connection{nodes: transactions, next: next}, nil
+			},
+		},
+		"tokenTransfers": &graphql.Field{
+			Type:        nonNull(t.tokenTransferConnection),
+			Description: "The token transfers sent or received, newest first.",
+			Args:        historyArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				first, after, err := page(p)
+				if err != nil {
+					return nil, err
+				}
+				direction, _ := p.Args["direction"].(address.Direction)
+
+				transfers, next, err := r.addressService.GetTokenTransfers(p.Context, p.Source.(common.Address), direction, after, first)
+				if err != nil {
+					return nil, r.fail("Failed to get address token transfers", err)
+				}
+				return &connection{nodes: transfers, next: next}, nil
+			},
+		},
+		"logs": &graphql.Field{
+			Type:        nonNull(t.logConnection),
+			Description: "The logs the address emitted, newest first.",
+			Args:        pageArgs(nil),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				first, after, err := page(p)
+				if err != nil {
+					return nil, err
+				}
+
+				logs, next, err := r.addressService.GetLogs(p.Context, p.Source.(common.Address), after, first)
+				if err != nil {
+					return nil, r.fail("Failed to get address logs", err)
+				}
+				return &connection{nodes: logs, next: next}, nil
+			},
+		},
+		"withdrawals": &graphql.Field{
+			Type:        nonNull(t.withdrawalConnection),
+			Description: "The withdrawals to the address, newest first.",
+			Args:        pageArgs(nil),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				first, after, err := page(p)
+				if err != nil {
+					return nil, err
+				}
+
+				withdrawals, next, err := r.addressService.GetWithdrawals(p.Context, p.Source.(common.Address), after, first)
+				if err != nil {
+					return nil, r.fail("Failed to get address withdrawals", err)
+				}
+				return &connection{nodes: withdrawals, next: next}, nil
+			},
+		},
+	}
+}
+
+// summaryField is a field of the summary of the account, read once for every account of a level
+func (r *resolver) summaryField(fieldType graphql.Output, description string, get func(*address.Summary) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type:        fieldType,
+		Description: description,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			summary := loadersFrom(p.Context).summaries.load(p.Context, p.Source.(common.Address))
+			return func() (interface{}, error) {
+				s, err := summary()
+				if err != nil {
+					return nil, errInternal
+				}
+				if s == nil {
+					return nil, nil
+				}
+				return get(s), nil
+			}, nil
+		},
+	}
+}
+
+func (r *resolver) withdrawalFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"index":          field(nonNull(longScalar), "", func(w *withdrawal.Withdrawal) interface{} { return w.Index }),
+		"validatorIndex": field(nonNull(longScalar), "", func(w *withdrawal.Withdrawal) interface{} { return w.ValidatorIndex }),
+		"account":        field(nonNull(t.account), "The recipient.", func(w *withdrawal.Withdrawal) interface{} { return w.AddressHash }),
+		"amount":         field(nonNull(longScalar), "In Gwei.", func(w *withdrawal.Withdrawal) interface{} { return w.Amount }),
+		"blockHash":      field(nonNull(bytes32Scalar), "", func(w *withdrawal.Withdrawal) interface{} { return w.BlockHash }),
+		"block": &graphql.Field{
+			Type: nonNull(t.block),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).blocks.load(p.Context, p.Source.(*withdrawal.Withdrawal).BlockHash)), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/graphql-go/graphql"
+)
+
+func (r *resolver) blockFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"hash":       field(nonNull(bytes32Scalar), "", func(b *block.Block) interface{} { return b.Hash }),
+		"number":     field(nonNull(bigIntScalar), "", func(b *block.Block) interface{} { return b.Number }),
+		"parentHash": field(nonNull(bytes32Scalar), "", func(b *block.Block) interface{} { return b.ParentHash }),
+		"parent": &graphql.Field{
+			Type:        t.block,
+			Description: "Null for the genesis block.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				b := p.Source.(*block.Block)
+				if (*big.Int)(&b.Number).Sign() == 0 {
+					return nil, nil
+				}
+				return thunk(loadersFrom(p.Context).blocks.load(p.Context, b.ParentHash)), nil
+			},
+		},
+		"miner":         field(nonNull(t.account), "", func(b *block.Block) interface{} { return b.MinerHash }),
+		"gasLimit":      field(nonNull(longScalar), "", func(b *block.Block) interface{} { return b.GasLimit }),
+		"gasUsed":       field(nonNull(longScalar), "", func(b *block.Block) interface{} { return b.GasUsed }),
+		"nonce":         field(nonNull(longScalar), "", func(b *block.Block) interface{} { return b.Nonce }),
+		"size":          field(nonNull(longScalar), "", func(b *block.Block) interface{} { return b.Size }),
+		"difficulty":    field(nonNull(bigIntScalar), "", func(b *block.Block) interface{} { return b.Difficulty }),
+		"isPos":         field(nonNull(graphql.Boolean), "", func(b *block.Block) interface{} { return b.IsPos }),
+		"baseFeePerGas": field(nonNull(bigIntScalar), "Zero before London.", func(b *block.Block) interface{} { return b.BaseFeePerGas }),
+		"timestamp":     field(nonNull(longScalar), "", func(b *block.Block) interface{} { return b.Timestamp }),
+		"transactionsRoot": field(bytes32Scalar, "Null for blocks indexed before it was stored.", func(b *block.Block) interface{} {
+			return b.TransactionsRoot
+		}),
+		"receiptsRoot": field(bytes32Scalar, "Null for blocks indexed before it was stored.", func(b *block.Block) interface{} {
+			return b.ReceiptsRoot
+		}),
+		"logsBloom": field(bytesScalar, "Null for blocks indexed before it was stored.", func(b *block.Block) interface{} {
+			if b.LogsBloom == nil {
+				return nil
+			}
+			return b.LogsBloom
+		}),
+		"transactionsCount": field(nonNull(graphql.Int), "", func(b *block.Block) interface{} { return b.TransactionsCount }),
+		"withdrawalsCount":  field(nonNull(graphql.Int), "", func(b *block.Block) interface{} { return b.WithdrawalsCount }),
+		"transactions": &graphql.Field{
+			Type:        nonNull(t.transactionConnection),
+			Description: "The transactions of the block by index.",
+			Args:        pageArgs(nil),
+			Resolve:     r.resolveBlockTransactions,
+		},
+		"withdrawals": &graphql.Field{
+			Type:        listOf(t.withdrawal),
+			Description: "The withdrawals of the block by index, a block holds at most 16.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				b := p.Source.(*block.Block)
+				if b.WithdrawalsCount == 0 {
+					return []*withdrawal.Withdrawal{}, nil
+				}
+				return thunk(loadersFrom(p.Context).blockWithdrawals.load(p.Context, b.Hash)), nil
+			},
+		},
+	}
+}
+
+// resolveBlockTransactions batches the first pages of the blocks of a level, later pages are read one
+// block at a time
+func (r *resolver) resolveBlockTransactions(p graphql.ResolveParams) (interface{}, error) {
+	b := p.Source.(*block.Block)
+	first, after, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if after != "" {
+		transactions, next, err := r.blockService.GetBlockTransactions(p.Context, service.BlockID{Hash: b.Hash}, after, first)
+		if errors.Is(err, service.ErrBlockNotFound) {
+			return &connection{nodes: transactions}, nil
+		}
+		if err != nil {
+			return nil, r.fail("Failed to get block transactions", err)
+		}
+		return &connection{nodes: transactions, next: next}, nil
+	}
+	if b.TransactionsCount == 0 {
+		return &connection{nodes: []interface{}{}}, nil
+	}
+
+	get := loadersFrom(p.Context).blockTransactions.load(p.Context, blockTransactionsKey{Hash: b.Hash, Limit: first})
+	return func() (interface{}, error) {
+		firstPage, err := get()
+		if err != nil {
+			return nil, errInternal
+		}
+		if firstPage == nil {
+			return &connection{nodes: []interface{}{}}, nil
+		}
+		return &connection{nodes: firstPage.Transactions, next: firstPage.Next}, nil
+	}, nil
+}
@@ -0,0 +1,177 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Limits of a query, checked before it runs. Every field costs one and the fields under a connection
+// cost first times as much, under any other list listComplexity times as much. Introspection is free,
+// the schema bounds it.
+const (
+	MaxQueryComplexity = 25000
+	MaxQueryDepth      = 12
+	listComplexity     = 10
+)
+
+// checkComplexity refuses the operation of doc that would run over MaxQueryComplexity or MaxQueryDepth.
+// doc is valid, an operation the executor would not select is left to it.
+func checkComplexity(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	var operation *ast.OperationDefinition
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		switch d := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				operation = d
+			}
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		}
+	}
+	if operation == nil || operation.Operation != ast.OperationTypeQuery {
+		return nil
+	}
+
+	c := &complexity{schema: schema, fragments: fragments, variables: make(map[string]interface{})}
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			c.variables[definition.Variable.Name.Value] = definition.DefaultValue
+		}
+	}
+	for name, value := range variables {
+		c.variables[name] = value
+	}
+
+	cost, depth := c.selectionSet(operation.SelectionSet, schema.QueryType())
+	if depth > MaxQueryDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, MaxQueryDepth)
+	}
+	if cost > MaxQueryComplexity {
+		return fmt.Errorf("query complexity exceeds the limit of %d", MaxQueryComplexity)
+	}
+
+	return nil
+}
+
+type complexity struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	// variables hold the request values, or the ast.Value defaults of the operation
+	variables map[string]interface{}
+}
+
+// selectionSet returns the cost of set on parent and its depth in fields. Costs saturate just over
+// MaxQueryComplexity so deep queries do not overflow.
+func (c *complexity) selectionSet(set *ast.SelectionSet, parent *graphql.Object) (int, int) {
+	if set == nil || parent == nil {
+		return 0, 0
+	}
+
+	cost, depth := 0, 0
+	for _, selection := range set.Selections {
+		var selectionCost, selectionDepth int
+		switch s := selection.(type) {
+		case *ast.Field:
+			selectionCost, selectionDepth = c.field(s, parent)
+		case *ast.InlineFragment:
+			selectionCost, selectionDepth = c.selectionSet(s.SelectionSet, c.object(s.TypeCondition, parent))
+		case *ast.FragmentSpread:
+			if fragment, ok := c.fragments[s.Name.Value]; ok {
+				selectionCost, selectionDepth = c.selectionSet(fragment.SelectionSet, c.object(fragment.TypeCondition, parent))
+			}
+		}
+		cost = min(cost+selectionCost, MaxQueryComplexity+1)
+		depth = max(depth, selectionDepth)
+	}
+
+	return cost, depth
+}
+
+func (c *complexity) field(f *ast.Field, parent *graphql.Object) (int, int) {
+	if strings.HasPrefix(f.Name.Value, "__") {
+		return 0, 0
+	}
+	definition, ok := parent.Fields()[f.Name.Value]
+	if !ok {
+		return 0, 0
+	}
+
+	child, _ := graphql.GetNamed(definition.Type).(*graphql.Object)
+	childCost, childDepth := c.selectionSet(f.SelectionSet, child)
+
+	return min(1+c.multiplier(f, definition, parent)*childCost, MaxQueryComplexity+1), 1 + childDepth
+}
+
+// multiplier is how many times the children of f are resolved at most
+func (c *complexity) multiplier(f *ast.Field, definition *graphql.FieldDefinition, parent *graphql.Object) int {
+	for _, arg := range definition.Args {
+		if arg.Name() == "first" {
+			return c.first(f)
+		}
+	}
+
+	fieldType := definition.Type
+	if nonNull, ok := fieldType.(*graphql.NonNull); ok {
+		fieldType = nonNull.OfType
+	}
+	// the nodes of a connection are counted by the first argument of the connection
+	if _, ok := fieldType.(*graphql.List); ok && !strings.HasSuffix(parent.Name(), "Connection") {
+		return listComplexity
+	}
+
+	return 1
+}
+
+// first reads the page size of f, resolvers refuse values out of range so those are counted as the largest page
+func (c *complexity) first(f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+
+		value := interface{}(arg.Value)
+		if variable, ok := arg.Value.(*ast.Variable); ok {
+			value = c.variables[variable.Name.Value]
+		}
+
+		var first int64
+		var err error
+		switch v := value.(type) {
+		case *ast.IntValue:
+			first, err = strconv.ParseInt(v.Value, 10, 64)
+		case float64:
+			first = int64(v)
+		case int:
+			first = int64(v)
+		case json.Number:
+			first, err = v.Int64()
+		case nil:
+			return service.DefaultPageSize
+		default:
+			return service.MaxPageSize
+		}
+		if err != nil || first < 1 || first > service.MaxPageSize {
+			return service.MaxPageSize
+		}
+		return int(first)
+	}
+
+	return service.DefaultPageSize
+}
+
+// object is the type a fragment applies to, parent when it has no type condition
+func (c *complexity) object(condition *ast.Named, parent *graphql.Object) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	object, _ := c.schema.Type(condition.Name.Value).(*graphql.Object)
+
+	return object
+}
@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// complexityOf validates query against schema and checks its complexity
+func complexityOf(t *testing.T, schema *graphql.Schema, query, operationName string, variables map[string]interface{}) error {
+	t.Helper()
+
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	require.NoError(t, err)
+	validation := graphql.ValidateDocument(schema, doc, nil)
+	require.True(t, validation.IsValid, "%v", validation.Errors)
+
+	return checkComplexity(schema, doc, operationName, variables)
+}
+
+// nestedParents selects the number of a block through depth levels of parents
+func nestedParents(depth int) string {
+	return "{ block { " + strings.Repeat("parent { ", depth) + "number" + strings.Repeat(" }", depth) + " } }"
+}
+
+func TestCheckComplexity(t *testing.T) {
+	schema, err := newSchema(&resolver{log: zap.NewNop()})
+	require.NoError(t, err)
+
+	const (
+		// 1 + 100 x (1 + (1 + 100 x (1 + 1 + 1))) = 20201
+		pages = `{ blocks(first: 100) { nodes { transactions(first: 100) { nodes { hash } } } } }`
+		// the logs of a transaction are a list counted 10 times, 1 + 10 x 1302 = 13021 with first 10
+		logs = `query Logs($first: Int) { blocks(first: $first) { nodes { transactions(first: 100) { nodes { hash logs { index } } } } } }`
+	)
+
+	for name, test := range map[string]struct {
+		query         string
+		operationName string
+		variables     map[string]interface{}
+		err           string
+	}{
+		"connections by first":    {query: pages},
+		"lists under connections": {query: logs, variables: map[string]interface{}{"first": 10}},
+		"variable from JSON":      {query: logs, variables: map[string]interface{}{"first": json.Number("10")}},
+		"variable over the limit": {query: logs, variables: map[string]interface{}{"first": 20}, err: "query complexity exceeds the limit of 25000"},
+		"missing variable":        {query: logs, err: "query complexity exceeds the limit of 25000"},
+		"default over the limit":  {query: strings.Replace(logs, "$first: Int", "$first: Int = 20", 1), err: "query complexity exceeds the limit of 25000"},
+		"default under the limit": {query: strings.Replace(logs, "$first: Int", "$first: Int = 10", 1)},
+		"first out of range":      {query: strings.Replace(pages, "first: 100) { nodes { hash", "first: 1000) { nodes { hash logs { index }", 1), err: "query complexity exceeds the limit of 25000"},
+		"aliases add up":          {query: `{ a: blocks(first: 100) { nodes { transactions(first: 100) { nodes { hash } } } } b: blocks(first: 100) { nodes { transactions(first: 100) { nodes { hash } } } } }`, err: "query complexity exceeds the limit of 25000"},
+		"fragments count":         {query: `query { blocks(first: 100) { ...page } } fragment page on BlockConnection { nodes { transactions(first: 100) { nodes { hash logs { index } } } } }`, err: "query complexity exceeds the limit of 25000"},
+		"inline fragments count":  {query: `{ blocks(first: 100) { nodes { ... on Block { transactions(first: 100) { nodes { hash logs { index } } } } } } }`, err: "query complexity exceeds the limit of 25000"},
+		"the selected operation":  {query: logs + ` query Small { blocks(first: 1) { nodes { number } } }`, operationName: "Small"},
+		"the other operation":     {query: logs + ` query Small { blocks(first: 1) { nodes { number } } }`, operationName: "Logs", variables: map[string]interface{}{"first": 100}, err: "query complexity exceeds the limit of 25000"},
+		"depth at the limit":      {query: nestedParents(MaxQueryDepth - 2)},
+		"depth over the limit":    {query: nestedParents(MaxQueryDepth - 1), err: "query depth 13 exceeds the limit of 12"},
+		"introspection is free":   {query: `{ __schema { types { name fields { name args { name type { name ofType { name ofType { name ofType { name } } } } } } } } }`},
+		"nested pages saturate":   {query: `{ blocks(first: 100) { nodes { transactions(first: 100) { nodes { block { transactions(first: 100) { nodes { block { transactions(first: 100) { nodes { hash } } } } } } } } } } }`, err: "query complexity exceeds the limit of 25000"},
+	} {
+		err := complexityOf(t, &schema, test.query, test.operationName, test.variables)
+		if test.err == "" {
+			assert.NoError(t, err, name)
+		} else {
+			assert.EqualError(t, err, test.err, name)
+		}
+	}
+}
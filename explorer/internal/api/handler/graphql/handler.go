@@ -0,0 +1,140 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"go.uber.org/zap"
+)
+
+// MaxRequestSize caps the body of a posted query
+const MaxRequestSize = 1 << 20
+
+var errEmptyQuery = errors.New("query is required")
+
+// Handler serves GraphQL queries posted as JSON or sent as GET parameters, mutations and subscriptions
+// are not supported. Requests that cannot run are answered with 400, errors of a query that ran are
+// reported next to its data with 200.
+type Handler struct {
+	schema         graphql.Schema
+	graphqlService *service.GraphQLService
+	log            *zap.Logger
+}
+
+func NewHandler(
+	blockService *service.BlockService,
+	transactionService *service.TransactionService,
+	addressService *service.AddressService,
+	logService *service.LogService,
+	tokenService *service.TokenService,
+	graphqlService *service.GraphQLService,
+	log *zap.Logger,
+) (*Handler, error) {
+	schema, err := newSchema(&resolver{
+		blockService:       blockService,
+		transactionService: transactionService,
+		addressService:     addressService,
+		logService:         logService,
+		tokenService:       tokenService,
+		graphqlService:     graphqlService,
+		log:                log,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{schema: schema, graphqlService: graphqlService, log: log}, nil
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	status := h.serve(w, r)
+
+	metrics.RequestDuration.
+		WithLabelValues("/graphql", r.Method, strconv.Itoa(status)).
+		Observe(time.Since(start).Seconds())
+}
+
+// serve answers r and returns the status it was answered with
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) int {
+	req, err := readRequest(w, r)
+	if err != nil {
+		return h.write(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return h.write(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+
+	validation := graphql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		return h.write(w, http.StatusBadRequest, &graphql.Result{Errors: validation.Errors})
+	}
+
+	if err := checkComplexity(&h.schema, doc, req.OperationName, req.Variables); err != nil {
+		return h.write(w, http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(r.Context(), newLoaders(h.graphqlService)),
+	})
+
+	return h.write(w, http.StatusOK, result)
+}
+
+// readRequest reads the query from the JSON body of a POST or the parameters of a GET
+func readRequest(w http.ResponseWriter, r *http.Request) (*request, error) {
+	req := &request{}
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, errors.New("variables must be a JSON object")
+			}
+		}
+	} else {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestSize)).Decode(req); err != nil {
+			return nil, errors.New("body must be a JSON object with a query")
+		}
+	}
+
+	if req.Query == "" {
+		return nil, errEmptyQuery
+	}
+
+	return req, nil
+}
+
+func (h *Handler) write(w http.ResponseWriter, status int, result *graphql.Result) int {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.log.Error("Failed to encode GraphQL response", zap.Error(err))
+	}
+
+	return status
+}
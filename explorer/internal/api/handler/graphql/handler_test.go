@@ -0,0 +1,293 @@
+package graphql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestHandler(t *testing.T, db *sql.DB) *Handler {
+	t.Helper()
+
+	log := zap.NewNop()
+	blockRepository := repository.NewBlockRepository(db, log)
+	transactionRepository := repository.NewTransactionRepository(db, log)
+	withdrawalRepository := repository.NewWithdrawalRepository(db)
+	internalTransactionRepository := repository.NewInternalTransactionRepository(db)
+	tokenRepository := repository.NewSQLiteTokenRepository(db)
+	addressRepository := repository.NewSQLiteAddressRepository(db)
+	smartContractRepository := repository.NewSmartContractRepository(db)
+
+	h, err := NewHandler(
+		service.NewBlockService(blockRepository, transactionRepository, withdrawalRepository, log),
+		service.NewTransactionService(transactionRepository, internalTransactionRepository, tokenRepository, smartContractRepository, log),
+		service.NewAddressService(addressRepository, transactionRepository, internalTransactionRepository, tokenRepository, withdrawalRepository,
+			repository.NewRewardRepository(db), smartContractRepository, log),
+		service.NewLogService(transactionRepository, smartContractRepository, log),
+		service.NewTokenService(tokenRepository, log),
+		service.NewGraphQLService(blockRepository, transactionRepository, withdrawalRepository, tokenRepository, addressRepository, smartContractRepository, log),
+		log,
+	)
+	require.NoError(t, err)
+
+	return h
+}
+
+// insertChain stores blocks 0 to 3, the others with two transactions of two logs each and block 2 with a withdrawal
+func insertChain(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	testdb.InsertBlock(t, db, testdb.Block{Number: 0})
+	for number := int64(1); number <= 3; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number, TransactionsCount: 2})
+		for index := 0; index < 2; index++ {
+			testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: number, Index: index})
+			for logIndex := 0; logIndex < 2; logIndex++ {
+				testdb.InsertLog(t, db, testdb.Log{BlockNumber: number, TransactionIndex: index, Index: index*2 + logIndex, Address: common.HexToAddress("0x0a")})
+			}
+		}
+	}
+	testdb.InsertWithdrawal(t, db, 2, 7, common.HexToAddress("0x0b"), 32)
+	_, err := db.Exec(`update block set withdrawals_count = 1 where number = 2`)
+	require.NoError(t, err)
+}
+
+// countBatches records the keys of every fetch of l
+func countBatches[K comparable, V any](l *loader[K, V]) *[][]K {
+	batches := &[][]K{}
+	fetch := l.fetch
+	l.fetch = func(ctx context.Context, keys []K) (map[K]V, error) {
+		*batches = append(*batches, append([]K(nil), keys...))
+		return fetch(ctx, keys)
+	}
+
+	return batches
+}
+
+// TestHandler_Batching checks each level of a nested query reads every row it needs with one fetch per loader
+func TestHandler_Batching(t *testing.T) {
+	db := testdb.Open(t)
+	insertChain(t, db)
+	h := newTestHandler(t, db)
+
+	l := newLoaders(h.graphqlService)
+	blocks := countBatches(l.blocks)
+	blockTransactions := countBatches(l.blockTransactions)
+	blockWithdrawals := countBatches(l.blockWithdrawals)
+	transactions := countBatches(l.transactions)
+	transactionLogs := countBatches(l.transactionLogs)
+
+	result := graphql.Do(graphql.Params{
+		Schema: h.schema,
+		RequestString: `{
+			blocks(first: 3, filter: {fromNumber: "1"}) {
+				nodes {
+					number
+					parent { number }
+					withdrawals { index }
+					transactions(first: 2) { nodes { index logs { index transaction { index } block { number } } } }
+				}
+			}
+		}`,
+		Context: withLoaders(context.Background(), l),
+	})
+	require.Empty(t, result.Errors)
+
+	hash, txHash := testdb.BlockHash, testdb.TransactionHash
+	// the parents of every block at once, then the one block of the logs the parents did not cache
+	assert.Equal(t, [][]common.Hash{{hash(0), hash(1), hash(2)}, {hash(3)}}, *blocks)
+	assert.Equal(t, [][]blockTransactionsKey{{{Hash: hash(1), Limit: 2}, {Hash: hash(2), Limit: 2}, {Hash: hash(3), Limit: 2}}}, *blockTransactions)
+	// blocks without withdrawals are not looked up
+	assert.Equal(t, [][]common.Hash{{hash(2)}}, *blockWithdrawals)
+	all := []common.Hash{txHash(1, 0), txHash(1, 1), txHash(2, 0), txHash(2, 1), txHash(3, 0), txHash(3, 1)}
+	assert.Equal(t, [][]common.Hash{all}, *transactionLogs)
+	assert.Equal(t, [][]common.Hash{all}, *transactions)
+
+	var data struct {
+		Blocks struct {
+			Nodes []struct {
+				Number       string
+				Parent       struct{ Number string }
+				Withdrawals  []struct{ Index uint64 }
+				Transactions struct {
+					Nodes []struct {
+						Index int
+						Logs  []struct {
+							Index       int
+							Transaction struct{ Index int }
+							Block       struct{ Number string }
+						}
+					}
+				}
+			}
+		}
+	}
+	raw, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &data))
+
+	// every thunk got its own row back from the batches
+	require.Len(t, data.Blocks.Nodes, 3)
+	for i, b := range data.Blocks.Nodes {
+		assert.Equal(t, strconv.Itoa(i+1), b.Number)
+		assert.Equal(t, strconv.Itoa(i), b.Parent.Number)
+		require.Len(t, b.Transactions.Nodes, 2)
+		for index, tx := range b.Transactions.Nodes {
+			assert.Equal(t, index, tx.Index)
+			require.Len(t, tx.Logs, 2)
+			for logIndex, l := range tx.Logs {
+				assert.Equal(t, index*2+logIndex, l.Index)
+				assert.Equal(t, index, l.Transaction.Index)
+				assert.Equal(t, b.Number, l.Block.Number)
+			}
+		}
+	}
+	assert.Empty(t, data.Blocks.Nodes[0].Withdrawals)
+	assert.Equal(t, []struct{ Index uint64 }{{Index: 7}}, data.Blocks.Nodes[1].Withdrawals)
+}
+
+// response is a GraphQL response with its data left undecoded
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func serve(t *testing.T, h *Handler, r *http.Request) (int, response) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, r)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var decoded response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded), recorder.Body.String())
+	return recorder.Code, decoded
+}
+
+func post(t *testing.T, h *Handler, body string) (int, response) {
+	t.Helper()
+	return serve(t, h, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+}
+
+func messages(r response) []string {
+	result := make([]string, len(r.Errors))
+	for i, err := range r.Errors {
+		result[i] = err.Message
+	}
+	return result
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	db := testdb.Open(t)
+	insertChain(t, db)
+	h := newTestHandler(t, db)
+
+	status, r := post(t, h, `{"query": "query Block($number: BigInt) { block(number: $number) { number transactionsCount } }", "variables": {"number": 2}}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, r.Errors)
+	assert.JSONEq(t, `{"block": {"number": "2", "transactionsCount": 2}}`, string(r.Data))
+
+	query := url.Values{
+		"query":         {`query A { block(number: "1") { number } } query B($n: BigInt) { block(number: $n) { number } }`},
+		"operationName": {"B"},
+		"variables":     {`{"n": "3"}`},
+	}
+	status, r = serve(t, h, httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil))
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"block": {"number": "3"}}`, string(r.Data))
+
+	// requests that cannot run are answered with 400 and no data
+	for body, message := range map[string]string{
+		`not json`:                 "body must be a JSON object with a query",
+		`{"query": ""}`:            "query is required",
+		`{"query": "{ block { "}`:  "Syntax Error GraphQL request (1:11) Expected Name, found EOF\n\n1: { block { \n             ^\n",
+		`{"query": "{ blocks }"}`:  `Field "blocks" of type "BlockConnection!" must have a sub selection.`,
+		`{"query": "{ unknown }"}`: `Cannot query field "unknown" on type "Query".`,
+		`{"query": "` + nestedParents(MaxQueryDepth-1) + `"}`:                                                          "query depth 13 exceeds the limit of 12",
+		`{"query": "{ blocks(first: 100) { nodes { transactions(first: 100) { nodes { hash logs { index } } } } } }"}`: "query complexity exceeds the limit of 25000",
+	} {
+		status, r := post(t, h, body)
+		assert.Equal(t, http.StatusBadRequest, status, body)
+		assert.Equal(t, []string{message}, messages(r), body)
+		assert.Equal(t, "null", string(r.Data), body)
+	}
+	status, r = serve(t, h, httptest.NewRequest(http.MethodGet, "/graphql?query=%7B+block+%7B+number+%7D+%7D&variables=%5B%5D", nil))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []string{"variables must be a JSON object"}, messages(r))
+
+	// errors of a query that ran come with the rest of its data
+	status, r = post(t, h, `{"query": "{ a: block(number: 1) { number } b: block(number: 2, hash: \"0x0000000000000000000000000000000000000000000000000000000000000001\") { number } }"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"invalid query: number excludes hash"}, messages(r))
+	assert.JSONEq(t, `{"a": {"number": "1"}, "b": null}`, string(r.Data))
+}
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("failed")
+	var batches [][]int
+	l := newLoader(func(_ context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		if keys[0] == 9 {
+			return nil, failure
+		}
+		result := make(map[int]string)
+		for _, key := range keys {
+			if key != 3 {
+				result[key] = strconv.Itoa(key)
+			}
+		}
+		return result, nil
+	})
+
+	// keys queued before the first thunk runs are fetched together, once each
+	one, two, again, missing := l.load(ctx, 1), l.load(ctx, 2), l.load(ctx, 1), l.load(ctx, 3)
+	for key, get := range map[string]func() (string, error){"1": one, "2": two, "again": again, "3": missing} {
+		value, err := get()
+		require.NoError(t, err)
+		if key == "again" {
+			key = "1"
+		} else if key == "3" {
+			key = ""
+		}
+		assert.Equal(t, key, value)
+	}
+	assert.Equal(t, [][]int{{1, 2, 3}}, batches)
+
+	// cached keys, found or not, are not fetched again
+	value, err := l.load(ctx, 2)()
+	require.NoError(t, err)
+	assert.Equal(t, "2", value)
+	_, err = l.load(ctx, 3)()
+	require.NoError(t, err)
+	assert.Len(t, batches, 1)
+
+	// a failed fetch fails every key of its batch and is not retried
+	failed, other := l.load(ctx, 9), l.load(ctx, 10)
+	_, err = failed()
+	assert.ErrorIs(t, err, failure)
+	_, err = other()
+	assert.ErrorIs(t, err, failure)
+	_, err = l.load(ctx, 9)()
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, [][]int{{1, 2, 3}, {9, 10}}, batches)
+}
@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// loader batches the lookups of one request. Resolvers queue their key and return a thunk, the executor
+// calls the thunks of a level of the query after every resolver of the level ran, so the first thunk
+// called fetches every key queued until then at once. Results are cached for the rest of the request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		queued:  make(map[K]bool),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// load queues key and returns the thunk resolving it, to the zero value when nothing matches key
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	_, done := l.results[key]
+	if !done && l.errs[key] == nil && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.queued[key] {
+			l.flush(ctx)
+		}
+
+		return l.results[key], l.errs[key]
+	}
+}
+
+// flush fetches the pending keys, l.mu is held
+func (l *loader[K, V]) flush(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	for _, key := range keys {
+		delete(l.queued, key)
+	}
+
+	results, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.results[key] = results[key]
+	}
+}
+
+// blockTransactionsKey selects the first Limit transactions of a block
+type blockTransactionsKey struct {
+	Hash  common.Hash
+	Limit int
+}
+
+// loaders are the loaders of one request
+type loaders struct {
+	blocks            *loader[common.Hash, *block.Block]
+	blockTransactions *loader[blockTransactionsKey, *service.TransactionPage]
+	blockWithdrawals  *loader[common.Hash, []*withdrawal.Withdrawal]
+	transactions      *loader[common.Hash, *transaction.Transaction]
+	transactionLogs   *loader[common.Hash, []*transaction.DecodedLog]
+	tokens            *loader[common.Address, *token.Token]
+	tokenCounts       *loader[*token.Token, *service.TokenCounts]
+	summaries         *loader[common.Address, *address.Summary]
+}
+
+func newLoaders(graphqlService *service.GraphQLService) *loaders {
+	return &loaders{
+		blocks:            newLoader(graphqlService.GetBlocksByHash),
+		blockTransactions: newLoader(blockTransactionsFetch(graphqlService)),
+		blockWithdrawals:  newLoader(graphqlService.GetBlocksWithdrawals),
+		transactions:      newLoader(graphqlService.GetTransactionsByHash),
+		transactionLogs:   newLoader(graphqlService.GetTransactionsLogs),
+		tokens:            newLoader(graphqlService.GetTokensByAddress),
+		tokenCounts: newLoader(func(ctx context.Context, tokens []*token.Token) (map[*token.Token]*service.TokenCounts, error) {
+			counts, err := graphqlService.GetTokensCounts(ctx, tokens)
+			if err != nil {
+				return nil, err
+			}
+
+			result := make(map[*token.Token]*service.TokenCounts, len(tokens))
+			for _, t := range tokens {
+				result[t] = counts[t.Address]
+			}
+			return result, nil
+		}),
+		summaries: newLoader(graphqlService.GetSummaries),
+	}
+}
+
+// blockTransactionsFetch reads the first pages of the blocks in one query per page size, aliases may
+// ask for pages of different sizes
+func blockTransactionsFetch(graphqlService *service.GraphQLService) func(context.Context, []blockTransactionsKey) (map[blockTransactionsKey]*service.TransactionPage, error) {
+	return func(ctx context.Context, keys []blockTransactionsKey) (map[blockTransactionsKey]*service.TransactionPage, error) {
+		byLimit := make(map[int][]common.Hash)
+		for _, key := range keys {
+			byLimit[key.Limit] = append(byLimit[key.Limit], key.Hash)
+		}
+
+		result := make(map[blockTransactionsKey]*service.TransactionPage, len(keys))
+		for limit, hashes := range byLimit {
+			pages, err := graphqlService.GetBlocksTransactions(ctx, hashes, limit)
+			if err != nil {
+				return nil, err
+			}
+			for hash, page := range pages {
+				result[blockTransactionsKey{Hash: hash, Limit: limit}] = page
+			}
+		}
+
+		return result, nil
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
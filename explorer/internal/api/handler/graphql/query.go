@@ -0,0 +1,282 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/graphql-go/graphql"
+)
+
+var (
+	blockSortFieldEnum = graphql.NewEnum(graphql.EnumConfig{
+		Name: "BlockSortField",
+		Values: graphql.EnumValueConfigMap{
+			"NUMBER":             &graphql.EnumValueConfig{Value: block.SortByNumber},
+			"GAS_USED":           &graphql.EnumValueConfig{Value: block.SortByGasUsed},
+			"TRANSACTIONS_COUNT": &graphql.EnumValueConfig{Value: block.SortByTransactionsCount},
+		},
+	})
+
+	blockFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "BlockFilter",
+		Description: "Selects blocks, every bound is inclusive and unset bounds are open.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"fromNumber":    &graphql.InputObjectFieldConfig{Type: bigIntScalar},
+			"toNumber":      &graphql.InputObjectFieldConfig{Type: bigIntScalar},
+			"fromTimestamp": &graphql.InputObjectFieldConfig{Type: longScalar},
+			"toTimestamp":   &graphql.InputObjectFieldConfig{Type: longScalar},
+			"sortBy":        &graphql.InputObjectFieldConfig{Type: blockSortFieldEnum, Description: "NUMBER by default, ties are broken by number."},
+			"descending":    &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+
+	transactionFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "TransactionFilter",
+		Description: "Selects transactions, unset fields match every transaction.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"blockNumber":    &graphql.InputObjectFieldConfig{Type: bigIntScalar},
+			"blockHash":      &graphql.InputObjectFieldConfig{Type: bytes32Scalar},
+			"from":           &graphql.InputObjectFieldConfig{Type: addressScalar},
+			"to":             &graphql.InputObjectFieldConfig{Type: addressScalar},
+			"status":         &graphql.InputObjectFieldConfig{Type: longScalar},
+			"methodSelector": &graphql.InputObjectFieldConfig{Type: bytesScalar, Description: "The first four bytes of the input."},
+			"descending":     &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+
+	logFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "LogFilter",
+		Description: "Selects logs like eth_getLogs does: a log matches when one of addresses emitted it, any address " +
+			"when unset, and for every position of topics with a non empty list its topic at that position is in the list.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"addresses":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(addressScalar))},
+			"topics":     &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewList(graphql.NewNonNull(bytes32Scalar)))},
+			"fromBlock":  &graphql.InputObjectFieldConfig{Type: bigIntScalar},
+			"toBlock":    &graphql.InputObjectFieldConfig{Type: bigIntScalar},
+			"blockHash":  &graphql.InputObjectFieldConfig{Type: bytes32Scalar, Description: "Excludes fromBlock and toBlock."},
+			"descending": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		},
+	})
+)
+
+func (r *resolver) queryFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"block": &graphql.Field{
+			Type:        t.block,
+			Description: "The block with number or hash, the newest indexed block when neither is set.",
+			Args: graphql.FieldConfigArgument{
+				"number": &graphql.ArgumentConfig{Type: bigIntScalar},
+				"hash":   &graphql.ArgumentConfig{Type: bytes32Scalar},
+			},
+			Resolve: r.resolveBlock,
+		},
+		"blocks": &graphql.Field{
+			Type: nonNull(t.blockConnection),
+			Args: pageArgs(graphql.FieldConfigArgument{
+				"filter": &graphql.ArgumentConfig{Type: blockFilterInput},
+			}),
+			Resolve: r.resolveBlocks,
+		},
+		"transaction": &graphql.Field{
+			Type: t.transaction,
+			Args: graphql.FieldConfigArgument{
+				"hash": &graphql.ArgumentConfig{Type: nonNull(bytes32Scalar)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).transactions.load(p.Context, p.Args["hash"].(common.Hash))), nil
+			},
+		},
+		"transactions": &graphql.Field{
+			Type:        nonNull(t.transactionConnection),
+			Description: "Transactions ordered by block number and index.",
+			Args: pageArgs(graphql.FieldConfigArgument{
+				"filter": &graphql.ArgumentConfig{Type: transactionFilterInput},
+			}),
+			Resolve: r.resolveTransactions,
+		},
+		"logs": &graphql.Field{
+			Type:        nonNull(t.logConnection),
+			Description: "Logs ordered by block number, transaction index and log index.",
+			Args: pageArgs(graphql.FieldConfigArgument{
+				"filter": &graphql.ArgumentConfig{Type: logFilterInput},
+			}),
+			Resolve: r.resolveLogs,
+		},
+		"token": &graphql.Field{
+			Type: t.token,
+			Args: graphql.FieldConfigArgument{
+				"address": &graphql.ArgumentConfig{Type: nonNull(addressScalar)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).tokens.load(p.Context, p.Args["address"].(common.Address))), nil
+			},
+		},
+		"tokens": &graphql.Field{
+			Type:        nonNull(t.tokenConnection),
+			Description: "Tokens ordered by name.",
+			Args: pageArgs(graphql.FieldConfigArgument{
+				"query": &graphql.ArgumentConfig{Type: graphql.String, Description: "Matches the symbol or the name case insensitively."},
+				"type":  &graphql.ArgumentConfig{Type: graphql.String, Description: "ERC-20, ERC-721 or ERC-1155."},
+			}),
+			Resolve: r.resolveTokens,
+		},
+		"account": &graphql.Field{
+			Type: nonNull(t.account),
+			Args: graphql.FieldConfigArgument{
+				"address": &graphql.ArgumentConfig{Type: nonNull(addressScalar)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Args["address"].(common.Address), nil
+			},
+		},
+	}
+}
+
+func (r *resolver) resolveBlock(p graphql.ResolveParams) (interface{}, error) {
+	number, hasNumber := p.Args["number"].(*domain.BigInt)
+	hash, hasHash := p.Args["hash"].(common.Hash)
+
+	switch {
+	case hasNumber && hasHash:
+		return nil, fmt.Errorf("%w: number excludes hash", service.ErrInvalidQuery)
+	case hasHash:
+		return thunk(loadersFrom(p.Context).blocks.load(p.Context, hash)), nil
+	case hasNumber:
+		b, err := r.blockService.GetBlock(p.Context, service.BlockID{Number: number})
+		if errors.Is(err, service.ErrBlockNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, r.fail("Failed to get block", err)
+		}
+		return b, nil
+	default:
+		b, err := r.blockService.GetCurrentBlock()
+		if err != nil {
+			return nil, r.fail("Failed to get current block", err)
+		}
+		return b, nil
+	}
+}
+
+func (r *resolver) resolveBlocks(p graphql.ResolveParams) (interface{}, error) {
+	first, after, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := block.Filter{Limit: first}
+	if args, ok := p.Args["filter"].(map[string]interface{}); ok {
+		filter.FromNumber, _ = args["fromNumber"].(*domain.BigInt)
+		filter.ToNumber, _ = args["toNumber"].(*domain.BigInt)
+		if value, ok := args["fromTimestamp"].(uint64); ok {
+			filter.FromTimestamp = &value
+		}
+		if value, ok := args["toTimestamp"].(uint64); ok {
+			filter.ToTimestamp = &value
+		}
+		filter.SortBy, _ = args["sortBy"].(block.SortField)
+		filter.Descending, _ = args["descending"].(bool)
+	}
+
+	blocks, next, err := r.blockService.GetBlocks(p.Context, filter, after)
+	if err != nil {
+		return nil, r.fail("Failed to get blocks", err)
+	}
+
+	return &connection{nodes: blocks, next: next}, nil
+}
+
+func (r *resolver) resolveTransactions(p graphql.ResolveParams) (interface{}, error) {
+	first, after, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := transaction.Filter{Limit: first}
+	if args, ok := p.Args["filter"].(map[string]interface{}); ok {
+		filter.BlockNumber, _ = args["blockNumber"].(*domain.BigInt)
+		if value, ok := args["blockHash"].(common.Hash); ok {
+			filter.BlockHash = &value
+		}
+		if value, ok := args["from"].(common.Address); ok {
+			filter.From = &value
+		}
+		if value, ok := args["to"].(common.Address); ok {
+			filter.To = &value
+		}
+		if value, ok := args["status"].(uint64); ok {
+			filter.Status = &value
+		}
+		filter.MethodSelector, _ = args["methodSelector"].([]byte)
+		filter.Descending, _ = args["descending"].(bool)
+	}
+
+	transactions, next, err := r.transactionService.GetTransactions(p.Context, filter, after)
+	if err != nil {
+		return nil, r.fail("Failed to get transactions", err)
+	}
+
+	return &connection{nodes: transactions, next: next}, nil
+}
+
+func (r *resolver) resolveLogs(p graphql.ResolveParams) (interface{}, error) {
+	first, after, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := transaction.LogFilter{Limit: first}
+	if args, ok := p.Args["filter"].(map[string]interface{}); ok {
+		addresses, _ := args["addresses"].([]interface{})
+		for _, value := range addresses {
+			filter.Addresses = append(filter.Addresses, value.(common.Address))
+		}
+		positions, _ := args["topics"].([]interface{})
+		for _, position := range positions {
+			values, _ := position.([]interface{})
+			var topics []common.Hash
+			for _, value := range values {
+				topics = append(topics, value.(common.Hash))
+			}
+			filter.Topics = append(filter.Topics, topics)
+		}
+		filter.FromBlock, _ = args["fromBlock"].(*domain.BigInt)
+		filter.ToBlock, _ = args["toBlock"].(*domain.BigInt)
+		if value, ok := args["blockHash"].(common.Hash); ok {
+			filter.BlockHash = &value
+		}
+		filter.Descending, _ = args["descending"].(bool)
+	}
+
+	logs, next, err := r.logService.GetLogs(p.Context, filter, after)
+	if err != nil {
+		return nil, r.fail("Failed to get logs", err)
+	}
+
+	return &connection{nodes: logs, next: next}, nil
+}
+
+func (r *resolver) resolveTokens(p graphql.ResolveParams) (interface{}, error) {
+	first, after, err := page(p)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := token.Filter{Limit: first}
+	filter.Query, _ = p.Args["query"].(string)
+	filter.Type, _ = p.Args["type"].(string)
+
+	tokens, next, err := r.tokenService.GetTokens(p.Context, filter, after)
+	if err != nil {
+		return nil, r.fail("Failed to get tokens", err)
+	}
+
+	return &connection{nodes: tokens, next: next}, nil
+}
@@ -0,0 +1,226 @@
+package graphql
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Scalars of the schema. Invalid input is reported by the executor as a value of the wrong type, parse
+// functions return nil for it.
+var (
+	bigIntScalar = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "BigInt",
+		Description: "An arbitrary precision integer, serialized as a decimal string. Input may be a string or an integer.",
+		Serialize: func(value interface{}) interface{} {
+			switch v := value.(type) {
+			case domain.BigInt:
+				return v.String()
+			case *domain.BigInt:
+				return v.String()
+			}
+			return nil
+		},
+		ParseValue: func(value interface{}) interface{} {
+			switch v := value.(type) {
+			case string:
+				return parseBigInt(v)
+			case json.Number:
+				return parseBigInt(v.String())
+			case float64:
+				if v != math.Trunc(v) {
+					return nil
+				}
+				number, _ := big.NewFloat(v).Int(nil)
+				return (*domain.BigInt)(number)
+			case int:
+				return (*domain.BigInt)(big.NewInt(int64(v)))
+			}
+			return nil
+		},
+		ParseLiteral: func(value ast.Value) interface{} {
+			switch v := value.(type) {
+			case *ast.StringValue:
+				return parseBigInt(v.Value)
+			case *ast.IntValue:
+				return parseBigInt(v.Value)
+			}
+			return nil
+		},
+	})
+
+	longScalar = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "Long",
+		Description: "An unsigned 64 bit integer, serialized as a number.",
+		Serialize: func(value interface{}) interface{} {
+			switch v := value.(type) {
+			case uint64:
+				return v
+			case int64:
+				return v
+			case int:
+				return v
+			case uint:
+				return v
+			case uint8:
+				return v
+			}
+			return nil
+		},
+		ParseValue: func(value interface{}) interface{} {
+			switch v := value.(type) {
+			case string:
+				return parseLong(v)
+			case json.Number:
+				return parseLong(v.String())
+			case float64:
+				if v < 0 || v != math.Trunc(v) || v > math.MaxUint64 {
+					return nil
+				}
+				return uint64(v)
+			case int:
+				if v < 0 {
+					return nil
+				}
+				return uint64(v)
+			}
+			return nil
+		},
+		ParseLiteral: func(value ast.Value) interface{} {
+			switch v := value.(type) {
+			case *ast.StringValue:
+				return parseLong(v.Value)
+			case *ast.IntValue:
+				return parseLong(v.Value)
+			}
+			return nil
+		},
+	})
+
+	bytesScalar = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "Bytes",
+		Description: "Arbitrary length binary data, serialized as a 0x prefixed hex string.",
+		Serialize: func(value interface{}) interface{} {
+			if v, ok := value.([]byte); ok {
+				return hexutil.Encode(v)
+			}
+			return nil
+		},
+		ParseValue: func(value interface{}) interface{} {
+			if v, ok := value.(string); ok {
+				return parseBytes(v)
+			}
+			return nil
+		},
+		ParseLiteral: func(value ast.Value) interface{} {
+			if v, ok := value.(*ast.StringValue); ok {
+				return parseBytes(v.Value)
+			}
+			return nil
+		},
+	})
+
+	bytes32Scalar = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "Bytes32",
+		Description: "A 32 byte hash, serialized as a 0x prefixed hex string.",
+		Serialize: func(value interface{}) interface{} {
+			switch v := value.(type) {
+			case common.Hash:
+				return v.Hex()
+			case *common.Hash:
+				return v.Hex()
+			}
+			return nil
+		},
+		ParseValue: func(value interface{}) interface{} {
+			if v, ok := value.(string); ok {
+				return parseHash(v)
+			}
+			return nil
+		},
+		ParseLiteral: func(value ast.Value) interface{} {
+			if v, ok := value.(*ast.StringValue); ok {
+				return parseHash(v.Value)
+			}
+			return nil
+		},
+	})
+
+	addressScalar = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "Address",
+		Description: "A 20 byte address, serialized as a checksummed 0x prefixed hex string.",
+		Serialize: func(value interface{}) interface{} {
+			switch v := value.(type) {
+			case common.Address:
+				return v.Hex()
+			case *common.Address:
+				return v.Hex()
+			}
+			return nil
+		},
+		ParseValue: func(value interface{}) interface{} {
+			if v, ok := value.(string); ok {
+				return parseAddress(v)
+			}
+			return nil
+		},
+		ParseLiteral: func(value ast.Value) interface{} {
+			if v, ok := value.(*ast.StringValue); ok {
+				return parseAddress(v.Value)
+			}
+			return nil
+		},
+	})
+)
+
+func parseBigInt(s string) interface{} {
+	number, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil
+	}
+
+	return (*domain.BigInt)(number)
+}
+
+func parseLong(s string) interface{} {
+	value, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return value
+}
+
+func parseBytes(s string) interface{} {
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func parseHash(s string) interface{} {
+	data, err := hexutil.Decode(s)
+	if err != nil || len(data) != common.HashLength {
+		return nil
+	}
+
+	return common.BytesToHash(data)
+}
+
+func parseAddress(s string) interface{} {
+	data, err := hexutil.Decode(s)
+	if err != nil || len(data) != common.AddressLength {
+		return nil
+	}
+
+	return common.BytesToAddress(data)
+}
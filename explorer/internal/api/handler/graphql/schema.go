@@ -0,0 +1,208 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/graphql-go/graphql"
+	"go.uber.org/zap"
+)
+
+var errInternal = errors.New("internal error")
+
+// resolver holds what the resolvers of the schema read from
+type resolver struct {
+	blockService       *service.BlockService
+	transactionService *service.TransactionService
+	addressService     *service.AddressService
+	logService         *service.LogService
+	tokenService       *service.TokenService
+	graphqlService     *service.GraphQLService
+	log                *zap.Logger
+}
+
+// fail words service errors for clients and hides the rest
+func (r *resolver) fail(message string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery), errors.Is(err, service.ErrInvalidBlockID),
+		errors.Is(err, service.ErrInvalidAddress):
+		return err
+	default:
+		r.log.Error(message, zap.Error(err))
+		return errInternal
+	}
+}
+
+// types are the object types of the schema, built lazily as they refer to each other
+type types struct {
+	block         *graphql.Object
+	transaction   *graphql.Object
+	fee           *graphql.Object
+	log           *graphql.Object
+	event         *graphql.Object
+	eventParam    *graphql.Object
+	withdrawal    *graphql.Object
+	account       *graphql.Object
+	token         *graphql.Object
+	tokenTransfer *graphql.Object
+	holder        *graphql.Object
+	pageInfo      *graphql.Object
+
+	blockConnection         *graphql.Object
+	transactionConnection   *graphql.Object
+	logConnection           *graphql.Object
+	withdrawalConnection    *graphql.Object
+	tokenConnection         *graphql.Object
+	tokenTransferConnection *graphql.Object
+	holderConnection        *graphql.Object
+}
+
+func newSchema(r *resolver) (graphql.Schema, error) {
+	t := &types{}
+	object := func(name, description string, fields func(*types) graphql.Fields) *graphql.Object {
+		return graphql.NewObject(graphql.ObjectConfig{
+			Name:        name,
+			Description: description,
+			Fields:      graphql.FieldsThunk(func() graphql.Fields { return fields(t) }),
+		})
+	}
+
+	t.block = object("Block", "A block of the canonical chain.", r.blockFields)
+	t.transaction = object("Transaction", "A mined transaction.", r.transactionFields)
+	t.fee = object("Fee", "What the sender of a transaction paid, blob gas is not included.", feeFields)
+	t.log = object("Log", "A log emitted by a transaction.", r.logFields)
+	t.event = object("Event", "A log decoded with the ABI of its contract or of a token standard.", eventFields)
+	t.eventParam = object("EventParam", "A decoded event argument, indexed dynamic values are only their hash.", eventParamFields)
+	t.withdrawal = object("Withdrawal", "A validator withdrawal.", r.withdrawalFields)
+	t.account = object("Account", "An address and its history, native balances are not indexed.", r.accountFields)
+	t.token = object("Token", "An ERC-20, ERC-721 or ERC-1155 token.", r.tokenFields)
+	t.tokenTransfer = object("TokenTransfer", "A transfer of a token.", r.tokenTransferFields)
+	t.holder = object("Holder", "An address holding a token.", holderFields)
+	t.pageInfo = graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"endCursor": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Continues the listing when passed as after, null on the last page.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if next := p.Source.(*connection).next; next != "" {
+						return next, nil
+					}
+					return nil, nil
+				},
+			},
+			"hasNextPage": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).next != "", nil
+				},
+			},
+		},
+	})
+
+	t.blockConnection = t.connection("BlockConnection", t.block)
+	t.transactionConnection = t.connection("TransactionConnection", t.transaction)
+	t.logConnection = t.connection("LogConnection", t.log)
+	t.withdrawalConnection = t.connection("WithdrawalConnection", t.withdrawal)
+	t.tokenConnection = t.connection("TokenConnection", t.token)
+	t.tokenTransferConnection = t.connection("TokenTransferConnection", t.tokenTransfer)
+	t.holderConnection = t.connection("HolderConnection", t.holder)
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: graphql.FieldsThunk(func() graphql.Fields { return r.queryFields(t) }),
+		}),
+	})
+}
+
+// connection is a page of a listing, nodes is a slice of the node type
+type connection struct {
+	nodes interface{}
+	next  string
+}
+
+// connection builds the type of a page of node, a connection's nodes are counted by its first argument
+func (t *types) connection(name string, node *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"nodes": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(node))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).nodes, nil
+				},
+			},
+			"pageInfo": &graphql.Field{
+				Type: graphql.NewNonNull(t.pageInfo),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+}
+
+// pageArgs are the arguments of a connection field, with extra ones
+func pageArgs(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{
+			Type:        graphql.Int,
+			Description: fmt.Sprintf("Page size, %d by default and at most %d.", service.DefaultPageSize, service.MaxPageSize),
+		},
+		"after": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "The endCursor of the previous page.",
+		},
+	}
+	for name, arg := range extra {
+		args[name] = arg
+	}
+
+	return args
+}
+
+// page reads the arguments of a connection field
+func page(p graphql.ResolveParams) (first int, after string, err error) {
+	first = service.DefaultPageSize
+	if value, ok := p.Args["first"].(int); ok {
+		if value < 1 || value > service.MaxPageSize {
+			return 0, "", fmt.Errorf("%w: first must be between 1 and %d", service.ErrInvalidQuery, service.MaxPageSize)
+		}
+		first = value
+	}
+	after, _ = p.Args["after"].(string)
+
+	return first, after, nil
+}
+
+// field is a field read from the source of type S without a query
+func field[S any](fieldType graphql.Output, description string, get func(S) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type:        fieldType,
+		Description: description,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(S)), nil
+		},
+	}
+}
+
+// thunk defers get to the executor, which calls it once every resolver of the level queued its key
+func thunk[V any](get func() (V, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		value, err := get()
+		if err != nil {
+			return nil, errInternal
+		}
+		return value, nil
+	}
+}
+
+func nonNull(t graphql.Output) graphql.Output {
+	return graphql.NewNonNull(t)
+}
+
+func listOf(t graphql.Output) graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"errors"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/graphql-go/graphql"
+)
+
+func (r *resolver) tokenFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"address":  field(nonNull(addressScalar), "", func(tk *token.Token) interface{} { return tk.Address }),
+		"type":     field(nonNull(graphql.String), "ERC-20, ERC-721 or ERC-1155.", func(tk *token.Token) interface{} { return tk.Type }),
+		"name":     field(nonNull(graphql.String), "", func(tk *token.Token) interface{} { return tk.Name }),
+		"symbol":   field(nonNull(graphql.String), "", func(tk *token.Token) interface{} { return tk.Symbol }),
+		"decimals": field(nonNull(graphql.Int), "", func(tk *token.Token) interface{} { return tk.Decimals }),
+		"totalSupply": field(bigIntScalar, "Null until core has set it.", func(tk *token.Token) interface{} {
+			return tk.TotalSupply
+		}),
+		"holdersCount": r.countsField("Addresses with a positive balance.", func(c *service.TokenCounts) interface{} {
+			return c.HoldersCount
+		}),
+		"transfersCount": r.countsField("", func(c *service.TokenCounts) interface{} {
+			return c.TransfersCount
+		}),
+		"holders": &graphql.Field{
+			Type:        nonNull(t.holderConnection),
+			Description: "The holders of the token by balance, largest first.",
+			Args:        pageArgs(nil),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				first, after, err := page(p)
+				if err != nil {
+					return nil, err
+				}
+
+				_, holders, next, err := r.tokenService.GetTokenHolders(p.Context, p.Source.(*token.Token).Address, after, first)
+				if errors.Is(err, service.ErrTokenNotFound) {
+					return &connection{nodes: holders}, nil
+				}
+				if err != nil {
+					return nil, r.fail("Failed to get token holders", err)
+				}
+				return &connection{nodes: holders, next: next}, nil
+			},
+		},
+		"transfers": &graphql.Field{
+			Type:        nonNull(t.tokenTransferConnection),
+			Description: "The transfers of the token, newest first.",
+			Args:        pageArgs(nil),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				first, after, err := page(p)
+				if err != nil {
+					return nil, err
+				}
+
+				_, transfers, next, err := r.tokenService.GetTokenTransfers(p.Context, p.Source.(*token.Token).Address, after, first)
+				if errors.Is(err, service.ErrTokenNotFound) {
+					return &connection{nodes: transfers}, nil
+				}
+				if err != nil {
+					return nil, r.fail("Failed to get token transfers", err)
+				}
+				return &connection{nodes: transfers, next: next}, nil
+			},
+		},
+	}
+}
+
+// countsField is a count of the activity of the token, counted once for every token of a level
+func (r *resolver) countsField(description string, get func(*service.TokenCounts) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type:        nonNull(longScalar),
+		Description: description,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			counts := loadersFrom(p.Context).tokenCounts.load(p.Context, p.Source.(*token.Token))
+			return func() (interface{}, error) {
+				c, err := counts()
+				if err != nil {
+					return nil, errInternal
+				}
+				return get(c), nil
+			}, nil
+		},
+	}
+}
+
+func (r *resolver) tokenTransferFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"transactionHash": field(nonNull(bytes32Scalar), "", func(tt *token.TokenTransfer) interface{} { return tt.TransactionHash }),
+		"transaction": &graphql.Field{
+			Type: nonNull(t.transaction),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).transactions.load(p.Context, p.Source.(*token.TokenTransfer).TransactionHash)), nil
+			},
+		},
+		"blockNumber": field(nonNull(bigIntScalar), "", func(tt *token.TokenTransfer) interface{} { return tt.BlockNumber }),
+		"timestamp":   field(nonNull(longScalar), "", func(tt *token.TokenTransfer) interface{} { return tt.Timestamp }),
+		"logIndex":    field(nonNull(graphql.Int), "", func(tt *token.TokenTransfer) interface{} { return tt.LogIndex }),
+		"from":        field(nonNull(t.account), "", func(tt *token.TokenTransfer) interface{} { return tt.From }),
+		"to":          field(nonNull(t.account), "", func(tt *token.TokenTransfer) interface{} { return tt.To }),
+		"contractAddress": field(nonNull(addressScalar), "", func(tt *token.TokenTransfer) interface{} {
+			return tt.TokenContractAddress
+		}),
+		"token": &graphql.Field{
+			Type:        t.token,
+			Description: "Null when the contract is not an indexed token.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				transfer := p.Source.(*token.TokenTransfer)
+				if transfer.Token != nil {
+					return transfer.Token, nil
+				}
+				return thunk(loadersFrom(p.Context).tokens.load(p.Context, transfer.TokenContractAddress)), nil
+			},
+		},
+		"amount": field(nonNull(bigIntScalar), "In the smallest unit of the token.", func(tt *token.TokenTransfer) interface{} {
+			return tt.Amount
+		}),
+		"tokenId": field(bigIntScalar, "Null for ERC-20 transfers.", func(tt *token.TokenTransfer) interface{} {
+			return tt.TokenId
+		}),
+	}
+}
+
+func holderFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"account": field(nonNull(t.account), "", func(h *token.Holder) interface{} { return h.Address }),
+		"balance": field(nonNull(bigIntScalar), "The net of the transfers, or the instances owned for ERC-721 and ERC-1155.", func(h *token.Holder) interface{} {
+			return h.Balance
+		}),
+	}
+}
@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/graphql-go/graphql"
+)
+
+func (r *resolver) transactionFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"hash":        field(nonNull(bytes32Scalar), "", func(tx *transaction.Transaction) interface{} { return tx.Hash }),
+		"blockHash":   field(nonNull(bytes32Scalar), "", func(tx *transaction.Transaction) interface{} { return tx.BlockHash }),
+		"blockNumber": field(nonNull(bigIntScalar), "", func(tx *transaction.Transaction) interface{} { return tx.BlockNumber }),
+		"block": &graphql.Field{
+			Type: nonNull(t.block),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).blocks.load(p.Context, p.Source.(*transaction.Transaction).BlockHash)), nil
+			},
+		},
+		"index":             field(nonNull(graphql.Int), "", func(tx *transaction.Transaction) interface{} { return tx.Index }),
+		"type":              field(nonNull(graphql.Int), "", func(tx *transaction.Transaction) interface{} { return int(tx.Type) }),
+		"status":            field(nonNull(longScalar), "1 for success, 0 for failure.", func(tx *transaction.Transaction) interface{} { return tx.Status }),
+		"gas":               field(nonNull(longScalar), "", func(tx *transaction.Transaction) interface{} { return tx.Gas }),
+		"gasUsed":           field(nonNull(longScalar), "", func(tx *transaction.Transaction) interface{} { return tx.GasUsed }),
+		"cumulativeGasUsed": field(nonNull(longScalar), "", func(tx *transaction.Transaction) interface{} { return tx.CumulativeGasUsed }),
+		"input":             field(nonNull(bytesScalar), "", func(tx *transaction.Transaction) interface{} { return tx.Input }),
+		"value":             field(nonNull(bigIntScalar), "", func(tx *transaction.Transaction) interface{} { return tx.Value }),
+		"from":              field(nonNull(t.account), "", func(tx *transaction.Transaction) interface{} { return tx.From }),
+		"to": field(t.account, "Null for a contract creation.", func(tx *transaction.Transaction) interface{} {
+			if tx.To == (common.Address{}) {
+				return nil
+			}
+			return tx.To
+		}),
+		"createdContract": field(t.account, "The contract a contract creation deployed, null for other transactions.", func(tx *transaction.Transaction) interface{} {
+			if tx.To != (common.Address{}) {
+				return nil
+			}
+			return crypto.CreateAddress(tx.From, tx.Nonce)
+		}),
+		"nonce":     field(nonNull(longScalar), "", func(tx *transaction.Transaction) interface{} { return tx.Nonce }),
+		"timestamp": field(nonNull(longScalar), "", func(tx *transaction.Transaction) interface{} { return tx.Timestamp }),
+		"logsCount": field(nonNull(graphql.Int), "", func(tx *transaction.Transaction) interface{} { return tx.LogsCount }),
+		"raw": field(bytesScalar, "The consensus encoding, null for transactions indexed before it was stored.", func(tx *transaction.Transaction) interface{} {
+			if tx.Raw == nil {
+				return nil
+			}
+			return tx.Raw
+		}),
+		"fee": field(t.fee, "Null for transactions indexed without their encoding.", func(tx *transaction.Transaction) interface{} {
+			return r.graphqlService.GetFee(tx)
+		}),
+		"logs": &graphql.Field{
+			Type:        listOf(t.log),
+			Description: "The logs of the transaction by log index.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).transactionLogs.load(p.Context, p.Source.(*transaction.Transaction).Hash)), nil
+			},
+		},
+	}
+}
+
+func feeFields(*types) graphql.Fields {
+	return graphql.Fields{
+		"gasPrice": field(nonNull(bigIntScalar), "The effective gas price.", func(f *transaction.Fee) interface{} { return f.GasPrice }),
+		"maxFeePerGas": field(bigIntScalar, "Null for transactions before EIP-1559.", func(f *transaction.Fee) interface{} {
+			return f.MaxFeePerGas
+		}),
+		"maxPriorityFeePerGas": field(bigIntScalar, "Null for transactions before EIP-1559.", func(f *transaction.Fee) interface{} {
+			return f.MaxPriorityFeePerGas
+		}),
+		"baseFeePerGas": field(nonNull(bigIntScalar), "", func(f *transaction.Fee) interface{} { return f.BaseFeePerGas }),
+		"total":         field(nonNull(bigIntScalar), "", func(f *transaction.Fee) interface{} { return f.Total }),
+		"burnt":         field(nonNull(bigIntScalar), "Destroyed by the base fee.", func(f *transaction.Fee) interface{} { return f.Burnt }),
+		"priority":      field(nonNull(bigIntScalar), "Paid to the block producer.", func(f *transaction.Fee) interface{} { return f.Priority }),
+	}
+}
+
+func (r *resolver) logFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"index":   field(nonNull(graphql.Int), "", func(l *transaction.DecodedLog) interface{} { return int(l.Index) }),
+		"account": field(nonNull(t.account), "The contract that emitted the log.", func(l *transaction.DecodedLog) interface{} { return l.Address }),
+		"topics":  field(listOf(bytes32Scalar), "", func(l *transaction.DecodedLog) interface{} { return l.Topics }),
+		"data":    field(nonNull(bytesScalar), "", func(l *transaction.DecodedLog) interface{} { return l.Data }),
+		"transactionIndex": field(nonNull(graphql.Int), "", func(l *transaction.DecodedLog) interface{} {
+			return int(l.TransactionIndex)
+		}),
+		"transactionHash": field(nonNull(bytes32Scalar), "", func(l *transaction.DecodedLog) interface{} { return l.TransactionHash }),
+		"transaction": &graphql.Field{
+			Type: nonNull(t.transaction),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).transactions.load(p.Context, p.Source.(*transaction.DecodedLog).TransactionHash)), nil
+			},
+		},
+		"blockNumber": field(nonNull(bigIntScalar), "", func(l *transaction.DecodedLog) interface{} { return l.BlockNumber }),
+		"blockHash":   field(nonNull(bytes32Scalar), "", func(l *transaction.DecodedLog) interface{} { return l.BlockHash }),
+		"block": &graphql.Field{
+			Type: nonNull(t.block),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return thunk(loadersFrom(p.Context).blocks.load(p.Context, p.Source.(*transaction.DecodedLog).BlockHash)), nil
+			},
+		},
+		"event": field(t.event, "Null when no known ABI matches the log.", func(l *transaction.DecodedLog) interface{} {
+			return l.Event
+		}),
+	}
+}
+
+func eventFields(t *types) graphql.Fields {
+	return graphql.Fields{
+		"name":      field(nonNull(graphql.String), "", func(e *transaction.Event) interface{} { return e.Name }),
+		"signature": field(nonNull(graphql.String), "", func(e *transaction.Event) interface{} { return e.Signature }),
+		"params": field(listOf(t.eventParam), "", func(e *transaction.Event) interface{} {
+			params := make([]*transaction.EventParam, len(e.Params))
+			for i := range e.Params {
+				params[i] = &e.Params[i]
+			}
+			return params
+		}),
+	}
+}
+
+func eventParamFields(*types) graphql.Fields {
+	return graphql.Fields{
+		"name":    field(nonNull(graphql.String), "", func(p *transaction.EventParam) interface{} { return p.Name }),
+		"type":    field(nonNull(graphql.String), "", func(p *transaction.EventParam) interface{} { return p.Type }),
+		"indexed": field(nonNull(graphql.Boolean), "", func(p *transaction.EventParam) interface{} { return p.Indexed }),
+		"value":   field(nonNull(graphql.String), "Formatted as a string.", func(p *transaction.EventParam) interface{} { return p.Value }),
+	}
+}
@@ -2,9 +2,12 @@ package grpc
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/elmiringos/indexer/explorer/internal/api/pb"
 	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
 	"go.uber.org/zap"
 )
 
@@ -29,21 +32,104 @@ func (h *BlockHandler) GetCurrentBlock(ctx context.Context, req *pb.GetCurrentBl
 	if err != nil {
 		return nil, err
 	}
+	if block == nil {
+		return nil, serviceError(service.ErrBlockNotFound)
+	}
+
+	return &pb.GetCurrentBlockResponse{Block: mapBlock(block)}, nil
+}
+
+func (h *BlockHandler) GetBlock(ctx context.Context, req *pb.GetBlockRequest) (*pb.GetBlockResponse, error) {
+	var number *uint64
+	if n, ok := req.Identifier.(*pb.GetBlockRequest_Number); ok {
+		number = &n.Number
+	}
+
+	id, err := blockID(req.GetHash(), number)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := h.BlockService.GetBlock(ctx, id)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &pb.GetBlockResponse{Block: mapBlock(b)}, nil
+}
+
+func (h *BlockHandler) GetBlocks(ctx context.Context, req *pb.GetBlocksRequest) (*pb.GetBlocksResponse, error) {
+	filter := block.Filter{
+		FromTimestamp: req.FromTimestamp,
+		ToTimestamp:   req.ToTimestamp,
+		SortBy:        blockSortFields[req.SortBy],
+		Descending:    req.SortOrder == pb.SortOrder_DESC,
+		Limit:         int(min(req.Limit, service.MaxPageSize)),
+	}
+	if req.From != nil {
+		filter.FromNumber = (*domain.BigInt)(new(big.Int).SetUint64(*req.From))
+	}
+	if req.To != nil {
+		filter.ToNumber = (*domain.BigInt)(new(big.Int).SetUint64(*req.To))
+	}
+
+	blocks, next, err := h.BlockService.GetBlocks(ctx, filter, req.Cursor)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	response := &pb.GetBlocksResponse{Blocks: make([]*pb.Block, len(blocks)), NextCursor: next}
+	for i, b := range blocks {
+		response.Blocks[i] = mapBlock(b)
+	}
+
+	return response, nil
+}
+
+func (h *BlockHandler) GetBlockTransactions(ctx context.Context, req *pb.GetBlockTransactionsRequest) (*pb.GetBlockTransactionsResponse, error) {
+	var number *uint64
+	if n, ok := req.Identifier.(*pb.GetBlockTransactionsRequest_Number); ok {
+		number = &n.Number
+	}
+
+	id, err := blockID(req.GetHash(), number)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, next, err := h.BlockService.GetBlockTransactions(ctx, id, req.Cursor, int(min(req.Limit, service.MaxPageSize)))
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	response := &pb.GetBlockTransactionsResponse{Transactions: make([]*pb.Transaction, len(transactions)), NextCursor: next}
+	for i, t := range transactions {
+		response.Transactions[i] = mapTransaction(t)
+	}
+
+	return response, nil
+}
+
+func (h *BlockHandler) GetBlockWithdrawals(ctx context.Context, req *pb.GetBlockWithdrawalsRequest) (*pb.GetBlockWithdrawalsResponse, error) {
+	var number *uint64
+	if n, ok := req.Identifier.(*pb.GetBlockWithdrawalsRequest_Number); ok {
+		number = &n.Number
+	}
+
+	id, err := blockID(req.GetHash(), number)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals, err := h.BlockService.GetBlockWithdrawals(ctx, id)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	response := &pb.GetBlockWithdrawalsResponse{Withdrawals: make([]*pb.Withdrawal, len(withdrawals))}
+	for i, w := range withdrawals {
+		response.Withdrawals[i] = mapWithdrawal(w)
+	}
 
-	return &pb.GetCurrentBlockResponse{
-		Block: &pb.Block{
-			Hash:          block.Hash.String(),
-			Number:        block.Number.String(),
-			ParentHash:    block.ParentHash.String(),
-			MinerHash:     block.MinerHash.String(),
-			GasLimit:      block.GasLimit,
-			GasUsed:       block.GasUsed,
-			Nonce:         block.Nonce,
-			Size:          block.Size,
-			Difficulty:    block.Difficulty.String(),
-			IsPos:         block.IsPos,
-			BaseFeePerGas: block.BaseFeePerGas.String(),
-			Timestamp:     block.Timestamp,
-		},
-	}, nil
+	return response, nil
 }
@@ -0,0 +1,9 @@
+package grpc
+
+// ExplorerHandler implements the gRPC service by embedding a handler per domain, BlockHandler carries
+// the unimplemented defaults
+type ExplorerHandler struct {
+	*BlockHandler
+	*TransactionHandler
+	*LogHandler
+}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/pb"
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LogHandler implements the log search of the gRPC service
+type LogHandler struct {
+	LogService *service.LogService
+	log        *zap.Logger
+}
+
+func NewLogHandler(s *service.LogService, log *zap.Logger) *LogHandler {
+	return &LogHandler{
+		LogService: s,
+		log:        log,
+	}
+}
+
+func (h *LogHandler) GetLogs(ctx context.Context, req *pb.GetLogsRequest) (*pb.GetLogsResponse, error) {
+	filter := transaction.LogFilter{
+		Descending: req.SortOrder == pb.SortOrder_DESC,
+		Limit:      int(min(req.Limit, service.MaxPageSize)),
+	}
+
+	for _, raw := range req.Addresses {
+		if !common.IsHexAddress(raw) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid address %q", raw)
+		}
+		filter.Addresses = append(filter.Addresses, common.HexToAddress(raw))
+	}
+
+	for _, set := range req.Topics {
+		topics := make([]common.Hash, len(set.Topics))
+		for i, raw := range set.Topics {
+			topic, err := hexutil.Decode(raw)
+			if err != nil || len(topic) != common.HashLength {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid topic %q", raw)
+			}
+			topics[i] = common.BytesToHash(topic)
+		}
+		filter.Topics = append(filter.Topics, topics)
+	}
+
+	if req.FromBlock != nil {
+		filter.FromBlock = (*domain.BigInt)(new(big.Int).SetUint64(*req.FromBlock))
+	}
+	if req.ToBlock != nil {
+		filter.ToBlock = (*domain.BigInt)(new(big.Int).SetUint64(*req.ToBlock))
+	}
+	if req.BlockHash != "" {
+		id, err := blockID(req.BlockHash, nil)
+		if err != nil {
+			return nil, err
+		}
+		filter.BlockHash = &id.Hash
+	}
+
+	logs, next, err := h.LogService.GetLogs(ctx, filter, req.Cursor)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	response := &pb.GetLogsResponse{Logs: make([]*pb.Log, len(logs)), NextCursor: next}
+	for i, l := range logs {
+		response.Logs[i] = mapLog(l)
+	}
+
+	return response, nil
+}
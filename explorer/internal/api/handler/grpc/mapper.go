@@ -0,0 +1,180 @@
+package grpc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/pb"
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func mapBlock(b *block.Block) *pb.Block {
+	return &pb.Block{
+		Hash:              b.Hash.String(),
+		Number:            b.Number.String(),
+		ParentHash:        b.ParentHash.String(),
+		MinerHash:         b.MinerHash.String(),
+		GasLimit:          b.GasLimit,
+		GasUsed:           b.GasUsed,
+		Nonce:             b.Nonce,
+		Size:              b.Size,
+		Difficulty:        b.Difficulty.String(),
+		IsPos:             b.IsPos,
+		BaseFeePerGas:     b.BaseFeePerGas.String(),
+		TransactionsCount: int32(b.TransactionsCount),
+		WithdrawalsCount:  int32(b.WithdrawalsCount),
+		Timestamp:         b.Timestamp,
+	}
+}
+
+func mapTransaction(t *transaction.Transaction) *pb.Transaction {
+	return &pb.Transaction{
+		Hash:        t.Hash.String(),
+		BlockHash:   t.BlockHash.String(),
+		Index:       int32(t.Index),
+		Status:      t.Status,
+		Gas:         t.Gas,
+		GasUsed:     t.GasUsed,
+		Input:       domain.HexFromBinary(t.Input),
+		Value:       t.Value.String(),
+		From:        t.From.String(),
+		To:          t.To.String(),
+		Nonce:       t.Nonce,
+		Timestamp:   t.Timestamp,
+		BlockNumber: t.BlockNumber.String(),
+		Type:        uint32(t.Type),
+	}
+}
+
+func mapTransactionDetails(details *service.TransactionDetails) *pb.GetTransactionResponse {
+	response := &pb.GetTransactionResponse{
+		Transaction:          mapTransaction(details.Transaction),
+		Logs:                 make([]*pb.Log, len(details.Logs)),
+		TokenTransfers:       make([]*pb.TokenTransfer, len(details.TokenTransfers)),
+		InternalTransactions: make([]*pb.InternalTransaction, len(details.InternalTransactions)),
+	}
+
+	if fee := details.Fee; fee != nil {
+		response.Fee = &pb.TransactionFee{
+			GasPrice:      fee.GasPrice.String(),
+			BaseFeePerGas: fee.BaseFeePerGas.String(),
+			Total:         fee.Total.String(),
+			Burnt:         fee.Burnt.String(),
+			Priority:      fee.Priority.String(),
+		}
+		if fee.MaxFeePerGas != nil {
+			maxFee, maxPriorityFee := fee.MaxFeePerGas.String(), fee.MaxPriorityFeePerGas.String()
+			response.Fee.MaxFeePerGas, response.Fee.MaxPriorityFeePerGas = &maxFee, &maxPriorityFee
+		}
+	}
+
+	for i, l := range details.Logs {
+		response.Logs[i] = mapLog(l)
+	}
+
+	for i, t := range details.TokenTransfers {
+		transfer := &pb.TokenTransfer{
+			LogIndex:     int32(t.LogIndex),
+			From:         t.From.String(),
+			To:           t.To.String(),
+			TokenAddress: t.TokenContractAddress.String(),
+			Amount:       t.Amount.String(),
+		}
+		if t.Token != nil {
+			decimals := int32(t.Token.Decimals)
+			transfer.TokenName, transfer.TokenSymbol, transfer.TokenDecimals = &t.Token.Name, &t.Token.Symbol, &decimals
+		}
+		response.TokenTransfers[i] = transfer
+	}
+
+	for i, it := range details.InternalTransactions {
+		response.InternalTransactions[i] = &pb.InternalTransaction{
+			Index:           int32(it.Index),
+			Status:          int32(it.Status),
+			From:            it.From.String(),
+			To:              it.To.String(),
+			ContractAddress: it.ContractAddress.String(),
+			Value:           it.Value.String(),
+			Gas:             it.Gas,
+			GasUsed:         it.GasUsed,
+			Input:           domain.HexFromBinary(it.Input),
+			Output:          domain.HexFromBinary(it.Output),
+		}
+	}
+
+	return response
+}
+
+func mapWithdrawal(w *withdrawal.Withdrawal) *pb.Withdrawal {
+	return &pb.Withdrawal{
+		Index:          w.Index,
+		BlockHash:      w.BlockHash.String(),
+		Address:        w.AddressHash.String(),
+		ValidatorIndex: w.ValidatorIndex,
+		Amount:         w.Amount,
+	}
+}
+
+var blockSortFields = map[pb.BlockSortField]block.SortField{
+	pb.BlockSortField_BLOCK_SORT_FIELD_NUMBER:             block.SortByNumber,
+	pb.BlockSortField_BLOCK_SORT_FIELD_GAS_USED:           block.SortByGasUsed,
+	pb.BlockSortField_BLOCK_SORT_FIELD_TRANSACTIONS_COUNT: block.SortByTransactionsCount,
+}
+
+// blockID reads the hash or number identifier shared by the block requests
+func blockID(hash string, number *uint64) (service.BlockID, error) {
+	if number != nil {
+		return service.BlockID{Number: (*domain.BigInt)(new(big.Int).SetUint64(*number))}, nil
+	}
+	if hash == "" {
+		return service.BlockID{}, status.Error(codes.InvalidArgument, "block hash or number is required")
+	}
+
+	id, err := service.ParseBlockID(hash)
+	if err != nil || id.Number != nil {
+		return service.BlockID{}, status.Error(codes.InvalidArgument, "invalid block hash")
+	}
+
+	return id, nil
+}
+
+// serviceError maps the service errors callers can fix to their gRPC codes
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery), errors.Is(err, service.ErrInvalidBlockID), errors.Is(err, service.ErrInvalidTransactionHash),
+		errors.Is(err, service.ErrInvalidAddress):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrBlockNotFound), errors.Is(err, service.ErrTransactionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func mapLog(l *transaction.DecodedLog) *pb.Log {
+	log := &pb.Log{
+		Index:           uint32(l.Index),
+		Address:         l.Address.String(),
+		Topics:          make([]string, len(l.Topics)),
+		Data:            domain.HexFromBinary(l.Data),
+		TransactionHash: l.TransactionHash.String(),
+		BlockNumber:     l.BlockNumber.String(),
+	}
+	for i, topic := range l.Topics {
+		log.Topics[i] = topic.String()
+	}
+	if l.Event != nil {
+		log.Event = &pb.Event{Name: l.Event.Name, Signature: l.Event.Signature, Params: make([]*pb.EventParam, len(l.Event.Params))}
+		for i, param := range l.Event.Params {
+			log.Event.Params[i] = &pb.EventParam{Name: param.Name, Type: param.Type, Indexed: param.Indexed, Value: param.Value}
+		}
+	}
+
+	return log
+}
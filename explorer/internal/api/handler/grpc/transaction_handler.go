@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/pb"
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TransactionHandler implements the transaction methods of the gRPC service
+type TransactionHandler struct {
+	TransactionService *service.TransactionService
+	log                *zap.Logger
+}
+
+func NewTransactionHandler(s *service.TransactionService, log *zap.Logger) *TransactionHandler {
+	return &TransactionHandler{
+		TransactionService: s,
+		log:                log,
+	}
+}
+
+func (h *TransactionHandler) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
+	hash, err := service.ParseTransactionHash(req.Hash)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	details, err := h.TransactionService.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return mapTransactionDetails(details), nil
+}
+
+func (h *TransactionHandler) GetTransactions(ctx context.Context, req *pb.GetTransactionsRequest) (*pb.GetTransactionsResponse, error) {
+	filter := transaction.Filter{
+		Status:     req.Status,
+		Descending: req.SortOrder == pb.SortOrder_DESC,
+		Limit:      int(min(req.Limit, service.MaxPageSize)),
+	}
+
+	switch block := req.Block.(type) {
+	case *pb.GetTransactionsRequest_BlockNumber:
+		filter.BlockNumber = (*domain.BigInt)(new(big.Int).SetUint64(block.BlockNumber))
+	case *pb.GetTransactionsRequest_BlockHash:
+		id, err := blockID(block.BlockHash, nil)
+		if err != nil {
+			return nil, err
+		}
+		filter.BlockHash = &id.Hash
+	}
+
+	var err error
+	if filter.From, err = address("from", req.From); err != nil {
+		return nil, err
+	}
+	if filter.To, err = address("to", req.To); err != nil {
+		return nil, err
+	}
+
+	if req.Method != "" {
+		if filter.MethodSelector, err = hexutil.Decode(req.Method); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "method must be a 0x prefixed 4 byte selector")
+		}
+	}
+
+	transactions, next, err := h.TransactionService.GetTransactions(ctx, filter, req.Cursor)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	response := &pb.GetTransactionsResponse{Transactions: make([]*pb.Transaction, len(transactions)), NextCursor: next}
+	for i, t := range transactions {
+		response.Transactions[i] = mapTransaction(t)
+	}
+
+	return response, nil
+}
+
+// address reads an optional address field of a request
+func address(name string, value *string) (*common.Address, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if !common.IsHexAddress(*value) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s address", name)
+	}
+
+	a := common.HexToAddress(*value)
+	return &a, nil
+}
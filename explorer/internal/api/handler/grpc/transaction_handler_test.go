@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/pb"
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.GWei))
+}
+
+// TestTransactionHandler_GetTransaction checks the gRPC response carries the fee breakdown and decoded
+// events the service computes
+func TestTransactionHandler_GetTransaction(t *testing.T) {
+	db := testdb.Open(t)
+	h := NewTransactionHandler(service.NewTransactionService(
+		repository.NewTransactionRepository(db, zap.NewNop()),
+		repository.NewInternalTransactionRepository(db),
+		repository.NewSQLiteTokenRepository(db),
+		repository.NewSmartContractRepository(db),
+		zap.NewNop(),
+	), zap.NewNop())
+	ctx := context.Background()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)),
+		&types.DynamicFeeTx{ChainID: big.NewInt(1), GasTipCap: gwei(2), GasFeeCap: gwei(30), Gas: 21000})
+	require.NoError(t, err)
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	testdb.InsertBlock(t, db, testdb.Block{Number: 1, BaseFeePerGas: gwei(10).String(), TransactionsCount: 1})
+	hash := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1, GasUsed: 21000, Type: types.DynamicFeeTxType, Raw: raw})
+	from, to := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	testdb.InsertLog(t, db, testdb.Log{BlockNumber: 1, Address: common.HexToAddress("0x0a"),
+		Topics: []common.Hash{crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:   common.BigToHash(big.NewInt(5)).Bytes()})
+
+	response, err := h.GetTransaction(ctx, &pb.GetTransactionRequest{Hash: hash.Hex()})
+	require.NoError(t, err)
+
+	maxFee, maxPriorityFee := gwei(30).String(), gwei(2).String()
+	assert.Equal(t, &pb.TransactionFee{
+		GasPrice:             gwei(12).String(),
+		MaxFeePerGas:         &maxFee,
+		MaxPriorityFeePerGas: &maxPriorityFee,
+		BaseFeePerGas:        gwei(10).String(),
+		Total:                new(big.Int).Mul(gwei(12), big.NewInt(21000)).String(),
+		Burnt:                new(big.Int).Mul(gwei(10), big.NewInt(21000)).String(),
+		Priority:             new(big.Int).Mul(gwei(2), big.NewInt(21000)).String(),
+	}, response.Fee)
+
+	require.Len(t, response.Logs, 1)
+	assert.Equal(t, &pb.Event{Name: "Transfer", Signature: "Transfer(address,address,uint256)", Params: []*pb.EventParam{
+		{Name: "from", Type: "address", Indexed: true, Value: from.Hex()},
+		{Name: "to", Type: "address", Indexed: true, Value: to.Hex()},
+		{Name: "value", Type: "uint256", Value: "5"},
+	}}, response.Logs[0].Event)
+
+	_, err = h.GetTransaction(ctx, &pb.GetTransactionRequest{Hash: "0x1234"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = h.GetTransaction(ctx, &pb.GetTransactionRequest{Hash: testdb.TransactionHash(1, 9).Hex()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
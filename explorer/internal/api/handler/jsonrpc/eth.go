@@ -0,0 +1,323 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+var (
+	errChainIDUnknown = errors.New("chain id is not configured")
+	errUnknownBlock   = errors.New("unknown block")
+	errInternal       = errors.New("internal error")
+)
+
+// invalidParamsError is reported with the JSON-RPC invalid params code
+type invalidParamsError struct {
+	message string
+}
+
+func (e *invalidParamsError) Error() string  { return e.message }
+func (e *invalidParamsError) ErrorCode() int { return -32602 }
+
+// EthAPI answers the eth namespace from the index. Latest and pending name the newest indexed block,
+// earliest is block 0 and safe and finalized are not tracked.
+type EthAPI struct {
+	rpcService *service.RPCService
+	upstream   *rpc.Client
+	// chainID is configured, or read from the upstream on first use when it is not
+	chainID atomic.Pointer[big.Int]
+	log     *zap.Logger
+}
+
+func newEthAPI(rpcService *service.RPCService, upstream *rpc.Client, chainID uint64, log *zap.Logger) *EthAPI {
+	api := &EthAPI{rpcService: rpcService, upstream: upstream, log: log}
+	if chainID != 0 {
+		api.chainID.Store(new(big.Int).SetUint64(chainID))
+	}
+
+	return api
+}
+
+// rpcError words service errors for clients and hides the rest
+func (api *EthAPI) rpcError(message string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery), errors.Is(err, service.ErrInvalidAddress):
+		return &invalidParamsError{message: strings.TrimPrefix(err.Error(), service.ErrInvalidQuery.Error()+": ")}
+	case errors.Is(err, service.ErrBlockNotFound):
+		return errUnknownBlock
+	default:
+		api.log.Error(message, zap.Error(err))
+		return errInternal
+	}
+}
+
+// ChainId serves eth_chainId
+func (api *EthAPI) ChainId(ctx context.Context) (*hexutil.Big, error) {
+	if chainID := api.chainID.Load(); chainID != nil {
+		return (*hexutil.Big)(chainID), nil
+	}
+	if api.upstream == nil {
+		return nil, errChainIDUnknown
+	}
+
+	var chainID hexutil.Big
+	if err := api.upstream.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+		api.log.Error("Failed to get chain id from upstream", zap.Error(err))
+		return nil, errInternal
+	}
+	api.chainID.Store((*big.Int)(&chainID))
+
+	return &chainID, nil
+}
+
+// BlockNumber serves eth_blockNumber, the newest indexed block
+func (api *EthAPI) BlockNumber(ctx context.Context) (*hexutil.Big, error) {
+	number, err := api.rpcService.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return nil, api.rpcError("Failed to get block number", err)
+	}
+	if number == nil {
+		return (*hexutil.Big)(new(big.Int)), nil
+	}
+
+	return bigHex(*number), nil
+}
+
+// resolveBlockNumber turns a block number or tag into a block number, nil when the tag names a block
+// that is not indexed yet
+func (api *EthAPI) resolveBlockNumber(ctx context.Context, number rpc.BlockNumber) (*domain.BigInt, error) {
+	switch number {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		latest, err := api.rpcService.GetLatestBlockNumber(ctx)
+		if err != nil {
+			return nil, api.rpcError("Failed to get block number", err)
+		}
+		return latest, nil
+	case rpc.EarliestBlockNumber:
+		return (*domain.BigInt)(new(big.Int)), nil
+	case rpc.SafeBlockNumber, rpc.FinalizedBlockNumber:
+		return nil, &invalidParamsError{message: fmt.Sprintf("%s block is not tracked by the index", number)}
+	default:
+		return (*domain.BigInt)(big.NewInt(number.Int64())), nil
+	}
+}
+
+// GetBlockByNumber serves eth_getBlockByNumber
+func (api *EthAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (*Block, error) {
+	resolved, err := api.resolveBlockNumber(ctx, number)
+	if err != nil || resolved == nil {
+		return nil, err
+	}
+
+	return api.getBlock(ctx, service.BlockID{Number: resolved}, fullTx)
+}
+
+// GetBlockByHash serves eth_getBlockByHash
+func (api *EthAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (*Block, error) {
+	return api.getBlock(ctx, service.BlockID{Hash: hash}, fullTx)
+}
+
+func (api *EthAPI) getBlock(ctx context.Context, id service.BlockID, fullTx bool) (*Block, error) {
+	b, err := api.rpcService.GetBlock(ctx, id)
+	if err != nil {
+		return nil, api.rpcError("Failed to get block", err)
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	transactions, err := api.rpcService.GetBlockTransactions(ctx, b)
+	if err != nil {
+		return nil, api.rpcError("Failed to get block transactions", err)
+	}
+	withdrawals, err := api.rpcService.GetBlockWithdrawals(ctx, b)
+	if err != nil {
+		return nil, api.rpcError("Failed to get block withdrawals", err)
+	}
+
+	result := newBlock(b)
+	for _, tx := range transactions {
+		if fullTx {
+			result.Transactions = append(result.Transactions, newTransaction(tx, api.decode(tx)))
+		} else {
+			result.Transactions = append(result.Transactions, tx.Hash)
+		}
+	}
+	for _, w := range withdrawals {
+		result.Withdrawals = append(result.Withdrawals, newWithdrawal(w))
+	}
+
+	return result, nil
+}
+
+// GetTransactionByHash serves eth_getTransactionByHash
+func (api *EthAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (*Transaction, error) {
+	tx, err := api.rpcService.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, api.rpcError("Failed to get transaction", err)
+	}
+	if tx == nil {
+		return nil, nil
+	}
+
+	return newTransaction(tx, api.decode(tx)), nil
+}
+
+// GetTransactionReceipt serves eth_getTransactionReceipt
+func (api *EthAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	receipt, err := api.rpcService.GetReceipt(ctx, hash)
+	if err != nil {
+		return nil, api.rpcError("Failed to get transaction receipt", err)
+	}
+	if receipt == nil {
+		return nil, nil
+	}
+
+	return newReceipt(receipt), nil
+}
+
+// decode returns the consensus form of tx, nil when it was indexed without its encoding
+func (api *EthAPI) decode(tx *service.TransactionWithFee) *types.Transaction {
+	if len(tx.Raw) == 0 {
+		return nil
+	}
+
+	var decoded types.Transaction
+	if err := decoded.UnmarshalBinary(tx.Raw); err != nil {
+		api.log.Warn("Failed to decode transaction encoding", zap.String("hash", tx.Hash.Hex()), zap.Error(err))
+		return nil
+	}
+
+	return &decoded
+}
+
+// FilterCriteria is the eth_getLogs filter object. Address is one address or a list of them, and every
+// position of Topics is null, one topic or a list of them.
+type FilterCriteria struct {
+	BlockHash *common.Hash      `json:"blockHash"`
+	FromBlock *rpc.BlockNumber  `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber  `json:"toBlock"`
+	Address   json.RawMessage   `json:"address"`
+	Topics    []json.RawMessage `json:"topics"`
+}
+
+// GetLogs serves eth_getLogs. The block range defaults to the latest block and a result over
+// service.MaxRPCLogs logs is refused.
+func (api *EthAPI) GetLogs(ctx context.Context, criteria FilterCriteria) ([]*Log, error) {
+	filter, err := api.logFilter(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return []*Log{}, nil
+	}
+
+	logs, err := api.rpcService.GetLogs(ctx, *filter)
+	if err != nil {
+		return nil, api.rpcError("Failed to get logs", err)
+	}
+
+	return newLogs(logs), nil
+}
+
+// logFilter resolves criteria, nil when the range starts at the latest block and none is indexed yet
+func (api *EthAPI) logFilter(ctx context.Context, criteria FilterCriteria) (*transaction.LogFilter, error) {
+	filter := &transaction.LogFilter{}
+
+	addresses, err := parseAddresses(criteria.Address)
+	if err != nil {
+		return nil, err
+	}
+	filter.Addresses = addresses
+
+	for position, raw := range criteria.Topics {
+		topics, err := parseTopics(raw)
+		if err != nil {
+			return nil, &invalidParamsError{message: fmt.Sprintf("invalid topic at position %d: %v", position, err)}
+		}
+		filter.Topics = append(filter.Topics, topics)
+	}
+
+	if criteria.BlockHash != nil {
+		if criteria.FromBlock != nil || criteria.ToBlock != nil {
+			return nil, &invalidParamsError{message: "blockHash cannot be combined with fromBlock or toBlock"}
+		}
+		filter.BlockHash = criteria.BlockHash
+		return filter, nil
+	}
+
+	from, to := rpc.LatestBlockNumber, rpc.LatestBlockNumber
+	if criteria.FromBlock != nil {
+		from = *criteria.FromBlock
+	}
+	if criteria.ToBlock != nil {
+		to = *criteria.ToBlock
+	}
+	if filter.FromBlock, err = api.resolveBlockNumber(ctx, from); err != nil || filter.FromBlock == nil {
+		return nil, err
+	}
+	if filter.ToBlock, err = api.resolveBlockNumber(ctx, to); err != nil || filter.ToBlock == nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// parseAddresses reads the address of a filter, absent and null match every address
+func parseAddresses(raw json.RawMessage) ([]common.Address, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var single common.Address
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []common.Address{single}, nil
+	}
+
+	var list []common.Address
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, &invalidParamsError{message: "address must be an address or a list of addresses"}
+	}
+
+	return list, nil
+}
+
+// parseTopics reads one topic position, null and a list holding null match every topic
+func parseTopics(raw json.RawMessage) ([]common.Hash, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+
+	var single common.Hash
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []common.Hash{single}, nil
+	}
+
+	var list []*common.Hash
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	topics := make([]common.Hash, 0, len(list))
+	for _, topic := range list {
+		if topic == nil {
+			return nil, nil
+		}
+		topics = append(topics, *topic)
+	}
+
+	return topics, nil
+}
@@ -0,0 +1,75 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+// Limits of a batch request, larger batches are answered with an error per item
+const (
+	MaxBatchItems        = 100
+	MaxBatchResponseSize = 25 * 1024 * 1024
+)
+
+// Server serves the JSON-RPC facade on one path, posted requests over HTTP and upgraded ones over
+// WebSocket. Subscriptions are not supported.
+type Server struct {
+	rpc       *rpc.Server
+	websocket http.Handler
+}
+
+// NewServer registers the eth methods answered from the index, and the state methods forwarded to
+// upstream when it is not nil. wsOrigins are the origins browsers may open a WebSocket from, "*"
+// allows any.
+func NewServer(rpcService *service.RPCService, upstream *rpc.Client, chainID uint64, wsOrigins []string, log *zap.Logger) (*Server, error) {
+	server := rpc.NewServer()
+	server.SetBatchLimits(MaxBatchItems, MaxBatchResponseSize)
+
+	if upstream != nil {
+		if err := server.RegisterName("eth", newUpstreamAPI(upstream, log)); err != nil {
+			return nil, err
+		}
+	}
+	// registered last so the indexed methods win should a name be in both
+	if err := server.RegisterName("eth", newEthAPI(rpcService, upstream, chainID, log)); err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		rpc:       server,
+		websocket: server.WebsocketHandler(wsOrigins),
+	}, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		s.websocket.ServeHTTP(w, r)
+		return
+	}
+
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.rpc.ServeHTTP(recorder, r)
+
+	metrics.RequestDuration.
+		WithLabelValues("/rpc", r.Method, strconv.Itoa(recorder.status)).
+		Observe(time.Since(start).Seconds())
+}
+
+// statusRecorder captures the status code written by the rpc server
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,183 @@
+package jsonrpc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// dial serves the indexed eth methods of db over HTTP, without an upstream, to an ethclient
+func dial(t *testing.T, db *sql.DB) *ethclient.Client {
+	t.Helper()
+
+	rpcService := service.NewRPCService(
+		repository.NewBlockRepository(db, zap.NewNop()),
+		repository.NewTransactionRepository(db, zap.NewNop()),
+		repository.NewWithdrawalRepository(db),
+		zap.NewNop(),
+	)
+	server, err := NewServer(rpcService, nil, 1, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	client, err := ethclient.Dial(httpServer.URL)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func logPositions(logs []types.Log) []string {
+	positions := make([]string, len(logs))
+	for i, l := range logs {
+		positions[i] = fmt.Sprintf("%d:%d", l.BlockNumber, l.Index)
+	}
+
+	return positions
+}
+
+func TestEthAPI_GetLogsTopics(t *testing.T) {
+	db := testdb.Open(t)
+	client := dial(t, db)
+	ctx := context.Background()
+	a, b, c := common.HexToHash("0xa"), common.HexToHash("0xb"), common.HexToHash("0xc")
+	x, y := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	for number := int64(1); number <= 2; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number, TransactionsCount: 1})
+		testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: number})
+	}
+	for _, l := range []testdb.Log{
+		{BlockNumber: 1, Index: 0, Address: x, Topics: []common.Hash{a, b}},
+		{BlockNumber: 1, Index: 1, Address: y, Topics: []common.Hash{b}},
+		{BlockNumber: 2, Index: 0, Address: x, Topics: []common.Hash{c, b}},
+		{BlockNumber: 2, Index: 1, Address: y},
+	} {
+		testdb.InsertLog(t, db, l)
+	}
+
+	for name, test := range map[string]struct {
+		query ethereum.FilterQuery
+		want  []string
+	}{
+		"every log":         {ethereum.FilterQuery{}, []string{"1:0", "1:1", "2:0", "2:1"}},
+		"topics or":         {ethereum.FilterQuery{Topics: [][]common.Hash{{a, c}}}, []string{"1:0", "2:0"}},
+		"null position":     {ethereum.FilterQuery{Topics: [][]common.Hash{nil, {b}}}, []string{"1:0", "2:0"}},
+		"empty position":    {ethereum.FilterQuery{Topics: [][]common.Hash{{}}}, []string{"1:0", "1:1", "2:0"}},
+		"trailing wildcard": {ethereum.FilterQuery{Topics: [][]common.Hash{{b, c}, nil}}, []string{"2:0"}},
+		"addresses or":      {ethereum.FilterQuery{Addresses: []common.Address{y}, Topics: [][]common.Hash{{a, b}}}, []string{"1:1"}},
+		"block hash":        {ethereum.FilterQuery{BlockHash: ptr(testdb.BlockHash(2))}, []string{"2:0", "2:1"}},
+	} {
+		if test.query.BlockHash == nil {
+			test.query.FromBlock, test.query.ToBlock = big.NewInt(1), big.NewInt(2)
+		}
+		logs, err := client.FilterLogs(ctx, test.query)
+		require.NoError(t, err, name)
+		assert.Equal(t, test.want, logPositions(logs), name)
+	}
+
+	// a null inside a position's list and a single address or topic are accepted like nodes accept them
+	var logs []types.Log
+	require.NoError(t, client.Client().CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{
+		"fromBlock": "0x1", "toBlock": "latest", "address": x, "topics": []interface{}{[]interface{}{nil, a}, b},
+	}))
+	assert.Equal(t, []string{"1:0", "2:0"}, logPositions(logs))
+
+	// the latest block is the default range
+	require.NoError(t, client.Client().CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{}))
+	assert.Equal(t, []string{"2:0", "2:1"}, logPositions(logs))
+
+	var rpcErr rpc.Error
+	err := client.Client().CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{"blockHash": testdb.BlockHash(2), "fromBlock": "0x1"})
+	require.True(t, errors.As(err, &rpcErr), err)
+	assert.Equal(t, -32602, rpcErr.ErrorCode())
+
+	_, err = client.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(1), Topics: make([][]common.Hash, service.MaxLogTopicPositions+1)})
+	require.True(t, errors.As(err, &rpcErr), err)
+	assert.Equal(t, -32602, rpcErr.ErrorCode())
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// TestEthAPI_BlockHeader checks a served block decodes to the header it was indexed from, hash included
+func TestEthAPI_BlockHeader(t *testing.T) {
+	db := testdb.Open(t)
+	client := dial(t, db)
+	ctx := context.Background()
+
+	var bloom types.Bloom
+	bloom.Add([]byte("indexed"))
+	header := &types.Header{
+		ParentHash:  testdb.BlockHash(0),
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    common.HexToAddress("0x2f14582947E292a2eCd20C430B46f2d27CFE213c"),
+		Root:        common.HexToHash("0xd7f8974fb5ac78d9ac099b9ad5018bedc2ce0a72dad1827a1709da30580f0544"),
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Bloom:       bloom,
+		Difficulty:  big.NewInt(131072),
+		Number:      big.NewInt(1),
+		GasLimit:    30000000,
+		Time:        uint64(testdb.Timestamp(1)),
+		Extra:       []byte{},
+		MixDigest:   common.HexToHash("0x969b900de27b6ac6a67742365dd65f55a0526c41fd18e1b16f1a1215c2e66f59"),
+		Nonce:       types.EncodeNonce(0x539bd4979fef1ec4),
+		BaseFee:     big.NewInt(1000000000),
+	}
+	hash := testdb.InsertHeader(t, db, header, 0)
+	// a block indexed before core stored the roots, the bloom and the rest of the header
+	testdb.InsertBlock(t, db, testdb.Block{Number: 2})
+
+	served, err := client.HeaderByNumber(ctx, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, header, served)
+	assert.Equal(t, hash, served.Hash())
+
+	served, err = client.HeaderByHash(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, hash, served.Hash())
+
+	// ethclient checks the uncles and transactions agree with the header
+	block, err := client.BlockByNumber(ctx, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, hash, block.Hash())
+	assert.Empty(t, block.Uncles())
+
+	served, err = client.HeaderByNumber(ctx, big.NewInt(2))
+	require.NoError(t, err)
+	assert.Equal(t, types.EmptyUncleHash, served.UncleHash)
+	assert.Equal(t, common.Hash{}, served.Root)
+	assert.Equal(t, common.Hash{}, served.TxHash)
+	assert.Equal(t, types.Bloom{}, served.Bloom)
+	assert.Nil(t, served.BaseFee)
+
+	var fields map[string]interface{}
+	require.NoError(t, client.Client().CallContext(ctx, &fields, "eth_getBlockByNumber", "0x2", false))
+	for _, field := range []string{"sha3Uncles", "stateRoot", "transactionsRoot", "receiptsRoot", "logsBloom", "mixHash", "nonce", "extraData", "uncles", "transactions"} {
+		assert.Contains(t, fields, field)
+	}
+	assert.Equal(t, []interface{}{}, fields["uncles"])
+	assert.Equal(t, hexutil.Encode(make([]byte, types.BloomByteLength)), fields["logsBloom"])
+}
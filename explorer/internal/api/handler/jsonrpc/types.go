@@ -0,0 +1,279 @@
+package jsonrpc
+
+import (
+	"math/big"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Block is a block as nodes return it, every header field clients decode is set. The extra data is not
+// indexed and always empty, and core never stored uncles. The roots, the bloom and the mix hash are
+// zero for blocks indexed before core stored them.
+type Block struct {
+	Number           *hexutil.Big     `json:"number"`
+	Hash             common.Hash      `json:"hash"`
+	ParentHash       common.Hash      `json:"parentHash"`
+	Sha3Uncles       common.Hash      `json:"sha3Uncles"`
+	StateRoot        common.Hash      `json:"stateRoot"`
+	TransactionsRoot common.Hash      `json:"transactionsRoot"`
+	ReceiptsRoot     common.Hash      `json:"receiptsRoot"`
+	LogsBloom        types.Bloom      `json:"logsBloom"`
+	MixHash          common.Hash      `json:"mixHash"`
+	Nonce            types.BlockNonce `json:"nonce"`
+	Miner            common.Address   `json:"miner"`
+	Difficulty       *hexutil.Big     `json:"difficulty"`
+	ExtraData        hexutil.Bytes    `json:"extraData"`
+	Size             hexutil.Uint64   `json:"size"`
+	GasLimit         hexutil.Uint64   `json:"gasLimit"`
+	GasUsed          hexutil.Uint64   `json:"gasUsed"`
+	Timestamp        hexutil.Uint64   `json:"timestamp"`
+	BaseFeePerGas    *hexutil.Big     `json:"baseFeePerGas,omitempty"`
+	// Transactions holds hashes, or *Transaction when the full transactions were asked for
+	Transactions []interface{} `json:"transactions"`
+	Uncles       []common.Hash `json:"uncles"`
+	Withdrawals  []*Withdrawal `json:"withdrawals,omitempty"`
+}
+
+type Withdrawal struct {
+	Index          hexutil.Uint64 `json:"index"`
+	ValidatorIndex hexutil.Uint64 `json:"validatorIndex"`
+	Address        common.Address `json:"address"`
+	// Amount is in Gwei
+	Amount hexutil.Uint64 `json:"amount"`
+}
+
+// Transaction is a mined transaction. The fee caps, the access list and the signature come from the
+// consensus encoding and are left out for transactions indexed without it.
+type Transaction struct {
+	BlockHash            common.Hash                  `json:"blockHash"`
+	BlockNumber          *hexutil.Big                 `json:"blockNumber"`
+	From                 common.Address               `json:"from"`
+	Gas                  hexutil.Uint64               `json:"gas"`
+	GasPrice             *hexutil.Big                 `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas     *hexutil.Big                 `json:"maxFeePerBlobGas,omitempty"`
+	Hash                 common.Hash                  `json:"hash"`
+	Input                hexutil.Bytes                `json:"input"`
+	Nonce                hexutil.Uint64               `json:"nonce"`
+	To                   *common.Address              `json:"to"`
+	TransactionIndex     hexutil.Uint64               `json:"transactionIndex"`
+	Value                *hexutil.Big                 `json:"value"`
+	Type                 hexutil.Uint64               `json:"type"`
+	AccessList           *types.AccessList            `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big                 `json:"chainId,omitempty"`
+	BlobVersionedHashes  []common.Hash                `json:"blobVersionedHashes,omitempty"`
+	AuthorizationList    []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                    *hexutil.Big                 `json:"v,omitempty"`
+	R                    *hexutil.Big                 `json:"r,omitempty"`
+	S                    *hexutil.Big                 `json:"s,omitempty"`
+	YParity              *hexutil.Uint64              `json:"yParity,omitempty"`
+}
+
+type Receipt struct {
+	Type              hexutil.Uint64  `json:"type"`
+	Status            hexutil.Uint64  `json:"status"`
+	CumulativeGasUsed hexutil.Uint64  `json:"cumulativeGasUsed"`
+	LogsBloom         types.Bloom     `json:"logsBloom"`
+	Logs              []*Log          `json:"logs"`
+	TransactionHash   common.Hash     `json:"transactionHash"`
+	TransactionIndex  hexutil.Uint64  `json:"transactionIndex"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	BlockNumber       *hexutil.Big    `json:"blockNumber"`
+	From              common.Address  `json:"from"`
+	To                *common.Address `json:"to"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice,omitempty"`
+}
+
+// Log is never removed, the index only holds canonical blocks
+type Log struct {
+	Address          common.Address `json:"address"`
+	Topics           []common.Hash  `json:"topics"`
+	Data             hexutil.Bytes  `json:"data"`
+	BlockNumber      *hexutil.Big   `json:"blockNumber"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	LogIndex         hexutil.Uint64 `json:"logIndex"`
+	Removed          bool           `json:"removed"`
+}
+
+func bigHex(n domain.BigInt) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).Set((*big.Int)(&n)))
+}
+
+func hashOrZero(hash *common.Hash) common.Hash {
+	if hash == nil {
+		return common.Hash{}
+	}
+
+	return *hash
+}
+
+func newBlock(b *block.Block) *Block {
+	result := &Block{
+		Number:           bigHex(b.Number),
+		Hash:             b.Hash,
+		ParentHash:       b.ParentHash,
+		Sha3Uncles:       types.EmptyUncleHash,
+		StateRoot:        hashOrZero(b.StateRoot),
+		TransactionsRoot: hashOrZero(b.TransactionsRoot),
+		ReceiptsRoot:     hashOrZero(b.ReceiptsRoot),
+		LogsBloom:        types.BytesToBloom(b.LogsBloom),
+		MixHash:          hashOrZero(b.MixHash),
+		Nonce:            types.EncodeNonce(b.Nonce),
+		Miner:            b.MinerHash,
+		Difficulty:       bigHex(b.Difficulty),
+		ExtraData:        hexutil.Bytes{},
+		Size:             hexutil.Uint64(b.Size),
+		GasLimit:         hexutil.Uint64(b.GasLimit),
+		GasUsed:          hexutil.Uint64(b.GasUsed),
+		Timestamp:        hexutil.Uint64(b.Timestamp),
+		Transactions:     []interface{}{},
+		Uncles:           []common.Hash{},
+	}
+	// blocks before London have no base fee, core stores it as 0
+	if (*big.Int)(&b.BaseFeePerGas).Sign() > 0 {
+		result.BaseFeePerGas = bigHex(b.BaseFeePerGas)
+	}
+
+	return result
+}
+
+func newWithdrawal(w *withdrawal.Withdrawal) *Withdrawal {
+	return &Withdrawal{
+		Index:          hexutil.Uint64(w.Index),
+		ValidatorIndex: hexutil.Uint64(w.ValidatorIndex),
+		Address:        w.AddressHash,
+		Amount:         hexutil.Uint64(w.Amount),
+	}
+}
+
+// recipient is the to of tx, nil for a contract creation which core stores with the zero address
+func recipient(tx *transaction.Transaction) *common.Address {
+	if tx.To == (common.Address{}) {
+		return nil
+	}
+	to := tx.To
+
+	return &to
+}
+
+func newTransaction(tx *service.TransactionWithFee, decoded *types.Transaction) *Transaction {
+	result := &Transaction{
+		BlockHash:        tx.BlockHash,
+		BlockNumber:      bigHex(tx.BlockNumber),
+		From:             tx.From,
+		Gas:              hexutil.Uint64(tx.Gas),
+		Hash:             tx.Hash,
+		Input:            tx.Input,
+		Nonce:            hexutil.Uint64(tx.Nonce),
+		To:               recipient(tx.Transaction),
+		TransactionIndex: hexutil.Uint64(tx.Index),
+		Value:            bigHex(tx.Value),
+		Type:             hexutil.Uint64(tx.Type),
+	}
+	if tx.Fee != nil {
+		result.GasPrice = bigHex(tx.Fee.GasPrice)
+	}
+	if decoded == nil {
+		return result
+	}
+
+	if decoded.Protected() {
+		result.ChainID = (*hexutil.Big)(decoded.ChainId())
+	}
+	v, r, s := decoded.RawSignatureValues()
+	result.V, result.R, result.S = (*hexutil.Big)(v), (*hexutil.Big)(r), (*hexutil.Big)(s)
+
+	if decoded.Type() == types.LegacyTxType {
+		return result
+	}
+	yParity := hexutil.Uint64(v.Uint64())
+	result.YParity = &yParity
+	accessList := decoded.AccessList()
+	result.AccessList = &accessList
+
+	if decoded.Type() >= types.DynamicFeeTxType {
+		result.MaxFeePerGas = (*hexutil.Big)(decoded.GasFeeCap())
+		result.MaxPriorityFeePerGas = (*hexutil.Big)(decoded.GasTipCap())
+	}
+	if decoded.Type() == types.BlobTxType {
+		result.MaxFeePerBlobGas = (*hexutil.Big)(decoded.BlobGasFeeCap())
+		result.BlobVersionedHashes = decoded.BlobHashes()
+	}
+	if decoded.Type() == types.SetCodeTxType {
+		result.AuthorizationList = decoded.SetCodeAuthorizations()
+	}
+
+	return result
+}
+
+func newLog(l *transaction.TransactionLog) *Log {
+	topics := l.Topics
+	if topics == nil {
+		topics = []common.Hash{}
+	}
+
+	return &Log{
+		Address:          l.Address,
+		Topics:           topics,
+		Data:             l.Data,
+		BlockNumber:      bigHex(l.BlockNumber),
+		TransactionHash:  l.TransactionHash,
+		TransactionIndex: hexutil.Uint64(l.TransactionIndex),
+		BlockHash:        l.BlockHash,
+		LogIndex:         hexutil.Uint64(l.Index),
+	}
+}
+
+func newLogs(logs []*transaction.TransactionLog) []*Log {
+	result := make([]*Log, len(logs))
+	for i, l := range logs {
+		result[i] = newLog(l)
+	}
+
+	return result
+}
+
+func newReceipt(receipt *service.Receipt) *Receipt {
+	tx := receipt.TransactionWithFee
+	result := &Receipt{
+		Type:              hexutil.Uint64(tx.Type),
+		Status:            hexutil.Uint64(tx.Status),
+		CumulativeGasUsed: hexutil.Uint64(tx.CumulativeGasUsed),
+		Logs:              newLogs(receipt.Logs),
+		TransactionHash:   tx.Hash,
+		TransactionIndex:  hexutil.Uint64(tx.Index),
+		BlockHash:         tx.BlockHash,
+		BlockNumber:       bigHex(tx.BlockNumber),
+		From:              tx.From,
+		To:                recipient(tx.Transaction),
+		GasUsed:           hexutil.Uint64(tx.GasUsed),
+	}
+	if result.To == nil {
+		contract := crypto.CreateAddress(tx.From, tx.Nonce)
+		result.ContractAddress = &contract
+	}
+	if tx.Fee != nil {
+		result.EffectiveGasPrice = bigHex(tx.Fee.GasPrice)
+	}
+	for _, l := range receipt.Logs {
+		result.LogsBloom.Add(l.Address.Bytes())
+		for _, topic := range l.Topics {
+			result.LogsBloom.Add(topic.Bytes())
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,88 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+var errUpstreamUnavailable = errors.New("upstream node unavailable")
+
+// UpstreamAPI forwards the eth methods reading chain state, which the index does not hold, to a node.
+// Parameters and results are passed through untouched and errors of the node keep their code.
+// Trailing optional parameters left out by the caller are left out upstream as well.
+type UpstreamAPI struct {
+	client *rpc.Client
+	log    *zap.Logger
+}
+
+func newUpstreamAPI(client *rpc.Client, log *zap.Logger) *UpstreamAPI {
+	return &UpstreamAPI{client: client, log: log}
+}
+
+func (api *UpstreamAPI) forward(ctx context.Context, method string, args ...*json.RawMessage) (json.RawMessage, error) {
+	for len(args) > 0 && args[len(args)-1] == nil {
+		args = args[:len(args)-1]
+	}
+
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		params[i] = arg
+	}
+
+	var result json.RawMessage
+	if err := api.client.CallContext(ctx, &result, method, params...); err != nil {
+		// transport errors would name the node, only its JSON-RPC errors are passed on
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			return nil, err
+		}
+		api.log.Error("Failed to call upstream node", zap.String("method", method), zap.Error(err))
+		return nil, errUpstreamUnavailable
+	}
+
+	return result, nil
+}
+
+func (api *UpstreamAPI) GetBalance(ctx context.Context, address json.RawMessage, block *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_getBalance", &address, block)
+}
+
+func (api *UpstreamAPI) GetCode(ctx context.Context, address json.RawMessage, block *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_getCode", &address, block)
+}
+
+func (api *UpstreamAPI) GetStorageAt(ctx context.Context, address, slot json.RawMessage, block *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_getStorageAt", &address, &slot, block)
+}
+
+func (api *UpstreamAPI) GetTransactionCount(ctx context.Context, address json.RawMessage, block *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_getTransactionCount", &address, block)
+}
+
+func (api *UpstreamAPI) GetProof(ctx context.Context, address, keys json.RawMessage, block *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_getProof", &address, &keys, block)
+}
+
+func (api *UpstreamAPI) Call(ctx context.Context, args json.RawMessage, block, stateOverrides, blockOverrides *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_call", &args, block, stateOverrides, blockOverrides)
+}
+
+func (api *UpstreamAPI) EstimateGas(ctx context.Context, args json.RawMessage, block, stateOverrides *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_estimateGas", &args, block, stateOverrides)
+}
+
+func (api *UpstreamAPI) GasPrice(ctx context.Context) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_gasPrice")
+}
+
+func (api *UpstreamAPI) MaxPriorityFeePerGas(ctx context.Context) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_maxPriorityFeePerGas")
+}
+
+func (api *UpstreamAPI) FeeHistory(ctx context.Context, blockCount, newestBlock json.RawMessage, rewardPercentiles *json.RawMessage) (json.RawMessage, error) {
+	return api.forward(ctx, "eth_feeHistory", &blockCount, &newestBlock, rewardPercentiles)
+}
@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type AddressHandler struct {
+	addressService *service.AddressService
+	log            *zap.Logger
+}
+
+func NewAddressHandler(addressService *service.AddressService, log *zap.Logger) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+		log:            log,
+	}
+}
+
+// GetAddress serves /address/{address} with the overview of the address
+func (h *AddressHandler) GetAddress(w http.ResponseWriter, r *http.Request) {
+	addr, err := service.ParseAddress(mux.Vars(r)["address"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.addressService.GetSummary(r.Context(), addr)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get address", err)
+		return
+	}
+
+	writeJSON(w, h.log, MapAddressSummaryToResponse(summary))
+}
+
+// GetAddressTransactions serves /address/{address}/transactions, direction in or out keeps one side only
+func (h *AddressHandler) GetAddressTransactions(w http.ResponseWriter, r *http.Request) {
+	addr, direction, limit, err := parseAddressPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transactions, next, err := h.addressService.GetTransactions(r.Context(), addr, direction, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get address transactions", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(transactions, next, MapTransactionToResponse))
+}
+
+// GetAddressInternalTransactions serves /address/{address}/internal-transactions
+func (h *AddressHandler) GetAddressInternalTransactions(w http.ResponseWriter, r *http.Request) {
+	addr, direction, limit, err := parseAddressPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	internalTransactions, next, err := h.addressService.GetInternalTransactions(r.Context(), addr, direction, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get address internal transactions", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(internalTransactions, next, MapInternalTransactionToResponse))
+}
+
+// GetAddressTokenTransfers serves /address/{address}/token-transfers
+func (h *AddressHandler) GetAddressTokenTransfers(w http.ResponseWriter, r *http.Request) {
+	addr, direction, limit, err := parseAddressPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transfers, next, err := h.addressService.GetTokenTransfers(r.Context(), addr, direction, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get address token transfers", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(transfers, next, MapTokenTransferToResponse))
+}
+
+// GetAddressLogs serves /address/{address}/logs with the logs emitted by the address
+func (h *AddressHandler) GetAddressLogs(w http.ResponseWriter, r *http.Request) {
+	addr, limit, err := parseAddressLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logs, next, err := h.addressService.GetLogs(r.Context(), addr, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get address logs", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(logs, next, MapLogToResponse))
+}
+
+// GetAddressWithdrawals serves /address/{address}/withdrawals
+func (h *AddressHandler) GetAddressWithdrawals(w http.ResponseWriter, r *http.Request) {
+	addr, limit, err := parseAddressLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	withdrawals, next, err := h.addressService.GetWithdrawals(r.Context(), addr, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get address withdrawals", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(withdrawals, next, MapWithdrawalToResponse))
+}
+
+// GetAddressRewards serves /address/{address}/rewards
+func (h *AddressHandler) GetAddressRewards(w http.ResponseWriter, r *http.Request) {
+	addr, limit, err := parseAddressLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rewards, next, err := h.addressService.GetRewards(r.Context(), addr, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get address rewards", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(rewards, next, MapRewardToResponse))
+}
+
+func parseAddressLimit(r *http.Request) (common.Address, int, error) {
+	addr, err := service.ParseAddress(mux.Vars(r)["address"])
+	if err != nil {
+		return common.Address{}, 0, err
+	}
+
+	limit, err := queryLimit(r)
+	return addr, limit, err
+}
+
+func parseAddressPage(r *http.Request) (common.Address, address.Direction, int, error) {
+	addr, limit, err := parseAddressLimit(r)
+	if err != nil {
+		return common.Address{}, "", 0, err
+	}
+
+	// the service rejects unknown directions
+	return addr, address.Direction(r.URL.Query().Get("direction")), limit, nil
+}
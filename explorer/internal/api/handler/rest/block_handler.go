@@ -1,10 +1,14 @@
 package rest
 
 import (
-	"encoding/json"
+	"fmt"
+	"math/big"
 	"net/http"
 
 	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
@@ -27,13 +31,142 @@ func (h *BlockHandler) GetCurrentBlock(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to get current block", http.StatusInternalServerError)
 		return
 	}
+	if block == nil {
+		http.Error(w, service.ErrBlockNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, h.log, MapBlockToResponse(block))
+}
+
+// GetBlock serves /block/{id}, id is a block number or hash
+func (h *BlockHandler) GetBlock(w http.ResponseWriter, r *http.Request) {
+	id, err := service.ParseBlockID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b, err := h.blockService.GetBlock(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get block", err)
+		return
+	}
+
+	writeJSON(w, h.log, MapBlockToResponse(b))
+}
+
+// GetBlocks serves /blocks, filtered by the inclusive from/to number and from_timestamp/to_timestamp
+// bounds, ordered by sort (number, gas_used or transactions_count) in order (desc by default)
+func (h *BlockHandler) GetBlocks(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseBlockFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	blocks, next, err := h.blockService.GetBlocks(r.Context(), filter, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get blocks", err)
+		return
+	}
+
+	response := PageResponse[*BlockResponse]{Items: make([]*BlockResponse, len(blocks)), NextCursor: next}
+	for i, b := range blocks {
+		response.Items[i] = MapBlockToResponse(b)
+	}
 
-	response := MapBlockToCurrentBlockResponse(block)
+	writeJSON(w, h.log, response)
+}
+
+func parseBlockFilter(r *http.Request) (block.Filter, error) {
+	query := r.URL.Query()
+	filter := block.Filter{
+		SortBy:     block.SortField(query.Get("sort")),
+		Descending: true,
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Descending = false
+	default:
+		return filter, fmt.Errorf("%w: order must be asc or desc", service.ErrInvalidQuery)
+	}
+
+	var err error
+	for name, bound := range map[string]**domain.BigInt{"from": &filter.FromNumber, "to": &filter.ToNumber} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+
+		number, ok := new(big.Int).SetString(raw, 10)
+		if !ok || number.Sign() < 0 {
+			return filter, fmt.Errorf("%w: %s must be a block number", service.ErrInvalidQuery, name)
+		}
+		*bound = (*domain.BigInt)(number)
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		h.log.Error("Failed to encode response", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	if filter.FromTimestamp, err = queryUint(r, "from_timestamp"); err != nil {
+		return filter, err
 	}
+	if filter.ToTimestamp, err = queryUint(r, "to_timestamp"); err != nil {
+		return filter, err
+	}
+	if filter.Limit, err = queryLimit(r); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// GetBlockTransactions serves /block/{id}/transactions, paged by transaction index
+func (h *BlockHandler) GetBlockTransactions(w http.ResponseWriter, r *http.Request) {
+	id, err := service.ParseBlockID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit, err := queryLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transactions, next, err := h.blockService.GetBlockTransactions(r.Context(), id, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get block transactions", err)
+		return
+	}
+
+	response := PageResponse[*TransactionResponse]{Items: make([]*TransactionResponse, len(transactions)), NextCursor: next}
+	for i, t := range transactions {
+		response.Items[i] = MapTransactionToResponse(t)
+	}
+
+	writeJSON(w, h.log, response)
+}
+
+// GetBlockWithdrawals serves /block/{id}/withdrawals
+func (h *BlockHandler) GetBlockWithdrawals(w http.ResponseWriter, r *http.Request) {
+	id, err := service.ParseBlockID(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	withdrawals, err := h.blockService.GetBlockWithdrawals(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get block withdrawals", err)
+		return
+	}
+
+	response := make([]*WithdrawalResponse, len(withdrawals))
+	for i, wd := range withdrawals {
+		response[i] = MapWithdrawalToResponse(wd)
+	}
+
+	writeJSON(w, h.log, response)
 }
@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+type LogHandler struct {
+	logService *service.LogService
+	log        *zap.Logger
+}
+
+func NewLogHandler(logService *service.LogService, log *zap.Logger) *LogHandler {
+	return &LogHandler{
+		logService: logService,
+		log:        log,
+	}
+}
+
+// GetLogs serves /logs with the semantics of eth_getLogs. address and topic0 to topic3 take comma separated
+// values, a log matches any of the addresses and, at each given position, any of the topics. The range is
+// from_block to to_block (inclusive) or a single block_hash, ordered by position in order (asc by default).
+func (h *LogHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLogFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logs, next, err := h.logService.GetLogs(r.Context(), filter, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get logs", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(logs, next, MapLogToResponse))
+}
+
+func parseLogFilter(r *http.Request) (transaction.LogFilter, error) {
+	query := r.URL.Query()
+	var filter transaction.LogFilter
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return filter, fmt.Errorf("%w: order must be asc or desc", service.ErrInvalidQuery)
+	}
+
+	for _, raw := range queryList(r, "address") {
+		if !common.IsHexAddress(raw) || !strings.HasPrefix(raw, "0x") {
+			return filter, fmt.Errorf("%w: address must be a list of 0x prefixed addresses", service.ErrInvalidQuery)
+		}
+		filter.Addresses = append(filter.Addresses, common.HexToAddress(raw))
+	}
+
+	// positions after the last given topic are wildcards and are left out
+	for position := 0; position < service.MaxLogTopicPositions; position++ {
+		name := fmt.Sprintf("topic%d", position)
+		var topics []common.Hash
+		for _, raw := range queryList(r, name) {
+			topic, err := hexutil.Decode(raw)
+			if err != nil || len(topic) != common.HashLength {
+				return filter, fmt.Errorf("%w: %s must be a list of 0x prefixed 32 byte topics", service.ErrInvalidQuery, name)
+			}
+			topics = append(topics, common.BytesToHash(topic))
+		}
+		if len(topics) > 0 {
+			filter.Topics = append(filter.Topics, make([][]common.Hash, position+1-len(filter.Topics))...)
+			filter.Topics[position] = topics
+		}
+	}
+
+	for name, bound := range map[string]**domain.BigInt{"from_block": &filter.FromBlock, "to_block": &filter.ToBlock} {
+		raw := query.Get(name)
+		if raw == "" {
+			continue
+		}
+
+		number, ok := new(big.Int).SetString(raw, 10)
+		if !ok || number.Sign() < 0 {
+			return filter, fmt.Errorf("%w: %s must be a block number", service.ErrInvalidQuery, name)
+		}
+		*bound = (*domain.BigInt)(number)
+	}
+
+	if raw := query.Get("block_hash"); raw != "" {
+		hash, err := hexutil.Decode(raw)
+		if err != nil || len(hash) != common.HashLength {
+			return filter, fmt.Errorf("%w: block_hash must be a 0x prefixed 32 byte hash", service.ErrInvalidQuery)
+		}
+		blockHash := common.BytesToHash(hash)
+		filter.BlockHash = &blockHash
+	}
+
+	var err error
+	if filter.Limit, err = queryLimit(r); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// queryList reads a query parameter given as comma separated values, repeated or both
+func queryList(r *http.Request, name string) []string {
+	var values []string
+	for _, raw := range r.URL.Query()[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type NFTHandler struct {
+	nftService *service.NFTService
+	log        *zap.Logger
+}
+
+func NewNFTHandler(nftService *service.NFTService, log *zap.Logger) *NFTHandler {
+	return &NFTHandler{
+		nftService: nftService,
+		log:        log,
+	}
+}
+
+// GetCollection serves /nft/{contract} with the owners, supply and transfers of an ERC-721 or ERC-1155 token
+func (h *NFTHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	contract, err := service.ParseAddress(mux.Vars(r)["contract"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.nftService.GetCollection(r.Context(), contract)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get nft collection", err)
+		return
+	}
+
+	writeJSON(w, h.log, MapCollectionToResponse(stats))
+}
+
+// GetNFT serves /nft/{contract}/{tokenId} with the owner, the metadata and the first page of transfers
+func (h *NFTHandler) GetNFT(w http.ResponseWriter, r *http.Request) {
+	contract, tokenId, limit, err := parseNFT(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.nftService.GetNFT(r.Context(), contract, tokenId, limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get nft", err)
+		return
+	}
+
+	writeJSON(w, h.log, MapNFTDetailsToResponse(details))
+}
+
+// GetNFTTransfers serves /nft/{contract}/{tokenId}/transfers, the pages after the one of /nft/{contract}/{tokenId}
+func (h *NFTHandler) GetNFTTransfers(w http.ResponseWriter, r *http.Request) {
+	contract, tokenId, limit, err := parseNFT(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transfers, next, err := h.nftService.GetNFTTransfers(r.Context(), contract, tokenId, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get nft transfers", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(transfers, next, MapTokenTransferToResponse))
+}
+
+// GetInventory serves /address/{address}/nfts, the instances the address holds grouped by collection
+func (h *NFTHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	owner, limit, err := parseAddressLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collections, next, err := h.nftService.GetInventory(r.Context(), owner, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get nft inventory", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(collections, next, MapCollectionInventoryToResponse))
+}
+
+func parseNFT(r *http.Request) (common.Address, domain.BigInt, int, error) {
+	vars := mux.Vars(r)
+	contract, err := service.ParseAddress(vars["contract"])
+	if err != nil {
+		return common.Address{}, domain.BigInt{}, 0, err
+	}
+
+	tokenId, err := service.ParseTokenID(vars["tokenId"])
+	if err != nil {
+		return common.Address{}, domain.BigInt{}, 0, err
+	}
+
+	limit, err := queryLimit(r)
+	return contract, tokenId, limit, err
+}
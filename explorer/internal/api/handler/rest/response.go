@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// PageResponse wraps a page of a listing, NextCursor is empty on the last page
+type PageResponse[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, log *zap.Logger, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error("Failed to encode response", zap.Error(err))
+	}
+}
+
+// mapPage maps a page of domain items to their responses
+func mapPage[T, R any](items []T, next string, mapItem func(T) R) PageResponse[R] {
+	response := PageResponse[R]{Items: make([]R, len(items)), NextCursor: next}
+	for i, item := range items {
+		response.Items[i] = mapItem(item)
+	}
+
+	return response
+}
+
+// writeServiceError maps the service errors callers can fix to client errors and hides the rest
+func writeServiceError(w http.ResponseWriter, log *zap.Logger, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuery), errors.Is(err, service.ErrInvalidBlockID), errors.Is(err, service.ErrInvalidTransactionHash),
+		errors.Is(err, service.ErrInvalidAddress), errors.Is(err, service.ErrInvalidTokenID):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrBlockNotFound), errors.Is(err, service.ErrTransactionNotFound),
+		errors.Is(err, service.ErrTokenNotFound), errors.Is(err, service.ErrCollectionNotFound), errors.Is(err, service.ErrNFTNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Error(message, zap.Error(err))
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
+
+// queryUint reads an optional unsigned query parameter
+func queryUint(r *http.Request, name string) (*uint64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an unsigned integer", service.ErrInvalidQuery, name)
+	}
+
+	return &value, nil
+}
+
+// queryAddress reads an optional 0x prefixed address query parameter
+func queryAddress(r *http.Request, name string) (*common.Address, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	if !common.IsHexAddress(raw) || !strings.HasPrefix(raw, "0x") {
+		return nil, fmt.Errorf("%w: %s must be a 0x prefixed address", service.ErrInvalidQuery, name)
+	}
+
+	address := common.HexToAddress(raw)
+	return &address, nil
+}
+
+// queryLimit reads the page size, the service applies the default and the cap
+func queryLimit(r *http.Request) (int, error) {
+	limit, err := queryUint(r, "limit")
+	if err != nil || limit == nil {
+		return 0, err
+	}
+
+	return int(min(*limit, service.MaxPageSize)), nil
+}
@@ -4,21 +4,65 @@ import (
 	"net/http"
 
 	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/pkg/health"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
 func NewRouter(
 	BlockService *service.BlockService,
+	transactionService *service.TransactionService,
+	addressService *service.AddressService,
+	logService *service.LogService,
+	tokenService *service.TokenService,
+	nftService *service.NFTService,
+	searchService *service.SearchService,
+	checker *health.Checker,
 	logger *zap.Logger,
 ) *mux.Router {
 	r := mux.NewRouter()
 
+	r.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	r.Handle("/healthz", checker.LivenessHandler()).Methods(http.MethodGet)
+	r.Handle("/readyz", checker.ReadinessHandler()).Methods(http.MethodGet)
+
 	blockHandler := NewBlockHandler(BlockService, logger)
+	transactionHandler := NewTransactionHandler(transactionService, logger)
+	addressHandler := NewAddressHandler(addressService, logger)
+	logHandler := NewLogHandler(logService, logger)
+	tokenHandler := NewTokenHandler(tokenService, logger)
+	nftHandler := NewNFTHandler(nftService, logger)
+	searchHandler := NewSearchHandler(searchService, logger)
 
 	api := r.PathPrefix("/api/v1").Subrouter()
+	api.Use(metricsMiddleware)
 
 	api.HandleFunc("/block/current", blockHandler.GetCurrentBlock).Methods(http.MethodGet)
+	api.HandleFunc("/block/{id}", blockHandler.GetBlock).Methods(http.MethodGet)
+	api.HandleFunc("/block/{id}/transactions", blockHandler.GetBlockTransactions).Methods(http.MethodGet)
+	api.HandleFunc("/block/{id}/withdrawals", blockHandler.GetBlockWithdrawals).Methods(http.MethodGet)
+	api.HandleFunc("/blocks", blockHandler.GetBlocks).Methods(http.MethodGet)
+	api.HandleFunc("/tx/{hash}", transactionHandler.GetTransaction).Methods(http.MethodGet)
+	api.HandleFunc("/txs", transactionHandler.GetTransactions).Methods(http.MethodGet)
+
+	api.HandleFunc("/address/{address}", addressHandler.GetAddress).Methods(http.MethodGet)
+	api.HandleFunc("/address/{address}/transactions", addressHandler.GetAddressTransactions).Methods(http.MethodGet)
+	api.HandleFunc("/address/{address}/internal-transactions", addressHandler.GetAddressInternalTransactions).Methods(http.MethodGet)
+	api.HandleFunc("/address/{address}/token-transfers", addressHandler.GetAddressTokenTransfers).Methods(http.MethodGet)
+	api.HandleFunc("/address/{address}/logs", addressHandler.GetAddressLogs).Methods(http.MethodGet)
+	api.HandleFunc("/address/{address}/withdrawals", addressHandler.GetAddressWithdrawals).Methods(http.MethodGet)
+	api.HandleFunc("/address/{address}/rewards", addressHandler.GetAddressRewards).Methods(http.MethodGet)
+	api.HandleFunc("/address/{address}/nfts", nftHandler.GetInventory).Methods(http.MethodGet)
+	api.HandleFunc("/logs", logHandler.GetLogs).Methods(http.MethodGet)
+	api.HandleFunc("/tokens", tokenHandler.GetTokens).Methods(http.MethodGet)
+	api.HandleFunc("/token/{address}", tokenHandler.GetToken).Methods(http.MethodGet)
+	api.HandleFunc("/token/{address}/transfers", tokenHandler.GetTokenTransfers).Methods(http.MethodGet)
+	api.HandleFunc("/token/{address}/holders", tokenHandler.GetTokenHolders).Methods(http.MethodGet)
+	api.HandleFunc("/nft/{contract}", nftHandler.GetCollection).Methods(http.MethodGet)
+	api.HandleFunc("/nft/{contract}/{tokenId}", nftHandler.GetNFT).Methods(http.MethodGet)
+	api.HandleFunc("/nft/{contract}/{tokenId}/transfers", nftHandler.GetNFTTransfers).Methods(http.MethodGet)
+	api.HandleFunc("/search", searchHandler.Search).Methods(http.MethodGet)
 
 	return r
 }
@@ -1,37 +1,490 @@
 package rest
 
 import (
+	"encoding/json"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
 	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/internal_transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/reward"
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
 )
 
 type BlockResponse struct {
-	Hash          string `json:"hash"`
-	Number        string `json:"number"`
-	MinerHash     string `json:"miner_hash"`
-	ParentHash    string `json:"parent_hash"`
-	GasLimit      uint64 `json:"gas_limit"`
-	GasUsed       uint64 `json:"gas_used"`
-	Nonce         uint64 `json:"nonce"`
-	Size          uint64 `json:"size"`
-	Difficulty    string `json:"difficulty"`
-	IsPos         bool   `json:"is_pos"`
-	BaseFeePerGas string `json:"base_fee_per_gas"`
-	Timestamp     uint64 `json:"timestamp"`
-}
-
-func MapBlockToCurrentBlockResponse(block *block.Block) *BlockResponse {
+	Hash              string `json:"hash"`
+	Number            string `json:"number"`
+	MinerHash         string `json:"miner_hash"`
+	ParentHash        string `json:"parent_hash"`
+	GasLimit          uint64 `json:"gas_limit"`
+	GasUsed           uint64 `json:"gas_used"`
+	Nonce             uint64 `json:"nonce"`
+	Size              uint64 `json:"size"`
+	Difficulty        string `json:"difficulty"`
+	IsPos             bool   `json:"is_pos"`
+	BaseFeePerGas     string `json:"base_fee_per_gas"`
+	TransactionsCount int    `json:"transactions_count"`
+	WithdrawalsCount  int    `json:"withdrawals_count"`
+	Timestamp         uint64 `json:"timestamp"`
+}
+
+func MapBlockToResponse(block *block.Block) *BlockResponse {
 	return &BlockResponse{
-		Hash:          block.Hash.String(),
-		Number:        block.Number.String(),
-		MinerHash:     block.MinerHash.String(),
-		ParentHash:    block.ParentHash.String(),
-		GasLimit:      block.GasLimit,
-		GasUsed:       block.GasUsed,
-		Nonce:         block.Nonce,
-		Size:          block.Size,
-		Difficulty:    block.Difficulty.String(),
-		IsPos:         block.IsPos,
-		BaseFeePerGas: block.BaseFeePerGas.String(),
-		Timestamp:     block.Timestamp,
+		Hash:              block.Hash.String(),
+		Number:            block.Number.String(),
+		MinerHash:         block.MinerHash.String(),
+		ParentHash:        block.ParentHash.String(),
+		GasLimit:          block.GasLimit,
+		GasUsed:           block.GasUsed,
+		Nonce:             block.Nonce,
+		Size:              block.Size,
+		Difficulty:        block.Difficulty.String(),
+		IsPos:             block.IsPos,
+		BaseFeePerGas:     block.BaseFeePerGas.String(),
+		TransactionsCount: block.TransactionsCount,
+		WithdrawalsCount:  block.WithdrawalsCount,
+		Timestamp:         block.Timestamp,
+	}
+}
+
+type TransactionResponse struct {
+	Hash        string `json:"hash"`
+	BlockHash   string `json:"block_hash"`
+	BlockNumber string `json:"block_number"`
+	Index       int    `json:"index"`
+	Type        uint8  `json:"type"`
+	Status      uint64 `json:"status"`
+	Gas         uint64 `json:"gas"`
+	GasUsed     uint64 `json:"gas_used"`
+	Input       string `json:"input"`
+	Value       string `json:"value"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Nonce       uint64 `json:"nonce"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+func MapTransactionToResponse(t *transaction.Transaction) *TransactionResponse {
+	return &TransactionResponse{
+		Hash:        t.Hash.String(),
+		BlockHash:   t.BlockHash.String(),
+		BlockNumber: t.BlockNumber.String(),
+		Index:       t.Index,
+		Type:        t.Type,
+		Status:      t.Status,
+		Gas:         t.Gas,
+		GasUsed:     t.GasUsed,
+		Input:       domain.HexFromBinary(t.Input),
+		Value:       t.Value.String(),
+		From:        t.From.String(),
+		To:          t.To.String(),
+		Nonce:       t.Nonce,
+		Timestamp:   t.Timestamp,
+	}
+}
+
+// TransactionDetailsResponse is the transaction page, Fee is null when core indexed the transaction without its encoding
+type TransactionDetailsResponse struct {
+	*TransactionResponse
+	Fee                  *FeeResponse                   `json:"fee"`
+	Logs                 []*LogResponse                 `json:"logs"`
+	TokenTransfers       []*TokenTransferResponse       `json:"token_transfers"`
+	InternalTransactions []*InternalTransactionResponse `json:"internal_transactions"`
+}
+
+type FeeResponse struct {
+	GasPrice             string  `json:"gas_price"`
+	MaxFeePerGas         *string `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *string `json:"max_priority_fee_per_gas"`
+	BaseFeePerGas        string  `json:"base_fee_per_gas"`
+	Total                string  `json:"total"`
+	Burnt                string  `json:"burnt"`
+	Priority             string  `json:"priority"`
+}
+
+type LogResponse struct {
+	Index           uint               `json:"index"`
+	TransactionHash string             `json:"transaction_hash"`
+	BlockNumber     string             `json:"block_number"`
+	Address         string             `json:"address"`
+	Topics          []string           `json:"topics"`
+	Data            string             `json:"data"`
+	Event           *transaction.Event `json:"event"`
+}
+
+type TokenTransferResponse struct {
+	TransactionHash string  `json:"transaction_hash"`
+	BlockNumber     string  `json:"block_number"`
+	Timestamp       int64   `json:"timestamp"`
+	LogIndex        int     `json:"log_index"`
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	TokenAddress    string  `json:"token_address"`
+	TokenName       *string `json:"token_name"`
+	TokenSymbol     *string `json:"token_symbol"`
+	TokenDecimals   *int    `json:"token_decimals"`
+	Amount          string  `json:"amount"`
+	// AmountNormalized is the amount in units of the token, null when the token is not indexed
+	AmountNormalized *string `json:"amount_normalized"`
+	// TokenId is null for ERC-20 transfers
+	TokenId *string `json:"token_id"`
+}
+
+type InternalTransactionResponse struct {
+	TransactionHash string `json:"transaction_hash"`
+	Index           int    `json:"index"`
+	Status          int    `json:"status"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	ContractAddress string `json:"contract_address"`
+	Value           string `json:"value"`
+	Gas             uint64 `json:"gas"`
+	GasUsed         uint64 `json:"gas_used"`
+	Input           string `json:"input"`
+	Output          string `json:"output"`
+	Timestamp       int64  `json:"timestamp"`
+}
+
+func MapTransactionDetailsToResponse(details *service.TransactionDetails) *TransactionDetailsResponse {
+	response := &TransactionDetailsResponse{
+		TransactionResponse:  MapTransactionToResponse(details.Transaction),
+		Logs:                 make([]*LogResponse, len(details.Logs)),
+		TokenTransfers:       make([]*TokenTransferResponse, len(details.TokenTransfers)),
+		InternalTransactions: make([]*InternalTransactionResponse, len(details.InternalTransactions)),
+	}
+
+	if fee := details.Fee; fee != nil {
+		response.Fee = &FeeResponse{
+			GasPrice:      fee.GasPrice.String(),
+			BaseFeePerGas: fee.BaseFeePerGas.String(),
+			Total:         fee.Total.String(),
+			Burnt:         fee.Burnt.String(),
+			Priority:      fee.Priority.String(),
+		}
+		if fee.MaxFeePerGas != nil {
+			maxFee, maxPriorityFee := fee.MaxFeePerGas.String(), fee.MaxPriorityFeePerGas.String()
+			response.Fee.MaxFeePerGas, response.Fee.MaxPriorityFeePerGas = &maxFee, &maxPriorityFee
+		}
+	}
+
+	for i, l := range details.Logs {
+		response.Logs[i] = MapLogToResponse(l)
+	}
+
+	for i, t := range details.TokenTransfers {
+		response.TokenTransfers[i] = MapTokenTransferToResponse(t)
+	}
+
+	for i, it := range details.InternalTransactions {
+		response.InternalTransactions[i] = MapInternalTransactionToResponse(it)
+	}
+
+	return response
+}
+
+func MapLogToResponse(l *transaction.DecodedLog) *LogResponse {
+	topics := make([]string, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i] = topic.String()
+	}
+
+	return &LogResponse{
+		Index:           l.Index,
+		TransactionHash: l.TransactionHash.String(),
+		BlockNumber:     l.BlockNumber.String(),
+		Address:         l.Address.String(),
+		Topics:          topics,
+		Data:            domain.HexFromBinary(l.Data),
+		Event:           l.Event,
+	}
+}
+
+func MapTokenTransferToResponse(t *token.TokenTransfer) *TokenTransferResponse {
+	response := &TokenTransferResponse{
+		TransactionHash: t.TransactionHash.String(),
+		BlockNumber:     t.BlockNumber.String(),
+		Timestamp:       t.Timestamp,
+		LogIndex:        t.LogIndex,
+		From:            t.From.String(),
+		To:              t.To.String(),
+		TokenAddress:    t.TokenContractAddress.String(),
+		Amount:          t.Amount.String(),
+	}
+	if t.TokenId != nil {
+		tokenId := t.TokenId.String()
+		response.TokenId = &tokenId
+	}
+	if t.Token != nil {
+		amount := t.Amount.FormatUnits(t.Token.Decimals)
+		response.TokenName = &t.Token.Name
+		response.TokenSymbol = &t.Token.Symbol
+		response.TokenDecimals = &t.Token.Decimals
+		response.AmountNormalized = &amount
+	}
+
+	return response
+}
+
+func MapInternalTransactionToResponse(it *internal_transaction.InternalTransaction) *InternalTransactionResponse {
+	return &InternalTransactionResponse{
+		TransactionHash: it.TransactionHash.String(),
+		Index:           it.Index,
+		Status:          it.Status,
+		From:            it.From.String(),
+		To:              it.To.String(),
+		ContractAddress: it.ContractAddress.String(),
+		Value:           it.Value.String(),
+		Gas:             it.Gas,
+		GasUsed:         it.GasUsed,
+		Input:           domain.HexFromBinary(it.Input),
+		Output:          domain.HexFromBinary(it.Output),
+		Timestamp:       it.Timestamp,
+	}
+}
+
+type WithdrawalResponse struct {
+	Index          uint64 `json:"index"`
+	BlockHash      string `json:"block_hash"`
+	Address        string `json:"address"`
+	ValidatorIndex uint64 `json:"validator_index"`
+	Amount         uint64 `json:"amount"`
+}
+
+func MapWithdrawalToResponse(w *withdrawal.Withdrawal) *WithdrawalResponse {
+	return &WithdrawalResponse{
+		Index:          w.Index,
+		BlockHash:      w.BlockHash.String(),
+		Address:        w.AddressHash.String(),
+		ValidatorIndex: w.ValidatorIndex,
+		Amount:         w.Amount,
+	}
+}
+
+// AddressResponse is the address overview, Balance is null because native balances are not indexed
+type AddressResponse struct {
+	Address            string  `json:"address"`
+	TransactionsCount  int64   `json:"transactions_count"`
+	FirstSeen          *int64  `json:"first_seen"`
+	LastSeen           *int64  `json:"last_seen"`
+	IsContract         bool    `json:"is_contract"`
+	TokenHoldingsCount int64   `json:"token_holdings_count"`
+	Balance            *string `json:"balance"`
+}
+
+func MapAddressSummaryToResponse(s *address.Summary) *AddressResponse {
+	response := &AddressResponse{
+		Address:            s.Address.String(),
+		TransactionsCount:  s.TransactionsCount,
+		FirstSeen:          s.FirstSeen,
+		LastSeen:           s.LastSeen,
+		IsContract:         s.IsContract,
+		TokenHoldingsCount: s.TokenHoldingsCount,
+	}
+	if s.Balance != nil {
+		balance := s.Balance.String()
+		response.Balance = &balance
+	}
+
+	return response
+}
+
+type RewardResponse struct {
+	BlockHash   string `json:"block_hash"`
+	BlockNumber string `json:"block_number"`
+	Address     string `json:"address"`
+	Amount      string `json:"amount"`
+}
+
+func MapRewardToResponse(r *reward.Reward) *RewardResponse {
+	return &RewardResponse{
+		BlockHash:   r.BlockHash.String(),
+		BlockNumber: r.BlockNumber.String(),
+		Address:     r.Address.String(),
+		Amount:      r.Amount.String(),
+	}
+}
+
+// TokenResponse carries the raw total supply and the supply in units of the token, both null until core
+// has read the supply
+type TokenResponse struct {
+	Address               string  `json:"address"`
+	Type                  string  `json:"type"`
+	Name                  string  `json:"name"`
+	Symbol                string  `json:"symbol"`
+	Decimals              int     `json:"decimals"`
+	TotalSupply           *string `json:"total_supply"`
+	TotalSupplyNormalized *string `json:"total_supply_normalized"`
+}
+
+func MapTokenToResponse(t *token.Token) *TokenResponse {
+	response := &TokenResponse{
+		Address:  t.Address.String(),
+		Type:     t.Type,
+		Name:     t.Name,
+		Symbol:   t.Symbol,
+		Decimals: t.Decimals,
+	}
+	if t.TotalSupply != nil {
+		supply, normalized := t.TotalSupply.String(), t.TotalSupply.FormatUnits(t.Decimals)
+		response.TotalSupply, response.TotalSupplyNormalized = &supply, &normalized
+	}
+
+	return response
+}
+
+type TokenDetailsResponse struct {
+	*TokenResponse
+	HoldersCount   int64 `json:"holders_count"`
+	TransfersCount int64 `json:"transfers_count"`
+}
+
+func MapTokenDetailsToResponse(d *token.Details) *TokenDetailsResponse {
+	return &TokenDetailsResponse{
+		TokenResponse:  MapTokenToResponse(d.Token),
+		HoldersCount:   d.HoldersCount,
+		TransfersCount: d.TransfersCount,
+	}
+}
+
+type HolderResponse struct {
+	Address           string `json:"address"`
+	Balance           string `json:"balance"`
+	BalanceNormalized string `json:"balance_normalized"`
+}
+
+// MapHolderToResponse normalizes the balance with the decimals of t, instance counts are kept as they are
+func MapHolderToResponse(t *token.Token, h *token.Holder) *HolderResponse {
+	response := &HolderResponse{
+		Address:           h.Address.String(),
+		Balance:           h.Balance.String(),
+		BalanceNormalized: h.Balance.String(),
+	}
+	if !t.HoldsInstances() {
+		response.BalanceNormalized = h.Balance.FormatUnits(t.Decimals)
+	}
+
+	return response
+}
+
+type CollectionResponse struct {
+	*TokenResponse
+	OwnersCount    int64 `json:"owners_count"`
+	Supply         int64 `json:"supply"`
+	TransfersCount int64 `json:"transfers_count"`
+}
+
+func MapCollectionToResponse(c *token.CollectionStats) *CollectionResponse {
+	return &CollectionResponse{
+		TokenResponse:  MapTokenToResponse(c.Token),
+		OwnersCount:    c.OwnersCount,
+		Supply:         c.Supply,
+		TransfersCount: c.TransfersCount,
+	}
+}
+
+// NFTResponse is an instance, Metadata is null until core has fetched it
+type NFTResponse struct {
+	Contract  string                               `json:"contract"`
+	TokenId   string                               `json:"token_id"`
+	Owner     string                               `json:"owner"`
+	Metadata  *json.RawMessage                     `json:"metadata"`
+	Token     *TokenResponse                       `json:"token"`
+	Transfers PageResponse[*TokenTransferResponse] `json:"transfers"`
+}
+
+func MapNFTDetailsToResponse(d *service.NFTDetails) *NFTResponse {
+	return &NFTResponse{
+		Contract:  d.Instance.TokenContractAddress.String(),
+		TokenId:   d.Instance.TokenId.String(),
+		Owner:     d.Instance.OwnerAddress.String(),
+		Metadata:  d.Instance.Metadata,
+		Token:     MapTokenToResponse(d.Instance.Token),
+		Transfers: mapPage(d.Transfers, d.NextCursor, MapTokenTransferToResponse),
+	}
+}
+
+type InventoryItemResponse struct {
+	TokenId  string           `json:"token_id"`
+	Metadata *json.RawMessage `json:"metadata"`
+}
+
+// InventoryCollectionResponse holds the instances of one collection in the page, OwnedCount counts all of them
+type InventoryCollectionResponse struct {
+	Token      *TokenResponse           `json:"token"`
+	OwnedCount int64                    `json:"owned_count"`
+	Instances  []*InventoryItemResponse `json:"instances"`
+}
+
+func MapCollectionInventoryToResponse(c *token.Collection) *InventoryCollectionResponse {
+	response := &InventoryCollectionResponse{
+		Token:      MapTokenToResponse(c.Token),
+		OwnedCount: c.OwnedCount,
+		Instances:  make([]*InventoryItemResponse, len(c.Instances)),
+	}
+	for i, instance := range c.Instances {
+		response.Instances[i] = &InventoryItemResponse{TokenId: instance.TokenId.String(), Metadata: instance.Metadata}
 	}
+
+	return response
+}
+
+// ContractResponse describes a verified contract, without its source and ABI
+type ContractResponse struct {
+	Address         string `json:"address"`
+	Name            string `json:"name"`
+	CompilerVersion string `json:"compiler_version"`
+	EvmVersion      string `json:"evm_version"`
+	VerifiedByEth   bool   `json:"verified_by_eth"`
+}
+
+func MapContractToResponse(c *smartcontract.SmartContract) *ContractResponse {
+	return &ContractResponse{
+		Address:         c.AddressHash.String(),
+		Name:            c.Name,
+		CompilerVersion: c.CompilerVersion,
+		EvmVersion:      c.EvmVersion,
+		VerifiedByEth:   c.VerifiedByEth,
+	}
+}
+
+// SearchResultResponse sets the one field named by Type
+type SearchResultResponse struct {
+	Type        service.ResultType   `json:"type"`
+	Block       *BlockResponse       `json:"block,omitempty"`
+	Transaction *TransactionResponse `json:"transaction,omitempty"`
+	Address     *AddressResponse     `json:"address,omitempty"`
+	Token       *TokenResponse       `json:"token,omitempty"`
+	Contract    *ContractResponse    `json:"contract,omitempty"`
+}
+
+type SearchResponse struct {
+	Query   string                  `json:"query"`
+	Kind    service.QueryKind       `json:"kind"`
+	Results []*SearchResultResponse `json:"results"`
+}
+
+func MapSearchResultsToResponse(s *service.SearchResults) *SearchResponse {
+	response := &SearchResponse{Query: s.Query, Kind: s.Kind, Results: make([]*SearchResultResponse, len(s.Results))}
+	for i, result := range s.Results {
+		item := &SearchResultResponse{Type: result.Type}
+		switch result.Type {
+		case service.ResultBlock:
+			item.Block = MapBlockToResponse(result.Block)
+		case service.ResultTransaction:
+			item.Transaction = MapTransactionToResponse(result.Transaction)
+		case service.ResultAddress:
+			item.Address = MapAddressSummaryToResponse(result.Address)
+		case service.ResultToken:
+			item.Token = MapTokenToResponse(result.Token)
+		case service.ResultContract:
+			item.Contract = MapContractToResponse(result.Contract)
+		}
+		response.Results[i] = item
+	}
+
+	return response
 }
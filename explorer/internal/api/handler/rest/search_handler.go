@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"go.uber.org/zap"
+)
+
+type SearchHandler struct {
+	searchService *service.SearchService
+	log           *zap.Logger
+}
+
+func NewSearchHandler(searchService *service.SearchService, log *zap.Logger) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		log:           log,
+	}
+}
+
+// Search serves /search, q is classified and matched against blocks, transactions, addresses, tokens
+// and verified contracts, limit caps the ranked results
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.searchService.Search(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to search", err)
+		return
+	}
+
+	writeJSON(w, h.log, MapSearchResultsToResponse(results))
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type TokenHandler struct {
+	tokenService *service.TokenService
+	log          *zap.Logger
+}
+
+func NewTokenHandler(tokenService *service.TokenService, log *zap.Logger) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+		log:          log,
+	}
+}
+
+// GetTokens serves /tokens ordered by name, q matches the symbol or the name and type keeps one standard
+func (h *TokenHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := token.Filter{Query: query.Get("q"), Type: query.Get("type"), Limit: limit}
+
+	tokens, next, err := h.tokenService.GetTokens(r.Context(), filter, query.Get("cursor"))
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get tokens", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(tokens, next, MapTokenToResponse))
+}
+
+// GetToken serves /token/{address} with the metadata, supply and activity of the token
+func (h *TokenHandler) GetToken(w http.ResponseWriter, r *http.Request) {
+	addr, err := service.ParseAddress(mux.Vars(r)["address"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.tokenService.GetToken(r.Context(), addr)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get token", err)
+		return
+	}
+
+	writeJSON(w, h.log, MapTokenDetailsToResponse(details))
+}
+
+// GetTokenTransfers serves /token/{address}/transfers newest first
+func (h *TokenHandler) GetTokenTransfers(w http.ResponseWriter, r *http.Request) {
+	addr, limit, err := parseAddressLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, transfers, next, err := h.tokenService.GetTokenTransfers(r.Context(), addr, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get token transfers", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(transfers, next, MapTokenTransferToResponse))
+}
+
+// GetTokenHolders serves /token/{address}/holders by balance, largest first
+func (h *TokenHandler) GetTokenHolders(w http.ResponseWriter, r *http.Request) {
+	addr, limit, err := parseAddressLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t, holders, next, err := h.tokenService.GetTokenHolders(r.Context(), addr, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get token holders", err)
+		return
+	}
+
+	writeJSON(w, h.log, mapPage(holders, next, func(holder *token.Holder) *HolderResponse {
+		return MapHolderToResponse(t, holder)
+	}))
+}
@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type TransactionHandler struct {
+	transactionService *service.TransactionService
+	log                *zap.Logger
+}
+
+func NewTransactionHandler(transactionService *service.TransactionService, log *zap.Logger) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		log:                log,
+	}
+}
+
+// GetTransaction serves /tx/{hash} with the fee breakdown, decoded logs, token transfers and internal transactions
+func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	hash, err := service.ParseTransactionHash(mux.Vars(r)["hash"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.transactionService.GetTransaction(r.Context(), hash)
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get transaction", err)
+		return
+	}
+
+	writeJSON(w, h.log, MapTransactionDetailsToResponse(details))
+}
+
+// GetTransactions serves /txs, filtered by block (number or hash), from, to, status and method (a 4 byte
+// selector), ordered by block number and index in order (desc by default)
+func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transactions, next, err := h.transactionService.GetTransactions(r.Context(), filter, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeServiceError(w, h.log, "Failed to get transactions", err)
+		return
+	}
+
+	response := PageResponse[*TransactionResponse]{Items: make([]*TransactionResponse, len(transactions)), NextCursor: next}
+	for i, t := range transactions {
+		response.Items[i] = MapTransactionToResponse(t)
+	}
+
+	writeJSON(w, h.log, response)
+}
+
+func parseTransactionFilter(r *http.Request) (transaction.Filter, error) {
+	query := r.URL.Query()
+	filter := transaction.Filter{Descending: true}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Descending = false
+	default:
+		return filter, fmt.Errorf("%w: order must be asc or desc", service.ErrInvalidQuery)
+	}
+
+	if raw := query.Get("block"); raw != "" {
+		id, err := service.ParseBlockID(raw)
+		if err != nil {
+			return filter, err
+		}
+		if id.Number != nil {
+			filter.BlockNumber = id.Number
+		} else {
+			filter.BlockHash = &id.Hash
+		}
+	}
+
+	var err error
+	if filter.From, err = queryAddress(r, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = queryAddress(r, "to"); err != nil {
+		return filter, err
+	}
+
+	if filter.Status, err = queryUint(r, "status"); err != nil {
+		return filter, err
+	}
+	if filter.Status != nil && *filter.Status > 1 {
+		return filter, fmt.Errorf("%w: status must be 0 or 1", service.ErrInvalidQuery)
+	}
+
+	if raw := query.Get("method"); raw != "" {
+		selector, err := hexutil.Decode(raw)
+		if err != nil || len(selector) != 4 {
+			return filter, fmt.Errorf("%w: method must be a 0x prefixed 4 byte selector", service.ErrInvalidQuery)
+		}
+		filter.MethodSelector = selector
+	}
+
+	if filter.Limit, err = queryLimit(r); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/config"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/pkg/health"
+)
+
+// registerHealthChecks registers the explorer's readiness checks: database reachability and data freshness
+func registerHealthChecks(checker *health.Checker, db Database, blockRepository block.Repository, cfg *config.Config) {
+	checker.Register(cfg.Database.Driver, db.Ping)
+
+	checker.Register("data_freshness", func(ctx context.Context) error {
+		latest, err := blockRepository.GetCurrentBlock(ctx)
+		if err != nil {
+			return err
+		}
+		if latest == nil {
+			return errors.New("no blocks indexed yet")
+		}
+
+		age := time.Since(time.Unix(int64(latest.Timestamp), 0))
+		if age > cfg.Health.MaxBlockAge {
+			return fmt.Errorf("latest block %s is %s old, exceeds %s", latest.Number.String(), age.Truncate(time.Second), cfg.Health.MaxBlockAge)
+		}
+
+		return nil
+	})
+}
@@ -6,16 +6,29 @@ import (
 	"github.com/elmiringos/indexer/explorer/internal/api/service"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
-func NewGRPCServer(blockService *service.BlockService, log *zap.Logger) *grpc.Server {
+func NewGRPCServer(
+	blockService *service.BlockService,
+	transactionService *service.TransactionService,
+	logService *service.LogService,
+	healthServer *grpchealth.Server,
+	log *zap.Logger,
+) *grpc.Server {
 	s := grpc.NewServer()
 
 	// Inititalize handlers
-	blockHandler := grpchandlers.NewBlockHandler(blockService, log)
+	explorerHandler := &grpchandlers.ExplorerHandler{
+		BlockHandler:       grpchandlers.NewBlockHandler(blockService, log),
+		TransactionHandler: grpchandlers.NewTransactionHandler(transactionService, log),
+		LogHandler:         grpchandlers.NewLogHandler(logService, log),
+	}
 
-	pb.RegisterExplorerServiceServer(s, blockHandler)
+	pb.RegisterExplorerServiceServer(s, explorerHandler)
+	healthpb.RegisterHealthServer(s, healthServer)
 
 	reflection.Register(s)
 
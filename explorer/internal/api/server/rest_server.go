@@ -5,8 +5,12 @@ import (
 
 	"go.uber.org/zap"
 
+	etherscanhandler "github.com/elmiringos/indexer/explorer/internal/api/handler/etherscan"
+	graphqlhandler "github.com/elmiringos/indexer/explorer/internal/api/handler/graphql"
+	jsonrpchandler "github.com/elmiringos/indexer/explorer/internal/api/handler/jsonrpc"
 	resthandler "github.com/elmiringos/indexer/explorer/internal/api/handler/rest"
 	"github.com/elmiringos/indexer/explorer/internal/api/service"
+	"github.com/elmiringos/indexer/explorer/pkg/health"
 )
 
 type HTTPServer struct {
@@ -15,9 +19,23 @@ type HTTPServer struct {
 
 func NewRESTServer(
 	blockService *service.BlockService,
+	transactionService *service.TransactionService,
+	addressService *service.AddressService,
+	logService *service.LogService,
+	tokenService *service.TokenService,
+	nftService *service.NFTService,
+	searchService *service.SearchService,
+	etherscanService *service.EtherscanService,
+	rpcServer *jsonrpchandler.Server,
+	graphqlHandler *graphqlhandler.Handler,
+	checker *health.Checker,
 	log *zap.Logger,
 ) *HTTPServer {
-	router := resthandler.NewRouter(blockService, log)
+	router := resthandler.NewRouter(blockService, transactionService, addressService, logService, tokenService, nftService, searchService, checker, log)
+	router.Handle("/api", etherscanhandler.NewRouter(etherscanService, log)).Methods(http.MethodGet, http.MethodPost)
+	// GET upgrades to a WebSocket, a plain GET is answered with 200 for health checks
+	router.Handle("/rpc", rpcServer).Methods(http.MethodGet, http.MethodPost)
+	router.Handle("/graphql", graphqlHandler).Methods(http.MethodGet, http.MethodPost)
 
 	return &HTTPServer{
 		router: router,
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/internal_transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/reward"
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidAddress = errors.New("address must be a 0x prefixed 20 byte address")
+)
+
+// ParseAddress accepts a 0x prefixed address
+func ParseAddress(s string) (common.Address, error) {
+	data, err := hexutil.Decode(s)
+	if err != nil || len(data) != common.AddressLength {
+		return common.Address{}, ErrInvalidAddress
+	}
+
+	return common.BytesToAddress(data), nil
+}
+
+// AddressService serves the history of an address, every listing is newest first
+type AddressService struct {
+	addressRepository             address.Repository
+	transactionRepository         transaction.Repository
+	internalTransactionRepository internal_transaction.Repository
+	tokenRepository               token.Repository
+	withdrawalRepository          withdrawal.Repository
+	rewardRepository              reward.Repository
+	logDecoder                    *logDecoder
+	logger                        *zap.Logger
+}
+
+func NewAddressService(
+	addressRepository address.Repository,
+	transactionRepository transaction.Repository,
+	internalTransactionRepository internal_transaction.Repository,
+	tokenRepository token.Repository,
+	withdrawalRepository withdrawal.Repository,
+	rewardRepository reward.Repository,
+	smartContractRepository smartcontract.Repository,
+	logger *zap.Logger,
+) *AddressService {
+	return &AddressService{
+		addressRepository:             addressRepository,
+		transactionRepository:         transactionRepository,
+		internalTransactionRepository: internalTransactionRepository,
+		tokenRepository:               tokenRepository,
+		withdrawalRepository:          withdrawalRepository,
+		rewardRepository:              rewardRepository,
+		logDecoder:                    newLogDecoder(smartContractRepository, logger),
+		logger:                        logger,
+	}
+}
+
+func (s *AddressService) GetSummary(ctx context.Context, addr common.Address) (*address.Summary, error) {
+	summary, err := s.addressRepository.GetSummary(ctx, addr)
+	if err != nil {
+		s.logger.Error("Failed to get address summary", zap.Error(err))
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (s *AddressService) GetTransactions(ctx context.Context, addr common.Address, direction address.Direction, cursor string, limit int) ([]*transaction.Transaction, string, error) {
+	filter := transaction.AddressFilter{Address: addr, Direction: direction}
+	if err := checkDirection(direction); err != nil {
+		return nil, "", err
+	}
+
+	if cursor != "" {
+		parts, err := cursorInts(cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = &transaction.AddressCursor{Timestamp: parts[0], Index: int(parts[1])}
+	}
+
+	limit = pageSize(limit)
+	filter.Limit = limit + 1
+
+	transactions, err := s.transactionRepository.GetAddressTransactions(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get address transactions", zap.Error(err))
+		return nil, "", err
+	}
+
+	transactions, next := nextPage(transactions, limit, func(t *transaction.Transaction) string {
+		return domain.EncodeCursor(strconv.FormatInt(t.Timestamp, 10), strconv.Itoa(t.Index))
+	})
+	return transactions, next, nil
+}
+
+func (s *AddressService) GetInternalTransactions(ctx context.Context, addr common.Address, direction address.Direction, cursor string, limit int) ([]*internal_transaction.InternalTransaction, string, error) {
+	filter := internal_transaction.AddressFilter{Address: addr, Direction: direction}
+	if err := checkDirection(direction); err != nil {
+		return nil, "", err
+	}
+
+	if cursor != "" {
+		parts, err := cursorInts(cursor, 2)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = &internal_transaction.Cursor{Timestamp: parts[0], Index: int(parts[1])}
+	}
+
+	limit = pageSize(limit)
+	filter.Limit = limit + 1
+
+	internalTransactions, err := s.internalTransactionRepository.GetAddressInternalTransactions(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get address internal transactions", zap.Error(err))
+		return nil, "", err
+	}
+
+	internalTransactions, next := nextPage(internalTransactions, limit, func(i *internal_transaction.InternalTransaction) string {
+		return domain.EncodeCursor(strconv.FormatInt(i.Timestamp, 10), strconv.Itoa(i.Index))
+	})
+	return internalTransactions, next, nil
+}
+
+func (s *AddressService) GetTokenTransfers(ctx context.Context, addr common.Address, direction address.Direction, cursor string, limit int) ([]*token.TokenTransfer, string, error) {
+	filter := token.TransferFilter{Address: addr, Direction: direction}
+	if err := checkDirection(direction); err != nil {
+		return nil, "", err
+	}
+
+	after, err := decodeTransferCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	filter.After = after
+
+	limit = pageSize(limit)
+	filter.Limit = limit + 1
+
+	transfers, err := s.tokenRepository.GetAddressTokenTransfers(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get address token transfers", zap.Error(err))
+		return nil, "", err
+	}
+
+	transfers, next := nextPage(transfers, limit, encodeTransferCursor)
+	return transfers, next, nil
+}
+
+// GetLogs returns a page of the logs the address emitted, decoded like the logs of a transaction
+func (s *AddressService) GetLogs(ctx context.Context, addr common.Address, cursor string, limit int) ([]*transaction.DecodedLog, string, error) {
+	filter := transaction.LogFilter{Addresses: []common.Address{addr}, Descending: true}
+
+	if cursor != "" {
+		after, err := decodeLogCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = after
+	}
+
+	limit = pageSize(limit)
+	filter.Limit = limit + 1
+
+	logs, err := s.transactionRepository.GetLogs(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get address logs", zap.Error(err))
+		return nil, "", err
+	}
+
+	logs, next := nextPage(logs, limit, encodeLogCursor)
+
+	decoded, err := s.logDecoder.decode(ctx, logs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return decoded, next, nil
+}
+
+// GetWithdrawals returns a page of the withdrawals to the address, by withdrawal index
+func (s *AddressService) GetWithdrawals(ctx context.Context, addr common.Address, cursor string, limit int) ([]*withdrawal.Withdrawal, string, error) {
+	var before *uint64
+	if cursor != "" {
+		parts, err := cursorInts(cursor, 1)
+		if err != nil {
+			return nil, "", err
+		}
+		index := uint64(parts[0])
+		before = &index
+	}
+
+	limit = pageSize(limit)
+	withdrawals, err := s.withdrawalRepository.GetAddressWithdrawals(ctx, addr, before, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to get address withdrawals", zap.Error(err))
+		return nil, "", err
+	}
+
+	withdrawals, next := nextPage(withdrawals, limit, func(w *withdrawal.Withdrawal) string {
+		return domain.EncodeCursor(strconv.FormatUint(w.Index, 10))
+	})
+	return withdrawals, next, nil
+}
+
+// GetRewards returns a page of the block rewards paid to the address, by block number
+func (s *AddressService) GetRewards(ctx context.Context, addr common.Address, cursor string, limit int) ([]*reward.Reward, string, error) {
+	var before *domain.BigInt
+	if cursor != "" {
+		parts, err := domain.DecodeCursor(cursor, 1)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		number, ok := new(big.Int).SetString(parts[0], 10)
+		if !ok {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		before = (*domain.BigInt)(number)
+	}
+
+	limit = pageSize(limit)
+	rewards, err := s.rewardRepository.GetAddressRewards(ctx, addr, before, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to get address rewards", zap.Error(err))
+		return nil, "", err
+	}
+
+	rewards, next := nextPage(rewards, limit, func(r *reward.Reward) string {
+		return domain.EncodeCursor(r.BlockNumber.String())
+	})
+	return rewards, next, nil
+}
+
+func checkDirection(direction address.Direction) error {
+	switch direction {
+	case address.DirectionAny, address.DirectionIn, address.DirectionOut:
+		return nil
+	}
+	return fmt.Errorf("%w: direction must be in or out", ErrInvalidQuery)
+}
+
+// cursorInts decodes a cursor of n non negative integers
+func cursorInts(cursor string, n int) ([]int64, error) {
+	parts, err := domain.DecodeCursor(cursor, n)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+
+	values := make([]int64, n)
+	for i, part := range parts {
+		value, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// nextPage drops the extra item fetched to know whether another page exists and returns the cursor
+// of the last item kept, empty on the last page
+func nextPage[T any](items []T, limit int, cursor func(T) string) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+	return items, cursor(items[limit-1])
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/internal_transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/reward"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestAddressService(db *sql.DB) *AddressService {
+	return NewAddressService(
+		repository.NewSQLiteAddressRepository(db),
+		repository.NewTransactionRepository(db, zap.NewNop()),
+		repository.NewInternalTransactionRepository(db),
+		repository.NewSQLiteTokenRepository(db),
+		repository.NewWithdrawalRepository(db),
+		repository.NewRewardRepository(db),
+		repository.NewSmartContractRepository(db),
+		zap.NewNop(),
+	)
+}
+
+// walkPages follows the cursors of a listing to its last page and returns the key of every item in order
+func walkPages[T any](t *testing.T, page func(cursor string) ([]T, string, error), key func(T) string) []string {
+	t.Helper()
+
+	var (
+		keys   []string
+		cursor string
+	)
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 20, "the cursor must make progress")
+
+		items, next, err := page(cursor)
+		require.NoError(t, err)
+		for _, item := range items {
+			keys = append(keys, key(item))
+		}
+
+		if next == "" {
+			return keys
+		}
+		cursor = next
+	}
+}
+
+// addressHistory seeds blocks 1..4 with the history of holder: in every block it sends the first transaction
+// and receives the second, the third of block 2 it sends to itself and the others are between strangers
+type addressHistory struct {
+	db                      *sql.DB
+	holder, other, contract common.Address
+}
+
+func newAddressHistory(t *testing.T) *addressHistory {
+	h := &addressHistory{
+		db:       testdb.Open(t),
+		holder:   common.HexToAddress("0x0a"),
+		other:    common.HexToAddress("0x0b"),
+		contract: common.HexToAddress("0x0c"),
+	}
+	stranger := common.HexToAddress("0x0d")
+
+	testdb.InsertSmartContract(t, h.db, testdb.SmartContract{Address: h.contract, Name: "Factory", ABI: "[]"})
+	testdb.InsertToken(t, h.db, testdb.Token{Address: h.contract, Name: "Token", Symbol: "TKN", Decimals: 18})
+	for number := int64(1); number <= 4; number++ {
+		testdb.InsertBlock(t, h.db, testdb.Block{Number: number, TransactionsCount: 3})
+		testdb.InsertTransaction(t, h.db, testdb.Transaction{BlockNumber: number, Index: 0, From: h.holder, To: h.other})
+		testdb.InsertTransaction(t, h.db, testdb.Transaction{BlockNumber: number, Index: 1, From: h.other, To: h.holder})
+		if number == 2 {
+			testdb.InsertTransaction(t, h.db, testdb.Transaction{BlockNumber: number, Index: 2, From: h.holder, To: h.holder})
+		} else {
+			testdb.InsertTransaction(t, h.db, testdb.Transaction{BlockNumber: number, Index: 2, From: stranger, To: h.other})
+		}
+	}
+
+	return h
+}
+
+func TestAddressService_GetTransactionsPages(t *testing.T) {
+	h := newAddressHistory(t)
+	s := newTestAddressService(h.db)
+	position := func(tx *transaction.Transaction) string {
+		return fmt.Sprintf("%d:%d", int64Of(tx.BlockNumber), tx.Index)
+	}
+	pages := func(direction address.Direction, limit int) []string {
+		return walkPages(t, func(cursor string) ([]*transaction.Transaction, string, error) {
+			return s.GetTransactions(context.Background(), h.holder, direction, cursor, limit)
+		}, position)
+	}
+
+	// the transaction holder sent itself is listed once
+	assert.Equal(t, []string{"4:1", "4:0", "3:1", "3:0", "2:2", "2:1", "2:0", "1:1", "1:0"}, pages(address.DirectionAny, 2))
+	assert.Equal(t, []string{"4:0", "3:0", "2:2", "2:0", "1:0"}, pages(address.DirectionOut, 3))
+	assert.Equal(t, []string{"4:1", "3:1", "2:2", "2:1", "1:1"}, pages(address.DirectionIn, 1))
+
+	_, _, err := s.GetTransactions(context.Background(), h.holder, "both", "", 0)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, _, err = s.GetTransactions(context.Background(), h.holder, address.DirectionAny, "x", 0)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestAddressService_GetInternalTransactionsPages(t *testing.T) {
+	h := newAddressHistory(t)
+	s := newTestAddressService(h.db)
+	for number := int64(3); number <= 4; number++ {
+		testdb.InsertInternalTransaction(t, h.db, testdb.InternalTransaction{BlockNumber: number, Index: 0, From: h.holder, To: h.other, Contract: h.contract})
+		testdb.InsertInternalTransaction(t, h.db, testdb.InternalTransaction{BlockNumber: number, Index: 1, From: h.other, To: h.holder, Contract: h.contract})
+		testdb.InsertInternalTransaction(t, h.db, testdb.InternalTransaction{BlockNumber: number, Index: 2, From: h.holder, To: h.holder, Contract: h.contract})
+	}
+
+	position := func(i *internal_transaction.InternalTransaction) string {
+		return fmt.Sprintf("%s:%d", i.BlockHash.Big(), i.Index)
+	}
+	pages := func(direction address.Direction) []string {
+		return walkPages(t, func(cursor string) ([]*internal_transaction.InternalTransaction, string, error) {
+			return s.GetInternalTransactions(context.Background(), h.holder, direction, cursor, 2)
+		}, position)
+	}
+
+	assert.Equal(t, []string{"4:2", "4:1", "4:0", "3:2", "3:1", "3:0"}, pages(address.DirectionAny))
+	assert.Equal(t, []string{"4:2", "4:0", "3:2", "3:0"}, pages(address.DirectionOut))
+	assert.Equal(t, []string{"4:2", "4:1", "3:2", "3:1"}, pages(address.DirectionIn))
+}
+
+func TestAddressService_GetTokenTransfersPages(t *testing.T) {
+	h := newAddressHistory(t)
+	s := newTestAddressService(h.db)
+
+	// two transfers in one transaction break a page between their logs
+	for number := int64(1); number <= 2; number++ {
+		testdb.InsertTokenTransfer(t, h.db, testdb.TokenTransfer{BlockNumber: number, LogIndex: 0, Token: h.contract, From: h.holder, To: h.other, Amount: "1"})
+		testdb.InsertTokenTransfer(t, h.db, testdb.TokenTransfer{BlockNumber: number, LogIndex: 1, Token: h.contract, From: h.other, To: h.holder, Amount: "2"})
+		testdb.InsertTokenTransfer(t, h.db, testdb.TokenTransfer{BlockNumber: number, TransactionIndex: 1, LogIndex: 2, Token: h.contract, From: h.holder, To: h.holder, Amount: "3"})
+	}
+
+	position := func(transfer *token.TokenTransfer) string {
+		return fmt.Sprintf("%d:%d:%d", int64Of(transfer.BlockNumber), transfer.TransactionIndex, transfer.LogIndex)
+	}
+	pages := func(direction address.Direction, limit int) []string {
+		return walkPages(t, func(cursor string) ([]*token.TokenTransfer, string, error) {
+			return s.GetTokenTransfers(context.Background(), h.holder, direction, cursor, limit)
+		}, position)
+	}
+
+	assert.Equal(t, []string{"2:1:2", "2:0:1", "2:0:0", "1:1:2", "1:0:1", "1:0:0"}, pages(address.DirectionAny, 2))
+	assert.Equal(t, []string{"2:1:2", "2:0:0", "1:1:2", "1:0:0"}, pages(address.DirectionOut, 1))
+	assert.Equal(t, []string{"2:1:2", "2:0:1", "1:1:2", "1:0:1"}, pages(address.DirectionIn, 3))
+
+	transfers, _, err := s.GetTokenTransfers(context.Background(), h.holder, address.DirectionAny, "", 1)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.NotNil(t, transfers[0].Token)
+	assert.Equal(t, "TKN", transfers[0].Token.Symbol)
+}
+
+func TestAddressService_GetWithdrawalsAndRewardsPages(t *testing.T) {
+	h := newAddressHistory(t)
+	s := newTestAddressService(h.db)
+	index := uint64(0)
+	for number := int64(1); number <= 4; number++ {
+		for _, recipient := range []common.Address{h.holder, h.other, h.holder} {
+			testdb.InsertWithdrawal(t, h.db, number, index, recipient, 100)
+			index++
+		}
+		testdb.InsertReward(t, h.db, number, h.holder, "2000000000000000000")
+	}
+	testdb.InsertReward(t, h.db, 2, h.other, "1")
+
+	withdrawals := walkPages(t, func(cursor string) ([]*withdrawal.Withdrawal, string, error) {
+		return s.GetWithdrawals(context.Background(), h.holder, cursor, 3)
+	}, func(w *withdrawal.Withdrawal) string { return fmt.Sprint(w.Index) })
+	assert.Equal(t, []string{"11", "9", "8", "6", "5", "3", "2", "0"}, withdrawals)
+
+	rewards := walkPages(t, func(cursor string) ([]*reward.Reward, string, error) {
+		return s.GetRewards(context.Background(), h.holder, cursor, 3)
+	}, func(r *reward.Reward) string { return r.BlockNumber.String() + ":" + r.Amount.String() })
+	assert.Equal(t, []string{"4:2000000000000000000", "3:2000000000000000000", "2:2000000000000000000", "1:2000000000000000000"}, rewards)
+
+	_, _, err := s.GetWithdrawals(context.Background(), h.holder, "x", 3)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, _, err = s.GetRewards(context.Background(), h.holder, "x", 3)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestAddressService_GetSummary(t *testing.T) {
+	h := newAddressHistory(t)
+	s := newTestAddressService(h.db)
+
+	summary, err := s.GetSummary(context.Background(), h.holder)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), summary.TransactionsCount)
+	require.NotNil(t, summary.FirstSeen)
+	assert.Equal(t, testdb.Timestamp(1), *summary.FirstSeen)
+	assert.Equal(t, testdb.Timestamp(4), *summary.LastSeen)
+	assert.False(t, summary.IsContract)
+
+	// an address nothing touched has no activity, a verified contract is a contract
+	summary, err = s.GetSummary(context.Background(), common.HexToAddress("0xff"))
+	require.NoError(t, err)
+	assert.Zero(t, summary.TransactionsCount)
+	assert.Nil(t, summary.FirstSeen)
+
+	summary, err = s.GetSummary(context.Background(), h.contract)
+	require.NoError(t, err)
+	assert.True(t, summary.IsContract)
+}
@@ -2,20 +2,72 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
 
+	"github.com/elmiringos/indexer/explorer/internal/domain"
 	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.uber.org/zap"
 )
 
+const (
+	DefaultPageSize = 25
+	MaxPageSize     = 100
+)
+
+var (
+	ErrBlockNotFound  = errors.New("block not found")
+	ErrInvalidBlockID = errors.New("block id must be a decimal number or a 0x prefixed hash")
+	ErrInvalidQuery   = errors.New("invalid query")
+)
+
+// BlockID identifies a block by number, or by hash when Number is nil
+type BlockID struct {
+	Number *domain.BigInt
+	Hash   common.Hash
+}
+
+// ParseBlockID accepts a decimal block number or a 0x prefixed block hash
+func ParseBlockID(id string) (BlockID, error) {
+	if data, err := hexutil.Decode(id); err == nil {
+		if len(data) != common.HashLength {
+			return BlockID{}, ErrInvalidBlockID
+		}
+		return BlockID{Hash: common.BytesToHash(data)}, nil
+	}
+
+	number, ok := new(big.Int).SetString(id, 10)
+	if !ok || number.Sign() < 0 {
+		return BlockID{}, ErrInvalidBlockID
+	}
+
+	return BlockID{Number: (*domain.BigInt)(number)}, nil
+}
+
 type BlockService struct {
-	Blockrepository block.Repository
-	logger          *zap.Logger
+	Blockrepository       block.Repository
+	transactionRepository transaction.Repository
+	withdrawalRepository  withdrawal.Repository
+	logger                *zap.Logger
 }
 
-func NewBlockService(blockRepository block.Repository, logger *zap.Logger) *BlockService {
+func NewBlockService(
+	blockRepository block.Repository,
+	transactionRepository transaction.Repository,
+	withdrawalRepository withdrawal.Repository,
+	logger *zap.Logger,
+) *BlockService {
 	return &BlockService{
-		Blockrepository: blockRepository,
-		logger:          logger,
+		Blockrepository:       blockRepository,
+		transactionRepository: transactionRepository,
+		withdrawalRepository:  withdrawalRepository,
+		logger:                logger,
 	}
 }
 
@@ -28,3 +80,135 @@ func (s *BlockService) GetCurrentBlock() (*block.Block, error) {
 
 	return block, nil
 }
+
+func (s *BlockService) GetBlock(ctx context.Context, id BlockID) (*block.Block, error) {
+	b, err := s.Blockrepository.GetBlock(ctx, id.Number, id.Hash)
+	if err != nil {
+		s.logger.Error("Failed to get block", zap.Error(err))
+		return nil, err
+	}
+
+	if b == nil {
+		return nil, ErrBlockNotFound
+	}
+
+	return b, nil
+}
+
+// GetBlocks returns a page of blocks and the cursor of the next page, empty on the last page.
+// filter.After is taken from cursor and filter.Limit is clamped to MaxPageSize.
+func (s *BlockService) GetBlocks(ctx context.Context, filter block.Filter, cursor string) ([]*block.Block, string, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = block.SortByNumber
+	}
+	switch filter.SortBy {
+	case block.SortByNumber, block.SortByGasUsed, block.SortByTransactionsCount:
+	default:
+		return nil, "", fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, filter.SortBy)
+	}
+
+	if cursor != "" {
+		parts, err := domain.DecodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		value, okValue := new(big.Int).SetString(parts[0], 10)
+		number, okNumber := new(big.Int).SetString(parts[1], 10)
+		if !okValue || !okNumber {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		filter.After = &block.Cursor{Value: domain.BigInt(*value), Number: domain.BigInt(*number)}
+	}
+
+	limit := pageSize(filter.Limit)
+	// fetch one extra block to know whether another page exists
+	filter.Limit = limit + 1
+
+	blocks, err := s.Blockrepository.GetBlocks(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get blocks", zap.Error(err))
+		return nil, "", err
+	}
+
+	next := ""
+	if len(blocks) > limit {
+		blocks = blocks[:limit]
+		last := blocks[limit-1]
+		next = domain.EncodeCursor(blockSortValue(last, filter.SortBy), last.Number.String())
+	}
+
+	return blocks, next, nil
+}
+
+func blockSortValue(b *block.Block, sortBy block.SortField) string {
+	switch sortBy {
+	case block.SortByGasUsed:
+		return strconv.FormatUint(b.GasUsed, 10)
+	case block.SortByTransactionsCount:
+		return strconv.Itoa(b.TransactionsCount)
+	default:
+		return b.Number.String()
+	}
+}
+
+// GetBlockTransactions returns a page of the block's transactions by index and the cursor of the next page
+func (s *BlockService) GetBlockTransactions(ctx context.Context, id BlockID, cursor string, limit int) ([]*transaction.Transaction, string, error) {
+	var afterIndex *int
+	if cursor != "" {
+		parts, err := domain.DecodeCursor(cursor, 1)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		index, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		afterIndex = &index
+	}
+
+	b, err := s.GetBlock(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	limit = pageSize(limit)
+	transactions, err := s.transactionRepository.GetBlockTransactions(ctx, b.Hash, afterIndex, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to get block transactions", zap.Error(err))
+		return nil, "", err
+	}
+
+	next := ""
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		next = domain.EncodeCursor(strconv.Itoa(transactions[limit-1].Index))
+	}
+
+	return transactions, next, nil
+}
+
+// GetBlockWithdrawals returns every withdrawal of the block, a block holds at most 16
+func (s *BlockService) GetBlockWithdrawals(ctx context.Context, id BlockID) ([]*withdrawal.Withdrawal, error) {
+	b, err := s.GetBlock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals, err := s.withdrawalRepository.GetBlockWithdrawals(ctx, b.Hash)
+	if err != nil {
+		s.logger.Error("Failed to get block withdrawals", zap.Error(err))
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+// pageSize applies DefaultPageSize to an unset limit and caps it at MaxPageSize
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestBlockService(db *sql.DB) *BlockService {
+	return NewBlockService(
+		repository.NewBlockRepository(db, zap.NewNop()),
+		repository.NewTransactionRepository(db, zap.NewNop()),
+		repository.NewWithdrawalRepository(db),
+		zap.NewNop(),
+	)
+}
+
+func bigInt(n int64) *domain.BigInt {
+	return (*domain.BigInt)(big.NewInt(n))
+}
+
+func int64Of(n domain.BigInt) int64 {
+	return (*big.Int)(&n).Int64()
+}
+
+// blockNumbers walks every page of the listing and returns the block numbers in the order they came
+func blockNumbers(t *testing.T, s *BlockService, filter block.Filter) []int64 {
+	t.Helper()
+
+	var (
+		numbers []int64
+		cursor  string
+	)
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "the cursor must make progress")
+
+		blocks, next, err := s.GetBlocks(context.Background(), filter, cursor)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(blocks), filter.Limit)
+		for _, b := range blocks {
+			numbers = append(numbers, int64Of(b.Number))
+		}
+
+		if next == "" {
+			return numbers
+		}
+		cursor = next
+	}
+}
+
+func TestBlockService_GetBlocksPages(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestBlockService(db)
+	for number := int64(1); number <= 7; number++ {
+		// odd blocks used more gas, the ties are ordered by number
+		gasUsed := uint64(3)
+		if number%2 == 1 {
+			gasUsed = 5
+		}
+		testdb.InsertBlock(t, db, testdb.Block{Number: number, GasUsed: gasUsed, TransactionsCount: int(number % 3)})
+	}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7}, blockNumbers(t, s, block.Filter{Limit: 3}))
+	assert.Equal(t, []int64{7, 6, 5, 4, 3, 2, 1}, blockNumbers(t, s, block.Filter{Descending: true, Limit: 2}))
+	assert.Equal(t, []int64{3, 4, 5}, blockNumbers(t, s, block.Filter{FromNumber: bigInt(3), ToNumber: bigInt(5), Limit: 2}))
+
+	assert.Equal(t, []int64{7, 5, 3, 1, 6, 4, 2},
+		blockNumbers(t, s, block.Filter{SortBy: block.SortByGasUsed, Descending: true, Limit: 3}))
+	assert.Equal(t, []int64{3, 6, 1, 4, 7, 2, 5},
+		blockNumbers(t, s, block.Filter{SortBy: block.SortByTransactionsCount, Limit: 2}))
+
+	// the extra block fetched to detect a next page is never returned, an exact last page has no cursor
+	blocks, next, err := s.GetBlocks(context.Background(), block.Filter{Limit: 7}, "")
+	require.NoError(t, err)
+	assert.Len(t, blocks, 7)
+	assert.Empty(t, next)
+}
+
+func TestBlockService_GetBlocksLimit(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestBlockService(db)
+	for number := int64(0); number <= MaxPageSize; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number})
+	}
+
+	blocks, next, err := s.GetBlocks(context.Background(), block.Filter{}, "")
+	require.NoError(t, err)
+	assert.Len(t, blocks, DefaultPageSize)
+	assert.NotEmpty(t, next)
+
+	blocks, next, err = s.GetBlocks(context.Background(), block.Filter{Limit: MaxPageSize + 50}, "")
+	require.NoError(t, err)
+	assert.Len(t, blocks, MaxPageSize)
+	assert.NotEmpty(t, next)
+}
+
+func TestBlockService_GetBlocksInvalid(t *testing.T) {
+	s := newTestBlockService(testdb.Open(t))
+	ctx := context.Background()
+
+	_, _, err := s.GetBlocks(ctx, block.Filter{SortBy: "miner"}, "")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	for _, cursor := range []string{"not base64!", domain.EncodeCursor("1"), domain.EncodeCursor("a", "1")} {
+		_, _, err = s.GetBlocks(ctx, block.Filter{}, cursor)
+		assert.ErrorIs(t, err, ErrInvalidQuery, cursor)
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor, cursor)
+	}
+}
+
+func TestBlockService_GetBlockTransactionsPages(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestBlockService(db)
+	ctx := context.Background()
+	hash := testdb.InsertBlock(t, db, testdb.Block{Number: 9, TransactionsCount: 5})
+	for index := 0; index < 5; index++ {
+		testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 9, Index: index})
+	}
+
+	var (
+		indexes []int
+		cursor  string
+	)
+	for {
+		transactions, next, err := s.GetBlockTransactions(ctx, BlockID{Hash: hash}, cursor, 2)
+		require.NoError(t, err)
+		for _, tx := range transactions {
+			assert.Equal(t, testdb.TransactionHash(9, tx.Index), tx.Hash)
+			assert.Equal(t, int64(9), int64Of(tx.BlockNumber))
+			indexes = append(indexes, tx.Index)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, indexes)
+
+	_, _, err := s.GetBlockTransactions(ctx, BlockID{Number: bigInt(10)}, "", 2)
+	assert.ErrorIs(t, err, ErrBlockNotFound)
+	_, _, err = s.GetBlockTransactions(ctx, BlockID{Hash: hash}, domain.EncodeCursor("x"), 2)
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func TestBlockService_GetBlock(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestBlockService(db)
+	ctx := context.Background()
+	testdb.InsertBlock(t, db, testdb.Block{Number: 0})
+	hash := testdb.InsertBlock(t, db, testdb.Block{Number: 1})
+
+	for _, id := range []string{"1", hash.Hex()} {
+		blockID, err := ParseBlockID(id)
+		require.NoError(t, err)
+		b, err := s.GetBlock(ctx, blockID)
+		require.NoError(t, err)
+		assert.Equal(t, hash, b.Hash, id)
+	}
+
+	// block 0 is looked up by number, not mistaken for an unset number
+	genesis, err := ParseBlockID("0")
+	require.NoError(t, err)
+	b, err := s.GetBlock(ctx, genesis)
+	require.NoError(t, err)
+	assert.Equal(t, testdb.BlockHash(0), b.Hash)
+
+	missing, err := ParseBlockID("2")
+	require.NoError(t, err)
+	_, err = s.GetBlock(ctx, missing)
+	assert.ErrorIs(t, err, ErrBlockNotFound)
+
+	for _, id := range []string{"-1", "0x1234", "latest", ""} {
+		_, err := ParseBlockID(id)
+		assert.ErrorIs(t, err, ErrInvalidBlockID, id)
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrContractNotVerified = errors.New("contract source code not verified")
+)
+
+// TransactionWithFee is a transaction with what its sender paid, Fee is nil when the transaction was
+// indexed without its encoding
+type TransactionWithFee struct {
+	*transaction.Transaction
+	Fee *transaction.Fee
+}
+
+// TransferWithTransaction is a token transfer with the transaction that made it
+type TransferWithTransaction struct {
+	*token.TokenTransfer
+	Transaction *TransactionWithFee
+}
+
+// LogWithTransaction is a log with the transaction that emitted it
+type LogWithTransaction struct {
+	*transaction.TransactionLog
+	Transaction *TransactionWithFee
+}
+
+// EtherscanService reads what the Etherscan compatible API serves. Its listings are paged by offset,
+// callers bound the offset, there is no cursor to keep deep pages cheap.
+type EtherscanService struct {
+	blockRepository         block.Repository
+	transactionRepository   transaction.Repository
+	tokenRepository         token.Repository
+	smartContractRepository smartcontract.Repository
+	logger                  *zap.Logger
+}
+
+func NewEtherscanService(
+	blockRepository block.Repository,
+	transactionRepository transaction.Repository,
+	tokenRepository token.Repository,
+	smartContractRepository smartcontract.Repository,
+	logger *zap.Logger,
+) *EtherscanService {
+	return &EtherscanService{
+		blockRepository:         blockRepository,
+		transactionRepository:   transactionRepository,
+		tokenRepository:         tokenRepository,
+		smartContractRepository: smartContractRepository,
+		logger:                  logger,
+	}
+}
+
+// GetLatestBlockNumber returns the number of the newest indexed block, the listings count confirmations from it
+func (s *EtherscanService) GetLatestBlockNumber(ctx context.Context) (domain.BigInt, error) {
+	b, err := s.blockRepository.GetCurrentBlock(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get current block", zap.Error(err))
+		return domain.BigInt{}, err
+	}
+	if b == nil {
+		return domain.BigIntZero(), nil
+	}
+
+	return b.Number, nil
+}
+
+func (s *EtherscanService) GetAccountTransactions(ctx context.Context, filter transaction.AccountFilter) ([]*TransactionWithFee, error) {
+	if err := checkBlockRange(filter.FromBlock, filter.ToBlock); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactionRepository.GetAccountTransactions(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get account transactions", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*TransactionWithFee, len(transactions))
+	for i, tx := range transactions {
+		result[i] = &TransactionWithFee{Transaction: tx, Fee: transactionFee(tx, s.logger)}
+	}
+
+	return result, nil
+}
+
+// GetAccountTokenTransfers returns the transfers matching filter, which names an address, a token or both
+func (s *EtherscanService) GetAccountTokenTransfers(ctx context.Context, filter token.AccountTransferFilter) ([]*TransferWithTransaction, error) {
+	if filter.Address == nil && filter.Token == nil {
+		return nil, fmt.Errorf("%w: an address or a token is required", ErrInvalidQuery)
+	}
+	if err := checkBlockRange(filter.FromBlock, filter.ToBlock); err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.tokenRepository.GetAccountTokenTransfers(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get account token transfers", zap.Error(err))
+		return nil, err
+	}
+
+	hashes := make([]common.Hash, len(transfers))
+	for i, transfer := range transfers {
+		hashes[i] = transfer.TransactionHash
+	}
+	transactions, err := s.getTransactions(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*TransferWithTransaction, len(transfers))
+	for i, transfer := range transfers {
+		result[i] = &TransferWithTransaction{TokenTransfer: transfer, Transaction: transactions[transfer.TransactionHash]}
+	}
+
+	return result, nil
+}
+
+// GetLogs returns the logs matching filter like LogService.GetLogs does, paged by filter.Offset
+func (s *EtherscanService) GetLogs(ctx context.Context, filter transaction.LogFilter) ([]*LogWithTransaction, error) {
+	if err := checkLogFilter(filter); err != nil {
+		return nil, err
+	}
+
+	logs, err := s.transactionRepository.GetLogs(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get logs", zap.Error(err))
+		return nil, err
+	}
+
+	hashes := make([]common.Hash, len(logs))
+	for i, l := range logs {
+		hashes[i] = l.TransactionHash
+	}
+	transactions, err := s.getTransactions(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*LogWithTransaction, len(logs))
+	for i, l := range logs {
+		result[i] = &LogWithTransaction{TransactionLog: l, Transaction: transactions[l.TransactionHash]}
+	}
+
+	return result, nil
+}
+
+// getTransactions reads the transactions of hashes once each, keyed by hash
+func (s *EtherscanService) getTransactions(ctx context.Context, hashes []common.Hash) (map[common.Hash]*TransactionWithFee, error) {
+	seen := make(map[common.Hash]bool, len(hashes))
+	unique := make([]common.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		if !seen[hash] {
+			seen[hash] = true
+			unique = append(unique, hash)
+		}
+	}
+
+	transactions, err := s.transactionRepository.GetTransactionsByHash(ctx, unique)
+	if err != nil {
+		s.logger.Error("Failed to get transactions", zap.Error(err))
+		return nil, err
+	}
+
+	result := make(map[common.Hash]*TransactionWithFee, len(transactions))
+	for _, tx := range transactions {
+		result[tx.Hash] = &TransactionWithFee{Transaction: tx, Fee: transactionFee(tx, s.logger)}
+	}
+
+	return result, nil
+}
+
+// GetBlockByTimestamp returns the last block at or before timestamp, or the first at or after it when
+// after is set
+func (s *EtherscanService) GetBlockByTimestamp(ctx context.Context, timestamp uint64, after bool) (*block.Block, error) {
+	b, err := s.blockRepository.GetBlockByTimestamp(ctx, timestamp, after)
+	if err != nil {
+		s.logger.Error("Failed to get block by timestamp", zap.Error(err))
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBlockNotFound
+	}
+
+	return b, nil
+}
+
+// GetABI returns the JSON ABI of the verified contract at addr
+func (s *EtherscanService) GetABI(ctx context.Context, addr common.Address) (string, error) {
+	abis, err := s.smartContractRepository.GetABIs(ctx, []common.Address{addr})
+	if err != nil {
+		s.logger.Error("Failed to get contract ABI", zap.Error(err))
+		return "", err
+	}
+
+	abi, ok := abis[addr]
+	if !ok {
+		return "", ErrContractNotVerified
+	}
+
+	return abi, nil
+}
+
+// checkBlockRange rejects an inclusive block range ending before it starts
+func checkBlockRange(from, to *domain.BigInt) error {
+	if from != nil && to != nil && from.Cmp(*to) > 0 {
+		return fmt.Errorf("%w: the start block is after the end block", ErrInvalidQuery)
+	}
+
+	return nil
+}
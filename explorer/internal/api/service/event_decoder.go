@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+// standardEventsABI holds the ERC-20, ERC-721 and ERC-1155 events, the ERC-20 and ERC-721 ones share
+// their signature and differ by which arguments are indexed
+const standardEventsABI = `[
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"Approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"approved","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]},
+	{"type":"event","name":"ApprovalForAll","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"operator","type":"address","indexed":true},{"name":"approved","type":"bool","indexed":false}]},
+	{"type":"event","name":"TransferSingle","inputs":[{"name":"operator","type":"address","indexed":true},{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"id","type":"uint256","indexed":false},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"TransferBatch","inputs":[{"name":"operator","type":"address","indexed":true},{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"ids","type":"uint256[]","indexed":false},{"name":"values","type":"uint256[]","indexed":false}]}
+]`
+
+var standardEvents = mustEventDecoder(standardEventsABI)
+
+// logDecoder decodes logs for the services returning them
+type logDecoder struct {
+	smartContractRepository smartcontract.Repository
+	logger                  *zap.Logger
+}
+
+func newLogDecoder(smartContractRepository smartcontract.Repository, logger *zap.Logger) *logDecoder {
+	return &logDecoder{smartContractRepository: smartContractRepository, logger: logger}
+}
+
+// decode decodes the logs with the ABI of their verified contract, or with the token standard events
+func (d *logDecoder) decode(ctx context.Context, logs []*transaction.TransactionLog) ([]*transaction.DecodedLog, error) {
+	seen := make(map[common.Address]bool)
+	var addresses []common.Address
+	for _, l := range logs {
+		if !seen[l.Address] {
+			seen[l.Address] = true
+			addresses = append(addresses, l.Address)
+		}
+	}
+
+	abis, err := d.smartContractRepository.GetABIs(ctx, addresses)
+	if err != nil {
+		d.logger.Error("Failed to get contract ABIs", zap.Error(err))
+		return nil, err
+	}
+
+	decoders := make(map[common.Address]*eventDecoder, len(abis))
+	for address, abiJSON := range abis {
+		decoder, err := newEventDecoder(abiJSON)
+		if err != nil {
+			d.logger.Warn("Failed to parse contract ABI", zap.String("address", address.Hex()), zap.Error(err))
+			continue
+		}
+		decoders[address] = decoder
+	}
+
+	decoded := make([]*transaction.DecodedLog, len(logs))
+	for i, l := range logs {
+		var event *transaction.Event
+		if decoder, ok := decoders[l.Address]; ok {
+			event = decoder.decode(l)
+		}
+		if event == nil {
+			event = standardEvents.decode(l)
+		}
+		decoded[i] = &transaction.DecodedLog{TransactionLog: l, Event: event}
+	}
+
+	return decoded, nil
+}
+
+// eventDecoder finds the event of a log by its first topic and the number of indexed arguments
+type eventDecoder struct {
+	events map[common.Hash][]abi.Event
+}
+
+func newEventDecoder(abiJSON string) (*eventDecoder, error) {
+	// abi.JSON keys events by name, overloads like the two Transfer events get a numbered name
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := &eventDecoder{events: make(map[common.Hash][]abi.Event)}
+	for _, event := range parsed.Events {
+		if !event.Anonymous {
+			decoder.events[event.ID] = append(decoder.events[event.ID], event)
+		}
+	}
+
+	return decoder, nil
+}
+
+func mustEventDecoder(abiJSON string) *eventDecoder {
+	decoder, err := newEventDecoder(abiJSON)
+	if err != nil {
+		panic(err)
+	}
+	return decoder
+}
+
+// decode returns nil when no event matches the log or its data does not unpack
+func (d *eventDecoder) decode(log *transaction.TransactionLog) *transaction.Event {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+
+	for _, event := range d.events[log.Topics[0]] {
+		indexed := len(event.Inputs) - len(event.Inputs.NonIndexed())
+		if indexed != len(log.Topics)-1 {
+			continue
+		}
+
+		values, err := event.Inputs.NonIndexed().UnpackValues(log.Data)
+		if err != nil {
+			continue
+		}
+
+		decoded := &transaction.Event{Name: event.RawName, Signature: event.Sig, Params: make([]transaction.EventParam, len(event.Inputs))}
+		topic := 1
+		for i, input := range event.Inputs {
+			param := transaction.EventParam{Name: input.Name, Type: input.Type.String(), Indexed: input.Indexed}
+			if input.Indexed {
+				param.Value = topicValue(input, log.Topics[topic])
+				topic++
+			} else {
+				param.Value = formatValue(values[0])
+				values = values[1:]
+			}
+			decoded.Params[i] = param
+		}
+
+		return decoded
+	}
+
+	return nil
+}
+
+// topicValue decodes an indexed argument, dynamic types are stored as their hash which is returned as is
+func topicValue(input abi.Argument, topic common.Hash) string {
+	out := make(map[string]interface{}, 1)
+	if err := abi.ParseTopicsIntoMap(out, abi.Arguments{input}, []common.Hash{topic}); err != nil {
+		return topic.Hex()
+	}
+
+	return formatValue(out[input.Name])
+}
+
+// formatValue renders an unpacked value, numbers in decimal, bytes in hex and lists in brackets
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case string:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(data), rv)
+			return hexutil.Encode(data)
+		}
+
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = formatValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+
+	return fmt.Sprint(value)
+}
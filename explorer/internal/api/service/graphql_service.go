@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// TransactionPage is the first page of the transactions of a block, Next continues it with
+// BlockService.GetBlockTransactions and is empty on the last page
+type TransactionPage struct {
+	Transactions []*transaction.Transaction
+	Next         string
+}
+
+// TokenCounts is the activity of a token, see token.Details
+type TokenCounts struct {
+	HoldersCount   int64
+	TransfersCount int64
+}
+
+// GraphQLService reads what the GraphQL API resolves nested in other results. Its methods take the keys
+// of a whole level of the query at once and return what they found keyed by them, keys that match
+// nothing are left out of the result.
+type GraphQLService struct {
+	blockRepository       block.Repository
+	transactionRepository transaction.Repository
+	withdrawalRepository  withdrawal.Repository
+	tokenRepository       token.Repository
+	addressRepository     address.Repository
+	logDecoder            *logDecoder
+	logger                *zap.Logger
+}
+
+func NewGraphQLService(
+	blockRepository block.Repository,
+	transactionRepository transaction.Repository,
+	withdrawalRepository withdrawal.Repository,
+	tokenRepository token.Repository,
+	addressRepository address.Repository,
+	smartContractRepository smartcontract.Repository,
+	logger *zap.Logger,
+) *GraphQLService {
+	return &GraphQLService{
+		blockRepository:       blockRepository,
+		transactionRepository: transactionRepository,
+		withdrawalRepository:  withdrawalRepository,
+		tokenRepository:       tokenRepository,
+		addressRepository:     addressRepository,
+		logDecoder:            newLogDecoder(smartContractRepository, logger),
+		logger:                logger,
+	}
+}
+
+func (s *GraphQLService) GetBlocksByHash(ctx context.Context, hashes []common.Hash) (map[common.Hash]*block.Block, error) {
+	blocks, err := s.blockRepository.GetBlocksByHash(ctx, hashes)
+	if err != nil {
+		s.logger.Error("Failed to get blocks by hash", zap.Error(err))
+		return nil, err
+	}
+
+	result := make(map[common.Hash]*block.Block, len(blocks))
+	for _, b := range blocks {
+		result[b.Hash] = b
+	}
+
+	return result, nil
+}
+
+// GetBlocksTransactions returns the first page of limit transactions of each block
+func (s *GraphQLService) GetBlocksTransactions(ctx context.Context, blockHashes []common.Hash, limit int) (map[common.Hash]*TransactionPage, error) {
+	limit = pageSize(limit)
+	transactions, err := s.transactionRepository.GetBlocksTransactions(ctx, blockHashes, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to get blocks transactions", zap.Error(err))
+		return nil, err
+	}
+
+	result := make(map[common.Hash]*TransactionPage, len(blockHashes))
+	for _, hash := range blockHashes {
+		result[hash] = &TransactionPage{}
+	}
+	for _, tx := range transactions {
+		page := result[tx.BlockHash]
+		page.Transactions = append(page.Transactions, tx)
+	}
+	for _, page := range result {
+		page.Transactions, page.Next = nextPage(page.Transactions, limit, func(t *transaction.Transaction) string {
+			return domain.EncodeCursor(strconv.Itoa(t.Index))
+		})
+	}
+
+	return result, nil
+}
+
+// GetBlocksWithdrawals returns the withdrawals of each block ordered by index, a block holds at most 16
+func (s *GraphQLService) GetBlocksWithdrawals(ctx context.Context, blockHashes []common.Hash) (map[common.Hash][]*withdrawal.Withdrawal, error) {
+	withdrawals, err := s.withdrawalRepository.GetBlocksWithdrawals(ctx, blockHashes)
+	if err != nil {
+		s.logger.Error("Failed to get blocks withdrawals", zap.Error(err))
+		return nil, err
+	}
+
+	result := make(map[common.Hash][]*withdrawal.Withdrawal, len(blockHashes))
+	for _, w := range withdrawals {
+		result[w.BlockHash] = append(result[w.BlockHash], w)
+	}
+
+	return result, nil
+}
+
+func (s *GraphQLService) GetTransactionsByHash(ctx context.Context, hashes []common.Hash) (map[common.Hash]*transaction.Transaction, error) {
+	transactions, err := s.transactionRepository.GetTransactionsByHash(ctx, hashes)
+	if err != nil {
+		s.logger.Error("Failed to get transactions", zap.Error(err))
+		return nil, err
+	}
+
+	result := make(map[common.Hash]*transaction.Transaction, len(transactions))
+	for _, tx := range transactions {
+		result[tx.Hash] = tx
+	}
+
+	return result, nil
+}
+
+// GetTransactionsLogs returns the logs of each transaction ordered by log index, decoded like the logs
+// of a transaction
+func (s *GraphQLService) GetTransactionsLogs(ctx context.Context, hashes []common.Hash) (map[common.Hash][]*transaction.DecodedLog, error) {
+	logs, err := s.transactionRepository.GetTransactionsLogs(ctx, hashes)
+	if err != nil {
+		s.logger.Error("Failed to get transactions logs", zap.Error(err))
+		return nil, err
+	}
+
+	decoded, err := s.logDecoder.decode(ctx, logs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[common.Hash][]*transaction.DecodedLog, len(hashes))
+	for _, l := range decoded {
+		result[l.TransactionHash] = append(result[l.TransactionHash], l)
+	}
+
+	return result, nil
+}
+
+// GetFee derives what the sender of tx paid, nil when tx was indexed without its encoding
+func (s *GraphQLService) GetFee(tx *transaction.Transaction) *transaction.Fee {
+	return transactionFee(tx, s.logger)
+}
+
+func (s *GraphQLService) GetTokensByAddress(ctx context.Context, addresses []common.Address) (map[common.Address]*token.Token, error) {
+	tokens, err := s.tokenRepository.GetTokensByAddress(ctx, addresses)
+	if err != nil {
+		s.logger.Error("Failed to get tokens by address", zap.Error(err))
+		return nil, err
+	}
+
+	result := make(map[common.Address]*token.Token, len(tokens))
+	for _, t := range tokens {
+		result[t.Address] = t
+	}
+
+	return result, nil
+}
+
+// GetTokensCounts counts the holders and the transfers of each token, one query per token
+func (s *GraphQLService) GetTokensCounts(ctx context.Context, tokens []*token.Token) (map[common.Address]*TokenCounts, error) {
+	result := make(map[common.Address]*TokenCounts, len(tokens))
+	for _, t := range tokens {
+		holders, transfers, err := s.tokenRepository.GetTokenCounts(ctx, t)
+		if err != nil {
+			s.logger.Error("Failed to count token holders and transfers", zap.Error(err))
+			return nil, err
+		}
+		result[t.Address] = &TokenCounts{HoldersCount: holders, TransfersCount: transfers}
+	}
+
+	return result, nil
+}
+
+// GetSummaries describes each address, one query per address
+func (s *GraphQLService) GetSummaries(ctx context.Context, addresses []common.Address) (map[common.Address]*address.Summary, error) {
+	result := make(map[common.Address]*address.Summary, len(addresses))
+	for _, addr := range addresses {
+		summary, err := s.addressRepository.GetSummary(ctx, addr)
+		if err != nil {
+			s.logger.Error("Failed to get address summary", zap.Error(err))
+			return nil, err
+		}
+		result[addr] = summary
+	}
+
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"go.uber.org/zap"
+)
+
+// Limits of a log search, a log has at most MaxLogTopicPositions topics
+const (
+	MaxLogAddresses      = 100
+	MaxLogTopicPositions = 4
+	MaxLogTopicsPerSet   = 100
+)
+
+// LogService searches logs with the semantics of eth_getLogs
+type LogService struct {
+	transactionRepository transaction.Repository
+	logDecoder            *logDecoder
+	logger                *zap.Logger
+}
+
+func NewLogService(
+	transactionRepository transaction.Repository,
+	smartContractRepository smartcontract.Repository,
+	logger *zap.Logger,
+) *LogService {
+	return &LogService{
+		transactionRepository: transactionRepository,
+		logDecoder:            newLogDecoder(smartContractRepository, logger),
+		logger:                logger,
+	}
+}
+
+// GetLogs returns a page of the logs matching filter, decoded like the logs of a transaction.
+// filter.After is taken from cursor and filter.Limit is clamped to MaxPageSize.
+func (s *LogService) GetLogs(ctx context.Context, filter transaction.LogFilter, cursor string) ([]*transaction.DecodedLog, string, error) {
+	if err := checkLogFilter(filter); err != nil {
+		return nil, "", err
+	}
+
+	if cursor != "" {
+		after, err := decodeLogCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.After = after
+	}
+
+	limit := pageSize(filter.Limit)
+	filter.Limit = limit + 1
+
+	logs, err := s.transactionRepository.GetLogs(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get logs", zap.Error(err))
+		return nil, "", err
+	}
+
+	logs, next := nextPage(logs, limit, encodeLogCursor)
+
+	decoded, err := s.logDecoder.decode(ctx, logs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return decoded, next, nil
+}
+
+func checkLogFilter(filter transaction.LogFilter) error {
+	if len(filter.Addresses) > MaxLogAddresses {
+		return fmt.Errorf("%w: at most %d addresses", ErrInvalidQuery, MaxLogAddresses)
+	}
+
+	if len(filter.Topics) > MaxLogTopicPositions {
+		return fmt.Errorf("%w: at most %d topic positions", ErrInvalidQuery, MaxLogTopicPositions)
+	}
+	for _, topics := range filter.Topics {
+		if len(topics) > MaxLogTopicsPerSet {
+			return fmt.Errorf("%w: at most %d topics per position", ErrInvalidQuery, MaxLogTopicsPerSet)
+		}
+	}
+
+	if filter.BlockHash != nil && (filter.FromBlock != nil || filter.ToBlock != nil) {
+		return fmt.Errorf("%w: block_hash excludes from_block and to_block", ErrInvalidQuery)
+	}
+	if filter.FromBlock != nil && filter.ToBlock != nil && filter.FromBlock.Cmp(*filter.ToBlock) > 0 {
+		return fmt.Errorf("%w: from_block is after to_block", ErrInvalidQuery)
+	}
+
+	return nil
+}
+
+// decodeLogCursor reads the "number:transaction index:log index" position of a log
+func decodeLogCursor(cursor string) (*transaction.LogCursor, error) {
+	parts, err := domain.DecodeCursor(cursor, 3)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+
+	number, ok := new(big.Int).SetString(parts[0], 10)
+	txIndex, txErr := strconv.ParseUint(parts[1], 10, 32)
+	logIndex, logErr := strconv.ParseUint(parts[2], 10, 32)
+	if !ok || txErr != nil || logErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+	}
+
+	return &transaction.LogCursor{BlockNumber: domain.BigInt(*number), TransactionIndex: uint(txIndex), LogIndex: uint(logIndex)}, nil
+}
+
+func encodeLogCursor(l *transaction.TransactionLog) string {
+	return domain.EncodeCursor(l.BlockNumber.String(), strconv.FormatUint(uint64(l.TransactionIndex), 10), strconv.FormatUint(uint64(l.Index), 10))
+}
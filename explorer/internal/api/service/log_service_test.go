@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestLogService_GetLogsFilters(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewLogService(repository.NewTransactionRepository(db, zap.NewNop()), repository.NewSmartContractRepository(db), zap.NewNop())
+	a, b, c, d := common.HexToHash("0xa"), common.HexToHash("0xb"), common.HexToHash("0xc"), common.HexToHash("0xd")
+	x, y, z := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")
+
+	testdb.InsertBlock(t, db, testdb.Block{Number: 1, TransactionsCount: 1})
+	testdb.InsertBlock(t, db, testdb.Block{Number: 2, TransactionsCount: 1})
+	block3 := testdb.InsertBlock(t, db, testdb.Block{Number: 3, TransactionsCount: 2})
+	for _, position := range [][2]int{{1, 0}, {2, 0}, {3, 0}, {3, 1}} {
+		testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: int64(position[0]), Index: position[1]})
+	}
+	for _, l := range []testdb.Log{
+		{BlockNumber: 1, Index: 0, Address: x, Topics: []common.Hash{a, b}},
+		{BlockNumber: 1, Index: 1, Address: y, Topics: []common.Hash{a, c}},
+		{BlockNumber: 1, Index: 2, Address: x, Topics: []common.Hash{b}},
+		{BlockNumber: 1, Index: 3, Address: z},
+		{BlockNumber: 2, Index: 0, Address: x, Topics: []common.Hash{a, b, c}},
+		{BlockNumber: 2, Index: 1, Address: y, Topics: []common.Hash{d}},
+		{BlockNumber: 3, Index: 0, Address: x, Topics: []common.Hash{a}},
+		{BlockNumber: 3, TransactionIndex: 1, Index: 1, Address: z, Topics: []common.Hash{c, b}},
+	} {
+		testdb.InsertLog(t, db, l)
+	}
+
+	logs := func(filter transaction.LogFilter) []string {
+		return walkPages(t, func(cursor string) ([]*transaction.DecodedLog, string, error) {
+			return s.GetLogs(context.Background(), filter, cursor)
+		}, func(l *transaction.DecodedLog) string {
+			return fmt.Sprintf("%d:%d:%d", int64Of(l.BlockNumber), l.TransactionIndex, l.Index)
+		})
+	}
+
+	for name, test := range map[string]struct {
+		filter transaction.LogFilter
+		want   []string
+	}{
+		"everything": {transaction.LogFilter{}, []string{"1:0:0", "1:0:1", "1:0:2", "1:0:3", "2:0:0", "2:0:1", "3:0:0", "3:1:1"}},
+		"newest first": {transaction.LogFilter{Descending: true},
+			[]string{"3:1:1", "3:0:0", "2:0:1", "2:0:0", "1:0:3", "1:0:2", "1:0:1", "1:0:0"}},
+		"one topic":                   {transaction.LogFilter{Topics: [][]common.Hash{{a}}}, []string{"1:0:0", "1:0:1", "2:0:0", "3:0:0"}},
+		"topics of a position or":     {transaction.LogFilter{Topics: [][]common.Hash{{a, b}}}, []string{"1:0:0", "1:0:1", "1:0:2", "2:0:0", "3:0:0"}},
+		"positions and":               {transaction.LogFilter{Topics: [][]common.Hash{{a}, {b, c}}}, []string{"1:0:0", "1:0:1", "2:0:0"}},
+		"leading wildcard":            {transaction.LogFilter{Topics: [][]common.Hash{nil, {b}}}, []string{"1:0:0", "2:0:0", "3:1:1"}},
+		"wildcard needs a topic":      {transaction.LogFilter{Topics: [][]common.Hash{{}}}, []string{"1:0:0", "1:0:1", "1:0:2", "2:0:0", "2:0:1", "3:0:0", "3:1:1"}},
+		"trailing wildcard":           {transaction.LogFilter{Topics: [][]common.Hash{{a}, nil}}, []string{"1:0:0", "1:0:1", "2:0:0"}},
+		"three positions":             {transaction.LogFilter{Topics: [][]common.Hash{nil, nil, {c}}}, []string{"2:0:0"}},
+		"addresses or":                {transaction.LogFilter{Addresses: []common.Address{x, z}}, []string{"1:0:0", "1:0:2", "1:0:3", "2:0:0", "3:0:0", "3:1:1"}},
+		"addresses and topics":        {transaction.LogFilter{Addresses: []common.Address{y}, Topics: [][]common.Hash{{a}}}, []string{"1:0:1"}},
+		"block range":                 {transaction.LogFilter{FromBlock: bigInt(2), ToBlock: bigInt(2)}, []string{"2:0:0", "2:0:1"}},
+		"block hash":                  {transaction.LogFilter{BlockHash: &block3}, []string{"3:0:0", "3:1:1"}},
+		"range and topics descending": {transaction.LogFilter{FromBlock: bigInt(2), Topics: [][]common.Hash{{c, d}}, Descending: true}, []string{"3:1:1", "2:0:1"}},
+	} {
+		for _, limit := range []int{1, 3, 0} {
+			test.filter.Limit = limit
+			assert.Equal(t, test.want, logs(test.filter), "%s, limit %d", name, limit)
+		}
+	}
+}
+
+func TestLogService_GetLogsInvalid(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewLogService(repository.NewTransactionRepository(db, zap.NewNop()), repository.NewSmartContractRepository(db), zap.NewNop())
+	hash := common.HexToHash("0x01")
+
+	for name, filter := range map[string]transaction.LogFilter{
+		"addresses":          {Addresses: make([]common.Address, MaxLogAddresses+1)},
+		"positions":          {Topics: make([][]common.Hash, MaxLogTopicPositions+1)},
+		"topics":             {Topics: [][]common.Hash{nil, make([]common.Hash, MaxLogTopicsPerSet+1)}},
+		"hash and range":     {BlockHash: &hash, FromBlock: bigInt(1)},
+		"range out of order": {FromBlock: bigInt(3), ToBlock: bigInt(2)},
+	} {
+		_, _, err := s.GetLogs(context.Background(), filter, "")
+		assert.ErrorIs(t, err, ErrInvalidQuery, name)
+	}
+
+	_, _, err := s.GetLogs(context.Background(), transaction.LogFilter{}, "x")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCollectionNotFound = errors.New("nft collection not found")
+	ErrNFTNotFound        = errors.New("nft not found")
+	ErrInvalidTokenID     = errors.New("token id must be a decimal uint256")
+)
+
+var maxTokenID = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ParseTokenID accepts a decimal uint256
+func ParseTokenID(s string) (domain.BigInt, error) {
+	id, ok := new(big.Int).SetString(s, 10)
+	if !ok || id.Sign() < 0 || id.Cmp(maxTokenID) > 0 {
+		return domain.BigInt{}, ErrInvalidTokenID
+	}
+
+	return domain.BigInt(*id), nil
+}
+
+// NFTDetails is an instance with the first page of its transfers
+type NFTDetails struct {
+	Instance   *token.TokenInstance
+	Transfers  []*token.TokenTransfer
+	NextCursor string
+}
+
+// NFTService serves the instances of ERC-721 and ERC-1155 tokens
+type NFTService struct {
+	tokenRepository token.Repository
+	logger          *zap.Logger
+}
+
+func NewNFTService(tokenRepository token.Repository, logger *zap.Logger) *NFTService {
+	return &NFTService{
+		tokenRepository: tokenRepository,
+		logger:          logger,
+	}
+}
+
+func (s *NFTService) GetCollection(ctx context.Context, contract common.Address) (*token.CollectionStats, error) {
+	t, err := s.tokenRepository.GetToken(ctx, contract)
+	if err != nil {
+		s.logger.Error("Failed to get token", zap.Error(err))
+		return nil, err
+	}
+	if t == nil || !t.HoldsInstances() {
+		return nil, ErrCollectionNotFound
+	}
+
+	owners, supply, transfers, err := s.tokenRepository.GetCollectionCounts(ctx, contract)
+	if err != nil {
+		s.logger.Error("Failed to count collection", zap.Error(err))
+		return nil, err
+	}
+
+	return &token.CollectionStats{Token: t, OwnersCount: owners, Supply: supply, TransfersCount: transfers}, nil
+}
+
+// GetNFT returns the instance with the first page of its transfers, newest first
+func (s *NFTService) GetNFT(ctx context.Context, contract common.Address, tokenId domain.BigInt, limit int) (*NFTDetails, error) {
+	instance, err := s.tokenRepository.GetTokenInstance(ctx, contract, tokenId)
+	if err != nil {
+		s.logger.Error("Failed to get token instance", zap.Error(err))
+		return nil, err
+	}
+	if instance == nil {
+		return nil, ErrNFTNotFound
+	}
+
+	transfers, next, err := s.getTransfers(ctx, contract, tokenId, nil, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NFTDetails{Instance: instance, Transfers: transfers, NextCursor: next}, nil
+}
+
+// GetNFTTransfers pages the transfers of an instance past the ones GetNFT returns
+func (s *NFTService) GetNFTTransfers(ctx context.Context, contract common.Address, tokenId domain.BigInt, cursor string, limit int) ([]*token.TokenTransfer, string, error) {
+	after, err := decodeTransferCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.getTransfers(ctx, contract, tokenId, after, limit)
+}
+
+func (s *NFTService) getTransfers(ctx context.Context, contract common.Address, tokenId domain.BigInt, after *token.TransferCursor, limit int) ([]*token.TokenTransfer, string, error) {
+	limit = pageSize(limit)
+	transfers, err := s.tokenRepository.GetInstanceTransfers(ctx, contract, tokenId, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to get token instance transfers", zap.Error(err))
+		return nil, "", err
+	}
+
+	transfers, next := nextPage(transfers, limit, encodeTransferCursor)
+	return transfers, next, nil
+}
+
+// GetInventory returns a page of the instances the owner holds grouped by collection, a collection may
+// continue on the next page
+func (s *NFTService) GetInventory(ctx context.Context, owner common.Address, cursor string, limit int) ([]*token.Collection, string, error) {
+	filter := token.InventoryFilter{Owner: owner}
+	if cursor != "" {
+		parts, err := domain.DecodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		tokenId, err := ParseTokenID(parts[1])
+		if err != nil || !common.IsHexAddress(parts[0]) {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		filter.After = &token.InstanceCursor{Contract: common.HexToAddress(parts[0]), TokenId: tokenId}
+	}
+
+	limit = pageSize(limit)
+	filter.Limit = limit + 1
+
+	instances, err := s.tokenRepository.GetOwnerInstances(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get owner instances", zap.Error(err))
+		return nil, "", err
+	}
+
+	instances, next := nextPage(instances, limit, func(i *token.TokenInstance) string {
+		return domain.EncodeCursor(i.TokenContractAddress.Hex(), i.TokenId.String())
+	})
+
+	// instances come ordered by contract, each collection is a run of them
+	var collections []*token.Collection
+	var contracts []common.Address
+	for _, instance := range instances {
+		if len(collections) == 0 || collections[len(collections)-1].Token.Address != instance.TokenContractAddress {
+			collections = append(collections, &token.Collection{Token: instance.Token})
+			contracts = append(contracts, instance.TokenContractAddress)
+		}
+		last := collections[len(collections)-1]
+		last.Instances = append(last.Instances, instance)
+	}
+
+	counts, err := s.tokenRepository.GetOwnerCollectionCounts(ctx, owner, contracts)
+	if err != nil {
+		s.logger.Error("Failed to count owner collections", zap.Error(err))
+		return nil, "", err
+	}
+	for _, collection := range collections {
+		collection.OwnedCount = counts[collection.Token.Address]
+	}
+
+	return collections, next, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseTokenID(t *testing.T) {
+	for _, id := range []string{"0", "1", pow2(64, 1), pow2(256, -1)} {
+		parsed, err := ParseTokenID(id)
+		require.NoError(t, err, id)
+		assert.Equal(t, id, parsed.String())
+	}
+
+	for _, id := range []string{"", "-1", "0x1", "1.5", pow2(256, 0)} {
+		_, err := ParseTokenID(id)
+		assert.ErrorIs(t, err, ErrInvalidTokenID, id)
+	}
+}
+
+// TestNFTService_TokenIDs keeps ids around 2^64 and up to 2^256 - 1 apart, which REAL would round together,
+// and orders them as numbers rather than as text
+func TestNFTService_TokenIDs(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewNFTService(repository.NewSQLiteTokenRepository(db), zap.NewNop())
+	ctx := context.Background()
+	punks, items, coin := common.HexToAddress("0xaa"), common.HexToAddress("0xbb"), common.HexToAddress("0xcc")
+	owner, other, zero := common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), common.Address{}
+
+	testdb.InsertToken(t, db, testdb.Token{Address: punks, Name: "Punks", Symbol: "PNK", Type: token.TypeERC721})
+	testdb.InsertToken(t, db, testdb.Token{Address: items, Name: "Items", Symbol: "ITM", Type: token.TypeERC1155})
+	testdb.InsertToken(t, db, testdb.Token{Address: coin, Name: "Coin", Symbol: "CN"})
+
+	punkIDs := []string{"10", pow2(256, -1), "9", pow2(64, 1), "0", pow2(64, 0)}
+	for _, id := range punkIDs {
+		testdb.InsertTokenInstance(t, db, testdb.TokenInstance{Token: punks, TokenID: id, Owner: owner})
+	}
+	testdb.InsertTokenInstance(t, db, testdb.TokenInstance{Token: items, TokenID: "2", Owner: owner, Metadata: `{"name":"Sword"}`})
+	testdb.InsertTokenInstance(t, db, testdb.TokenInstance{Token: items, TokenID: "3", Owner: other})
+	testdb.InsertTokenInstance(t, db, testdb.TokenInstance{Token: items, TokenID: "4", Owner: zero})
+
+	// the inventory runs through the collections by contract and the ids of each in numeric order
+	var inventory []string
+	owned := map[common.Address]int64{}
+	walkPages(t, func(cursor string) ([]*token.Collection, string, error) {
+		return s.GetInventory(ctx, owner, cursor, 2)
+	}, func(c *token.Collection) string {
+		owned[c.Token.Address] = c.OwnedCount
+		for _, instance := range c.Instances {
+			inventory = append(inventory, c.Token.Symbol+" "+instance.TokenId.String())
+		}
+		return ""
+	})
+	assert.Equal(t, []string{"PNK 0", "PNK 9", "PNK 10", "PNK " + pow2(64, 0), "PNK " + pow2(64, 1), "PNK " + pow2(256, -1), "ITM 2"}, inventory)
+	assert.Equal(t, map[common.Address]int64{punks: 6, items: 1}, owned)
+
+	// a transfer of 2^64 is not one of 2^64 + 1
+	testdb.InsertBlock(t, db, testdb.Block{Number: 1, TransactionsCount: 1})
+	testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1})
+	for i, id := range []string{pow2(64, 1), pow2(64, 0), pow2(64, 1), pow2(256, -1)} {
+		testdb.InsertTokenTransfer(t, db, testdb.TokenTransfer{BlockNumber: 1, LogIndex: i, Token: punks, From: zero, To: owner, Amount: "1", TokenID: id})
+	}
+
+	id, err := ParseTokenID(pow2(64, 1))
+	require.NoError(t, err)
+	nft, err := s.GetNFT(ctx, punks, id, 1)
+	require.NoError(t, err)
+	assert.Equal(t, owner, nft.Instance.OwnerAddress)
+	require.Len(t, nft.Transfers, 1)
+	assert.Equal(t, 2, nft.Transfers[0].LogIndex)
+	require.NotNil(t, nft.Transfers[0].TokenId)
+	assert.Equal(t, pow2(64, 1), nft.Transfers[0].TokenId.String())
+
+	transfers, next, err := s.GetNFTTransfers(ctx, punks, id, nft.NextCursor, 1)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, 0, transfers[0].LogIndex)
+	assert.Empty(t, next)
+
+	maxID, err := ParseTokenID(pow2(256, -1))
+	require.NoError(t, err)
+	nft, err = s.GetNFT(ctx, punks, maxID, 10)
+	require.NoError(t, err)
+	assert.Len(t, nft.Transfers, 1)
+
+	_, err = s.GetNFT(ctx, punks, domain.BigInt(*bigInt(11)), 10)
+	assert.ErrorIs(t, err, ErrNFTNotFound)
+
+	// the instance the zero address owns is burnt
+	stats, err := s.GetCollection(ctx, items)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.OwnersCount)
+	assert.Equal(t, int64(2), stats.Supply)
+
+	_, err = s.GetCollection(ctx, coin)
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+	_, _, err = s.GetInventory(ctx, owner, domain.EncodeCursor(punks.Hex(), "-1"), 2)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// MaxRPCLogs caps the logs an eth_getLogs call returns, larger results are refused like nodes do
+const MaxRPCLogs = 10000
+
+// Receipt is a transaction with the logs it emitted
+type Receipt struct {
+	*TransactionWithFee
+	Logs []*transaction.TransactionLog
+}
+
+// RPCService reads what the JSON-RPC facade serves. Lookups of something that is not indexed return nil
+// without an error, JSON-RPC answers them with null.
+type RPCService struct {
+	blockRepository       block.Repository
+	transactionRepository transaction.Repository
+	withdrawalRepository  withdrawal.Repository
+	logger                *zap.Logger
+}
+
+func NewRPCService(
+	blockRepository block.Repository,
+	transactionRepository transaction.Repository,
+	withdrawalRepository withdrawal.Repository,
+	logger *zap.Logger,
+) *RPCService {
+	return &RPCService{
+		blockRepository:       blockRepository,
+		transactionRepository: transactionRepository,
+		withdrawalRepository:  withdrawalRepository,
+		logger:                logger,
+	}
+}
+
+// GetLatestBlockNumber returns the number of the newest indexed block, nil before the first one is indexed
+func (s *RPCService) GetLatestBlockNumber(ctx context.Context) (*domain.BigInt, error) {
+	b, err := s.blockRepository.GetCurrentBlock(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get current block", zap.Error(err))
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	return &b.Number, nil
+}
+
+// GetBlock returns the block with id.Number or id.Hash
+func (s *RPCService) GetBlock(ctx context.Context, id BlockID) (*block.Block, error) {
+	b, err := s.blockRepository.GetBlock(ctx, id.Number, id.Hash)
+	if err != nil {
+		s.logger.Error("Failed to get block", zap.Error(err))
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// GetBlockTransactions returns every transaction of b ordered by index
+func (s *RPCService) GetBlockTransactions(ctx context.Context, b *block.Block) ([]*TransactionWithFee, error) {
+	if b.TransactionsCount == 0 {
+		return nil, nil
+	}
+
+	transactions, err := s.transactionRepository.GetBlockTransactions(ctx, b.Hash, nil, b.TransactionsCount)
+	if err != nil {
+		s.logger.Error("Failed to get block transactions", zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]*TransactionWithFee, len(transactions))
+	for i, tx := range transactions {
+		result[i] = &TransactionWithFee{Transaction: tx, Fee: transactionFee(tx, s.logger)}
+	}
+
+	return result, nil
+}
+
+// GetBlockWithdrawals returns the withdrawals of b ordered by index
+func (s *RPCService) GetBlockWithdrawals(ctx context.Context, b *block.Block) ([]*withdrawal.Withdrawal, error) {
+	if b.WithdrawalsCount == 0 {
+		return nil, nil
+	}
+
+	withdrawals, err := s.withdrawalRepository.GetBlockWithdrawals(ctx, b.Hash)
+	if err != nil {
+		s.logger.Error("Failed to get block withdrawals", zap.Error(err))
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+func (s *RPCService) GetTransaction(ctx context.Context, hash common.Hash) (*TransactionWithFee, error) {
+	tx, err := s.transactionRepository.GetTransaction(ctx, hash)
+	if err != nil {
+		s.logger.Error("Failed to get transaction", zap.Error(err))
+		return nil, err
+	}
+	if tx == nil {
+		return nil, nil
+	}
+
+	return &TransactionWithFee{Transaction: tx, Fee: transactionFee(tx, s.logger)}, nil
+}
+
+func (s *RPCService) GetReceipt(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	tx, err := s.GetTransaction(ctx, hash)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+
+	logs, err := s.transactionRepository.GetTransactionLogs(ctx, hash)
+	if err != nil {
+		s.logger.Error("Failed to get transaction logs", zap.Error(err))
+		return nil, err
+	}
+
+	return &Receipt{TransactionWithFee: tx, Logs: logs}, nil
+}
+
+// GetLogs returns every log matching filter, or ErrInvalidQuery when there are more than MaxRPCLogs.
+// A filter.BlockHash that is not indexed is ErrBlockNotFound.
+func (s *RPCService) GetLogs(ctx context.Context, filter transaction.LogFilter) ([]*transaction.TransactionLog, error) {
+	if err := checkLogFilter(filter); err != nil {
+		return nil, err
+	}
+	if err := checkBlockRange(filter.FromBlock, filter.ToBlock); err != nil {
+		return nil, err
+	}
+
+	if filter.BlockHash != nil {
+		b, err := s.GetBlock(ctx, BlockID{Hash: *filter.BlockHash})
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, ErrBlockNotFound
+		}
+	}
+
+	filter.Limit = MaxRPCLogs + 1
+	logs, err := s.transactionRepository.GetLogs(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get logs", zap.Error(err))
+		return nil, err
+	}
+	if len(logs) > MaxRPCLogs {
+		return nil, fmt.Errorf("%w: query returns more than %d results", ErrInvalidQuery, MaxRPCLogs)
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultSearchResults = 10
+	MaxSearchResults     = 50
+	MaxSearchQueryLength = 256
+)
+
+// QueryKind is what the input of a search was classified as
+type QueryKind string
+
+const (
+	QueryBlockNumber QueryKind = "block_number"
+	QueryHash        QueryKind = "hash"
+	QueryAddress     QueryKind = "address"
+	// QueryLabel is an ENS-like name such as vitalik.eth
+	QueryLabel QueryKind = "label"
+	QueryText  QueryKind = "text"
+)
+
+// ResultType is the kind of entity a search result points at
+type ResultType string
+
+const (
+	ResultBlock       ResultType = "block"
+	ResultTransaction ResultType = "transaction"
+	ResultAddress     ResultType = "address"
+	ResultToken       ResultType = "token"
+	ResultContract    ResultType = "contract"
+)
+
+// SearchResult holds the one entity of its Type
+type SearchResult struct {
+	Type        ResultType
+	Block       *block.Block
+	Transaction *transaction.Transaction
+	Address     *address.Summary
+	Token       *token.Token
+	Contract    *smartcontract.SmartContract
+}
+
+// SearchResults are ranked, exact identifier matches come before name matches
+type SearchResults struct {
+	Query   string
+	Kind    QueryKind
+	Results []*SearchResult
+}
+
+// labelPattern matches dot separated names ending in an alphabetic top level domain
+var labelPattern = regexp.MustCompile(`^(?i)([a-z0-9_-]+\.)+[a-z]{2,}$`)
+
+// ClassifyQuery tells what a search input is. Hashes and addresses may come without the 0x prefix.
+func ClassifyQuery(query string) QueryKind {
+	if data, err := hexutil.Decode(withHexPrefix(query)); err == nil {
+		switch len(data) {
+		case common.HashLength:
+			return QueryHash
+		case common.AddressLength:
+			return QueryAddress
+		}
+	}
+
+	if strings.Trim(query, "0123456789") == "" {
+		return QueryBlockNumber
+	}
+
+	if labelPattern.MatchString(query) {
+		return QueryLabel
+	}
+
+	return QueryText
+}
+
+// withHexPrefix adds the 0x prefix to bare hashes and addresses
+func withHexPrefix(query string) string {
+	if len(query) != 2*common.HashLength && len(query) != 2*common.AddressLength {
+		return query
+	}
+	if _, err := hexutil.Decode("0x" + query); err != nil {
+		return query
+	}
+
+	return "0x" + query
+}
+
+// SearchService resolves free form input to blocks, transactions, addresses, tokens and verified contracts
+type SearchService struct {
+	blockRepository         block.Repository
+	transactionRepository   transaction.Repository
+	addressRepository       address.Repository
+	tokenRepository         token.Repository
+	smartContractRepository smartcontract.Repository
+	logger                  *zap.Logger
+}
+
+func NewSearchService(
+	blockRepository block.Repository,
+	transactionRepository transaction.Repository,
+	addressRepository address.Repository,
+	tokenRepository token.Repository,
+	smartContractRepository smartcontract.Repository,
+	logger *zap.Logger,
+) *SearchService {
+	return &SearchService{
+		blockRepository:         blockRepository,
+		transactionRepository:   transactionRepository,
+		addressRepository:       addressRepository,
+		tokenRepository:         tokenRepository,
+		smartContractRepository: smartContractRepository,
+		logger:                  logger,
+	}
+}
+
+// Search classifies query and returns at most limit results, limit defaults to DefaultSearchResults and
+// is clamped to MaxSearchResults.
+// A hash matches a transaction or a block, an address its token, its verified contract and the address
+// itself. Block numbers are matched against names as well, and labels without their top level domain,
+// ENS names are not resolved.
+func (s *SearchService) Search(ctx context.Context, query string, limit int) (*SearchResults, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: q is required", ErrInvalidQuery)
+	}
+	if len(query) > MaxSearchQueryLength {
+		return nil, fmt.Errorf("%w: q is longer than %d bytes", ErrInvalidQuery, MaxSearchQueryLength)
+	}
+
+	switch {
+	case limit <= 0:
+		limit = DefaultSearchResults
+	case limit > MaxSearchResults:
+		limit = MaxSearchResults
+	}
+
+	results := &SearchResults{Query: query, Kind: ClassifyQuery(query)}
+
+	var (
+		found []*SearchResult
+		err   error
+	)
+	switch results.Kind {
+	case QueryHash:
+		found, err = s.searchHash(ctx, common.HexToHash(withHexPrefix(query)))
+	case QueryAddress:
+		found, err = s.searchAddress(ctx, common.HexToAddress(withHexPrefix(query)))
+	case QueryBlockNumber:
+		if found, err = s.searchBlockNumber(ctx, query); err == nil {
+			var named []*SearchResult
+			named, err = s.searchNames(ctx, query, limit)
+			found = append(found, named...)
+		}
+	case QueryLabel:
+		found, err = s.searchNames(ctx, query[:strings.LastIndex(query, ".")], limit)
+	default:
+		found, err = s.searchNames(ctx, query, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	results.Results = found
+
+	return results, nil
+}
+
+func (s *SearchService) searchHash(ctx context.Context, hash common.Hash) ([]*SearchResult, error) {
+	var found []*SearchResult
+
+	t, err := s.transactionRepository.GetTransaction(ctx, hash)
+	if err != nil {
+		s.logger.Error("Failed to search transaction", zap.Error(err))
+		return nil, err
+	}
+	if t != nil {
+		found = append(found, &SearchResult{Type: ResultTransaction, Transaction: t})
+	}
+
+	b, err := s.blockRepository.GetBlock(ctx, nil, hash)
+	if err != nil {
+		s.logger.Error("Failed to search block", zap.Error(err))
+		return nil, err
+	}
+	if b != nil {
+		found = append(found, &SearchResult{Type: ResultBlock, Block: b})
+	}
+
+	return found, nil
+}
+
+func (s *SearchService) searchAddress(ctx context.Context, addr common.Address) ([]*SearchResult, error) {
+	var found []*SearchResult
+
+	t, err := s.tokenRepository.GetToken(ctx, addr)
+	if err != nil {
+		s.logger.Error("Failed to search token", zap.Error(err))
+		return nil, err
+	}
+	if t != nil {
+		found = append(found, &SearchResult{Type: ResultToken, Token: t})
+	}
+
+	c, err := s.smartContractRepository.GetContract(ctx, addr)
+	if err != nil {
+		s.logger.Error("Failed to search contract", zap.Error(err))
+		return nil, err
+	}
+	if c != nil {
+		found = append(found, &SearchResult{Type: ResultContract, Contract: c})
+	}
+
+	// every address has a page, also without indexed activity
+	summary, err := s.addressRepository.GetSummary(ctx, addr)
+	if err != nil {
+		s.logger.Error("Failed to search address", zap.Error(err))
+		return nil, err
+	}
+
+	return append(found, &SearchResult{Type: ResultAddress, Address: summary}), nil
+}
+
+func (s *SearchService) searchBlockNumber(ctx context.Context, query string) ([]*SearchResult, error) {
+	number, _ := new(big.Int).SetString(query, 10)
+
+	b, err := s.blockRepository.GetBlock(ctx, (*domain.BigInt)(number), common.Hash{})
+	if err != nil {
+		s.logger.Error("Failed to search block", zap.Error(err))
+		return nil, err
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	return []*SearchResult{{Type: ResultBlock, Block: b}}, nil
+}
+
+// searchNames matches tokens and verified contracts by name, and tokens by symbol. Both lists come ranked
+// and are merged by rank, tokens first on a tie.
+func (s *SearchService) searchNames(ctx context.Context, query string, limit int) ([]*SearchResult, error) {
+	tokens, err := s.tokenRepository.SearchTokens(ctx, query, limit)
+	if err != nil {
+		s.logger.Error("Failed to search tokens", zap.Error(err))
+		return nil, err
+	}
+
+	contracts, err := s.smartContractRepository.SearchContracts(ctx, query, limit)
+	if err != nil {
+		s.logger.Error("Failed to search contracts", zap.Error(err))
+		return nil, err
+	}
+
+	type ranked struct {
+		result *SearchResult
+		rank   int
+	}
+	matches := make([]ranked, 0, len(tokens)+len(contracts))
+	for _, t := range tokens {
+		matches = append(matches, ranked{&SearchResult{Type: ResultToken, Token: t}, nameRank(query, t.Symbol, t.Name)})
+	}
+	for _, c := range contracts {
+		matches = append(matches, ranked{&SearchResult{Type: ResultContract, Contract: c}, nameRank(query, c.Name)})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].rank < matches[j].rank })
+
+	found := make([]*SearchResult, len(matches))
+	for i, match := range matches {
+		found[i] = match.result
+	}
+
+	return found, nil
+}
+
+// nameRank orders name matches like the repositories do: 0 for an exact match, 1 for a prefix match and
+// 2 for a match anywhere
+func nameRank(query string, names ...string) int {
+	query = strings.ToLower(query)
+	rank := 2
+	for _, name := range names {
+		name = strings.ToLower(name)
+		switch {
+		case name == query:
+			return 0
+		case strings.HasPrefix(name, query):
+			rank = 1
+		}
+	}
+
+	return rank
+}
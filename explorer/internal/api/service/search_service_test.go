@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestClassifyQuery(t *testing.T) {
+	hash := testdb.TransactionHash(1, 0).Hex()
+	addr := common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa").Hex()
+
+	for query, kind := range map[string]QueryKind{
+		hash:                         QueryHash,
+		hash[2:]:                     QueryHash,
+		"0X" + hash[2:]:              QueryHash,
+		strings.Repeat("1", 64):      QueryHash,
+		addr:                         QueryAddress,
+		strings.ToLower(addr[2:]):    QueryAddress,
+		"0":                          QueryBlockNumber,
+		"19000000":                   QueryBlockNumber,
+		"99999999999999999999999999": QueryBlockNumber,
+		"vitalik.eth":                QueryLabel,
+		"my_vault.base.xyz":          QueryLabel,
+		"USDC":                       QueryText,
+		"Wrapped Ether":              QueryText,
+		"1.5":                        QueryText,
+		"0x1234":                     QueryText,
+		hash[:65]:                    QueryText,
+		"100%":                       QueryText,
+	} {
+		assert.Equal(t, kind, ClassifyQuery(query), query)
+	}
+}
+
+func TestSearchService_Search(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewSearchService(
+		repository.NewBlockRepository(db, zap.NewNop()),
+		repository.NewTransactionRepository(db, zap.NewNop()),
+		repository.NewSQLiteAddressRepository(db),
+		repository.NewSQLiteTokenRepository(db),
+		repository.NewSmartContractRepository(db),
+		zap.NewNop(),
+	)
+	ctx := context.Background()
+	usdc, usdt, oneInch, vault := common.HexToAddress("0xa1"), common.HexToAddress("0xa2"), common.HexToAddress("0xa3"), common.HexToAddress("0xa4")
+	staked := common.HexToAddress("0xa5")
+
+	blockHash := testdb.InsertBlock(t, db, testdb.Block{Number: 1, TransactionsCount: 1})
+	transactionHash := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1})
+	testdb.InsertToken(t, db, testdb.Token{Address: usdc, Name: "USD Coin", Symbol: "USDC", Decimals: 6})
+	testdb.InsertToken(t, db, testdb.Token{Address: usdt, Name: "Tether USD", Symbol: "USDT", Decimals: 6})
+	testdb.InsertToken(t, db, testdb.Token{Address: oneInch, Name: "1inch", Symbol: "1INCH", Decimals: 18})
+	testdb.InsertToken(t, db, testdb.Token{Address: staked, Name: "Staked Dollar", Symbol: "sUSD", Decimals: 18})
+	testdb.InsertSmartContract(t, db, testdb.SmartContract{Address: usdc, Name: "FiatTokenProxy", ABI: "[]"})
+	testdb.InsertSmartContract(t, db, testdb.SmartContract{Address: vault, Name: "USD", ABI: "[]"})
+
+	// results names each by its type and address or hash
+	results := func(query string, limit int) []string {
+		t.Helper()
+
+		found, err := s.Search(ctx, query, limit)
+		require.NoError(t, err, query)
+
+		var names []string
+		for _, r := range found.Results {
+			switch r.Type {
+			case ResultBlock:
+				names = append(names, "block "+r.Block.Hash.Hex())
+			case ResultTransaction:
+				names = append(names, "transaction "+r.Transaction.Hash.Hex())
+			case ResultAddress:
+				names = append(names, "address "+r.Address.Address.Hex())
+			case ResultToken:
+				names = append(names, "token "+r.Token.Address.Hex())
+			case ResultContract:
+				names = append(names, "contract "+r.Contract.AddressHash.Hex())
+			}
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"transaction " + transactionHash.Hex()}, results(transactionHash.Hex()[2:], 0))
+	assert.Equal(t, []string{"block " + blockHash.Hex()}, results(blockHash.Hex(), 0))
+	assert.Empty(t, results(testdb.BlockHash(9).Hex(), 0))
+
+	// an address lists its token and verified contract before its own page, which every address has
+	assert.Equal(t, []string{"token " + usdc.Hex(), "contract " + usdc.Hex(), "address " + usdc.Hex()}, results(usdc.Hex(), 0))
+	assert.Equal(t, []string{"address " + common.HexToAddress("0xff").Hex()}, results(common.HexToAddress("0xff").Hex(), 0))
+
+	// a number is a block and a name, a short query matches names by prefix only
+	assert.Equal(t, []string{"block " + blockHash.Hex(), "token " + oneInch.Hex()}, results("1", 0))
+	assert.Empty(t, results("2", 0))
+
+	// the exact contract name first, then the prefix matches by name and the matches anywhere
+	assert.Equal(t, []string{"contract " + vault.Hex(), "token " + usdt.Hex(), "token " + usdc.Hex(), "token " + staked.Hex()}, results("usd", 0))
+	assert.Equal(t, []string{"contract " + vault.Hex(), "token " + usdt.Hex()}, results("usd", 2))
+	// tokens come first on a tie and a short query is only matched by prefix
+	assert.Equal(t, []string{"token " + usdt.Hex(), "token " + usdc.Hex(), "contract " + vault.Hex()}, results("US", 0))
+	assert.Equal(t, []string{"token " + usdc.Hex()}, results("usdc.eth", 0))
+
+	// like wildcards in a query are matched literally
+	assert.Empty(t, results("%%%", 0))
+	assert.Empty(t, results("U_D", 0))
+
+	for _, query := range []string{"", "   ", strings.Repeat("a", MaxSearchQueryLength+1)} {
+		_, err := s.Search(ctx, query, 0)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	}
+}
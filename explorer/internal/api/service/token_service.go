@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+)
+
+type TokenService struct {
+	tokenRepository token.Repository
+	logger          *zap.Logger
+}
+
+func NewTokenService(tokenRepository token.Repository, logger *zap.Logger) *TokenService {
+	return &TokenService{
+		tokenRepository: tokenRepository,
+		logger:          logger,
+	}
+}
+
+// GetTokens returns a page of tokens ordered by name.
+// filter.After is taken from cursor and filter.Limit is clamped to MaxPageSize.
+func (s *TokenService) GetTokens(ctx context.Context, filter token.Filter, cursor string) ([]*token.Token, string, error) {
+	switch filter.Type {
+	case "", token.TypeERC20, token.TypeERC721, token.TypeERC1155:
+	default:
+		return nil, "", fmt.Errorf("%w: type must be %s, %s or %s", ErrInvalidQuery, token.TypeERC20, token.TypeERC721, token.TypeERC1155)
+	}
+
+	// the name is hex encoded, it may hold the cursor separator
+	if cursor != "" {
+		parts, err := domain.DecodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		name, err := hex.DecodeString(parts[0])
+		if err != nil || !common.IsHexAddress(parts[1]) {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		filter.After = &token.Cursor{Name: string(name), Address: common.HexToAddress(parts[1])}
+	}
+
+	limit := pageSize(filter.Limit)
+	filter.Limit = limit + 1
+
+	tokens, err := s.tokenRepository.GetTokens(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get tokens", zap.Error(err))
+		return nil, "", err
+	}
+
+	tokens, next := nextPage(tokens, limit, func(t *token.Token) string {
+		return domain.EncodeCursor(hex.EncodeToString([]byte(t.Name)), t.Address.Hex())
+	})
+	return tokens, next, nil
+}
+
+func (s *TokenService) GetToken(ctx context.Context, addr common.Address) (*token.Details, error) {
+	t, err := s.getToken(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	holders, transfers, err := s.tokenRepository.GetTokenCounts(ctx, t)
+	if err != nil {
+		s.logger.Error("Failed to count token holders and transfers", zap.Error(err))
+		return nil, err
+	}
+
+	return &token.Details{Token: t, HoldersCount: holders, TransfersCount: transfers}, nil
+}
+
+// GetTokenTransfers returns a page of the transfers of the token newest first, with the token so callers
+// can normalize the amounts
+func (s *TokenService) GetTokenTransfers(ctx context.Context, addr common.Address, cursor string, limit int) (*token.Token, []*token.TokenTransfer, string, error) {
+	t, err := s.getToken(ctx, addr)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	after, err := decodeTransferCursor(cursor)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	limit = pageSize(limit)
+	transfers, err := s.tokenRepository.GetTokenTransfers(ctx, addr, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to get token transfers", zap.Error(err))
+		return nil, nil, "", err
+	}
+
+	transfers, next := nextPage(transfers, limit, encodeTransferCursor)
+	return t, transfers, next, nil
+}
+
+// GetTokenHolders returns a page of the holders of the token by balance, largest first, with the token
+// so callers can normalize the balances
+func (s *TokenService) GetTokenHolders(ctx context.Context, addr common.Address, cursor string, limit int) (*token.Token, []*token.Holder, string, error) {
+	t, err := s.getToken(ctx, addr)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	filter := token.HolderFilter{Token: addr, Instances: t.HoldsInstances()}
+	if cursor != "" {
+		parts, err := domain.DecodeCursor(cursor, 2)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		balance, ok := new(big.Int).SetString(parts[0], 10)
+		if !ok || balance.Sign() < 0 || !common.IsHexAddress(parts[1]) {
+			return nil, nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		filter.After = &token.HolderCursor{Balance: domain.BigInt(*balance), Address: common.HexToAddress(parts[1])}
+	}
+
+	limit = pageSize(limit)
+	filter.Limit = limit + 1
+
+	holders, err := s.tokenRepository.GetTokenHolders(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get token holders", zap.Error(err))
+		return nil, nil, "", err
+	}
+
+	holders, next := nextPage(holders, limit, func(h *token.Holder) string {
+		return domain.EncodeCursor(h.Balance.String(), h.Address.Hex())
+	})
+	return t, holders, next, nil
+}
+
+func (s *TokenService) getToken(ctx context.Context, addr common.Address) (*token.Token, error) {
+	t, err := s.tokenRepository.GetToken(ctx, addr)
+	if err != nil {
+		s.logger.Error("Failed to get token", zap.Error(err))
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return t, nil
+}
+
+// decodeTransferCursor reads the "timestamp:transaction index:log index" position of a token transfer,
+// an empty cursor is the first page
+func decodeTransferCursor(cursor string) (*token.TransferCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	parts, err := cursorInts(cursor, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token.TransferCursor{Timestamp: parts[0], TransactionIndex: int(parts[1]), LogIndex: int(parts[2])}, nil
+}
+
+func encodeTransferCursor(t *token.TokenTransfer) string {
+	return domain.EncodeCursor(strconv.FormatInt(t.Timestamp, 10), strconv.Itoa(t.TransactionIndex), strconv.Itoa(t.LogIndex))
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// pow2 returns 2^n as decimal text
+func pow2(n uint, plus int64) string {
+	return new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), n), big.NewInt(plus)).String()
+}
+
+// TestTokenService_GetTokenHoldersExact moves amounts above 2^53, which a REAL sum would round, and checks
+// the balances, their order and the holders count are exact
+func TestTokenService_GetTokenHoldersExact(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewTokenService(repository.NewSQLiteTokenRepository(db), zap.NewNop())
+	ctx := context.Background()
+	tkn := common.HexToAddress("0xaa")
+	zero := common.Address{}
+	a, b, c, d, e, f := common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), common.HexToAddress("0x0c"),
+		common.HexToAddress("0x0d"), common.HexToAddress("0x0e"), common.HexToAddress("0x0f")
+
+	testdb.InsertToken(t, db, testdb.Token{Address: tkn, Name: "Token", Symbol: "TKN", Decimals: 18})
+	testdb.InsertBlock(t, db, testdb.Block{Number: 1, TransactionsCount: 1})
+	testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1})
+	for i, move := range []struct {
+		from, to common.Address
+		amount   string
+	}{
+		{zero, a, pow2(64, 1)},
+		{a, c, pow2(64, 0)},
+		{zero, b, pow2(53, 1)},
+		{zero, d, pow2(53, 0)},
+		{zero, f, pow2(53, 0)},
+		{zero, e, pow2(70, 0)},
+		{e, zero, pow2(70, 0)},
+	} {
+		testdb.InsertTokenTransfer(t, db, testdb.TokenTransfer{BlockNumber: 1, LogIndex: i, Token: tkn, From: move.from, To: move.to, Amount: move.amount})
+	}
+
+	details, err := s.GetToken(ctx, tkn)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), details.HoldersCount)
+	assert.Equal(t, int64(7), details.TransfersCount)
+
+	// the tie between d and f is broken by address, e burnt everything it had
+	balances := walkPages(t, func(cursor string) ([]*token.Holder, string, error) {
+		_, holders, next, err := s.GetTokenHolders(ctx, tkn, cursor, 1)
+		return holders, next, err
+	}, func(h *token.Holder) string { return h.Address.Hex() + " " + h.Balance.String() })
+	assert.Equal(t, []string{
+		c.Hex() + " " + pow2(64, 0),
+		b.Hex() + " " + pow2(53, 1),
+		d.Hex() + " " + pow2(53, 0),
+		f.Hex() + " " + pow2(53, 0),
+		a.Hex() + " 1",
+	}, balances)
+
+	_, _, _, err = s.GetTokenHolders(ctx, tkn, domain.EncodeCursor("-1", a.Hex()), 1)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, _, _, err = s.GetTokenHolders(ctx, common.HexToAddress("0xbb"), "", 1)
+	assert.ErrorIs(t, err, ErrTokenNotFound)
+}
+
+// TestAddressService_GetSummaryHoldingsExact counts a token left with a balance of 1 after moves above 2^64,
+// and not one whose large moves net to zero
+func TestAddressService_GetSummaryHoldingsExact(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestAddressService(db)
+	holder, other := common.HexToAddress("0x0a"), common.HexToAddress("0x0b")
+	kept, spent := common.HexToAddress("0xaa"), common.HexToAddress("0xbb")
+
+	testdb.InsertBlock(t, db, testdb.Block{Number: 1, TransactionsCount: 1})
+	testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1})
+	for i, transfer := range []testdb.TokenTransfer{
+		{Token: kept, From: other, To: holder, Amount: pow2(64, 1)},
+		{Token: kept, From: holder, To: other, Amount: pow2(64, 0)},
+		{Token: spent, From: other, To: holder, Amount: pow2(64, 1)},
+		{Token: spent, From: holder, To: other, Amount: pow2(64, 1)},
+	} {
+		transfer.BlockNumber, transfer.LogIndex = 1, i
+		testdb.InsertTokenTransfer(t, db, transfer)
+	}
+
+	summary, err := s.GetSummary(context.Background(), holder)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), summary.TokenHoldingsCount)
+
+	// other paid out more of kept than it got back and broke even on spent
+	summary, err = s.GetSummary(context.Background(), other)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), summary.TokenHoldingsCount)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/internal_transaction"
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrTransactionNotFound    = errors.New("transaction not found")
+	ErrInvalidTransactionHash = errors.New("transaction hash must be a 0x prefixed 32 byte hash")
+)
+
+// ParseTransactionHash accepts a 0x prefixed transaction hash
+func ParseTransactionHash(hash string) (common.Hash, error) {
+	data, err := hexutil.Decode(hash)
+	if err != nil || len(data) != common.HashLength {
+		return common.Hash{}, ErrInvalidTransactionHash
+	}
+
+	return common.BytesToHash(data), nil
+}
+
+// TransactionDetails is a transaction with everything its page shows, Fee is nil when the transaction
+// was indexed without its encoding
+type TransactionDetails struct {
+	Transaction          *transaction.Transaction
+	Fee                  *transaction.Fee
+	Logs                 []*transaction.DecodedLog
+	TokenTransfers       []*token.TokenTransfer
+	InternalTransactions []*internal_transaction.InternalTransaction
+}
+
+type TransactionService struct {
+	transactionRepository         transaction.Repository
+	internalTransactionRepository internal_transaction.Repository
+	tokenRepository               token.Repository
+	logDecoder                    *logDecoder
+	logger                        *zap.Logger
+}
+
+func NewTransactionService(
+	transactionRepository transaction.Repository,
+	internalTransactionRepository internal_transaction.Repository,
+	tokenRepository token.Repository,
+	smartContractRepository smartcontract.Repository,
+	logger *zap.Logger,
+) *TransactionService {
+	return &TransactionService{
+		transactionRepository:         transactionRepository,
+		internalTransactionRepository: internalTransactionRepository,
+		tokenRepository:               tokenRepository,
+		logDecoder:                    newLogDecoder(smartContractRepository, logger),
+		logger:                        logger,
+	}
+}
+
+func (s *TransactionService) GetTransaction(ctx context.Context, hash common.Hash) (*TransactionDetails, error) {
+	tx, err := s.transactionRepository.GetTransaction(ctx, hash)
+	if err != nil {
+		s.logger.Error("Failed to get transaction", zap.Error(err))
+		return nil, err
+	}
+	if tx == nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	details := &TransactionDetails{Transaction: tx, Fee: transactionFee(tx, s.logger)}
+
+	logs, err := s.transactionRepository.GetTransactionLogs(ctx, hash)
+	if err != nil {
+		s.logger.Error("Failed to get transaction logs", zap.Error(err))
+		return nil, err
+	}
+	if details.Logs, err = s.logDecoder.decode(ctx, logs); err != nil {
+		return nil, err
+	}
+
+	if details.TokenTransfers, err = s.tokenRepository.GetTransactionTokenTransfers(ctx, hash); err != nil {
+		s.logger.Error("Failed to get transaction token transfers", zap.Error(err))
+		return nil, err
+	}
+
+	if details.InternalTransactions, err = s.internalTransactionRepository.GetTransactionInternalTransactions(ctx, hash); err != nil {
+		s.logger.Error("Failed to get internal transactions", zap.Error(err))
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// transactionFee derives the paid gas price from the transaction encoding and the block base fee
+func transactionFee(tx *transaction.Transaction, logger *zap.Logger) *transaction.Fee {
+	if len(tx.Raw) == 0 {
+		return nil
+	}
+
+	var decoded types.Transaction
+	if err := decoded.UnmarshalBinary(tx.Raw); err != nil {
+		logger.Warn("Failed to decode transaction encoding", zap.String("hash", tx.Hash.Hex()), zap.Error(err))
+		return nil
+	}
+
+	baseFee := (*big.Int)(&tx.BaseFeePerGas)
+	tip, err := decoded.EffectiveGasTip(baseFee)
+	if err != nil {
+		// a fee cap under the base fee never makes it into a block, the stored base fee is wrong
+		logger.Warn("Transaction fee cap is below the block base fee", zap.String("hash", tx.Hash.Hex()), zap.Error(err))
+		return nil
+	}
+
+	gasUsed := new(big.Int).SetUint64(tx.GasUsed)
+	gasPrice := new(big.Int).Add(baseFee, tip)
+	fee := &transaction.Fee{
+		GasPrice:      domain.BigInt(*gasPrice),
+		BaseFeePerGas: tx.BaseFeePerGas,
+		Total:         domain.BigInt(*new(big.Int).Mul(gasUsed, gasPrice)),
+		Burnt:         domain.BigInt(*new(big.Int).Mul(gasUsed, baseFee)),
+		Priority:      domain.BigInt(*new(big.Int).Mul(gasUsed, tip)),
+	}
+	if decoded.Type() >= types.DynamicFeeTxType {
+		fee.MaxFeePerGas = (*domain.BigInt)(decoded.GasFeeCap())
+		fee.MaxPriorityFeePerGas = (*domain.BigInt)(decoded.GasTipCap())
+	}
+
+	return fee
+}
+
+// GetTransactions returns a page of transactions ordered by block number and index, and the cursor of the next page
+func (s *TransactionService) GetTransactions(ctx context.Context, filter transaction.Filter, cursor string) ([]*transaction.Transaction, string, error) {
+	if len(filter.MethodSelector) != 0 && len(filter.MethodSelector) != 4 {
+		return nil, "", fmt.Errorf("%w: method selector must be 4 bytes", ErrInvalidQuery)
+	}
+
+	if cursor != "" {
+		parts, err := domain.DecodeCursor(cursor, 2)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		number, ok := new(big.Int).SetString(parts[0], 10)
+		index, err := strconv.Atoi(parts[1])
+		if !ok || err != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidQuery, domain.ErrInvalidCursor)
+		}
+		filter.After = &transaction.Cursor{BlockNumber: domain.BigInt(*number), Index: index}
+	}
+
+	limit := pageSize(filter.Limit)
+	// fetch one extra transaction to know whether another page exists
+	filter.Limit = limit + 1
+
+	transactions, err := s.transactionRepository.GetTransactions(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get transactions", zap.Error(err))
+		return nil, "", err
+	}
+
+	next := ""
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		last := transactions[limit-1]
+		next = domain.EncodeCursor(last.BlockNumber.String(), strconv.Itoa(last.Index))
+	}
+
+	return transactions, next, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/internal/infrastructure/repository"
+	"github.com/elmiringos/indexer/explorer/internal/testdb"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestTransactionService(db *sql.DB) *TransactionService {
+	return NewTransactionService(
+		repository.NewTransactionRepository(db, zap.NewNop()),
+		repository.NewInternalTransactionRepository(db),
+		repository.NewSQLiteTokenRepository(db),
+		repository.NewSmartContractRepository(db),
+		zap.NewNop(),
+	)
+}
+
+// transactionPositions walks every page of the listing and returns the "block:index" of each transaction
+func transactionPositions(t *testing.T, s *TransactionService, filter transaction.Filter) []string {
+	t.Helper()
+
+	var (
+		positions []string
+		cursor    string
+	)
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 20, "the cursor must make progress")
+
+		transactions, next, err := s.GetTransactions(context.Background(), filter, cursor)
+		require.NoError(t, err)
+		for _, tx := range transactions {
+			positions = append(positions, tx.BlockNumber.String()+":"+big.NewInt(int64(tx.Index)).String())
+		}
+
+		if next == "" {
+			return positions
+		}
+		cursor = next
+	}
+}
+
+func TestTransactionService_GetTransactionsPages(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestTransactionService(db)
+	sender, receiver := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	failed := uint64(0)
+	for number := int64(1); number <= 3; number++ {
+		testdb.InsertBlock(t, db, testdb.Block{Number: number, TransactionsCount: 3})
+		for index := 0; index < 3; index++ {
+			tx := testdb.Transaction{BlockNumber: number, Index: index, From: sender, To: receiver}
+			if index == 1 {
+				tx.From, tx.Input, tx.Status = receiver, []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01}, &failed
+			}
+			testdb.InsertTransaction(t, db, tx)
+		}
+	}
+
+	// pages break inside a block and the cursor resumes after the last index
+	assert.Equal(t, []string{"1:0", "1:1", "1:2", "2:0", "2:1", "2:2", "3:0", "3:1", "3:2"},
+		transactionPositions(t, s, transaction.Filter{Limit: 2}))
+	assert.Equal(t, []string{"3:2", "3:1", "3:0", "2:2", "2:1", "2:0", "1:2", "1:1", "1:0"},
+		transactionPositions(t, s, transaction.Filter{Descending: true, Limit: 4}))
+
+	assert.Equal(t, []string{"1:0", "1:2", "2:0", "2:2", "3:0", "3:2"},
+		transactionPositions(t, s, transaction.Filter{From: &sender, Limit: 4}))
+	assert.Equal(t, []string{"3:1", "2:1", "1:1"},
+		transactionPositions(t, s, transaction.Filter{Status: &failed, Descending: true, Limit: 1}))
+	assert.Equal(t, []string{"1:1", "2:1", "3:1"},
+		transactionPositions(t, s, transaction.Filter{MethodSelector: []byte{0xa9, 0x05, 0x9c, 0xbb}, Limit: 2}))
+	assert.Equal(t, []string{"2:0", "2:1", "2:2"},
+		transactionPositions(t, s, transaction.Filter{BlockNumber: bigInt(2), Limit: 2}))
+
+	_, _, err := s.GetTransactions(context.Background(), transaction.Filter{MethodSelector: []byte{0xa9}}, "")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, _, err = s.GetTransactions(context.Background(), transaction.Filter{}, domain.EncodeCursor("1", "x"))
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+}
+
+func gwei(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.GWei))
+}
+
+func gweiBigInt(n int64) domain.BigInt {
+	return domain.BigInt(*gwei(n))
+}
+
+func signedTransaction(t *testing.T, data types.TxData) []byte {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), data)
+	require.NoError(t, err)
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestTransactionService_Fee(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestTransactionService(db)
+	ctx := context.Background()
+	testdb.InsertBlock(t, db, testdb.Block{Number: 1, BaseFeePerGas: gwei(10).String(), TransactionsCount: 4})
+
+	dynamic := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1, Index: 0, GasUsed: 21000, Type: types.DynamicFeeTxType,
+		Raw: signedTransaction(t, &types.DynamicFeeTx{ChainID: big.NewInt(1), GasTipCap: gwei(2), GasFeeCap: gwei(30), Gas: 21000})})
+	legacy := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1, Index: 1, GasUsed: 21000,
+		Raw: signedTransaction(t, &types.LegacyTx{GasPrice: gwei(15), Gas: 21000})})
+	underpriced := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1, Index: 2, GasUsed: 21000, Type: types.DynamicFeeTxType,
+		Raw: signedTransaction(t, &types.DynamicFeeTx{ChainID: big.NewInt(1), GasTipCap: gwei(1), GasFeeCap: gwei(5), Gas: 21000})})
+	unencoded := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1, Index: 3, GasUsed: 21000})
+
+	details, err := s.GetTransaction(ctx, dynamic)
+	require.NoError(t, err)
+	maxFee, maxPriorityFee := gweiBigInt(30), gweiBigInt(2)
+	assert.Equal(t, &transaction.Fee{
+		GasPrice:             gweiBigInt(12),
+		MaxFeePerGas:         &maxFee,
+		MaxPriorityFeePerGas: &maxPriorityFee,
+		BaseFeePerGas:        gweiBigInt(10),
+		Total:                gweiBigInt(12 * 21000),
+		Burnt:                gweiBigInt(10 * 21000),
+		Priority:             gweiBigInt(2 * 21000),
+	}, details.Fee)
+
+	// a legacy transaction tips whatever its gas price leaves above the base fee
+	details, err = s.GetTransaction(ctx, legacy)
+	require.NoError(t, err)
+	assert.Equal(t, &transaction.Fee{
+		GasPrice:      gweiBigInt(15),
+		BaseFeePerGas: gweiBigInt(10),
+		Total:         gweiBigInt(15 * 21000),
+		Burnt:         gweiBigInt(10 * 21000),
+		Priority:      gweiBigInt(5 * 21000),
+	}, details.Fee)
+
+	for _, hash := range []common.Hash{underpriced, unencoded} {
+		details, err = s.GetTransaction(ctx, hash)
+		require.NoError(t, err)
+		assert.Nil(t, details.Fee)
+	}
+
+	_, err = s.GetTransaction(ctx, testdb.TransactionHash(1, 9))
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+}
+
+func TestTransactionService_DecodedLogs(t *testing.T) {
+	db := testdb.Open(t)
+	s := newTestTransactionService(db)
+	token, verified, unknown := common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), common.HexToAddress("0x0c")
+	from, to := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	testdb.InsertBlock(t, db, testdb.Block{Number: 1, TransactionsCount: 1})
+	hash := testdb.InsertTransaction(t, db, testdb.Transaction{BlockNumber: 1})
+
+	testdb.InsertSmartContract(t, db, testdb.SmartContract{Address: verified, Name: "Vault",
+		ABI: `[{"type":"event","name":"Deposit","inputs":[{"name":"account","type":"address","indexed":true},{"name":"memo","type":"string","indexed":true},{"name":"shares","type":"uint256","indexed":false}]}]`})
+
+	transfer := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	deposit := crypto.Keccak256Hash([]byte("Deposit(address,string,uint256)"))
+	memo := crypto.Keccak256Hash([]byte("savings"))
+	amount := common.BigToHash(new(big.Int).Lsh(big.NewInt(1), 200))
+	tokenID := common.BigToHash(big.NewInt(42))
+
+	// an ERC-20 transfer, an ERC-721 transfer with the token id indexed, a verified contract's event and a log nobody knows
+	testdb.InsertLog(t, db, testdb.Log{BlockNumber: 1, Index: 0, Address: token,
+		Topics: []common.Hash{transfer, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}, Data: amount.Bytes()})
+	testdb.InsertLog(t, db, testdb.Log{BlockNumber: 1, Index: 1, Address: token,
+		Topics: []common.Hash{transfer, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), tokenID}})
+	testdb.InsertLog(t, db, testdb.Log{BlockNumber: 1, Index: 2, Address: verified,
+		Topics: []common.Hash{deposit, common.BytesToHash(from.Bytes()), memo}, Data: common.BigToHash(big.NewInt(7)).Bytes()})
+	testdb.InsertLog(t, db, testdb.Log{BlockNumber: 1, Index: 3, Address: unknown,
+		Topics: []common.Hash{crypto.Keccak256Hash([]byte("Unknown()"))}})
+
+	details, err := s.GetTransaction(context.Background(), hash)
+	require.NoError(t, err)
+	require.Len(t, details.Logs, 4)
+
+	assert.Equal(t, &transaction.Event{Name: "Transfer", Signature: "Transfer(address,address,uint256)", Params: []transaction.EventParam{
+		{Name: "from", Type: "address", Indexed: true, Value: from.Hex()},
+		{Name: "to", Type: "address", Indexed: true, Value: to.Hex()},
+		{Name: "value", Type: "uint256", Value: new(big.Int).Lsh(big.NewInt(1), 200).String()},
+	}}, details.Logs[0].Event)
+
+	assert.Equal(t, &transaction.Event{Name: "Transfer", Signature: "Transfer(address,address,uint256)", Params: []transaction.EventParam{
+		{Name: "from", Type: "address", Indexed: true, Value: from.Hex()},
+		{Name: "to", Type: "address", Indexed: true, Value: to.Hex()},
+		{Name: "tokenId", Type: "uint256", Indexed: true, Value: "42"},
+	}}, details.Logs[1].Event)
+
+	// an indexed string is only its hash
+	assert.Equal(t, &transaction.Event{Name: "Deposit", Signature: "Deposit(address,string,uint256)", Params: []transaction.EventParam{
+		{Name: "account", Type: "address", Indexed: true, Value: from.Hex()},
+		{Name: "memo", Type: "string", Indexed: true, Value: memo.Hex()},
+		{Name: "shares", Type: "uint256", Value: "7"},
+	}}, details.Logs[2].Event)
+
+	assert.Nil(t, details.Logs[3].Event)
+	assert.Equal(t, unknown, details.Logs[3].Address)
+}
@@ -0,0 +1,11 @@
+package address
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type Repository interface {
+	GetSummary(ctx context.Context, address common.Address) (*Summary, error)
+}
@@ -0,0 +1,28 @@
+package address
+
+import (
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Direction selects the side of a transfer the address is on, DirectionAny matches both
+type Direction string
+
+const (
+	DirectionAny Direction = ""
+	DirectionIn  Direction = "in"
+	DirectionOut Direction = "out"
+)
+
+// Summary describes an address from the indexed data, FirstSeen and LastSeen are the timestamps of its
+// first and last transaction and are nil when it has none
+type Summary struct {
+	Address            common.Address `json:"address"`
+	TransactionsCount  int64          `json:"transactions_count"`
+	FirstSeen          *int64         `json:"first_seen"`
+	LastSeen           *int64         `json:"last_seen"`
+	IsContract         bool           `json:"is_contract"`
+	TokenHoldingsCount int64          `json:"token_holdings_count"`
+	// Balance is nil, native balances are not indexed
+	Balance *domain.BigInt `json:"balance"`
+}
@@ -9,6 +9,12 @@ import (
 
 type Repository interface {
 	GetCurrentBlock(ctx context.Context) (*Block, error)
-	GetBlock(ctx context.Context, blockNumber domain.BigInt, hash common.Hash) (*Block, error)
-	GetBlocks(ctx context.Context, fromblockNumber domain.BigInt, toBlockNumber domain.BigInt) ([]*Block, error)
+	// GetBlock looks the block up by number, or by hash when blockNumber is nil
+	GetBlock(ctx context.Context, blockNumber *domain.BigInt, hash common.Hash) (*Block, error)
+	GetBlocks(ctx context.Context, filter Filter) ([]*Block, error)
+	// GetBlocksByHash returns the blocks among hashes, in no particular order
+	GetBlocksByHash(ctx context.Context, hashes []common.Hash) ([]*Block, error)
+	// GetBlockByTimestamp returns the last block at or before timestamp, or the first one at or after it
+	// when after is set, nil when there is none
+	GetBlockByTimestamp(ctx context.Context, timestamp uint64, after bool) (*Block, error)
 }
@@ -20,6 +20,13 @@ type Block struct {
 	TransactionsCount int            `json:"transactions_count"`
 	WithdrawalsCount  int            `json:"withdrawals_count"`
 	Timestamp         uint64         `json:"timestamp"`
+	// TransactionsRoot, ReceiptsRoot and LogsBloom are nil for blocks indexed before core stored them,
+	// StateRoot and MixHash for blocks indexed before it stored the rest of the header
+	TransactionsRoot *common.Hash `json:"-"`
+	ReceiptsRoot     *common.Hash `json:"-"`
+	LogsBloom        []byte       `json:"-"`
+	StateRoot        *common.Hash `json:"-"`
+	MixHash          *common.Hash `json:"-"`
 }
 
 func (b *Block) ToMap() map[string]interface{} {
@@ -48,3 +55,30 @@ func MakeSlice(blocks []*Block) []map[string]interface{} {
 	}
 	return slices
 }
+
+// SortField is the column a block listing is ordered by, ties are broken by number
+type SortField string
+
+const (
+	SortByNumber            SortField = "number"
+	SortByGasUsed           SortField = "gas_used"
+	SortByTransactionsCount SortField = "transactions_count"
+)
+
+// Cursor is the sort key of the last block of the previous page
+type Cursor struct {
+	Value  domain.BigInt
+	Number domain.BigInt
+}
+
+// Filter selects a page of blocks, every bound is inclusive and nil bounds are open
+type Filter struct {
+	FromNumber    *domain.BigInt
+	ToNumber      *domain.BigInt
+	FromTimestamp *uint64
+	ToTimestamp   *uint64
+	SortBy        SortField
+	Descending    bool
+	After         *Cursor
+	Limit         int
+}
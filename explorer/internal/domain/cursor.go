@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// EncodeCursor packs the sort key of the last item of a page into an opaque token
+func EncodeCursor(parts ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, ":")))
+}
+
+// DecodeCursor unpacks a token made by EncodeCursor holding n parts
+func DecodeCursor(cursor string, n int) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(data), ":")
+	if len(parts) != n {
+		return nil, ErrInvalidCursor
+	}
+
+	return parts, nil
+}
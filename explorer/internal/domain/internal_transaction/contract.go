@@ -1,7 +1,13 @@
 package internal_transaction
 
-import "context"
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 type Repository interface {
-	SaveInternalTransaction(ctx context.Context, tx *InternalTransaction) (string, error)
+	// GetTransactionInternalTransactions returns the calls made by the transaction, ordered by index
+	GetTransactionInternalTransactions(ctx context.Context, transactionHash common.Hash) ([]*InternalTransaction, error)
+	GetAddressInternalTransactions(ctx context.Context, filter AddressFilter) ([]*InternalTransaction, error)
 }
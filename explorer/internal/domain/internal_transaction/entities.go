@@ -1,28 +1,56 @@
 package internal_transaction
 
-import "math/big"
+import (
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/ethereum/go-ethereum/common"
+)
 
 type InternalTransaction struct {
-	BlockHash       string
+	BlockHash       common.Hash
 	Index           int
-	Hash            string
+	TransactionHash common.Hash
 	Status          int
 	Gas             uint64
 	GasUsed         uint64
 	Input           []byte
 	Output          []byte
-	Value           *big.Int
-	From            string
-	To              string
-	ContractAddress string
-	Timestamp       uint64
-	ErrorMsg        string
-	Nonce           uint64
+	Value           domain.BigInt
+	From            common.Address
+	To              common.Address
+	ContractAddress common.Address
+	Timestamp       int64
+}
+
+// Cursor is the position of the last internal transaction of an address history page
+type Cursor struct {
+	Timestamp int64
+	Index     int
+}
+
+// AddressFilter selects a page of the internal transactions sent or received by Address, newest first
+type AddressFilter struct {
+	Address   common.Address
+	Direction address.Direction
+	After     *Cursor
+	Limit     int
 }
 
 func (i *InternalTransaction) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"block_hash": i.BlockHash,
+		"block_hash":       i.BlockHash,
+		"index":            i.Index,
+		"transaction_hash": i.TransactionHash,
+		"status":           i.Status,
+		"gas":              i.Gas,
+		"gas_used":         i.GasUsed,
+		"input":            i.Input,
+		"output":           i.Output,
+		"value":            i.Value,
+		"from":             i.From,
+		"to":               i.To,
+		"contract_address": i.ContractAddress,
+		"timestamp":        i.Timestamp,
 	}
 }
 
@@ -1,7 +1,14 @@
 package reward
 
-import "context"
+import (
+	"context"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/ethereum/go-ethereum/common"
+)
 
 type Repository interface {
-	SaveReward(ctx context.Context, reward *Reward) error
+	// GetAddressRewards returns a page of the rewards paid to the address, newest first, starting before
+	// beforeNumber when it is set
+	GetAddressRewards(ctx context.Context, address common.Address, beforeNumber *domain.BigInt, limit int) ([]*Reward, error)
 }
@@ -1,20 +1,23 @@
 package reward
 
 import (
+	"github.com/elmiringos/indexer/explorer/internal/domain"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type Reward struct {
-	BlockHash common.Hash    `json:"block_hash"`
-	Address   common.Address `json:"address"`
-	Amount    uint64         `json:"amount"`
+	BlockHash   common.Hash    `json:"block_hash"`
+	BlockNumber domain.BigInt  `json:"block_number"`
+	Address     common.Address `json:"address"`
+	Amount      domain.BigInt  `json:"amount"`
 }
 
 func (r *Reward) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"block_hash": r.BlockHash,
-		"address":    r.Address,
-		"amount":     r.Amount,
+		"block_hash":   r.BlockHash,
+		"block_number": r.BlockNumber,
+		"address":      r.Address,
+		"amount":       r.Amount,
 	}
 }
 
@@ -2,8 +2,16 @@ package smartcontract
 
 import (
 	"context"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type Repository interface {
-	SaveSmartContract(ctx context.Context, smartContract *SmartContract) error
+	// GetABIs returns the JSON ABI of the verified contracts among addresses
+	GetABIs(ctx context.Context, addresses []common.Address) (map[common.Address]string, error)
+	// GetContract returns the verified contract at address without its source and ABI, nil when there is none
+	GetContract(ctx context.Context, address common.Address) (*SmartContract, error)
+	// SearchContracts returns the verified contracts whose name matches query case insensitively, ranked like
+	// token.Repository.SearchTokens, without their source and ABI
+	SearchContracts(ctx context.Context, query string, limit int) ([]*SmartContract, error)
 }
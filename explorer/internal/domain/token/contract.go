@@ -8,9 +8,30 @@ import (
 )
 
 type Repository interface {
-	SaveToken(ctx context.Context, token *Token) error
-	IncreaseTokenSupply(ctx context.Context, addressHash common.Address, addSupply domain.BigInt) error
-	DecreaseTokenSupply(ctx context.Context, addressHash common.Address, subSupply domain.BigInt) error
-	SaveOrUpdateTokenInstance(ctx context.Context, tokenInstance *TokenInstance) error
-	SaveTokenTransfer(ctx context.Context, tokenInstance *TokenTransfer) error
+	// GetToken returns nil when no token has the address
+	GetToken(ctx context.Context, address common.Address) (*Token, error)
+	// GetTokensByAddress returns the tokens among addresses, in no particular order
+	GetTokensByAddress(ctx context.Context, addresses []common.Address) ([]*Token, error)
+	GetTokens(ctx context.Context, filter Filter) ([]*Token, error)
+	// SearchTokens returns the tokens whose symbol or name matches query case insensitively, exact matches
+	// first, then prefix matches, then matches anywhere
+	SearchTokens(ctx context.Context, query string, limit int) ([]*Token, error)
+	// GetTokenCounts counts the holders and the transfers of the token
+	GetTokenCounts(ctx context.Context, token *Token) (holders int64, transfers int64, err error)
+	GetTokenHolders(ctx context.Context, filter HolderFilter) ([]*Holder, error)
+	// GetTokenTransfers returns a page of the transfers of the token newest first, starting after after when it is set
+	GetTokenTransfers(ctx context.Context, token common.Address, after *TransferCursor, limit int) ([]*TokenTransfer, error)
+	// GetTokenInstance returns nil when the token has no instance with the id
+	GetTokenInstance(ctx context.Context, token common.Address, tokenId domain.BigInt) (*TokenInstance, error)
+	// GetInstanceTransfers returns a page of the transfers of one instance newest first, like GetTokenTransfers
+	GetInstanceTransfers(ctx context.Context, token common.Address, tokenId domain.BigInt, after *TransferCursor, limit int) ([]*TokenTransfer, error)
+	GetOwnerInstances(ctx context.Context, filter InventoryFilter) ([]*TokenInstance, error)
+	// GetOwnerCollectionCounts counts the instances of each of tokens the owner holds
+	GetOwnerCollectionCounts(ctx context.Context, owner common.Address, tokens []common.Address) (map[common.Address]int64, error)
+	// GetCollectionCounts counts the owners, the supply and the transfers of an ERC-721 or ERC-1155 token
+	GetCollectionCounts(ctx context.Context, token common.Address) (owners int64, supply int64, transfers int64, err error)
+	// GetTransactionTokenTransfers returns the token transfers of the transaction with their token, ordered by log index
+	GetTransactionTokenTransfers(ctx context.Context, transactionHash common.Hash) ([]*TokenTransfer, error)
+	GetAddressTokenTransfers(ctx context.Context, filter TransferFilter) ([]*TokenTransfer, error)
+	GetAccountTokenTransfers(ctx context.Context, filter AccountTransferFilter) ([]*TokenTransfer, error)
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -25,19 +26,35 @@ type TokenEvent struct {
 	SmartContractDeployed bool           `json:"smart_contract_deployed"`
 }
 
+// Token standards, stored in token.type
+const (
+	TypeERC20   = "ERC-20"
+	TypeERC721  = "ERC-721"
+	TypeERC1155 = "ERC-1155"
+)
+
 type Token struct {
-	Address              common.Address
-	Name                 string
-	Symbol               string
-	TotalSupply          domain.BigInt
+	Address common.Address
+	Type    string
+	Name    string
+	Symbol  string
+	// TotalSupply, FiatValue and CirculationMarketCap are nil when core has not set them
+	TotalSupply          *domain.BigInt
 	Decimals             int
-	FiatValue            domain.BigInt
-	CirculationMarketCap domain.BigInt
+	FiatValue            *domain.BigInt
+	CirculationMarketCap *domain.BigInt
+}
+
+// HoldsInstances reports whether the holders of the token are read from its instances rather than from
+// the net of its transfers
+func (t *Token) HoldsInstances() bool {
+	return t.Type == TypeERC721 || t.Type == TypeERC1155
 }
 
 func (t *Token) ToMap() map[string]interface{} {
 	return map[string]interface{}{
 		"address":                t.Address,
+		"type":                   t.Type,
 		"name":                   t.Name,
 		"symbol":                 t.Symbol,
 		"total_supply":           t.TotalSupply,
@@ -56,13 +73,91 @@ func MakeTokenSlice(tokens []*Token) []map[string]interface{} {
 	return slices
 }
 
+// Cursor is the position of the last token of a listing page
+type Cursor struct {
+	Name    string
+	Address common.Address
+}
+
+// Filter selects a page of tokens ordered by name and address. Query matches the symbol or the name
+// case insensitively, Type keeps one standard.
+type Filter struct {
+	Query string
+	Type  string
+	After *Cursor
+	Limit int
+}
+
+// Details adds the activity of the token, a holder is an address with a positive balance
+type Details struct {
+	*Token
+	HoldersCount   int64
+	TransfersCount int64
+}
+
+// Holder is an address holding the token, Balance is the net of its transfers or, for tokens holding
+// instances, the number of instances it owns
+type Holder struct {
+	Address common.Address
+	Balance domain.BigInt
+}
+
+// HolderCursor is the position of the last holder of a page
+type HolderCursor struct {
+	Balance domain.BigInt
+	Address common.Address
+}
+
+// HolderFilter selects a page of the holders of Token ordered by balance, largest first
+type HolderFilter struct {
+	Token     common.Address
+	Instances bool
+	After     *HolderCursor
+	Limit     int
+}
+
 type TokenTransfer struct {
 	TransactionHash      common.Hash
+	BlockNumber          domain.BigInt
+	TransactionIndex     int
+	Timestamp            int64
 	LogIndex             int
 	From                 common.Address
 	To                   common.Address
 	TokenContractAddress common.Address
 	Amount               domain.BigInt
+	// TokenId is nil for ERC-20 transfers and for transfers indexed before core stored it
+	TokenId *domain.BigInt
+	// Token is nil when the contract is not an indexed token
+	Token *Token
+}
+
+// TransferCursor is the position of the last token transfer of a page
+type TransferCursor struct {
+	Timestamp        int64
+	TransactionIndex int
+	LogIndex         int
+}
+
+// TransferFilter selects a page of the token transfers sent or received by Address, newest first
+type TransferFilter struct {
+	Address   common.Address
+	Direction address.Direction
+	After     *TransferCursor
+	Limit     int
+}
+
+// AccountTransferFilter selects the transfers of Type tokens sent or received by Address, of Token or both,
+// within the inclusive block range, ordered by position in the chain and paged by Offset like the Etherscan API does
+type AccountTransferFilter struct {
+	Address    *common.Address
+	Token      *common.Address
+	Type       string
+	FromBlock  *domain.BigInt
+	ToBlock    *domain.BigInt
+	Descending bool
+	Offset     int
+	Limit      int
 }
 
 func (t *TokenTransfer) ToMap() map[string]interface{} {
@@ -89,7 +184,9 @@ type TokenInstance struct {
 	TokenId              domain.BigInt
 	TokenContractAddress common.Address
 	OwnerAddress         common.Address
-	Metadata             *json.RawMessage
+	// Metadata is nil until core has fetched it
+	Metadata *json.RawMessage
+	Token    *Token
 }
 
 func (t *TokenInstance) ToMap() map[string]interface{} {
@@ -109,3 +206,33 @@ func MakeTokenInstanceSlice(tokenInstances []*TokenInstance) []map[string]interf
 
 	return slices
 }
+
+// InstanceCursor is the position of the last instance of an inventory page
+type InstanceCursor struct {
+	Contract common.Address
+	TokenId  domain.BigInt
+}
+
+// InventoryFilter selects a page of the instances Owner holds, ordered by contract and token id
+type InventoryFilter struct {
+	Owner common.Address
+	After *InstanceCursor
+	Limit int
+}
+
+// Collection groups the instances of one token in an inventory page, OwnedCount counts every instance
+// of the token the owner holds, not only the ones of the page
+type Collection struct {
+	Token      *Token
+	OwnedCount int64
+	Instances  []*TokenInstance
+}
+
+// CollectionStats describes an ERC-721 or ERC-1155 token, instances owned by the zero address are burnt
+// and left out of OwnersCount and Supply
+type CollectionStats struct {
+	*Token
+	OwnersCount    int64
+	Supply         int64
+	TransfersCount int64
+}
@@ -7,13 +7,20 @@ import (
 )
 
 type Repository interface {
-	SaveTransaction(ctx context.Context, tx *Transaction) error
-	SaveTransactionLog(ctx context.Context, txLog *TransactionLog) error
-	SaveTransactionAction(ctx context.Context, txAction *TransactionAction) error
-	SaveTransactionHashForAction(ctx context.Context, hash common.Hash, count int) error
-	SaveTransactionHashForLog(ctx context.Context, hash common.Hash, count int) error
-	CheckTransactionExistForLog(ctx context.Context, hash common.Hash) (bool, error)
-	CheckTransactionExistForAction(ctx context.Context, hash common.Hash) (bool, error)
-	DecrementTransactionActionCount(ctx context.Context, hash common.Hash) error
-	DecrementTransactionLogCount(ctx context.Context, hash common.Hash) error
+	// GetTransaction returns nil when no transaction has the hash
+	GetTransaction(ctx context.Context, hash common.Hash) (*Transaction, error)
+	GetTransactions(ctx context.Context, filter Filter) ([]*Transaction, error)
+	// GetBlockTransactions returns a page of the block's transactions by index, starting after afterIndex when it is set
+	GetBlockTransactions(ctx context.Context, blockHash common.Hash, afterIndex *int, limit int) ([]*Transaction, error)
+	// GetBlocksTransactions returns the first limit transactions of each of blockHashes, ordered by block hash and index
+	GetBlocksTransactions(ctx context.Context, blockHashes []common.Hash, limit int) ([]*Transaction, error)
+	GetAddressTransactions(ctx context.Context, filter AddressFilter) ([]*Transaction, error)
+	GetAccountTransactions(ctx context.Context, filter AccountFilter) ([]*Transaction, error)
+	// GetTransactionsByHash returns the transactions among hashes, in no particular order
+	GetTransactionsByHash(ctx context.Context, hashes []common.Hash) ([]*Transaction, error)
+	// GetTransactionLogs returns the logs of the transaction with their topics, ordered by log index
+	GetTransactionLogs(ctx context.Context, hash common.Hash) ([]*TransactionLog, error)
+	// GetTransactionsLogs returns the logs of every transaction among hashes, ordered by transaction hash and log index
+	GetTransactionsLogs(ctx context.Context, hashes []common.Hash) ([]*TransactionLog, error)
+	GetLogs(ctx context.Context, filter LogFilter) ([]*TransactionLog, error)
 }
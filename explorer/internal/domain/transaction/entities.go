@@ -2,39 +2,48 @@ package transaction
 
 import (
 	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type Transaction struct {
-	Hash      common.Hash    `json:"hash"`
-	BlockHash common.Hash    `json:"block_hash"`
-	Index     int            `json:"index"`
-	Status    uint64         `json:"status"`
-	Gas       uint64         `json:"gas"`
-	GasUsed   uint64         `json:"gas_used"`
-	Input     []byte         `json:"input"`
-	Value     domain.BigInt  `json:"value"`
-	From      common.Address `json:"from"`
-	To        common.Address `json:"to"`
-	Nonce     uint64         `json:"nonce"`
-	Timestamp int64          `json:"timestamp"`
-	LogsCount int            `json:"logs_count"`
+	Hash        common.Hash    `json:"hash"`
+	BlockHash   common.Hash    `json:"block_hash"`
+	BlockNumber domain.BigInt  `json:"block_number"`
+	Index       int            `json:"index"`
+	Type        uint8          `json:"type"`
+	Status      uint64         `json:"status"`
+	Gas         uint64         `json:"gas"`
+	GasUsed     uint64         `json:"gas_used"`
+	Input       []byte         `json:"input"`
+	Value       domain.BigInt  `json:"value"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Nonce       uint64         `json:"nonce"`
+	Timestamp   int64          `json:"timestamp"`
+	LogsCount   int            `json:"logs_count"`
+	// Raw is the consensus encoding, nil for transactions indexed before core stored it
+	Raw               []byte        `json:"-"`
+	CumulativeGasUsed uint64        `json:"-"`
+	BaseFeePerGas     domain.BigInt `json:"-"`
 }
 
 func (t *Transaction) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"hash":       t.Hash,
-		"block_hash": t.BlockHash,
-		"index":      t.Index,
-		"status":     t.Status,
-		"gas":        t.Gas,
-		"gas_used":   t.GasUsed,
-		"input":      t.Input,
-		"value":      t.Value,
-		"from":       t.From,
-		"to":         t.To,
-		"timestamp":  t.Timestamp,
-		"nonce":      t.Nonce,
+		"hash":         t.Hash,
+		"block_hash":   t.BlockHash,
+		"block_number": t.BlockNumber,
+		"index":        t.Index,
+		"type":         t.Type,
+		"status":       t.Status,
+		"gas":          t.Gas,
+		"gas_used":     t.GasUsed,
+		"input":        t.Input,
+		"value":        t.Value,
+		"from":         t.From,
+		"to":           t.To,
+		"timestamp":    t.Timestamp,
+		"nonce":        t.Nonce,
 	}
 }
 
@@ -46,11 +55,94 @@ func MakeSlice(transactions []*Transaction) []map[string]interface{} {
 	return slices
 }
 
+// Cursor is the position of the last transaction of a page
+type Cursor struct {
+	BlockNumber domain.BigInt
+	Index       int
+}
+
+// Filter selects a page of transactions ordered by block number and index, unset fields match every transaction
+type Filter struct {
+	BlockNumber *domain.BigInt
+	BlockHash   *common.Hash
+	From        *common.Address
+	To          *common.Address
+	Status      *uint64
+	// MethodSelector matches the first four bytes of the input
+	MethodSelector []byte
+	Descending     bool
+	After          *Cursor
+	Limit          int
+}
+
+// AddressCursor is the position of the last transaction of an address history page
+type AddressCursor struct {
+	Timestamp int64
+	Index     int
+}
+
+// AddressFilter selects a page of the transactions sent or received by Address, newest first
+type AddressFilter struct {
+	Address   common.Address
+	Direction address.Direction
+	After     *AddressCursor
+	Limit     int
+}
+
+// AccountFilter selects the transactions sent or received by Address within the inclusive block range,
+// ordered by block number and index and paged by Offset like the Etherscan API does
+type AccountFilter struct {
+	Address    common.Address
+	FromBlock  *domain.BigInt
+	ToBlock    *domain.BigInt
+	Descending bool
+	Offset     int
+	Limit      int
+}
+
+// LogCursor is the position of the last log of a page
+type LogCursor struct {
+	BlockNumber      domain.BigInt
+	TransactionIndex uint
+	LogIndex         uint
+}
+
+// LogFilter selects a page of logs ordered by block number, transaction index and log index with the
+// semantics of eth_getLogs: a log matches when it was emitted by one of Addresses (any address when empty)
+// and, for every position of Topics with a non empty set, its topic at that position is one of the set.
+// An empty set is a wildcard, but the log still needs as many topics as Topics has positions.
+type LogFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+	// FromBlock and ToBlock are inclusive, BlockHash excludes them
+	FromBlock  *domain.BigInt
+	ToBlock    *domain.BigInt
+	BlockHash  *common.Hash
+	Descending bool
+	After      *LogCursor
+	// Offset skips that many logs, the Etherscan API pages by offset rather than by cursor
+	Offset int
+	Limit  int
+}
+
+// Fee breaks down what the sender paid, Burnt is destroyed by the base fee and Priority goes to the block producer.
+// Blob gas is not included.
+type Fee struct {
+	GasPrice             domain.BigInt  `json:"gas_price"`
+	MaxFeePerGas         *domain.BigInt `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *domain.BigInt `json:"max_priority_fee_per_gas"`
+	BaseFeePerGas        domain.BigInt  `json:"base_fee_per_gas"`
+	Total                domain.BigInt  `json:"total"`
+	Burnt                domain.BigInt  `json:"burnt"`
+	Priority             domain.BigInt  `json:"priority"`
+}
+
 type TransactionLog struct {
 	Address          common.Address `json:"address"`
 	Topics           []common.Hash  `json:"topics"`
 	TransactionHash  common.Hash    `json:"transactionHash"`
 	BlockHash        common.Hash    `json:"blockHash"`
+	BlockNumber      domain.BigInt  `json:"blockNumber"`
 	TransactionIndex uint           `json:"transactionIndex"`
 	Index            uint           `json:"logIndex"`
 	Data             []byte         `json:"data"`
@@ -68,6 +160,27 @@ func (t *TransactionLog) ToMap() map[string]interface{} {
 	}
 }
 
+// Event is a log decoded with the ABI of its contract or of a token standard
+type Event struct {
+	Name      string       `json:"name"`
+	Signature string       `json:"signature"`
+	Params    []EventParam `json:"params"`
+}
+
+// EventParam holds a decoded argument formatted as a string, indexed dynamic values are only their hash
+type EventParam struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed"`
+	Value   string `json:"value"`
+}
+
+// DecodedLog pairs a log with its event, Event is nil when no known ABI matches the log
+type DecodedLog struct {
+	*TransactionLog
+	Event *Event `json:"event"`
+}
+
 func MakeTransactionLogSlice(transactionLogs []*TransactionLog) []map[string]interface{} {
 	slices := make([]map[string]interface{}, len(transactionLogs))
 	for i, transactionLog := range transactionLogs {
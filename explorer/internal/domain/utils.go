@@ -96,10 +96,46 @@ func (i BigInt) Sub(j BigInt) BigInt {
 	return fromBigInt(result)
 }
 
-// Convert SQL string to big.Int
+// FormatUnits renders i divided by 10^decimals as a decimal number without trailing zeros
+func (i BigInt) FormatUnits(decimals int) string {
+	value := (*big.Int)(&i)
+	if decimals <= 0 {
+		return value.String()
+	}
+
+	digits := new(big.Int).Abs(value).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if value.Sign() < 0 {
+		whole = "-" + whole
+	}
+	if fraction == "" {
+		return whole
+	}
+
+	return whole + "." + fraction
+}
+
+// Scan reads a number column, lib/pq returns NUMERIC as its text bytes and SQLite returns
+// INTEGER columns as int64, decimal TEXT columns as strings and sums beyond int64 as float64
 func (i *BigInt) Scan(value interface{}) error {
-	str, ok := value.(string)
-	if !ok {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	case int64:
+		*i = BigInt(*big.NewInt(v))
+		return nil
+	case float64:
+		bi, _ := big.NewFloat(v).Int(nil)
+		*i = BigInt(*bi)
+		return nil
+	default:
 		return errors.New("failed to scan BigInt")
 	}
 	bi, ok := new(big.Int).SetString(str, 10)
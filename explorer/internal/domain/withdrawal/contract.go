@@ -1,7 +1,16 @@
 package withdrawal
 
-import "context"
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 type Repository interface {
-	SaveWithdrawal(ctx context.Context, withdrawal *Withdrawal) error
+	GetBlockWithdrawals(ctx context.Context, blockHash common.Hash) ([]*Withdrawal, error)
+	// GetBlocksWithdrawals returns the withdrawals of every block among blockHashes, ordered by block hash and index
+	GetBlocksWithdrawals(ctx context.Context, blockHashes []common.Hash) ([]*Withdrawal, error)
+	// GetAddressWithdrawals returns a page of the withdrawals to the address, newest first, starting before
+	// beforeIndex when it is set
+	GetAddressWithdrawals(ctx context.Context, address common.Address, beforeIndex *uint64, limit int) ([]*Withdrawal, error)
 }
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type AddressRepository struct {
+	db           *sql.DB
+	summaryQuery string
+}
+
+func NewAddressRepository(db *sql.DB) *AddressRepository {
+	return &AddressRepository{db: db, summaryQuery: newSummaryQuery(numericDialect{})}
+}
+
+// NewSQLiteAddressRepository reads core's SQLite database, where balances are summed as decimal text
+func NewSQLiteAddressRepository(db *sql.DB) *AddressRepository {
+	return &AddressRepository{db: db, summaryQuery: newSummaryQuery(numericDialect{sqlite: true})}
+}
+
+// newSummaryQuery reads each side of the address through its own index. An address emitting logs or known as a
+// token or verified contract is a contract, and a token is held while more of it was received than sent.
+func newSummaryQuery(numeric numericDialect) string {
+	return `select
+	(select count(*) from (
+		select hash from "transaction" where from_address = $1
+		union
+		select hash from "transaction" where to_address = $1) txs),
+	(select min(seen) from (
+		select min(timestamp) as seen from "transaction" where from_address = $1
+		union all
+		select min(timestamp) from "transaction" where to_address = $1) first_seen),
+	(select max(seen) from (
+		select max(timestamp) as seen from "transaction" where from_address = $1
+		union all
+		select max(timestamp) from "transaction" where to_address = $1) last_seen),
+	exists (select 1 from smart_contract where address_hash = $1)
+		or exists (select 1 from token where address_hash = $1)
+		or exists (select 1 from transaction_log where address = $1),
+	(select count(*) from (
+		select token_contract_address_hash from (
+			select token_contract_address_hash, amount from token_transfer where to_address = $1
+			union all
+			select token_contract_address_hash, ` + numeric.negate("amount") + ` from token_transfer where from_address = $1) moves
+		group by token_contract_address_hash
+		having ` + numeric.positive(numeric.sum("amount")) + `) holdings)`
+}
+
+func (r *AddressRepository) GetSummary(ctx context.Context, addr common.Address) (*address.Summary, error) {
+	var (
+		summary             = address.Summary{Address: addr}
+		firstSeen, lastSeen sql.NullInt64
+	)
+
+	start := time.Now()
+	err := r.db.QueryRowContext(ctx, r.summaryQuery, addr).Scan(
+		&summary.TransactionsCount,
+		&firstSeen,
+		&lastSeen,
+		&summary.IsContract,
+		&summary.TokenHoldingsCount,
+	)
+	metrics.ObserveQuery("get_address_summary", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if firstSeen.Valid {
+		summary.FirstSeen, summary.LastSeen = &firstSeen.Int64, &lastSeen.Int64
+	}
+
+	return &summary, nil
+}
@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/elmiringos/indexer/explorer/internal/domain"
 	"github.com/elmiringos/indexer/explorer/internal/domain/block"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
 	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
 )
@@ -20,41 +23,63 @@ func NewBlockRepository(db *sql.DB, log *zap.Logger) *BlockRepository {
 	return &BlockRepository{db: db, log: log}
 }
 
-func (r *BlockRepository) GetCurrentBlock(ctx context.Context) (*block.Block, error) {
-	var block block.Block
-
-	query := `
-		SELECT 
-			hash,
-			number,
-			miner_hash,
-			parent_hash,
-			gas_limit,
-			gas_used, 
-			nonce,
-			size,
-			difficulty,
-			is_pos,
-			base_fee_per_gas,
-			timestamp
-		FROM block 
-		ORDER BY number::numeric DESC LIMIT 1`
-
-	row := r.db.QueryRowContext(ctx, query)
+const blockColumns = `hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, transactions_count, withdrawals_count, timestamp,
+	transactions_root, receipts_root, logs_bloom, state_root, mix_hash`
+
+// scanBlock reads a row selected with blockColumns
+func scanBlock(row interface{ Scan(dest ...any) error }) (*block.Block, error) {
+	var (
+		b                                                  block.Block
+		transactionsRoot, receiptsRoot, stateRoot, mixHash []byte
+	)
 	err := row.Scan(
-		&block.Hash,
-		&block.Number,
-		&block.MinerHash,
-		&block.ParentHash,
-		&block.GasLimit,
-		&block.GasUsed,
-		&block.Nonce,
-		&block.Size,
-		&block.Difficulty,
-		&block.IsPos,
-		&block.BaseFeePerGas,
-		&block.Timestamp,
+		&b.Hash,
+		&b.Number,
+		&b.MinerHash,
+		&b.ParentHash,
+		&b.GasLimit,
+		&b.GasUsed,
+		&b.Nonce,
+		&b.Size,
+		&b.Difficulty,
+		&b.IsPos,
+		&b.BaseFeePerGas,
+		&b.TransactionsCount,
+		&b.WithdrawalsCount,
+		&b.Timestamp,
+		&transactionsRoot,
+		&receiptsRoot,
+		&b.LogsBloom,
+		&stateRoot,
+		&mixHash,
 	)
+	if err != nil {
+		return nil, err
+	}
+	b.TransactionsRoot = scanHash(transactionsRoot)
+	b.ReceiptsRoot = scanHash(receiptsRoot)
+	b.StateRoot = scanHash(stateRoot)
+	b.MixHash = scanHash(mixHash)
+
+	return &b, nil
+}
+
+// scanHash reads a nullable hash column, nil when it was never stored
+func scanHash(value []byte) *common.Hash {
+	if len(value) != common.HashLength {
+		return nil
+	}
+
+	hash := common.BytesToHash(value)
+	return &hash
+}
+
+func (r *BlockRepository) GetCurrentBlock(ctx context.Context) (*block.Block, error) {
+	query := `select ` + blockColumns + ` from block order by number desc limit 1`
+
+	start := time.Now()
+	b, err := scanBlock(r.db.QueryRowContext(ctx, query))
+	metrics.ObserveQuery("get_current_block", start, ignoreNoRows(err))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			r.log.Info("No blocks found")
@@ -63,100 +88,145 @@ func (r *BlockRepository) GetCurrentBlock(ctx context.Context) (*block.Block, er
 		return nil, err
 	}
 
-	return &block, nil
+	return b, nil
 }
 
-func (r *BlockRepository) GetBlock(ctx context.Context, blockNumber domain.BigInt, hash common.Hash) (*block.Block, error) {
-	var block block.Block
-
-	blockNumberStr := blockNumber.String()
+func (r *BlockRepository) GetBlock(ctx context.Context, blockNumber *domain.BigInt, hash common.Hash) (*block.Block, error) {
+	var row *sql.Row
 
-	if blockNumberStr == "" && hash == (common.Hash{}) {
-		return nil, errors.New("block number and hash cannot be empty")
+	start := time.Now()
+	if blockNumber != nil {
+		row = r.db.QueryRowContext(ctx, `select `+blockColumns+` from block where number = $1`, blockNumber)
+	} else {
+		row = r.db.QueryRowContext(ctx, `select `+blockColumns+` from block where hash = $1`, hash)
 	}
 
-	var query string
-	var row *sql.Row
-
-	if blockNumberStr == "" {
-		query = `
-			SELECT hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp
-			FROM blocks WHERE hash = $1`
+	b, err := scanBlock(row)
+	metrics.ObserveQuery("get_block", start, ignoreNoRows(err))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
 
-		row = r.db.QueryRowContext(ctx, query, hash.String())
-	} else if hash == (common.Hash{}) {
-		query = `
-			SELECT hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp
-			FROM blocks WHERE number = $1`
+	return b, nil
+}
 
-		row = r.db.QueryRowContext(ctx, query, blockNumberStr)
+func (r *BlockRepository) GetBlockByTimestamp(ctx context.Context, timestamp uint64, after bool) (*block.Block, error) {
+	query := `select ` + blockColumns + ` from block where timestamp <= $1 order by timestamp desc, number desc limit 1`
+	if after {
+		query = `select ` + blockColumns + ` from block where timestamp >= $1 order by timestamp, number limit 1`
 	}
 
-	err := row.Scan(
-		&block.Number,
-		&block.Hash,
-		&block.ParentHash,
-		&block.MinerHash,
-		&block.GasLimit,
-		&block.GasUsed,
-		&block.Nonce,
-		&block.Size,
-		&block.Difficulty,
-		&block.IsPos,
-		&block.BaseFeePerGas,
-		&block.Timestamp,
-	)
+	start := time.Now()
+	b, err := scanBlock(r.db.QueryRowContext(ctx, query, int64(timestamp)))
+	metrics.ObserveQuery("get_block_by_timestamp", start, ignoreNoRows(err))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			r.log.Info("No blocks found")
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &block, nil
+	return b, nil
 }
 
-func (r *BlockRepository) GetBlocks(ctx context.Context, fromBlockNumber domain.BigInt, toBlockNumber domain.BigInt) ([]*block.Block, error) {
+// blockSortColumns maps the sort fields to trusted column names
+var blockSortColumns = map[block.SortField]string{
+	block.SortByNumber:            "number",
+	block.SortByGasUsed:           "gas_used",
+	block.SortByTransactionsCount: "transactions_count",
+}
+
+func (r *BlockRepository) GetBlocks(ctx context.Context, filter block.Filter) ([]*block.Block, error) {
+	sortColumn, ok := blockSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = "number"
+	}
+
+	direction, comparison := "asc", ">"
+	if filter.Descending {
+		direction, comparison = "desc", "<"
+	}
 
-	var blocks []*block.Block
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-	query := `
-		SELECT hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp
-		FROM blocks WHERE number::numeric BETWEEN $1 AND $2 ORDER BY number ASC`
+	if filter.FromNumber != nil {
+		conditions = append(conditions, "number >= "+arg(filter.FromNumber))
+	}
+	if filter.ToNumber != nil {
+		conditions = append(conditions, "number <= "+arg(filter.ToNumber))
+	}
+	if filter.FromTimestamp != nil {
+		conditions = append(conditions, "timestamp >= "+arg(int64(*filter.FromTimestamp)))
+	}
+	if filter.ToTimestamp != nil {
+		conditions = append(conditions, "timestamp <= "+arg(int64(*filter.ToTimestamp)))
+	}
+	if filter.After != nil {
+		if sortColumn == "number" {
+			conditions = append(conditions, fmt.Sprintf("number %s %s", comparison, arg(filter.After.Number)))
+		} else {
+			value, number := arg(filter.After.Value), arg(filter.After.Number)
+			conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s %[3]s or (%[1]s = %[3]s and number %[2]s %[4]s))", sortColumn, comparison, value, number))
+		}
+	}
+
+	query := `select ` + blockColumns + ` from block`
+	if len(conditions) > 0 {
+		query += ` where ` + strings.Join(conditions, " and ")
+	}
+	query += fmt.Sprintf(` order by %[1]s %[2]s, number %[2]s limit %[3]s`, sortColumn, direction, arg(filter.Limit))
 
-	rows, err := r.db.QueryContext(ctx, query, fromBlockNumber.String(), toBlockNumber.String())
+	return r.queryBlocks(ctx, "get_blocks", filter.Limit, query, args...)
+}
+
+func (r *BlockRepository) GetBlocksByHash(ctx context.Context, hashes []common.Hash) ([]*block.Block, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	query := `select ` + blockColumns + ` from block where hash in (` + argList(arg, hashes) + `)`
+
+	return r.queryBlocks(ctx, "get_blocks_by_hash", len(hashes), query, args...)
+}
+
+func (r *BlockRepository) queryBlocks(ctx context.Context, name string, limit int, query string, args ...interface{}) ([]*block.Block, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
+		metrics.ObserveQuery(name, start, err)
 		return nil, err
 	}
 	defer rows.Close()
+
+	blocks := make([]*block.Block, 0, limit)
 	for rows.Next() {
-		var block block.Block
-		err := rows.Scan(
-			&block.Number,
-			&block.Hash,
-			&block.ParentHash,
-			&block.MinerHash,
-			&block.GasLimit,
-			&block.GasUsed,
-			&block.Nonce,
-			&block.Size,
-			&block.Difficulty,
-			&block.IsPos,
-			&block.BaseFeePerGas,
-			&block.Timestamp,
-		)
+		b, err := scanBlock(rows)
 		if err != nil {
+			metrics.ObserveQuery(name, start, err)
 			return nil, err
 		}
-		blocks = append(blocks, &block)
+		blocks = append(blocks, b)
 	}
-	if err := rows.Err(); err != nil {
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
 		return nil, err
 	}
-	if len(blocks) == 0 {
-		r.log.Info("No blocks found")
-		return nil, nil
-	}
+
 	return blocks, nil
 }
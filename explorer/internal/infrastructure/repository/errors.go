@@ -1,7 +1,18 @@
 package repository
 
-import "errors"
+import (
+	"database/sql"
+	"errors"
+)
 
 var (
 	ErrNotFound = errors.New("no record found")
 )
+
+// ignoreNoRows treats an empty result as a successful query for metrics purposes
+func ignoreNoRows(err error) error {
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
@@ -1,7 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/internal_transaction"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type InternalTransactionRepository struct {
@@ -11,3 +18,81 @@ type InternalTransactionRepository struct {
 func NewInternalTransactionRepository(db *sql.DB) *InternalTransactionRepository {
 	return &InternalTransactionRepository{db: db}
 }
+
+const internalTransactionSelect = `select block_hash, "index", transaction_hash, status, gas, gas_used, input, output, amount,
+	from_address, to_address, create_contract_address_hash, timestamp
+	from internal_transaction`
+
+func (r *InternalTransactionRepository) GetTransactionInternalTransactions(ctx context.Context, transactionHash common.Hash) ([]*internal_transaction.InternalTransaction, error) {
+	query := internalTransactionSelect + ` where transaction_hash = $1 order by "index"`
+
+	return r.queryInternalTransactions(ctx, "get_transaction_internal_transactions", query, transactionHash)
+}
+
+func (r *InternalTransactionRepository) GetAddressInternalTransactions(ctx context.Context, filter internal_transaction.AddressFilter) ([]*internal_transaction.InternalTransaction, error) {
+	args := []interface{}{filter.Address, filter.Limit}
+	keyset := ""
+	if filter.After != nil {
+		args = append(args, filter.After.Timestamp, filter.After.Index)
+		keyset = ` and (timestamp < $3 or (timestamp = $3 and "index" < $4))`
+	}
+
+	side := func(column string) string {
+		return internalTransactionSelect + ` where ` + column + ` = $1` + keyset + ` order by timestamp desc, "index" desc limit $2`
+	}
+
+	var query string
+	switch filter.Direction {
+	case address.DirectionIn:
+		query = side("to_address")
+	case address.DirectionOut:
+		query = side("from_address")
+	default:
+		query = `select * from (` + side("from_address") + `) sent union select * from (` + side("to_address") + `) received
+			order by timestamp desc, "index" desc limit $2`
+	}
+
+	return r.queryInternalTransactions(ctx, "get_address_internal_transactions", query, args...)
+}
+
+func (r *InternalTransactionRepository) queryInternalTransactions(ctx context.Context, name string, query string, args ...interface{}) ([]*internal_transaction.InternalTransaction, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery(name, start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var internalTransactions []*internal_transaction.InternalTransaction
+	for rows.Next() {
+		var i internal_transaction.InternalTransaction
+		err := rows.Scan(
+			&i.BlockHash,
+			&i.Index,
+			&i.TransactionHash,
+			&i.Status,
+			&i.Gas,
+			&i.GasUsed,
+			&i.Input,
+			&i.Output,
+			&i.Value,
+			&i.From,
+			&i.To,
+			&i.ContractAddress,
+			&i.Timestamp,
+		)
+		if err != nil {
+			metrics.ObserveQuery(name, start, err)
+			return nil, err
+		}
+		internalTransactions = append(internalTransactions, &i)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return internalTransactions, nil
+}
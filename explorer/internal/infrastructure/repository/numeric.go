@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/elmiringos/indexer/explorer/pkg/sqlite"
+)
+
+// numericDialect writes the expressions summing and comparing uint256 columns, the token amounts, balances
+// and token ids. Postgres keeps them NUMERIC. SQLite keeps them as TEXT it would coerce to REAL, so there
+// they are summed by sqlite.DecimalSum and compared as decimal text, by length first.
+type numericDialect struct {
+	sqlite bool
+}
+
+// negate negates an amount column
+func (d numericDialect) negate(amount string) string {
+	if d.sqlite {
+		return `'-' || ` + amount
+	}
+	return `-` + amount
+}
+
+// sum adds amounts up to a balance
+func (d numericDialect) sum(amount string) string {
+	if d.sqlite {
+		return fmt.Sprintf(`%s(%s)`, sqlite.DecimalSum, amount)
+	}
+	return fmt.Sprintf(`sum(%s)`, amount)
+}
+
+// count counts rows into a balance comparable with a sum
+func (d numericDialect) count() string {
+	if d.sqlite {
+		return `cast(count(*) as text)`
+	}
+	return `count(*)`
+}
+
+// positive tests a balance is above zero, decimal text without leading zeros starts with a non zero digit
+func (d numericDialect) positive(balance string) string {
+	if d.sqlite {
+		return balance + ` glob '[1-9]*'`
+	}
+	return balance + ` > 0`
+}
+
+// ascending orders non negative numbers from the smallest
+func (d numericDialect) ascending(column string) string {
+	if d.sqlite {
+		return fmt.Sprintf(`length(%[1]s), %[1]s`, column)
+	}
+	return column
+}
+
+// descending orders non negative numbers from the largest
+func (d numericDialect) descending(column string) string {
+	if d.sqlite {
+		return fmt.Sprintf(`length(%[1]s) desc, %[1]s desc`, column)
+	}
+	return column + ` desc`
+}
+
+// compare compares a non negative number with the one bound to placeholder, operator is <, = or >
+func (d numericDialect) compare(column, operator, placeholder string) string {
+	if d.sqlite {
+		return fmt.Sprintf(`(length(%[1]s), %[1]s) %[2]s (length(%[3]s), %[3]s)`, column, operator, placeholder)
+	}
+	return fmt.Sprintf(`%s %s cast(%s as numeric)`, column, operator, placeholder)
+}
@@ -1,7 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/reward"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type RewardRepository struct {
@@ -11,3 +18,38 @@ type RewardRepository struct {
 func NewRewardRepository(db *sql.DB) *RewardRepository {
 	return &RewardRepository{db: db}
 }
+
+func (r *RewardRepository) GetAddressRewards(ctx context.Context, address common.Address, beforeNumber *domain.BigInt, limit int) ([]*reward.Reward, error) {
+	query := `select rw.block_hash, b.number, rw.address, rw.amount from reward rw join block b on b.hash = rw.block_hash where rw.address = $1`
+	args := []interface{}{address, limit}
+	if beforeNumber != nil {
+		query += ` and b.number < $3`
+		args = append(args, beforeNumber)
+	}
+	query += ` order by b.number desc limit $2`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery("get_address_rewards", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rewards := make([]*reward.Reward, 0, limit)
+	for rows.Next() {
+		var rw reward.Reward
+		if err := rows.Scan(&rw.BlockHash, &rw.BlockNumber, &rw.Address, &rw.Amount); err != nil {
+			metrics.ObserveQuery("get_address_rewards", start, err)
+			return nil, err
+		}
+		rewards = append(rewards, &rw)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery("get_address_rewards", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return rewards, nil
+}
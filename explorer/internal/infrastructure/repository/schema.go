@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// RequiredSchemaVersion is the core migration the explorer queries are written against
+const RequiredSchemaVersion = 20261019230000
+
+var (
+	ErrSchemaVersionUnknown = errors.New("failed to read schema version")
+	ErrSchemaOutdated       = errors.New("schema version is older than the explorer expects")
+	ErrSchemaDirty          = errors.New("schema is dirty after a failed migration")
+)
+
+// CheckSchemaVersion reads the version core's migrations recorded in schema_migrations
+func CheckSchemaVersion(ctx context.Context, db *sql.DB) error {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, `select version, dirty from schema_migrations limit 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: no migration applied, expected %d", ErrSchemaOutdated, RequiredSchemaVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSchemaVersionUnknown, err)
+	}
+
+	if dirty {
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, version)
+	}
+	if version < RequiredSchemaVersion {
+		return fmt.Errorf("%w: version %d, expected %d", ErrSchemaOutdated, version, RequiredSchemaVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// openSchemaMigrations creates schema_migrations the way core's migrator does and records version in it,
+// a negative version leaves the table empty
+func openSchemaMigrations(t *testing.T, version int64, dirty bool) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`create table schema_migrations (version uint64, dirty bool)`)
+	require.NoError(t, err)
+	if version >= 0 {
+		_, err = db.Exec(`insert into schema_migrations (version, dirty) values ($1, $2)`, version, dirty)
+		require.NoError(t, err)
+	}
+
+	return db
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, CheckSchemaVersion(ctx, openSchemaMigrations(t, RequiredSchemaVersion, false)))
+	assert.NoError(t, CheckSchemaVersion(ctx, openSchemaMigrations(t, RequiredSchemaVersion+10000, false)),
+		"core may be ahead of the explorer")
+
+	assert.ErrorIs(t, CheckSchemaVersion(ctx, openSchemaMigrations(t, RequiredSchemaVersion-10000, false)), ErrSchemaOutdated)
+	assert.ErrorIs(t, CheckSchemaVersion(ctx, openSchemaMigrations(t, -1, false)), ErrSchemaOutdated)
+	assert.ErrorIs(t, CheckSchemaVersion(ctx, openSchemaMigrations(t, RequiredSchemaVersion, true)), ErrSchemaDirty)
+}
+
+func TestCheckSchemaVersion_NotMigrated(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "indexer.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.ErrorIs(t, CheckSchemaVersion(context.Background(), db), ErrSchemaVersionUnknown)
+}
+
+// TestRequiredSchemaVersion_IsCoreMigration keeps the required version pointing at a migration core ships
+// for both databases
+func TestRequiredSchemaVersion_IsCoreMigration(t *testing.T) {
+	for _, dir := range []string{"../../../../core/migrations", "../../../../core/migrations/sqlite"} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+
+		found := false
+		for _, entry := range entries {
+			version, _, _ := strings.Cut(entry.Name(), "_")
+			if strings.HasSuffix(entry.Name(), ".up.sql") && version == strconv.FormatInt(RequiredSchemaVersion, 10) {
+				found = true
+			}
+		}
+		assert.True(t, found, "%s has no migration %d", dir, RequiredSchemaVersion)
+	}
+}
@@ -1,7 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	smartcontract "github.com/elmiringos/indexer/explorer/internal/domain/smart_contract"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type SmartContractRepository struct {
@@ -11,3 +19,94 @@ type SmartContractRepository struct {
 func NewSmartContractRepository(db *sql.DB) *SmartContractRepository {
 	return &SmartContractRepository{db: db}
 }
+
+func (r *SmartContractRepository) GetABIs(ctx context.Context, addresses []common.Address) (map[common.Address]string, error) {
+	abis := make(map[common.Address]string)
+	if len(addresses) == 0 {
+		return abis, nil
+	}
+
+	placeholders := make([]string, len(addresses))
+	args := make([]interface{}, len(addresses))
+	for i, address := range addresses {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = address
+	}
+
+	query := `select address_hash, abi from smart_contract where address_hash in (` + strings.Join(placeholders, ", ") + `)`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery("get_abis", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			address common.Address
+			abi     string
+		)
+		if err := rows.Scan(&address, &abi); err != nil {
+			metrics.ObserveQuery("get_abis", start, err)
+			return nil, err
+		}
+		abis[address] = abi
+	}
+	err = rows.Err()
+	metrics.ObserveQuery("get_abis", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return abis, nil
+}
+
+// contractSelect leaves out the source and the ABI, the search results do not show them
+const contractSelect = `select address_hash, name, compiler_version, coalesce(evm_version, ''), coalesce(verified_by_eth, false) from smart_contract`
+
+func (r *SmartContractRepository) GetContract(ctx context.Context, address common.Address) (*smartcontract.SmartContract, error) {
+	contracts, err := r.queryContracts(ctx, "get_contract", contractSelect+` where address_hash = $1`, address)
+	if err != nil || len(contracts) == 0 {
+		return nil, err
+	}
+
+	return contracts[0], nil
+}
+
+func (r *SmartContractRepository) SearchContracts(ctx context.Context, query string, limit int) ([]*smartcontract.SmartContract, error) {
+	match, exact, prefix := searchPatterns(query)
+	sqlQuery := contractSelect + ` where lower(name) like $1 escape '\'
+		order by case when lower(name) = $2 then 0 when lower(name) like $3 escape '\' then 1 else 2 end, name, address_hash
+		limit $4`
+
+	return r.queryContracts(ctx, "search_contracts", sqlQuery, match, exact, prefix, limit)
+}
+
+func (r *SmartContractRepository) queryContracts(ctx context.Context, name string, query string, args ...interface{}) ([]*smartcontract.SmartContract, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery(name, start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contracts []*smartcontract.SmartContract
+	for rows.Next() {
+		var c smartcontract.SmartContract
+		if err := rows.Scan(&c.AddressHash, &c.Name, &c.CompilerVersion, &c.EvmVersion, &c.VerifiedByEth); err != nil {
+			metrics.ObserveQuery(name, start, err)
+			return nil, err
+		}
+		contracts = append(contracts, &c)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return contracts, nil
+}
@@ -1,13 +1,510 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain"
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/token"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type TokenRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	numeric numericDialect
 }
 
 func NewTokenRepository(db *sql.DB) *TokenRepository {
 	return &TokenRepository{db: db}
 }
+
+// NewSQLiteTokenRepository reads core's SQLite database, where balances are summed as decimal text
+func NewSQLiteTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{db: db, numeric: numericDialect{sqlite: true}}
+}
+
+// tokenTransferSelect reads the transfers with their position in the chain and their token
+const tokenTransferSelect = `select tt.transaction_hash, b.number, t."index" as transaction_index, t.timestamp, tt.log_index,
+	tt.from_address, tt.to_address, tt.token_contract_address_hash, tt.amount, tt.token_id, tk.type, tk.name, tk.symbol, tk.decimals
+	from token_transfer tt
+	join "transaction" t on t.hash = tt.transaction_hash
+	join block b on b.hash = t.block_hash
+	left join token tk on tk.address_hash = tt.token_contract_address_hash`
+
+func (r *TokenRepository) GetTransactionTokenTransfers(ctx context.Context, transactionHash common.Hash) ([]*token.TokenTransfer, error) {
+	query := tokenTransferSelect + ` where tt.transaction_hash = $1 order by tt.log_index`
+
+	return r.queryTokenTransfers(ctx, "get_transaction_token_transfers", query, transactionHash)
+}
+
+func (r *TokenRepository) GetAddressTokenTransfers(ctx context.Context, filter token.TransferFilter) ([]*token.TokenTransfer, error) {
+	args := []interface{}{filter.Address, filter.Limit}
+	keyset := ""
+	if filter.After != nil {
+		args = append(args, filter.After.Timestamp, filter.After.TransactionIndex, filter.After.LogIndex)
+		keyset = ` and (t.timestamp < $3 or (t.timestamp = $3 and (t."index" < $4 or (t."index" = $4 and tt.log_index < $5))))`
+	}
+
+	side := func(column string) string {
+		return tokenTransferSelect + ` where tt.` + column + ` = $1` + keyset +
+			` order by t.timestamp desc, t."index" desc, tt.log_index desc limit $2`
+	}
+
+	var query string
+	switch filter.Direction {
+	case address.DirectionIn:
+		query = side("to_address")
+	case address.DirectionOut:
+		query = side("from_address")
+	default:
+		query = `select * from (` + side("from_address") + `) sent union select * from (` + side("to_address") + `) received
+			order by timestamp desc, transaction_index desc, log_index desc limit $2`
+	}
+
+	return r.queryTokenTransfers(ctx, "get_address_token_transfers", query, args...)
+}
+
+func (r *TokenRepository) GetAccountTokenTransfers(ctx context.Context, filter token.AccountTransferFilter) ([]*token.TokenTransfer, error) {
+	direction := "asc"
+	if filter.Descending {
+		direction = "desc"
+	}
+
+	args := []interface{}{filter.Offset + filter.Limit}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var conditions []string
+	if filter.Token != nil {
+		conditions = append(conditions, "tt.token_contract_address_hash = "+arg(*filter.Token))
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "tk.type = "+arg(filter.Type))
+	}
+	if filter.FromBlock != nil {
+		conditions = append(conditions, "b.number >= "+arg(filter.FromBlock))
+	}
+	if filter.ToBlock != nil {
+		conditions = append(conditions, "b.number <= "+arg(filter.ToBlock))
+	}
+
+	order := fmt.Sprintf(` order by b.number %[1]s, t."index" %[1]s, tt.log_index %[1]s`, direction)
+	page := fmt.Sprintf(` limit %s offset %s`, arg(filter.Limit), arg(filter.Offset))
+
+	if filter.Address == nil {
+		query := tokenTransferSelect + ` where ` + strings.Join(conditions, " and ") + order + page
+		return r.queryTokenTransfers(ctx, "get_account_token_transfers", query, args...)
+	}
+
+	// each side reads up to the end of the page, union drops the transfers an address sent to itself twice
+	account := arg(*filter.Address)
+	side := func(column string) string {
+		return tokenTransferSelect + ` where ` + strings.Join(append(conditions, "tt."+column+" = "+account), " and ") + order + ` limit $1`
+	}
+	query := `select * from (` + side("from_address") + `) sent union select * from (` + side("to_address") + `) received` +
+		fmt.Sprintf(` order by number %[1]s, transaction_index %[1]s, log_index %[1]s`, direction) + page
+
+	return r.queryTokenTransfers(ctx, "get_account_token_transfers", query, args...)
+}
+
+func (r *TokenRepository) GetTokenTransfers(ctx context.Context, tokenAddress common.Address, after *token.TransferCursor, limit int) ([]*token.TokenTransfer, error) {
+	query := tokenTransferSelect + ` where tt.token_contract_address_hash = $1`
+	args := []interface{}{tokenAddress, limit}
+	if after != nil {
+		args = append(args, after.Timestamp, after.TransactionIndex, after.LogIndex)
+		query += ` and (t.timestamp < $3 or (t.timestamp = $3 and (t."index" < $4 or (t."index" = $4 and tt.log_index < $5))))`
+	}
+	query += ` order by t.timestamp desc, t."index" desc, tt.log_index desc limit $2`
+
+	return r.queryTokenTransfers(ctx, "get_token_transfers", query, args...)
+}
+
+func (r *TokenRepository) GetInstanceTransfers(ctx context.Context, tokenAddress common.Address, tokenId domain.BigInt, after *token.TransferCursor, limit int) ([]*token.TokenTransfer, error) {
+	query := tokenTransferSelect + ` where tt.token_contract_address_hash = $1 and tt.token_id = $2`
+	args := []interface{}{tokenAddress, tokenId, limit}
+	if after != nil {
+		args = append(args, after.Timestamp, after.TransactionIndex, after.LogIndex)
+		query += ` and (t.timestamp < $4 or (t.timestamp = $4 and (t."index" < $5 or (t."index" = $5 and tt.log_index < $6))))`
+	}
+	query += ` order by t.timestamp desc, t."index" desc, tt.log_index desc limit $3`
+
+	return r.queryTokenTransfers(ctx, "get_instance_transfers", query, args...)
+}
+
+const tokenSelect = `select address_hash, type, name, symbol, total_supply, decimals, fiat_value, circulation_market_cap from token`
+
+func (r *TokenRepository) GetToken(ctx context.Context, address common.Address) (*token.Token, error) {
+	tokens, err := r.queryTokens(ctx, "get_token", tokenSelect+` where address_hash = $1`, address)
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+
+	return tokens[0], nil
+}
+
+func (r *TokenRepository) GetTokensByAddress(ctx context.Context, addresses []common.Address) ([]*token.Token, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	return r.queryTokens(ctx, "get_tokens_by_address", tokenSelect+` where address_hash in (`+argList(arg, addresses)+`)`, args...)
+}
+
+func (r *TokenRepository) GetTokens(ctx context.Context, filter token.Filter) ([]*token.Token, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Query != "" {
+		pattern := arg("%" + likeEscaper.Replace(strings.ToLower(filter.Query)) + "%")
+		conditions = append(conditions, fmt.Sprintf(`(lower(symbol) like %[1]s escape '\' or lower(name) like %[1]s escape '\')`, pattern))
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = "+arg(filter.Type))
+	}
+	if filter.After != nil {
+		name, address := arg(filter.After.Name), arg(filter.After.Address)
+		conditions = append(conditions, fmt.Sprintf("(name > %[1]s or (name = %[1]s and address_hash > %[2]s))", name, address))
+	}
+
+	query := tokenSelect
+	if len(conditions) > 0 {
+		query += ` where ` + strings.Join(conditions, " and ")
+	}
+	query += ` order by name, address_hash limit ` + arg(filter.Limit)
+
+	return r.queryTokens(ctx, "get_tokens", query, args...)
+}
+
+func (r *TokenRepository) SearchTokens(ctx context.Context, query string, limit int) ([]*token.Token, error) {
+	match, exact, prefix := searchPatterns(query)
+	sqlQuery := tokenSelect + ` where lower(symbol) like $1 escape '\' or lower(name) like $1 escape '\'
+		order by case
+			when lower(symbol) = $2 or lower(name) = $2 then 0
+			when lower(symbol) like $3 escape '\' or lower(name) like $3 escape '\' then 1
+			else 2 end, name, address_hash
+		limit $4`
+
+	return r.queryTokens(ctx, "search_tokens", sqlQuery, match, exact, prefix, limit)
+}
+
+// minContainsQuery is the shortest query matched anywhere in a name, trigram indexes need three characters
+// and shorter queries are matched by prefix only
+const minContainsQuery = 3
+
+// searchPatterns returns the like pattern selecting the matches of a search query, the lowercased query and
+// the like pattern of its prefix matches
+func searchPatterns(query string) (match string, exact string, prefix string) {
+	exact = strings.ToLower(query)
+	prefix = likeEscaper.Replace(exact) + "%"
+	if len([]rune(exact)) < minContainsQuery {
+		return prefix, exact, prefix
+	}
+
+	return "%" + prefix, exact, prefix
+}
+
+// likeEscaper escapes the wildcards of a like pattern, the queries declare \ as the escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *TokenRepository) queryTokens(ctx context.Context, name string, query string, args ...interface{}) ([]*token.Token, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery(name, start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*token.Token
+	for rows.Next() {
+		var t token.Token
+		err := rows.Scan(&t.Address, &t.Type, &t.Name, &t.Symbol, &t.TotalSupply, &t.Decimals, &t.FiatValue, &t.CirculationMarketCap)
+		if err != nil {
+			metrics.ObserveQuery(name, start, err)
+			return nil, err
+		}
+		tokens = append(tokens, &t)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// holdersSource yields the holder and balance of every address with a positive balance of the token bound
+// to $1, the zero address bound to $2 takes the burnt supply and is left out
+func (r *TokenRepository) holdersSource(instances bool) string {
+	balances := `select holder, ` + r.numeric.sum("delta") + ` as balance from (
+			select to_address as holder, amount as delta from token_transfer where token_contract_address_hash = $1
+			union all
+			select from_address, ` + r.numeric.negate("amount") + ` from token_transfer where token_contract_address_hash = $1
+		) d group by holder`
+	if instances {
+		balances = `select owner_address_hash as holder, ` + r.numeric.count() + ` as balance from token_instance
+			where token_contract_address_hash = $1 group by owner_address_hash`
+	}
+
+	return `(select holder, balance from (` + balances + `) b where ` + r.numeric.positive("balance") + ` and holder <> $2) h`
+}
+
+func (r *TokenRepository) GetTokenCounts(ctx context.Context, t *token.Token) (int64, int64, error) {
+	query := `select (select count(*) from ` + r.holdersSource(t.HoldsInstances()) + `),
+		(select count(*) from token_transfer where token_contract_address_hash = $1)`
+
+	start := time.Now()
+	var holders, transfers int64
+	err := r.db.QueryRowContext(ctx, query, t.Address, common.Address{}).Scan(&holders, &transfers)
+	metrics.ObserveQuery("get_token_counts", start, err)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return holders, transfers, nil
+}
+
+func (r *TokenRepository) GetTokenHolders(ctx context.Context, filter token.HolderFilter) ([]*token.Holder, error) {
+	query := `select holder, balance from ` + r.holdersSource(filter.Instances)
+	args := []interface{}{filter.Token, common.Address{}, filter.Limit}
+	if filter.After != nil {
+		args = append(args, filter.After.Balance, filter.After.Address)
+		query += ` where ` + r.numeric.compare("balance", "<", "$4") + ` or (` + r.numeric.compare("balance", "=", "$4") + ` and holder > $5)`
+	}
+	query += ` order by ` + r.numeric.descending("balance") + `, holder limit $3`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery("get_token_holders", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holders []*token.Holder
+	for rows.Next() {
+		var h token.Holder
+		if err := rows.Scan(&h.Address, &h.Balance); err != nil {
+			metrics.ObserveQuery("get_token_holders", start, err)
+			return nil, err
+		}
+		holders = append(holders, &h)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery("get_token_holders", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return holders, nil
+}
+
+// instanceSelect reads the instances with their token
+const instanceSelect = `select ti.token_contract_address_hash, ti.token_id, ti.owner_address_hash, ti.metadata,
+	tk.type, tk.name, tk.symbol, tk.decimals
+	from token_instance ti
+	join token tk on tk.address_hash = ti.token_contract_address_hash`
+
+func (r *TokenRepository) GetTokenInstance(ctx context.Context, tokenAddress common.Address, tokenId domain.BigInt) (*token.TokenInstance, error) {
+	query := instanceSelect + ` where ti.token_contract_address_hash = $1 and ti.token_id = $2`
+
+	instances, err := r.queryInstances(ctx, "get_token_instance", query, tokenAddress, tokenId)
+	if err != nil || len(instances) == 0 {
+		return nil, err
+	}
+
+	return instances[0], nil
+}
+
+func (r *TokenRepository) GetOwnerInstances(ctx context.Context, filter token.InventoryFilter) ([]*token.TokenInstance, error) {
+	query := instanceSelect + ` where ti.owner_address_hash = $1`
+	args := []interface{}{filter.Owner, filter.Limit}
+	if filter.After != nil {
+		args = append(args, filter.After.Contract, filter.After.TokenId)
+		query += ` and (ti.token_contract_address_hash > $3 or (ti.token_contract_address_hash = $3 and ` + r.numeric.compare("ti.token_id", ">", "$4") + `))`
+	}
+	query += ` order by ti.token_contract_address_hash, ` + r.numeric.ascending("ti.token_id") + ` limit $2`
+
+	return r.queryInstances(ctx, "get_owner_instances", query, args...)
+}
+
+func (r *TokenRepository) GetOwnerCollectionCounts(ctx context.Context, owner common.Address, tokens []common.Address) (map[common.Address]int64, error) {
+	counts := make(map[common.Address]int64, len(tokens))
+	if len(tokens) == 0 {
+		return counts, nil
+	}
+
+	args := []interface{}{owner}
+	placeholders := make([]string, len(tokens))
+	for i, t := range tokens {
+		args = append(args, t)
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+	}
+	query := `select token_contract_address_hash, count(*) from token_instance
+		where owner_address_hash = $1 and token_contract_address_hash in (` + strings.Join(placeholders, ", ") + `)
+		group by token_contract_address_hash`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery("get_owner_collection_counts", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			address common.Address
+			count   int64
+		)
+		if err := rows.Scan(&address, &count); err != nil {
+			metrics.ObserveQuery("get_owner_collection_counts", start, err)
+			return nil, err
+		}
+		counts[address] = count
+	}
+	err = rows.Err()
+	metrics.ObserveQuery("get_owner_collection_counts", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *TokenRepository) GetCollectionCounts(ctx context.Context, tokenAddress common.Address) (int64, int64, int64, error) {
+	query := `select count(distinct owner_address_hash), count(*),
+		(select count(*) from token_transfer where token_contract_address_hash = $1)
+		from token_instance where token_contract_address_hash = $1 and owner_address_hash <> $2`
+
+	start := time.Now()
+	var owners, supply, transfers int64
+	err := r.db.QueryRowContext(ctx, query, tokenAddress, common.Address{}).Scan(&owners, &supply, &transfers)
+	metrics.ObserveQuery("get_collection_counts", start, err)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return owners, supply, transfers, nil
+}
+
+func (r *TokenRepository) queryInstances(ctx context.Context, name string, query string, args ...interface{}) ([]*token.TokenInstance, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery(name, start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var instances []*token.TokenInstance
+	for rows.Next() {
+		var (
+			i        token.TokenInstance
+			t        token.Token
+			metadata []byte
+		)
+		err := rows.Scan(&i.TokenContractAddress, &i.TokenId, &i.OwnerAddress, &metadata, &t.Type, &t.Name, &t.Symbol, &t.Decimals)
+		if err != nil {
+			metrics.ObserveQuery(name, start, err)
+			return nil, err
+		}
+
+		// SQLite keeps the metadata as unchecked text
+		if json.Valid(metadata) {
+			raw := json.RawMessage(metadata)
+			i.Metadata = &raw
+		}
+		t.Address = i.TokenContractAddress
+		i.Token = &t
+		instances = append(instances, &i)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+func (r *TokenRepository) queryTokenTransfers(ctx context.Context, name string, query string, args ...interface{}) ([]*token.TokenTransfer, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery(name, start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*token.TokenTransfer
+	for rows.Next() {
+		var (
+			t             token.TokenTransfer
+			tokenType     sql.NullString
+			tokenName     sql.NullString
+			tokenSymbol   sql.NullString
+			tokenDecimals sql.NullInt64
+		)
+		err := rows.Scan(
+			&t.TransactionHash,
+			&t.BlockNumber,
+			&t.TransactionIndex,
+			&t.Timestamp,
+			&t.LogIndex,
+			&t.From,
+			&t.To,
+			&t.TokenContractAddress,
+			&t.Amount,
+			&t.TokenId,
+			&tokenType,
+			&tokenName,
+			&tokenSymbol,
+			&tokenDecimals,
+		)
+		if err != nil {
+			metrics.ObserveQuery(name, start, err)
+			return nil, err
+		}
+
+		// name and decimals are NOT NULL, a NULL means the join found no token
+		if tokenDecimals.Valid {
+			t.Token = &token.Token{
+				Address:  t.TokenContractAddress,
+				Type:     tokenType.String,
+				Name:     tokenName.String,
+				Symbol:   tokenSymbol.String,
+				Decimals: int(tokenDecimals.Int64),
+			}
+		}
+		transfers = append(transfers, &t)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
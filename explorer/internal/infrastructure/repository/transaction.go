@@ -1,8 +1,16 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/elmiringos/indexer/explorer/internal/domain/address"
+	"github.com/elmiringos/indexer/explorer/internal/domain/transaction"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
 )
 
@@ -14,3 +22,369 @@ type TransactionRepository struct {
 func NewTransactionRepository(db *sql.DB, log *zap.Logger) *TransactionRepository {
 	return &TransactionRepository{db: db, log: log}
 }
+
+// transactionSelect reads transactionColumns, the block is joined for its number and base fee
+const transactionSelect = `select t.hash, t.block_hash, b.number, t."index", t.type, t.status, t.gas, t.gas_used, t.input, t.value,
+	t.from_address, t.to_address, t.nonce, t.timestamp, t.raw, t.cumulative_gas_used, b.base_fee_per_gas
+	from "transaction" t join block b on b.hash = t.block_hash`
+
+// scanTransaction reads a row selected with transactionSelect
+func scanTransaction(row interface{ Scan(dest ...any) error }) (*transaction.Transaction, error) {
+	var t transaction.Transaction
+	err := row.Scan(
+		&t.Hash,
+		&t.BlockHash,
+		&t.BlockNumber,
+		&t.Index,
+		&t.Type,
+		&t.Status,
+		&t.Gas,
+		&t.GasUsed,
+		&t.Input,
+		&t.Value,
+		&t.From,
+		&t.To,
+		&t.Nonce,
+		&t.Timestamp,
+		&t.Raw,
+		&t.CumulativeGasUsed,
+		&t.BaseFeePerGas,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *TransactionRepository) GetTransaction(ctx context.Context, hash common.Hash) (*transaction.Transaction, error) {
+	start := time.Now()
+	t, err := scanTransaction(r.db.QueryRowContext(ctx, transactionSelect+` where t.hash = $1`, hash))
+	metrics.ObserveQuery("get_transaction", start, ignoreNoRows(err))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r *TransactionRepository) GetTransactions(ctx context.Context, filter transaction.Filter) ([]*transaction.Transaction, error) {
+	direction, comparison := "asc", ">"
+	if filter.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.BlockNumber != nil {
+		conditions = append(conditions, "b.number = "+arg(filter.BlockNumber))
+	}
+	if filter.BlockHash != nil {
+		conditions = append(conditions, "t.block_hash = "+arg(*filter.BlockHash))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "t.from_address = "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "t.to_address = "+arg(*filter.To))
+	}
+	if filter.Status != nil {
+		conditions = append(conditions, "t.status = "+arg(int64(*filter.Status)))
+	}
+	if len(filter.MethodSelector) > 0 {
+		conditions = append(conditions, fmt.Sprintf("substr(t.input, 1, %d) = %s", len(filter.MethodSelector), arg(filter.MethodSelector)))
+	}
+	if filter.After != nil {
+		number, index := arg(filter.After.BlockNumber), arg(filter.After.Index)
+		conditions = append(conditions, fmt.Sprintf(`(b.number %[1]s %[2]s or (b.number = %[2]s and t."index" %[1]s %[3]s))`, comparison, number, index))
+	}
+
+	query := transactionSelect
+	if len(conditions) > 0 {
+		query += ` where ` + strings.Join(conditions, " and ")
+	}
+	query += fmt.Sprintf(` order by b.number %[1]s, t."index" %[1]s limit %[2]s`, direction, arg(filter.Limit))
+
+	return r.queryTransactions(ctx, "get_transactions", filter.Limit, query, args...)
+}
+
+func (r *TransactionRepository) GetAddressTransactions(ctx context.Context, filter transaction.AddressFilter) ([]*transaction.Transaction, error) {
+	args := []interface{}{filter.Address, filter.Limit}
+	keyset := ""
+	if filter.After != nil {
+		args = append(args, filter.After.Timestamp, filter.After.Index)
+		keyset = ` and (t.timestamp < $3 or (t.timestamp = $3 and t."index" < $4))`
+	}
+
+	side := func(column string) string {
+		return transactionSelect + ` where t.` + column + ` = $1` + keyset + ` order by t.timestamp desc, t."index" desc limit $2`
+	}
+
+	var query string
+	switch filter.Direction {
+	case address.DirectionIn:
+		query = side("to_address")
+	case address.DirectionOut:
+		query = side("from_address")
+	default:
+		// one index scan per side, union drops the transactions an address sent to itself twice
+		query = `select * from (` + side("from_address") + `) sent union select * from (` + side("to_address") + `) received
+			order by timestamp desc, "index" desc limit $2`
+	}
+
+	return r.queryTransactions(ctx, "get_address_transactions", filter.Limit, query, args...)
+}
+
+func (r *TransactionRepository) GetAccountTransactions(ctx context.Context, filter transaction.AccountFilter) ([]*transaction.Transaction, error) {
+	direction := "asc"
+	if filter.Descending {
+		direction = "desc"
+	}
+
+	args := []interface{}{filter.Address, filter.Offset + filter.Limit}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	bounds := ""
+	if filter.FromBlock != nil {
+		bounds += " and b.number >= " + arg(filter.FromBlock)
+	}
+	if filter.ToBlock != nil {
+		bounds += " and b.number <= " + arg(filter.ToBlock)
+	}
+
+	// each side reads up to the end of the page, union drops the transactions an address sent to itself twice
+	side := func(column string) string {
+		return transactionSelect + ` where t.` + column + ` = $1` + bounds +
+			fmt.Sprintf(` order by b.number %[1]s, t."index" %[1]s limit $2`, direction)
+	}
+	query := `select * from (` + side("from_address") + `) sent union select * from (` + side("to_address") + `) received` +
+		fmt.Sprintf(` order by number %[1]s, "index" %[1]s limit %[2]s offset %[3]s`, direction, arg(filter.Limit), arg(filter.Offset))
+
+	return r.queryTransactions(ctx, "get_account_transactions", filter.Limit, query, args...)
+}
+
+func (r *TransactionRepository) GetTransactionsByHash(ctx context.Context, hashes []common.Hash) ([]*transaction.Transaction, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	query := transactionSelect + ` where t.hash in (` + argList(arg, hashes) + `)`
+
+	return r.queryTransactions(ctx, "get_transactions_by_hash", len(hashes), query, args...)
+}
+
+func (r *TransactionRepository) GetBlockTransactions(ctx context.Context, blockHash common.Hash, afterIndex *int, limit int) ([]*transaction.Transaction, error) {
+	after := -1
+	if afterIndex != nil {
+		after = *afterIndex
+	}
+
+	query := transactionSelect + ` where t.block_hash = $1 and t."index" > $2 order by t."index" limit $3`
+
+	return r.queryTransactions(ctx, "get_block_transactions", limit, query, blockHash, after, limit)
+}
+
+// GetBlocksTransactions selects the first limit transactions of every block at once, they are ranked within
+// their block before the page is cut
+func (r *TransactionRepository) GetBlocksTransactions(ctx context.Context, blockHashes []common.Hash, limit int) ([]*transaction.Transaction, error) {
+	if len(blockHashes) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	query := transactionSelect + ` join (select hash, row_number() over (partition by block_hash order by "index") as position
+		from "transaction" where block_hash in (` + argList(arg, blockHashes) + `)) p on p.hash = t.hash
+		where p.position <= ` + arg(limit) + ` order by t.block_hash, t."index"`
+
+	return r.queryTransactions(ctx, "get_blocks_transactions", len(blockHashes)*limit, query, args...)
+}
+
+func (r *TransactionRepository) queryTransactions(ctx context.Context, name string, limit int, query string, args ...interface{}) ([]*transaction.Transaction, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery(name, start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := make([]*transaction.Transaction, 0, limit)
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			metrics.ObserveQuery(name, start, err)
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// logSelect reads the logs with their topics, the join yields a row per topic and scanLogs folds them
+const logSelect = `select l.address, l.transaction_hash, l.block_hash, b.number, l.transaction_index, l.log_index, l.data, tp.topic
+	from transaction_log l
+	join block b on b.hash = l.block_hash
+	left join transaction_log_topic tp on tp.transaction_hash = l.transaction_hash and tp.log_index = l.log_index`
+
+func (r *TransactionRepository) GetTransactionLogs(ctx context.Context, hash common.Hash) ([]*transaction.TransactionLog, error) {
+	query := logSelect + ` where l.transaction_hash = $1 order by l.log_index, tp.topic_index`
+
+	return r.queryLogs(ctx, "get_transaction_logs", query, hash)
+}
+
+func (r *TransactionRepository) GetTransactionsLogs(ctx context.Context, hashes []common.Hash) ([]*transaction.TransactionLog, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	query := logSelect + ` where l.transaction_hash in (` + argList(arg, hashes) + `) order by l.transaction_hash, l.log_index, tp.topic_index`
+
+	return r.queryLogs(ctx, "get_transactions_logs", query, args...)
+}
+
+func (r *TransactionRepository) GetLogs(ctx context.Context, filter transaction.LogFilter) ([]*transaction.TransactionLog, error) {
+	direction, comparison := "asc", ">"
+	if filter.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(filter.Addresses) > 0 {
+		conditions = append(conditions, "l.address in ("+argList(arg, filter.Addresses)+")")
+	}
+	for position, topics := range filter.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"exists (select 1 from transaction_log_topic t where t.transaction_hash = l.transaction_hash and t.log_index = l.log_index and t.topic_index = %d and t.topic in (%s))",
+			position, argList(arg, topics)))
+	}
+	// like nodes, a log needs a topic at every position given even when the last ones are wildcards
+	if last := len(filter.Topics) - 1; last >= 0 && len(filter.Topics[last]) == 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"exists (select 1 from transaction_log_topic t where t.transaction_hash = l.transaction_hash and t.log_index = l.log_index and t.topic_index = %d)",
+			last))
+	}
+	if filter.BlockHash != nil {
+		conditions = append(conditions, "l.block_hash = "+arg(*filter.BlockHash))
+	}
+	if filter.FromBlock != nil {
+		conditions = append(conditions, "b.number >= "+arg(filter.FromBlock))
+	}
+	if filter.ToBlock != nil {
+		conditions = append(conditions, "b.number <= "+arg(filter.ToBlock))
+	}
+	if filter.After != nil {
+		number, txIndex, logIndex := arg(filter.After.BlockNumber), arg(int64(filter.After.TransactionIndex)), arg(int64(filter.After.LogIndex))
+		conditions = append(conditions, fmt.Sprintf(
+			"(b.number %[1]s %[2]s or (b.number = %[2]s and (l.transaction_index %[1]s %[3]s or (l.transaction_index = %[3]s and l.log_index %[1]s %[4]s))))",
+			comparison, number, txIndex, logIndex))
+	}
+
+	// the page is cut on logs before their topics are joined
+	page := `select l.transaction_hash, l.log_index from transaction_log l join block b on b.hash = l.block_hash`
+	if len(conditions) > 0 {
+		page += ` where ` + strings.Join(conditions, " and ")
+	}
+	page += fmt.Sprintf(` order by b.number %[1]s, l.transaction_index %[1]s, l.log_index %[1]s limit %[2]s`, direction, arg(filter.Limit))
+	if filter.Offset > 0 {
+		page += ` offset ` + arg(filter.Offset)
+	}
+
+	query := logSelect + fmt.Sprintf(` join (%[1]s) p on p.transaction_hash = l.transaction_hash and p.log_index = l.log_index
+		order by b.number %[2]s, l.transaction_index %[2]s, l.log_index %[2]s, tp.topic_index`, page, direction)
+
+	return r.queryLogs(ctx, "get_logs", query, args...)
+}
+
+// argList binds each value and returns their placeholders separated by commas
+func argList[T any](arg func(interface{}) string, values []T) string {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = arg(value)
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+func (r *TransactionRepository) queryLogs(ctx context.Context, name string, query string, args ...interface{}) ([]*transaction.TransactionLog, error) {
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery(name, start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// rows of one log are adjacent
+	var logs []*transaction.TransactionLog
+	for rows.Next() {
+		var (
+			l     transaction.TransactionLog
+			topic []byte
+		)
+		err := rows.Scan(&l.Address, &l.TransactionHash, &l.BlockHash, &l.BlockNumber, &l.TransactionIndex, &l.Index, &l.Data, &topic)
+		if err != nil {
+			metrics.ObserveQuery(name, start, err)
+			return nil, err
+		}
+
+		if len(logs) == 0 || logs[len(logs)-1].TransactionHash != l.TransactionHash || logs[len(logs)-1].Index != l.Index {
+			l.Topics = []common.Hash{}
+			logs = append(logs, &l)
+		}
+		if topic != nil {
+			last := logs[len(logs)-1]
+			last.Topics = append(last.Topics, common.BytesToHash(topic))
+		}
+	}
+	err = rows.Err()
+	metrics.ObserveQuery(name, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
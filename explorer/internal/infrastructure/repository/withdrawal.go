@@ -1,7 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/elmiringos/indexer/explorer/internal/domain/withdrawal"
+	"github.com/elmiringos/indexer/explorer/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type WithdrawalRepository struct {
@@ -11,3 +18,106 @@ type WithdrawalRepository struct {
 func NewWithdrawalRepository(db *sql.DB) *WithdrawalRepository {
 	return &WithdrawalRepository{db: db}
 }
+
+func (r *WithdrawalRepository) GetBlockWithdrawals(ctx context.Context, blockHash common.Hash) ([]*withdrawal.Withdrawal, error) {
+	query := `select "index", block_hash, address_hash, validator_index, amount from withdrawal where block_hash = $1 order by "index"`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, blockHash)
+	if err != nil {
+		metrics.ObserveQuery("get_block_withdrawals", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdrawals []*withdrawal.Withdrawal
+	for rows.Next() {
+		var w withdrawal.Withdrawal
+		if err := rows.Scan(&w.Index, &w.BlockHash, &w.AddressHash, &w.ValidatorIndex, &w.Amount); err != nil {
+			metrics.ObserveQuery("get_block_withdrawals", start, err)
+			return nil, err
+		}
+		withdrawals = append(withdrawals, &w)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery("get_block_withdrawals", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+func (r *WithdrawalRepository) GetAddressWithdrawals(ctx context.Context, address common.Address, beforeIndex *uint64, limit int) ([]*withdrawal.Withdrawal, error) {
+	query := `select "index", block_hash, address_hash, validator_index, amount from withdrawal where address_hash = $1`
+	args := []interface{}{address, limit}
+	if beforeIndex != nil {
+		query += ` and "index" < $3`
+		args = append(args, int64(*beforeIndex))
+	}
+	query += ` order by "index" desc limit $2`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery("get_address_withdrawals", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	withdrawals := make([]*withdrawal.Withdrawal, 0, limit)
+	for rows.Next() {
+		var w withdrawal.Withdrawal
+		if err := rows.Scan(&w.Index, &w.BlockHash, &w.AddressHash, &w.ValidatorIndex, &w.Amount); err != nil {
+			metrics.ObserveQuery("get_address_withdrawals", start, err)
+			return nil, err
+		}
+		withdrawals = append(withdrawals, &w)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery("get_address_withdrawals", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+func (r *WithdrawalRepository) GetBlocksWithdrawals(ctx context.Context, blockHashes []common.Hash) ([]*withdrawal.Withdrawal, error) {
+	if len(blockHashes) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	query := `select "index", block_hash, address_hash, validator_index, amount from withdrawal
+		where block_hash in (` + argList(arg, blockHashes) + `) order by block_hash, "index"`
+
+	start := time.Now()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		metrics.ObserveQuery("get_blocks_withdrawals", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdrawals []*withdrawal.Withdrawal
+	for rows.Next() {
+		var w withdrawal.Withdrawal
+		if err := rows.Scan(&w.Index, &w.BlockHash, &w.AddressHash, &w.ValidatorIndex, &w.Amount); err != nil {
+			metrics.ObserveQuery("get_blocks_withdrawals", start, err)
+			return nil, err
+		}
+		withdrawals = append(withdrawals, &w)
+	}
+	err = rows.Err()
+	metrics.ObserveQuery("get_blocks_withdrawals", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
@@ -0,0 +1,325 @@
+// Package testdb builds SQLite databases with the schema core migrates, for the explorer's tests
+package testdb
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	// Register the pure Go SQLite driver with the functions the explorer queries use
+	_ "github.com/elmiringos/indexer/explorer/pkg/sqlite"
+)
+
+// GenesisTimestamp is the timestamp of block 0, blocks follow each other every 12 seconds
+const GenesisTimestamp = 1_700_000_000
+
+// migrationsDir is core's SQLite migrations directory, found from this file so every package can run them
+func migrationsDir(t testing.TB) string {
+	t.Helper()
+
+	_, file, _, ok := runtime.Caller(0)
+	require.True(t, ok)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "core", "migrations", "sqlite")
+}
+
+// Open migrates a SQLite database in a temporary directory with core's up migrations
+func Open(t testing.TB) *sql.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)", filepath.Join(t.TempDir(), "indexer.db"))
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	dir := migrationsDir(t)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".up.sql") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		migration, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		_, err = db.Exec(string(migration))
+		require.NoError(t, err, name)
+	}
+
+	return db
+}
+
+func BlockHash(number int64) common.Hash {
+	return common.BigToHash(big.NewInt(number))
+}
+
+func TransactionHash(blockNumber int64, index int) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("transaction %d %d", blockNumber, index)))
+}
+
+func Timestamp(blockNumber int64) int64 {
+	return GenesisTimestamp + blockNumber*12
+}
+
+// Block is a block row, unset fields take zero values and the timestamp follows the number
+type Block struct {
+	Number            int64
+	Miner             common.Address
+	GasUsed           uint64
+	BaseFeePerGas     string
+	TransactionsCount int
+	Timestamp         int64
+}
+
+func InsertBlock(t testing.TB, db *sql.DB, b Block) common.Hash {
+	t.Helper()
+
+	if b.BaseFeePerGas == "" {
+		b.BaseFeePerGas = "0"
+	}
+	if b.Timestamp == 0 {
+		b.Timestamp = Timestamp(b.Number)
+	}
+
+	hash := BlockHash(b.Number)
+	_, err := db.Exec(`
+		insert into block (hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp, transactions_count, complete)
+		values ($1, $2, $3, $4, 30000000, $5, 0, 0, '0', true, $6, $7, $8, true)`,
+		hash.Bytes(), b.Number, b.Miner.Bytes(), BlockHash(b.Number-1).Bytes(), b.GasUsed, b.BaseFeePerGas, b.Timestamp, b.TransactionsCount)
+	require.NoError(t, err)
+
+	return hash
+}
+
+// InsertHeader stores a block with every field of header core keeps, under the hash of the header
+func InsertHeader(t testing.TB, db *sql.DB, header *types.Header, transactionsCount int) common.Hash {
+	t.Helper()
+
+	baseFee := "0"
+	if header.BaseFee != nil {
+		baseFee = header.BaseFee.String()
+	}
+
+	hash := header.Hash()
+	_, err := db.Exec(`
+		insert into block (hash, number, miner_hash, parent_hash, gas_limit, gas_used, nonce, size, difficulty, is_pos, base_fee_per_gas, timestamp, transactions_count, complete,
+			transactions_root, receipts_root, logs_bloom, state_root, mix_hash)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, true, $14, $15, $16, $17, $18)`,
+		hash.Bytes(), header.Number.Int64(), header.Coinbase.Bytes(), header.ParentHash.Bytes(), header.GasLimit, header.GasUsed, header.Nonce.Uint64(),
+		uint64(header.Size()), header.Difficulty.String(), header.Difficulty.Sign() == 0, baseFee, header.Time, transactionsCount,
+		header.TxHash.Bytes(), header.ReceiptHash.Bytes(), header.Bloom.Bytes(), header.Root.Bytes(), header.MixDigest.Bytes())
+	require.NoError(t, err)
+
+	return hash
+}
+
+// Transaction is a transaction row of block BlockNumber, it succeeded unless Status is set
+type Transaction struct {
+	BlockNumber int64
+	Index       int
+	From        common.Address
+	To          common.Address
+	Value       string
+	Input       []byte
+	Status      *uint64
+	GasUsed     uint64
+	Type        uint8
+	Raw         []byte
+}
+
+func InsertTransaction(t testing.TB, db *sql.DB, tx Transaction) common.Hash {
+	t.Helper()
+
+	if tx.Value == "" {
+		tx.Value = "0"
+	}
+	status := uint64(1)
+	if tx.Status != nil {
+		status = *tx.Status
+	}
+
+	hash := TransactionHash(tx.BlockNumber, tx.Index)
+	_, err := db.Exec(`
+		insert into "transaction" (hash, block_hash, "index", status, gas, gas_used, input, value, from_address, to_address, nonce, timestamp, type, raw)
+		values ($1, $2, $3, $4, '21000', $5, $6, $7, $8, $9, $3, $10, $11, $12)`,
+		hash.Bytes(), BlockHash(tx.BlockNumber).Bytes(), tx.Index, status, fmt.Sprint(tx.GasUsed), tx.Input, tx.Value,
+		tx.From.Bytes(), tx.To.Bytes(), Timestamp(tx.BlockNumber), tx.Type, tx.Raw)
+	require.NoError(t, err)
+
+	return hash
+}
+
+// Log is a log of the transaction at TransactionIndex of block BlockNumber, its topics are stored by position
+type Log struct {
+	BlockNumber      int64
+	TransactionIndex int
+	Index            int
+	Address          common.Address
+	Topics           []common.Hash
+	Data             []byte
+}
+
+func InsertLog(t testing.TB, db *sql.DB, l Log) {
+	t.Helper()
+
+	transactionHash := TransactionHash(l.BlockNumber, l.TransactionIndex)
+	_, err := db.Exec(`
+		insert into transaction_log (address, transaction_hash, block_hash, transaction_index, log_index, data)
+		values ($1, $2, $3, $4, $5, $6)`,
+		l.Address.Bytes(), transactionHash.Bytes(), BlockHash(l.BlockNumber).Bytes(), l.TransactionIndex, l.Index, l.Data)
+	require.NoError(t, err)
+
+	for position, topic := range l.Topics {
+		_, err := db.Exec(`insert into transaction_log_topic (transaction_hash, log_index, topic_index, topic) values ($1, $2, $3, $4)`,
+			transactionHash.Bytes(), l.Index, position, topic.Bytes())
+		require.NoError(t, err)
+	}
+}
+
+// Token is a token row, an ERC-20 unless Type is set
+type Token struct {
+	Address     common.Address
+	Name        string
+	Symbol      string
+	Decimals    int
+	Type        string
+	TotalSupply string
+}
+
+func InsertToken(t testing.TB, db *sql.DB, token Token) {
+	t.Helper()
+
+	if token.Type == "" {
+		token.Type = "ERC-20"
+	}
+	var totalSupply interface{}
+	if token.TotalSupply != "" {
+		totalSupply = token.TotalSupply
+	}
+
+	_, err := db.Exec(`insert into token (address_hash, symbol, name, total_supply, decimals, type) values ($1, $2, $3, $4, $5, $6)`,
+		token.Address.Bytes(), token.Symbol, token.Name, totalSupply, token.Decimals, token.Type)
+	require.NoError(t, err)
+}
+
+// TokenTransfer is a transfer emitted by the log LogIndex of the transaction at TransactionIndex of block
+// BlockNumber, TokenID is empty for ERC-20 transfers
+type TokenTransfer struct {
+	BlockNumber      int64
+	TransactionIndex int
+	LogIndex         int
+	Token            common.Address
+	From             common.Address
+	To               common.Address
+	Amount           string
+	TokenID          string
+}
+
+func InsertTokenTransfer(t testing.TB, db *sql.DB, transfer TokenTransfer) {
+	t.Helper()
+
+	var tokenID interface{}
+	if transfer.TokenID != "" {
+		tokenID = transfer.TokenID
+	}
+
+	_, err := db.Exec(`
+		insert into token_transfer (transaction_hash, log_index, from_address, to_address, token_contract_address_hash, amount, token_id)
+		values ($1, $2, $3, $4, $5, $6, $7)`,
+		TransactionHash(transfer.BlockNumber, transfer.TransactionIndex).Bytes(), transfer.LogIndex, transfer.From.Bytes(), transfer.To.Bytes(),
+		transfer.Token.Bytes(), transfer.Amount, tokenID)
+	require.NoError(t, err)
+}
+
+// TokenInstance is an ERC-721 or ERC-1155 token held by Owner
+type TokenInstance struct {
+	Token    common.Address
+	TokenID  string
+	Owner    common.Address
+	Metadata string
+}
+
+func InsertTokenInstance(t testing.TB, db *sql.DB, instance TokenInstance) {
+	t.Helper()
+
+	var metadata interface{}
+	if instance.Metadata != "" {
+		metadata = instance.Metadata
+	}
+
+	_, err := db.Exec(`insert into token_instance (token_contract_address_hash, token_id, owner_address_hash, metadata) values ($1, $2, $3, $4)`,
+		instance.Token.Bytes(), instance.TokenID, instance.Owner.Bytes(), metadata)
+	require.NoError(t, err)
+}
+
+// SmartContract is a verified contract with its ABI as JSON
+type SmartContract struct {
+	Address common.Address
+	Name    string
+	ABI     string
+}
+
+func InsertSmartContract(t testing.TB, db *sql.DB, contract SmartContract) {
+	t.Helper()
+
+	_, err := db.Exec(`insert into smart_contract (address_hash, name, compiler_version, source_code, abi) values ($1, $2, 'v0.8.24', '', $3)`,
+		contract.Address.Bytes(), contract.Name, contract.ABI)
+	require.NoError(t, err)
+}
+
+// InternalTransaction is a call of the transaction at TransactionIndex of block BlockNumber, Contract has to
+// be a verified contract since core's schema references it
+type InternalTransaction struct {
+	BlockNumber      int64
+	TransactionIndex int
+	Index            int
+	From             common.Address
+	To               common.Address
+	Contract         common.Address
+	Amount           string
+}
+
+func InsertInternalTransaction(t testing.TB, db *sql.DB, internal InternalTransaction) {
+	t.Helper()
+
+	if internal.Amount == "" {
+		internal.Amount = "0"
+	}
+
+	_, err := db.Exec(`
+		insert into internal_transaction (block_hash, "index", transaction_hash, status, gas, gas_used, amount, from_address, to_address, create_contract_address_hash, timestamp)
+		values ($1, $2, $3, 1, '0', '0', $4, $5, $6, $7, $8)`,
+		BlockHash(internal.BlockNumber).Bytes(), internal.Index, TransactionHash(internal.BlockNumber, internal.TransactionIndex).Bytes(),
+		internal.Amount, internal.From.Bytes(), internal.To.Bytes(), internal.Contract.Bytes(), Timestamp(internal.BlockNumber))
+	require.NoError(t, err)
+}
+
+func InsertWithdrawal(t testing.TB, db *sql.DB, blockNumber int64, index uint64, address common.Address, amount uint64) {
+	t.Helper()
+
+	_, err := db.Exec(`insert into withdrawal ("index", block_hash, address_hash, validator_index, amount) values ($1, $2, $3, 0, $4)`,
+		index, BlockHash(blockNumber).Bytes(), address.Bytes(), fmt.Sprint(amount))
+	require.NoError(t, err)
+}
+
+func InsertReward(t testing.TB, db *sql.DB, blockNumber int64, address common.Address, amount string) {
+	t.Helper()
+
+	_, err := db.Exec(`insert into reward (block_hash, address, amount) values ($1, $2, $3)`, BlockHash(blockNumber).Bytes(), address.Bytes(), amount)
+	require.NoError(t, err)
+}
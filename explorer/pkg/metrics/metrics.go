@@ -0,0 +1,46 @@
+// Package metrics defines the Prometheus collectors exported by the explorer
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "explorer"
+
+var (
+	// RequestDuration tracks HTTP request latency by route template, method and status code
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests, by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	// QueryDuration tracks database query latency by query name
+	QueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database queries, by query name and result.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"query", "result"})
+)
+
+// ObserveQuery records the latency and outcome of a database query started at start
+func ObserveQuery(query string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	QueryDuration.WithLabelValues(query, result).Observe(time.Since(start).Seconds())
+}
+
+// Handler returns the HTTP handler serving the metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
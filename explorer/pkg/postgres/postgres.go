@@ -44,6 +44,11 @@ func (pc *Connection) Health() bool {
 	return true
 }
 
+// Ping verifies that the database is reachable
+func (pc *Connection) Ping(ctx context.Context) error {
+	return pc.db.PingContext(ctx)
+}
+
 func (pc *Connection) GetDb() *sql.DB {
 	return pc.db
 }
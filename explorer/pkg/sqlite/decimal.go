@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+
+	driversqlite "modernc.org/sqlite"
+)
+
+// DecimalSum names the aggregate summing decimal text exactly. SQLite's sum coerces TEXT to REAL, which
+// rounds token amounts above 2^53, so core's amount columns are summed with it instead. NULL is skipped,
+// the sum of no rows is "0" and the result is decimal text without leading zeros.
+const DecimalSum = "decimal_sum"
+
+func init() {
+	driversqlite.MustRegisterFunction(DecimalSum, &driversqlite.FunctionImpl{
+		NArgs:         1,
+		Deterministic: true,
+		MakeAggregate: func(driversqlite.FunctionContext) (driversqlite.AggregateFunction, error) {
+			return &decimalSum{}, nil
+		},
+	})
+}
+
+type decimalSum struct {
+	sum big.Int
+}
+
+func (s *decimalSum) Step(_ *driversqlite.FunctionContext, args []driver.Value) error {
+	value, err := decimal(args[0])
+	if err != nil || value == nil {
+		return err
+	}
+
+	s.sum.Add(&s.sum, value)
+	return nil
+}
+
+func (s *decimalSum) WindowInverse(_ *driversqlite.FunctionContext, args []driver.Value) error {
+	value, err := decimal(args[0])
+	if err != nil || value == nil {
+		return err
+	}
+
+	s.sum.Sub(&s.sum, value)
+	return nil
+}
+
+func (s *decimalSum) WindowValue(*driversqlite.FunctionContext) (driver.Value, error) {
+	return s.sum.String(), nil
+}
+
+func (s *decimalSum) Final(*driversqlite.FunctionContext) {}
+
+// decimal reads an INTEGER or a decimal TEXT value, nil for NULL
+func decimal(value driver.Value) (*big.Int, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		return big.NewInt(v), nil
+	case string:
+		if n, ok := new(big.Int).SetString(v, 10); ok {
+			return n, nil
+		}
+	case []byte:
+		if n, ok := new(big.Int).SetString(string(v), 10); ok {
+			return n, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %v is not a decimal integer", DecimalSum, value)
+}
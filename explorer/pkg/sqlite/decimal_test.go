@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimalSum(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	for query, want := range map[string]string{
+		// 2^64 + 1 and 2^53 + 1 would be rounded by a REAL sum
+		`select decimal_sum(v) from (select '18446744073709551617' as v union all select '-18446744073709551616')`:                                       "1",
+		`select decimal_sum(v) from (select '9007199254740993' as v union all select 0)`:                                                                 "9007199254740993",
+		`select decimal_sum(v) from (select 3 as v union all select null union all select '-0' union all select '-5')`:                                   "-2",
+		`select decimal_sum(v) from (select '1' as v) where false`:                                                                                       "0",
+		`select decimal_sum(v) from (select '115792089237316195423570985008687907853269984665640564039457584007913129639935' as v union all select '1')`: "115792089237316195423570985008687907853269984665640564039457584007913129639936",
+	} {
+		var sum string
+		require.NoError(t, db.QueryRow(query).Scan(&sum), query)
+		assert.Equal(t, want, sum, query)
+	}
+
+	var sum string
+	assert.Error(t, db.QueryRow(`select decimal_sum(v) from (select '1.5' as v)`).Scan(&sum))
+}
@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/elmiringos/indexer/explorer/config"
+
+	"go.uber.org/zap"
+	// Register the pure Go SQLite driver
+	_ "modernc.org/sqlite"
+)
+
+type Connection struct {
+	db  *sql.DB
+	log *zap.Logger
+}
+
+// NewSQLiteConnection opens the file core writes to, the explorer only reads so the connection
+// refuses writes and waits instead of failing while core holds the write lock
+func NewSQLiteConnection(cfg *config.Config, log *zap.Logger) *Connection {
+	log.Debug("Open sqlite database", zap.String("path", cfg.Database.SQLitePath))
+	dsn := fmt.Sprintf("file:%s?mode=ro&_pragma=query_only(1)&_pragma=busy_timeout(5000)", cfg.Database.SQLitePath)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		log.Fatal("Error in opening db", zap.Error(err))
+	}
+
+	if err := db.Ping(); err != nil {
+		log.Fatal("db down", zap.Error(err))
+	}
+
+	return &Connection{db: db, log: log}
+}
+
+// Ping verifies that the database file can be read
+func (c *Connection) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+func (c *Connection) GetDb() *sql.DB {
+	return c.db
+}
@@ -0,0 +1,99 @@
+// Package app starts the producer, cmd/server runs it against RabbitMQ and the all-in-one binary
+// hands it an in-process publisher
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/elmiringos/indexer/producer/config"
+	"github.com/elmiringos/indexer/producer/internal/blockchain"
+	"github.com/elmiringos/indexer/producer/internal/server"
+	grpccoreclient "github.com/elmiringos/indexer/producer/pkg/grpc_core_client"
+	"github.com/elmiringos/indexer/producer/pkg/health"
+	"github.com/elmiringos/indexer/producer/pkg/rabbitmq"
+
+	"go.uber.org/zap"
+)
+
+// Options replaces the connections the producer opens from its config, nil fields are created from it
+type Options struct {
+	Publisher rabbitmq.MessagePublisher
+}
+
+// Run serves the producer HTTP and gRPC endpoints and publishes blocks until ctx is cancelled
+func Run(ctx context.Context, cfg *config.Config, log *zap.Logger, opts Options) {
+	checker := health.NewChecker(cfg.Health.CheckTimeout)
+
+	httpServer := server.NewHTTPServer(cfg, checker)
+	go func() {
+		log.Info("Starting HTTP server", zap.String("address", httpServer.Addr))
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("HTTP server failed", zap.Error(err))
+		}
+	}()
+
+	healthServer := health.NewGRPCServer()
+	go checker.WatchGRPC(ctx, healthServer, 10*time.Second)
+
+	reindexQueue := server.NewReindexQueue(cfg.Reindex.QueueSize, cfg.Reindex.MaxRange)
+
+	grpcServer := server.NewGRPCServer(healthServer, reindexQueue)
+	go func() {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPC.Port))
+		if err != nil {
+			log.Fatal("failed to listen", zap.Error(err))
+		}
+
+		log.Info("Starting gRPC server", zap.String("address", lis.Addr().String()))
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatal("gRPC server failed", zap.Error(err))
+		}
+	}()
+	defer grpcServer.GracefulStop()
+
+	startProducerService(cfg, checker, reindexQueue, opts.Publisher, log)
+
+	<-ctx.Done()
+	log.Info("Server exiting")
+}
+
+func startProducerService(cfg *config.Config, checker *health.Checker, reindexQueue *server.ReindexQueue, publisher rabbitmq.MessagePublisher, log *zap.Logger) {
+	blockchainProcessor := blockchain.NewBlockchainProcessor(cfg)
+	defer blockchainProcessor.CloseClients()
+
+	var blockSource blockchain.BlockSource = blockchainProcessor
+	if cfg.Source.Type == blockchain.SourceRPC {
+		server.RegisterHealthChecks(checker, blockchainProcessor, cfg)
+	} else {
+		fileSource, err := blockchain.NewFileBlockSource(cfg.Source.Type, cfg.Source.Path)
+		if err != nil {
+			log.Fatal("failed to open block source files", zap.String("type", cfg.Source.Type), zap.Error(err))
+		}
+		blockchainProcessor.SetReceiptSource(fileSource)
+		blockSource = fileSource
+	}
+
+	if publisher == nil {
+		rmqPublisher := rabbitmq.NewPublisher(cfg.RMQ.URL)
+		defer func() {
+			if err := rmqPublisher.CloseConnection(); err != nil {
+				log.Error("failed to close RabbitMQ connection", zap.Error(err))
+			}
+		}()
+		publisher = rmqPublisher
+	}
+
+	coreClient, err := grpccoreclient.NewCoreClient(cfg.Server.CoreServiceURL, cfg.Server.CoreAdminToken)
+	if err != nil {
+		log.Error("failed to create core client", zap.Error(err))
+	}
+	defer coreClient.Close()
+
+	server := server.NewServer(blockchainProcessor, blockSource, publisher, coreClient, reindexQueue, cfg)
+	server.StartBlockchainDataConsuming()
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -12,8 +13,12 @@ type (
 	Config struct {
 		Server  `yaml:"server"`
 		HTTP    `yaml:"http"`
+		GRPC    `yaml:"grpc"`
+		Health  `yaml:"health"`
+		Reindex `yaml:"reindex"`
 		Logger  `yaml:"logger"`
 		EthNode `yaml:"eth_node"`
+		Source  `yaml:"source"`
 		RMQ
 	}
 
@@ -23,14 +28,33 @@ type (
 		Stage            string `yaml:"stage"`
 		WorkerCount      int    `yaml:"worker_count"`
 		BlockStartNumber string `yaml:"block_start_number"`
+		ForceStartBlock  string `yaml:"force_start_block" env:"FORCE_START_BLOCK"`
 		RealTimeMode     bool   `yaml:"real_time_mode"`
 		CoreServiceURL   string `env-required:"true" env:"CORE_SERVICE_URL"`
+		CoreAdminToken   string `env:"CORE_ADMIN_TOKEN"`
+		// ResetGenerationPoll is how often core is asked for its reset generation, messages
+		// stamped with an older one are dropped by core
+		ResetGenerationPoll time.Duration `yaml:"reset_generation_poll" env:"RESET_GENERATION_POLL" env-default:"5s"`
 	}
 
 	HTTP struct {
 		Port string `env-required:"false" yaml:"port"`
 	}
 
+	GRPC struct {
+		Port string `yaml:"port" env:"GRPC_PORT" env-default:"9091"`
+	}
+
+	Health struct {
+		CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" env-default:"2s"`
+		MaxHeadAge   time.Duration `yaml:"max_head_age"  env:"HEALTH_MAX_HEAD_AGE"  env-default:"2m"`
+	}
+
+	Reindex struct {
+		QueueSize int    `yaml:"queue_size" env:"REINDEX_QUEUE_SIZE" env-default:"16"`
+		MaxRange  uint64 `yaml:"max_range"  env:"REINDEX_MAX_RANGE"  env-default:"1000"`
+	}
+
 	Logger struct {
 		File string `env-required:"false" yaml:"file" env:"LOG_FILE"`
 	}
@@ -39,12 +63,23 @@ type (
 		URL string `env-required:"true" env:"RMQ_URL"`
 	}
 
+	// Source selects where blocks come from: the node ("rpc"), a `geth export` RLP dump ("rlp")
+	// or era1 archives ("era1"). Path is a file or a directory of files for the file sources.
+	Source struct {
+		Type string `yaml:"type" env:"BLOCK_SOURCE"      env-default:"rpc"`
+		Path string `yaml:"path" env:"BLOCK_SOURCE_PATH"`
+	}
+
 	EthNode struct {
 		HttpURL string `env-required:"true" env:"ETH_HTTP_NODE_RPC"`
 		WsURL   string `env-required:"true" env:"ETH_WS_NODE_RPC"`
 		ApiKey  string `env:"ETH_RPC_KEY"`
 		Network string `yaml:"network_type"`
 		Trace   bool   `yaml:"trace_enabled"`
+		// RecordDir captures every HTTP JSON-RPC exchange as a fixture, ReplayDir answers
+		// from those fixtures without contacting the node
+		RecordDir string `yaml:"record_dir" env:"ETH_RPC_RECORD_DIR"`
+		ReplayDir string `yaml:"replay_dir" env:"ETH_RPC_REPLAY_DIR"`
 	}
 )
 
@@ -0,0 +1,283 @@
+package blockchain
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/elmiringos/indexer/producer/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"go.uber.org/zap"
+)
+
+// Block source types selectable in the config
+const (
+	SourceRPC  = "rpc"
+	SourceRLP  = "rlp"
+	SourceEra1 = "era1"
+)
+
+var (
+	ErrUnknownBlockSource = errors.New("unknown block source")
+	ErrNoChainFiles       = errors.New("no chain files found")
+)
+
+// BlockSource streams the blocks to aggregate starting from a block number
+type BlockSource interface {
+	GenerateBlocks(ctx context.Context, startNumber *big.Int) (<-chan *types.Block, error)
+}
+
+// ReceiptSource supplies receipts that came together with the blocks, so they are not fetched from the node
+type ReceiptSource interface {
+	TransactionReceipt(hash common.Hash) (*types.Receipt, bool)
+}
+
+// FileBlockSource streams blocks from exported chain files instead of a node: RLP dumps written by
+// `geth export` (optionally gzipped) or era1 archives. Era1 archives carry receipts, which are served
+// through ReceiptSource, RLP dumps do not and their receipts are still fetched from the node.
+// The channel is closed once every file has been read.
+type FileBlockSource struct {
+	format string
+	files  []string
+
+	mu       sync.Mutex
+	receipts map[common.Hash]*types.Receipt
+
+	log *zap.Logger
+}
+
+// NewFileBlockSource opens a file source of the given format, path is a single file or a directory
+// whose files are read in name order
+func NewFileBlockSource(format, path string) (*FileBlockSource, error) {
+	if format != SourceRLP && format != SourceEra1 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBlockSource, format)
+	}
+
+	files, err := listChainFiles(format, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileBlockSource{
+		format:   format,
+		files:    files,
+		receipts: make(map[common.Hash]*types.Receipt),
+		log:      logger.GetLogger(),
+	}, nil
+}
+
+func listChainFiles(format, path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if format == SourceEra1 && filepath.Ext(entry.Name()) != ".era1" {
+			continue
+		}
+		files = append(files, filepath.Join(path, entry.Name()))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoChainFiles, path)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// GenerateBlocks sends the blocks at or above startNumber from every file in order
+func (s *FileBlockSource) GenerateBlocks(ctx context.Context, startNumber *big.Int) (<-chan *types.Block, error) {
+	blocks := make(chan *types.Block, 100)
+
+	go func() {
+		defer close(blocks)
+
+		for _, file := range s.files {
+			s.log.Info("Reading chain file", zap.String("file", file), zap.String("format", s.format))
+
+			err := s.readFile(ctx, file, startNumber, blocks)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					s.log.Error("Failed to read chain file", zap.String("file", file), zap.Error(err))
+				}
+				return
+			}
+		}
+
+		s.log.Info("All chain files read")
+	}()
+
+	return blocks, nil
+}
+
+func (s *FileBlockSource) readFile(ctx context.Context, file string, startNumber *big.Int, blocks chan<- *types.Block) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	send := func(block *types.Block) error {
+		select {
+		case blocks <- block:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if s.format == SourceRLP {
+		stream := rlp.NewStream(r, 0)
+		for {
+			var block types.Block
+			if err := stream.Decode(&block); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+			if block.Number().Cmp(startNumber) < 0 {
+				continue
+			}
+			if err := send(&block); err != nil {
+				return err
+			}
+		}
+	}
+
+	era, err := newEra1Reader(r)
+	if err != nil {
+		return err
+	}
+	for {
+		block, receipts, err := era.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if block.Number().Cmp(startNumber) < 0 {
+			continue
+		}
+		if err := s.storeReceipts(block, receipts); err != nil {
+			return err
+		}
+		if err := send(block); err != nil {
+			return err
+		}
+	}
+}
+
+// storeReceipts derives the non consensus fields of the receipts and keeps them until they are looked up
+func (s *FileBlockSource) storeReceipts(block *types.Block, receipts types.Receipts) error {
+	transactions := block.Transactions()
+	if len(transactions) != len(receipts) {
+		return fmt.Errorf("block %d has %d transactions but %d receipts", block.NumberU64(), len(transactions), len(receipts))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var logIndex uint
+	for i, receipt := range receipts {
+		transaction := transactions[i]
+
+		receipt.Type = transaction.Type()
+		receipt.TxHash = transaction.Hash()
+		receipt.BlockHash = block.Hash()
+		receipt.BlockNumber = block.Number()
+		receipt.TransactionIndex = uint(i)
+		receipt.EffectiveGasPrice = effectiveGasPrice(transaction, block.BaseFee())
+		receipt.GasUsed = receipt.CumulativeGasUsed
+		if i > 0 {
+			receipt.GasUsed -= receipts[i-1].CumulativeGasUsed
+		}
+
+		// the archive keeps the consensus fields only, the created address follows from the sender and its nonce
+		if transaction.To() == nil {
+			sender, err := types.Sender(types.LatestSignerForChainID(transaction.ChainId()), transaction)
+			if err != nil {
+				return fmt.Errorf("block %d transaction %s: %w", block.NumberU64(), transaction.Hash(), err)
+			}
+			receipt.ContractAddress = crypto.CreateAddress(sender, transaction.Nonce())
+		}
+
+		for _, txLog := range receipt.Logs {
+			txLog.TxHash = receipt.TxHash
+			txLog.BlockHash = receipt.BlockHash
+			txLog.BlockNumber = block.NumberU64()
+			txLog.TxIndex = receipt.TransactionIndex
+			txLog.Index = logIndex
+			logIndex++
+		}
+
+		s.receipts[receipt.TxHash] = receipt
+	}
+
+	return nil
+}
+
+// effectiveGasPrice is the price per gas the sender paid: the gas price before London, afterwards
+// the base fee plus the tip, capped at the fee cap
+func effectiveGasPrice(transaction *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return new(big.Int).Set(transaction.GasPrice())
+	}
+
+	price := new(big.Int).Add(transaction.GasTipCap(), baseFee)
+	if price.Cmp(transaction.GasFeeCap()) > 0 {
+		price.Set(transaction.GasFeeCap())
+	}
+
+	return price
+}
+
+// TransactionReceipt returns the receipt read with the transaction's block, each receipt is handed out once
+// so memory stays bounded by the blocks in flight
+func (s *FileBlockSource) TransactionReceipt(hash common.Hash) (*types.Receipt, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	receipt, ok := s.receipts[hash]
+	if ok {
+		delete(s.receipts, hash)
+	}
+
+	return receipt, ok
+}
@@ -0,0 +1,238 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testChain builds blocks numbered 1..count with a single signed transfer and a receipt carrying one log
+func testChain(t *testing.T, count int) ([]*types.Block, []types.Receipts) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+	)
+	for i := 1; i <= count; i++ {
+		to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(10),
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(int64(i)),
+		})
+		require.NoError(t, err)
+
+		receipt := &types.Receipt{
+			Type:              tx.Type(),
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			Logs: []*types.Log{{
+				Address: to,
+				Topics:  []common.Hash{common.HexToHash("0x01")},
+				Data:    []byte{byte(i)},
+			}},
+		}
+		receipt.Bloom = types.CreateBloom(receipt)
+
+		header := &types.Header{
+			Number:     big.NewInt(int64(i)),
+			Difficulty: big.NewInt(1),
+			GasLimit:   30_000_000,
+			GasUsed:    21000,
+			BaseFee:    big.NewInt(1),
+		}
+		blocks = append(blocks, types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: types.Transactions{tx}}))
+		receipts = append(receipts, types.Receipts{receipt})
+	}
+
+	return blocks, receipts
+}
+
+func writeE2storeEntry(t *testing.T, buf *bytes.Buffer, typ uint16, value []byte) {
+	header := make([]byte, e2storeHeaderSize)
+	binary.LittleEndian.PutUint16(header[0:2], typ)
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(value)))
+	buf.Write(header)
+	buf.Write(value)
+}
+
+func writeSnappyEntry(t *testing.T, buf *bytes.Buffer, typ uint16, value interface{}) {
+	data, err := rlp.EncodeToBytes(value)
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	w := snappy.NewBufferedWriter(&compressed)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	writeE2storeEntry(t, buf, typ, compressed.Bytes())
+}
+
+func collectBlocks(t *testing.T, source *FileBlockSource, start int64) []*types.Block {
+	blocks, err := source.GenerateBlocks(context.Background(), big.NewInt(start))
+	require.NoError(t, err)
+
+	var result []*types.Block
+	for block := range blocks {
+		result = append(result, block)
+	}
+	return result
+}
+
+func TestFileBlockSource_RLP(t *testing.T) {
+	blocks, _ := testChain(t, 3)
+
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		require.NoError(t, block.EncodeRLP(&buf))
+	}
+	path := filepath.Join(t.TempDir(), "chain.rlp")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	source, err := NewFileBlockSource(SourceRLP, path)
+	require.NoError(t, err)
+	source.log = zap.NewNop()
+
+	result := collectBlocks(t, source, 2)
+	require.Len(t, result, 2)
+	assert.Equal(t, blocks[1].Hash(), result[0].Hash())
+	assert.Equal(t, blocks[2].Hash(), result[1].Hash())
+
+	// RLP dumps carry no receipts
+	_, ok := source.TransactionReceipt(blocks[1].Transactions()[0].Hash())
+	assert.False(t, ok)
+}
+
+func TestFileBlockSource_Era1(t *testing.T) {
+	blocks, receipts := testChain(t, 2)
+
+	var buf bytes.Buffer
+	writeE2storeEntry(t, &buf, era1TypeVersion, nil)
+	for i, block := range blocks {
+		writeSnappyEntry(t, &buf, era1TypeCompressedHeader, block.Header())
+		writeSnappyEntry(t, &buf, era1TypeCompressedBody, block.Body())
+		writeSnappyEntry(t, &buf, era1TypeCompressedReceipts, receipts[i])
+		writeE2storeEntry(t, &buf, era1TypeTotalDifficulty, make([]byte, 32))
+	}
+	writeE2storeEntry(t, &buf, era1TypeAccumulator, make([]byte, 32))
+	writeE2storeEntry(t, &buf, era1TypeBlockIndex, make([]byte, 32))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test-00000-00000000.era1"), buf.Bytes(), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte("ignored"), 0o600))
+
+	source, err := NewFileBlockSource(SourceEra1, dir)
+	require.NoError(t, err)
+	source.log = zap.NewNop()
+
+	result := collectBlocks(t, source, 0)
+	require.Len(t, result, 2)
+
+	for i, block := range result {
+		assert.Equal(t, blocks[i].Hash(), block.Hash())
+
+		tx := block.Transactions()[0]
+		receipt, ok := source.TransactionReceipt(tx.Hash())
+		require.True(t, ok)
+		assert.Equal(t, tx.Hash(), receipt.TxHash)
+		assert.Equal(t, block.Hash(), receipt.BlockHash)
+		assert.Equal(t, uint64(21000), receipt.GasUsed)
+		require.Len(t, receipt.Logs, 1)
+		assert.Equal(t, tx.Hash(), receipt.Logs[0].TxHash)
+		assert.Equal(t, []byte{byte(i + 1)}, receipt.Logs[0].Data)
+
+		// receipts are handed out once
+		_, ok = source.TransactionReceipt(tx.Hash())
+		assert.False(t, ok)
+	}
+}
+
+// TestFileBlockSource_Era1Sepolia reads Sepolia blocks 175880-175882 and 175990, their entries are copied
+// verbatim from the sepolia-00021 era1 archive. They hold EIP-1559 and legacy contract creations.
+func TestFileBlockSource_Era1Sepolia(t *testing.T) {
+	source, err := NewFileBlockSource(SourceEra1, "testdata/sepolia-00021-cut.era1")
+	require.NoError(t, err)
+	source.log = zap.NewNop()
+
+	result := collectBlocks(t, source, 0)
+	hashes := make([]common.Hash, len(result))
+	for i, block := range result {
+		hashes[i] = block.Hash()
+	}
+	assert.Equal(t, []common.Hash{
+		common.HexToHash("0x8b699bb417a17d96550319721e7baf1da8a995d6c1515484017435a827626389"),
+		common.HexToHash("0x39723cd3caf2b11067d5a95564c802ed6504bb48ed3e70bb7ebff341d181ca13"),
+		common.HexToHash("0xb2379621ce12022352c4c3a585094505c804d0cdc27ad531be75bc8f83bebc5a"),
+		common.HexToHash("0xddd58b6941a1b5e7fab2b2f9fe0d5daa60cc81acf05212ba8ff8f6e449e0c2fd"),
+	}, hashes)
+
+	for _, block := range result {
+		var receipts types.Receipts
+		for _, tx := range block.Transactions() {
+			receipt, ok := source.TransactionReceipt(tx.Hash())
+			require.True(t, ok)
+			receipts = append(receipts, receipt)
+		}
+		require.Equal(t, block.ReceiptHash(), types.DeriveSha(receipts, trie.NewStackTrie(nil)))
+
+		// go-ethereum derives the same fields from the chain config
+		expected := make(types.Receipts, len(receipts))
+		for i, receipt := range receipts {
+			expected[i] = &types.Receipt{Type: receipt.Type, CumulativeGasUsed: receipt.CumulativeGasUsed, Logs: []*types.Log{}}
+		}
+		require.NoError(t, expected.DeriveFields(params.SepoliaChainConfig, block.Hash(), block.NumberU64(), block.Time(), block.BaseFee(), nil, block.Transactions()))
+
+		for i, receipt := range receipts {
+			assert.NotEqual(t, common.Address{}, receipt.ContractAddress, receipt.TxHash.Hex())
+			assert.Equal(t, expected[i].ContractAddress, receipt.ContractAddress, receipt.TxHash.Hex())
+			assert.Equal(t, expected[i].EffectiveGasPrice, receipt.EffectiveGasPrice, receipt.TxHash.Hex())
+			assert.Equal(t, expected[i].GasUsed, receipt.GasUsed, receipt.TxHash.Hex())
+		}
+	}
+
+	// base fee 7 plus the 4096 tip stays below the 4351 fee cap, legacy transactions pay their gas price
+	assert.Equal(t, big.NewInt(4103), effectiveGasPrice(result[0].Transactions()[0], result[0].BaseFee()))
+	assert.Equal(t, big.NewInt(4096), effectiveGasPrice(result[3].Transactions()[0], result[3].BaseFee()))
+}
+
+func TestEffectiveGasPrice(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(20)})
+
+	assert.Equal(t, big.NewInt(15), effectiveGasPrice(tx, big.NewInt(10)))
+	assert.Equal(t, big.NewInt(20), effectiveGasPrice(tx, big.NewInt(18)), "capped at the fee cap")
+
+	legacy := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(7)})
+	assert.Equal(t, big.NewInt(7), effectiveGasPrice(legacy, nil))
+	assert.Equal(t, big.NewInt(7), effectiveGasPrice(legacy, big.NewInt(3)))
+}
+
+func TestNewFileBlockSource_Errors(t *testing.T) {
+	_, err := NewFileBlockSource("ipfs", t.TempDir())
+	assert.ErrorIs(t, err, ErrUnknownBlockSource)
+
+	_, err = NewFileBlockSource(SourceEra1, t.TempDir())
+	assert.ErrorIs(t, err, ErrNoChainFiles)
+}
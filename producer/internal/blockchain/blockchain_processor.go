@@ -5,14 +5,18 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/elmiringos/indexer/producer/config"
 	"github.com/elmiringos/indexer/producer/pkg/logger"
+	"github.com/elmiringos/indexer/producer/pkg/metrics"
+	"github.com/elmiringos/indexer/producer/pkg/rpcreplay"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -23,11 +27,16 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	ErrNoSubscriptionClient = errors.New("no websocket client to subscribe to new blocks, RPC replay serves HTTP requests only")
+)
+
 type BlockchainProcessor struct {
 	ethHttpClient *ethclient.Client
 	rawHttpClient *rpc.Client
 	ethWSClient   *ethclient.Client
 	rawWSClient   *rpc.Client
+	receipts      ReceiptSource
 	log           *zap.Logger
 }
 
@@ -62,42 +71,71 @@ func basicAuth(username, password string) string {
 
 // NewBlockchainProcessor initializes BlockchainProcessor with HTTP and WebSocket clients
 func NewBlockchainProcessor(cfg *config.Config) *BlockchainProcessor {
-	// Create both HTTP and WebSocket clients
-	httpClient, err := createRPCClient(cfg.EthNode.HttpURL, cfg.EthNode.ApiKey)
+	transport, err := newRPCTransport(cfg)
 	if err != nil {
-		panic(fmt.Errorf("failed to create HTTP client: %v", err))
+		panic(fmt.Errorf("failed to create RPC transport: %v", err))
 	}
 
-	wsClient, err := createRPCClient(cfg.EthNode.WsURL, cfg.EthNode.ApiKey)
+	// Create both HTTP and WebSocket clients
+	httpClient, err := createRPCClient(cfg.EthNode.HttpURL, cfg.EthNode.ApiKey, transport)
 	if err != nil {
-		panic(fmt.Errorf("failed to create WebSocket client: %v", err))
+		panic(fmt.Errorf("failed to create HTTP client: %v", err))
 	}
 
 	// Initialize the BlockchainProcessor struct
 	blockchainProcessor := &BlockchainProcessor{
 		rawHttpClient: httpClient,
 		ethHttpClient: ethclient.NewClient(httpClient),
-		rawWSClient:   wsClient,
-		ethWSClient:   ethclient.NewClient(wsClient),
 		log:           logger.GetLogger(),
 	}
 
+	// Blocks read from files or replayed need no subscription, the HTTP client only dials when it is used
+	if cfg.Source.Type == SourceRPC && cfg.EthNode.ReplayDir == "" {
+		wsClient, err := createRPCClient(cfg.EthNode.WsURL, cfg.EthNode.ApiKey, nil)
+		if err != nil {
+			panic(fmt.Errorf("failed to create WebSocket client: %v", err))
+		}
+
+		blockchainProcessor.rawWSClient = wsClient
+		blockchainProcessor.ethWSClient = ethclient.NewClient(wsClient)
+	}
+
 	return blockchainProcessor
 }
 
-// createRPCClient creates an RPC client with authentication
-func createRPCClient(url, apiKey string) (*rpc.Client, error) {
-	client, err := rpc.DialOptions(
-		context.Background(),
-		url,
+// newRPCTransport returns the recording or replaying transport selected in the config, nil for the default one
+func newRPCTransport(cfg *config.Config) (http.RoundTripper, error) {
+	switch {
+	case cfg.EthNode.ReplayDir != "":
+		return rpcreplay.NewReplayTransport(cfg.EthNode.ReplayDir)
+	case cfg.EthNode.RecordDir != "":
+		return rpcreplay.NewRecordingTransport(cfg.EthNode.RecordDir, nil)
+	default:
+		return nil, nil
+	}
+}
+
+// createRPCClient creates an RPC client with authentication, HTTP requests go through transport when it is set
+func createRPCClient(url, apiKey string, transport http.RoundTripper) (*rpc.Client, error) {
+	options := []rpc.ClientOption{
 		rpc.WithHeader("Authorization", "Basic "+basicAuth("", apiKey)),
-	)
+	}
+	if transport != nil {
+		options = append(options, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+
+	client, err := rpc.DialOptions(context.Background(), url, options...)
 	if err != nil {
 		return nil, fmt.Errorf("error creating RPC client for URL %s: %v", url, err)
 	}
 	return client, nil
 }
 
+// SetReceiptSource makes receipt lookups consult source before the node
+func (p *BlockchainProcessor) SetReceiptSource(source ReceiptSource) {
+	p.receipts = source
+}
+
 // CloseClients closes both HTTP and WebSocket clients gracefully
 func (p *BlockchainProcessor) CloseClients() {
 	// Gracefully close HTTP and WebSocket clients
@@ -118,15 +156,47 @@ func (p *BlockchainProcessor) CloseClients() {
 	}
 }
 
+// Ping checks that the node answers JSON-RPC requests
+func (p *BlockchainProcessor) Ping(ctx context.Context) error {
+	start := time.Now()
+	_, err := p.ethHttpClient.BlockNumber(ctx)
+	metrics.ObserveRPC("eth_blockNumber", start, err)
+
+	return err
+}
+
+// LatestHeader returns the header of the latest block known to the node
+func (p *BlockchainProcessor) LatestHeader(ctx context.Context) (*types.Header, error) {
+	start := time.Now()
+	header, err := p.ethHttpClient.HeaderByNumber(ctx, nil)
+	metrics.ObserveRPC("eth_getBlockByNumber", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.ObserveChainHead(header.Number.Uint64())
+
+	return header, nil
+}
+
 // GetBlockByNumber gets a block by number
 func (p *BlockchainProcessor) GetBlockByNumber(ctx context.Context, blockNumber *big.Int) (*types.Block, error) {
-	block, err := p.ethHttpClient.BlockByNumber(ctx, blockNumber)
+	block, err := p.blockByNumber(ctx, blockNumber)
 	if err != nil {
 		return nil, fmt.Errorf("error in aggragating block %v: %v", blockNumber, err)
 	}
 	return block, nil
 }
 
+// blockByNumber fetches a block over HTTP and records the call in the RPC metrics
+func (p *BlockchainProcessor) blockByNumber(ctx context.Context, blockNumber *big.Int) (*types.Block, error) {
+	start := time.Now()
+	block, err := p.ethHttpClient.BlockByNumber(ctx, blockNumber)
+	metrics.ObserveRPC("eth_getBlockByNumber", start, err)
+
+	return block, err
+}
+
 func (p *BlockchainProcessor) GenerateHistoricalBlocks(ctx context.Context, configBlockNumber *big.Int, blocks chan<- *types.Block, latestBlock <-chan *types.Block) error {
 	select {
 	case latestBlockNumber := <-latestBlock:
@@ -135,7 +205,7 @@ func (p *BlockchainProcessor) GenerateHistoricalBlocks(ctx context.Context, conf
 			case <-ctx.Done():
 				return ctx.Err()
 			default:
-				block, err := p.ethHttpClient.BlockByNumber(ctx, configBlockNumber)
+				block, err := p.blockByNumber(ctx, configBlockNumber)
 				if err != nil {
 					p.log.Error("Failed to get historical block",
 						zap.Any("number", configBlockNumber),
@@ -160,6 +230,10 @@ func (p *BlockchainProcessor) GenerateHistoricalBlocks(ctx context.Context, conf
 }
 
 func (p *BlockchainProcessor) ListenNewBlocks(ctx context.Context, blocks chan<- *types.Block, latestBlock chan<- *types.Block) error {
+	if p.ethWSClient == nil {
+		return ErrNoSubscriptionClient
+	}
+
 	headers := make(chan *types.Header)
 	sentFirstBlock := false
 
@@ -180,7 +254,9 @@ func (p *BlockchainProcessor) ListenNewBlocks(ctx context.Context, blocks chan<-
 				return fmt.Errorf("headers channel closed unexpectedly")
 			}
 
-			block, err := p.ethHttpClient.BlockByNumber(ctx, header.Number)
+			metrics.ObserveChainHead(header.Number.Uint64())
+
+			block, err := p.blockByNumber(ctx, header.Number)
 			if err != nil {
 				p.log.Error("Failed to get block by hash",
 					zap.Error(err),
@@ -262,7 +338,9 @@ func (p *BlockchainProcessor) GenerateBlocks(ctx context.Context, configBlockNum
 // GetBlockTraces returns the traces of a block
 func (p *BlockchainProcessor) GetBlockTraces(blockNumber *big.Int) ([]map[string]interface{}, error) {
 	var blockTrace []map[string]interface{}
+	start := time.Now()
 	err := p.rawHttpClient.Call(&blockTrace, "trace_block", blockNumber.Uint64())
+	metrics.ObserveRPC("trace_block", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("error in fetching block traces: %v", err)
 	}
@@ -323,7 +401,7 @@ func (p *BlockchainProcessor) GetTokenEvents(receipt *types.Receipt, transaction
 				// Get Token Metadata for ERC-20 token
 				metadata := p.getERC20Metadata(log.Address)
 
-				event.Address = receipt.ContractAddress
+				event.Address = log.Address
 				event.TransactionHash = transactionHash
 				event.LogIndex = log.Index
 				event.From = from
@@ -343,7 +421,7 @@ func (p *BlockchainProcessor) GetTokenEvents(receipt *types.Receipt, transaction
 				// Get Token Metadata for ERC-721 token
 				metadata := p.getERC721Metadata(log.Address, tokenId)
 
-				event.Address = receipt.ContractAddress
+				event.Address = log.Address
 				event.TransactionHash = transactionHash
 				event.LogIndex = log.Index
 				event.TokenId = BigInt(*tokenId)
@@ -364,7 +442,7 @@ func (p *BlockchainProcessor) GetTokenEvents(receipt *types.Receipt, transaction
 				// Get Token Metadata for ERC-1155 token
 				metadata := p.getERC1155Metadata(log.Address, tokenId)
 
-				event.Address = receipt.ContractAddress
+				event.Address = log.Address
 				event.TransactionHash = transactionHash
 				event.LogIndex = log.Index
 				event.TokenId = BigInt(*tokenId)
@@ -420,7 +498,9 @@ func (p *BlockchainProcessor) GetTokenEvents(receipt *types.Receipt, transaction
 // getContractBytecode fetches the bytecode of a contract at a given address
 func (p *BlockchainProcessor) getContractBytecode(address common.Address) (string, error) {
 	// Call the eth_getCode method to get the bytecode from the Ethereum client
+	start := time.Now()
 	result, err := p.ethHttpClient.CodeAt(context.Background(), address, nil)
+	metrics.ObserveRPC("eth_getCode", start, err)
 	if err != nil {
 		return "", err
 	}
@@ -458,7 +538,7 @@ func (p *BlockchainProcessor) getERC20Metadata(tokenAddress common.Address) Toke
 			Data: append(method.ID, inputData...),
 		}
 
-		return p.ethHttpClient.CallContract(ctx, msg, nil)
+		return p.callContract(ctx, msg)
 	}
 
 	// Name
@@ -522,7 +602,7 @@ func (p *BlockchainProcessor) getERC721Metadata(tokenAddress common.Address, tok
 			Data: append(method.ID, data...),
 		}
 
-		return p.ethHttpClient.CallContract(ctx, msg, nil)
+		return p.callContract(ctx, msg)
 	}
 
 	// Fetch Name
@@ -586,7 +666,7 @@ func (p *BlockchainProcessor) getERC1155Metadata(tokenAddress common.Address, to
 			Data: append(method.ID, data...),
 		}
 
-		return p.ethHttpClient.CallContract(ctx, msg, nil)
+		return p.callContract(ctx, msg)
 	}
 
 	// Fetch Token URI
@@ -602,9 +682,20 @@ func (p *BlockchainProcessor) getERC1155Metadata(tokenAddress common.Address, to
 	return metadata
 }
 
+// callContract executes eth_call against the latest state and records the call in the RPC metrics
+func (p *BlockchainProcessor) callContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	start := time.Now()
+	result, err := p.ethHttpClient.CallContract(ctx, msg, nil)
+	metrics.ObserveRPC("eth_call", start, err)
+
+	return result, err
+}
+
 // GetTransaction returns a transaction by hash
 func (p *BlockchainProcessor) GetTransaction(txHash string) (*types.Transaction, error) {
+	start := time.Now()
 	tx, _, err := p.ethHttpClient.TransactionByHash(context.Background(), common.HexToHash(txHash))
+	metrics.ObserveRPC("eth_getTransactionByHash", start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -614,7 +705,15 @@ func (p *BlockchainProcessor) GetTransaction(txHash string) (*types.Transaction,
 
 // GetTransactionReceipt returns the receipt of a transaction
 func (p *BlockchainProcessor) GetTransactionReceipt(tx *types.Transaction) (*types.Receipt, error) {
+	if p.receipts != nil {
+		if receipt, ok := p.receipts.TransactionReceipt(tx.Hash()); ok {
+			return receipt, nil
+		}
+	}
+
+	start := time.Now()
 	receipt, err := p.ethHttpClient.TransactionReceipt(context.Background(), tx.Hash())
+	metrics.ObserveRPC("eth_getTransactionReceipt", start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -625,7 +724,9 @@ func (p *BlockchainProcessor) GetTransactionReceipt(tx *types.Transaction) (*typ
 // GetTransactionTrace returns the trace of a transaction (only for full node)
 func (p *BlockchainProcessor) GetTransactionTrace(tx *types.Transaction) ([]map[string]interface{}, error) {
 	var trace []map[string]interface{}
+	start := time.Now()
 	err := p.rawHttpClient.Call(&trace, "trace_transaction", tx.Hash().String())
+	metrics.ObserveRPC("trace_transaction", start, err)
 	if err != nil {
 		return nil, err
 	}
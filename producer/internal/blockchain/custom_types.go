@@ -24,11 +24,21 @@ type Block struct {
 	TransactionsCount int            `json:"transactions_count"`
 	WithdrawalsCount  int            `json:"withdrawals_count"`
 	Timestamp         uint64         `json:"timestamp"`
+	// Header commitments core verifies the stored transactions and logs against
+	TransactionsRoot common.Hash `json:"transactions_root"`
+	ReceiptsRoot     common.Hash `json:"receipts_root"`
+	LogsBloom        []byte      `json:"logs_bloom"`
+	// The rest of the header the explorer serves like a node does
+	StateRoot common.Hash `json:"state_root"`
+	MixHash   common.Hash `json:"mix_hash"`
+	// Reindexed marks a block published again on request, core restarts its counters from the full counts
+	Reindexed bool `json:"reindexed,omitempty"`
 }
 
 // ConvertBlockToBlock converts a types.Block to a custom type Block
 func ConvertBlockToBlock(block *types.Block) *Block {
-	isPoS := block.Difficulty().Cmp(big.NewInt(0)) == 0 && block.Nonce() == 0 && len(block.Extra()) >= 32
+	// the merge zeroes the difficulty and nonce of every block, the extra data is free for the builder
+	isPoS := block.Difficulty().Sign() == 0 && block.Nonce() == 0
 
 	var baseFee *big.Int
 	if block.Header().BaseFee != nil {
@@ -52,6 +62,11 @@ func ConvertBlockToBlock(block *types.Block) *Block {
 		TransactionsCount: block.Transactions().Len(),
 		WithdrawalsCount:  block.Withdrawals().Len(),
 		Timestamp:         block.Header().Time,
+		TransactionsRoot:  block.TxHash(),
+		ReceiptsRoot:      block.ReceiptHash(),
+		LogsBloom:         block.Bloom().Bytes(),
+		StateRoot:         block.Root(),
+		MixHash:           block.MixDigest(),
 	}
 }
 
@@ -70,6 +85,13 @@ type Transaction struct {
 	Nonce     uint64         `json:"nonce"`
 	Timestamp int64          `json:"timestamp"`
 	LogsCount int            `json:"logs_count"`
+	// Consensus encoding and receipt fields needed to recompute the block roots
+	Type              uint8  `json:"type"`
+	Raw               []byte `json:"raw"`
+	CumulativeGasUsed uint64 `json:"cumulative_gas_used"`
+	PostState         []byte `json:"post_state"`
+	// Reindexed is copied from the block the transaction was published with
+	Reindexed bool `json:"reindexed,omitempty"`
 }
 
 // ConvertTransactionToTransaction converts a types.Transaction to a custom type Transaction
@@ -84,6 +106,11 @@ func (p *BlockchainProcessor) ConvertTransactionToTransaction(
 		return nil, err
 	}
 
+	raw, err := transaction.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
 	transactionMessage := &Transaction{
 		Hash:      transaction.Hash(),
 		BlockHash: blockHash,
@@ -97,6 +124,11 @@ func (p *BlockchainProcessor) ConvertTransactionToTransaction(
 		Nonce:     transaction.Nonce(),
 		Timestamp: transaction.Time().Unix(),
 		LogsCount: len(receipt.Logs),
+
+		Type:              transaction.Type(),
+		Raw:               raw,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		PostState:         receipt.PostState,
 	}
 
 	toAddress := transaction.To()
@@ -0,0 +1,39 @@
+package blockchain_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/elmiringos/indexer/producer/internal/blockchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertBlockToBlock_Header(t *testing.T) {
+	var bloom types.Bloom
+	bloom[255] = 1
+	header := &types.Header{
+		ParentHash:  common.HexToHash("0x01"),
+		Coinbase:    common.HexToAddress("0x02"),
+		Root:        common.HexToHash("0x03"),
+		TxHash:      types.EmptyTxsHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Bloom:       bloom,
+		Difficulty:  big.NewInt(0),
+		Number:      big.NewInt(20000000),
+		GasLimit:    30000000,
+		Time:        1700000000,
+		MixDigest:   common.HexToHash("0x04"),
+		BaseFee:     big.NewInt(7),
+	}
+	block := blockchain.ConvertBlockToBlock(types.NewBlockWithHeader(header))
+
+	assert.Equal(t, header.Hash(), block.Hash)
+	assert.Equal(t, header.Root, block.StateRoot)
+	assert.Equal(t, header.MixDigest, block.MixHash)
+	assert.Equal(t, types.EmptyTxsHash, block.TransactionsRoot)
+	assert.Equal(t, types.EmptyReceiptsHash, block.ReceiptsRoot)
+	assert.Equal(t, bloom.Bytes(), block.LogsBloom)
+	assert.True(t, block.IsPos)
+}
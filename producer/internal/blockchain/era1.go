@@ -0,0 +1,142 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+// e2store entry types used by era1 archives
+const (
+	era1TypeVersion            uint16 = 0x3265
+	era1TypeCompressedHeader   uint16 = 0x03
+	era1TypeCompressedBody     uint16 = 0x04
+	era1TypeCompressedReceipts uint16 = 0x05
+	era1TypeTotalDifficulty    uint16 = 0x06
+	era1TypeAccumulator        uint16 = 0x07
+	era1TypeBlockIndex         uint16 = 0x3266
+
+	e2storeHeaderSize = 8
+)
+
+var (
+	ErrInvalidEra1File = errors.New("invalid era1 file")
+)
+
+// e2storeEntry is a single type-length-value record of an e2store file
+type e2storeEntry struct {
+	Type  uint16
+	Value []byte
+}
+
+// readE2storeEntry reads the next record, it returns io.EOF at the end of the file
+func readE2storeEntry(r io.Reader) (*e2storeEntry, error) {
+	header := make([]byte, e2storeHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: truncated entry header", ErrInvalidEra1File)
+		}
+		return nil, err
+	}
+
+	if binary.LittleEndian.Uint16(header[6:]) != 0 {
+		return nil, fmt.Errorf("%w: reserved bytes are not zero", ErrInvalidEra1File)
+	}
+
+	entry := &e2storeEntry{
+		Type:  binary.LittleEndian.Uint16(header[0:2]),
+		Value: make([]byte, binary.LittleEndian.Uint32(header[2:6])),
+	}
+	if _, err := io.ReadFull(r, entry.Value); err != nil {
+		return nil, fmt.Errorf("%w: truncated entry value: %w", ErrInvalidEra1File, err)
+	}
+
+	return entry, nil
+}
+
+// era1Reader walks an era1 archive sequentially, every block is stored as a compressed header,
+// body and receipts entry followed by its total difficulty
+type era1Reader struct {
+	r io.Reader
+}
+
+func newEra1Reader(r io.Reader) (*era1Reader, error) {
+	entry, err := readE2storeEntry(r)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Type != era1TypeVersion {
+		return nil, fmt.Errorf("%w: missing version entry", ErrInvalidEra1File)
+	}
+
+	return &era1Reader{r: r}, nil
+}
+
+// Next returns the next block with its receipts, it returns io.EOF after the last block
+func (e *era1Reader) Next() (*types.Block, types.Receipts, error) {
+	var header, body, receipts []byte
+
+	for {
+		entry, err := readE2storeEntry(e.r)
+		if err != nil {
+			if errors.Is(err, io.EOF) && header != nil {
+				return nil, nil, fmt.Errorf("%w: incomplete block at end of file", ErrInvalidEra1File)
+			}
+			return nil, nil, err
+		}
+
+		switch entry.Type {
+		case era1TypeCompressedHeader:
+			header, err = decompressSnappy(entry.Value)
+		case era1TypeCompressedBody:
+			body, err = decompressSnappy(entry.Value)
+		case era1TypeCompressedReceipts:
+			receipts, err = decompressSnappy(entry.Value)
+		case era1TypeTotalDifficulty:
+			// the difficulty closes a block record
+			if header == nil || body == nil || receipts == nil {
+				return nil, nil, fmt.Errorf("%w: block record is missing entries", ErrInvalidEra1File)
+			}
+			return decodeEra1Block(header, body, receipts)
+		case era1TypeAccumulator, era1TypeBlockIndex:
+			// trailing entries after the last block
+			if header != nil {
+				return nil, nil, fmt.Errorf("%w: incomplete block before index", ErrInvalidEra1File)
+			}
+			return nil, nil, io.EOF
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidEra1File, err)
+		}
+	}
+}
+
+func decodeEra1Block(headerRLP, bodyRLP, receiptsRLP []byte) (*types.Block, types.Receipts, error) {
+	var (
+		header   types.Header
+		body     types.Body
+		receipts types.Receipts
+	)
+	if err := rlp.DecodeBytes(headerRLP, &header); err != nil {
+		return nil, nil, fmt.Errorf("%w: header: %w", ErrInvalidEra1File, err)
+	}
+	if err := rlp.DecodeBytes(bodyRLP, &body); err != nil {
+		return nil, nil, fmt.Errorf("%w: body: %w", ErrInvalidEra1File, err)
+	}
+	if err := rlp.DecodeBytes(receiptsRLP, &receipts); err != nil {
+		return nil, nil, fmt.Errorf("%w: receipts: %w", ErrInvalidEra1File, err)
+	}
+
+	return types.NewBlockWithHeader(&header).WithBody(body), receipts, nil
+}
+
+// decompressSnappy decodes the framed snappy format used by e2store entries
+func decompressSnappy(data []byte) ([]byte, error) {
+	return io.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+}
@@ -22,12 +22,17 @@ type Trace struct {
 
 // aggregateBlock aggregates a block and publishes the messages to the broker
 func (s *Server) aggregateBlock(channel *amqp.Channel, block *types.Block) error {
+	// every message of the block carries the generation read once here, so core drops all of them
+	// or none when a reset happens meanwhile
+	headers := amqp.Table{rabbitmq.ResetGenerationHeader: int64(s.resetGeneration.Load())}
+
 	// publish block message
 	blockMessage := blockchain.ConvertBlockToBlock(block)
+	_, blockMessage.Reindexed = s.reindexing.LoadAndDelete(block.Hash())
 
 	s.log.Debug("publishing block message to broker", zap.Any("block", blockMessage))
 
-	err := s.publisher.PublishMessage(channel, rabbitmq.BlockExchange, rabbitmq.BlockRoute, blockMessage)
+	err := s.publisher.PublishMessage(channel, rabbitmq.BlockExchange, rabbitmq.BlockRoute, headers, blockMessage)
 	if err != nil {
 		s.log.Error("error in publishing block message to broker", zap.Any("block", block), zap.Error(err))
 		return err
@@ -36,21 +41,21 @@ func (s *Server) aggregateBlock(channel *amqp.Channel, block *types.Block) error
 	s.log.Debug("starting to aggregate transactions", zap.Bool("channel", channel == nil))
 
 	// aggregate transactions and compute reward
-	totalGasFees, err := s.aggregateTransactions(channel, block)
+	totalGasFees, err := s.aggregateTransactions(channel, headers, block, blockMessage.Reindexed)
 	if err != nil {
 		s.log.Error("error in aggregating transactions", zap.Error(err))
 		return err
 	}
 
 	// aggregate reward
-	err = s.aggregateReward(channel, totalGasFees, block)
+	err = s.aggregateReward(channel, headers, totalGasFees, block)
 	if err != nil {
 		s.log.Error("error in aggregating reward", zap.Error(err))
 		return err
 	}
 
 	// aggregate withdrawals
-	err = s.aggregateWithdrawals(channel, block.Withdrawals(), block.Hash())
+	err = s.aggregateWithdrawals(channel, headers, block.Withdrawals(), block.Hash())
 	if err != nil {
 		s.log.Error("error in aggregating withdrawals", zap.Error(err))
 		return err
@@ -59,7 +64,7 @@ func (s *Server) aggregateBlock(channel *amqp.Channel, block *types.Block) error
 	return nil
 }
 
-func (s *Server) aggregateTransactions(channel *amqp.Channel, block *types.Block) (uint64, error) {
+func (s *Server) aggregateTransactions(channel *amqp.Channel, headers amqp.Table, block *types.Block, reindexed bool) (uint64, error) {
 	var totalGasFees uint64
 
 	for index, transaction := range block.Transactions() {
@@ -77,6 +82,7 @@ func (s *Server) aggregateTransactions(channel *amqp.Channel, block *types.Block
 			s.log.Error("error in converting transaction to custom type", zap.Error(err))
 			return 0, err
 		}
+		transactionMessage.Reindexed = reindexed
 
 		s.log.Debug(
 			"publishing transaction message to broker",
@@ -84,7 +90,7 @@ func (s *Server) aggregateTransactions(channel *amqp.Channel, block *types.Block
 			zap.String("block hash", transactionMessage.BlockHash.String()),
 		)
 
-		err = s.publisher.PublishMessage(channel, rabbitmq.TransactionExchange, rabbitmq.TransactionRoute, transactionMessage)
+		err = s.publisher.PublishMessage(channel, rabbitmq.TransactionExchange, rabbitmq.TransactionRoute, headers, transactionMessage)
 		if err != nil {
 			s.log.Error("error in publishing transaction message to broker", zap.Error(err))
 			return 0, err
@@ -94,7 +100,7 @@ func (s *Server) aggregateTransactions(channel *amqp.Channel, block *types.Block
 		totalGasFees += transactionReceipt.CumulativeGasUsed
 
 		// aggregate transaction logs
-		err = s.aggragateTransactionLogs(channel, transactionReceipt.Logs)
+		err = s.aggragateTransactionLogs(channel, headers, transactionReceipt.Logs)
 		if err != nil {
 			s.log.Error("error in aggregating transaction logs", zap.Error(err))
 			return 0, err
@@ -108,7 +114,7 @@ func (s *Server) aggregateTransactions(channel *amqp.Channel, block *types.Block
 				return 0, err
 			}
 
-			err = s.processTransactionTrace(channel, block, transactionTrace)
+			err = s.processTransactionTrace(channel, headers, block, transactionTrace)
 			if err != nil {
 				s.log.Error("error in aggregating transaction trace", zap.Error(err), zap.String("transactionHash", transaction.Hash().String()))
 				return 0, err
@@ -117,7 +123,7 @@ func (s *Server) aggregateTransactions(channel *amqp.Channel, block *types.Block
 
 		// aggregate token events
 		tokenEvents := s.blockchainProcessor.GetTokenEvents(transactionReceipt, transaction.Hash())
-		err = s.aggregateTokenEvents(channel, tokenEvents)
+		err = s.aggregateTokenEvents(channel, headers, tokenEvents)
 		if err != nil {
 			s.log.Error("error in aggregating token events", zap.Error(err))
 			return 0, err
@@ -127,11 +133,11 @@ func (s *Server) aggregateTransactions(channel *amqp.Channel, block *types.Block
 	return totalGasFees, nil
 }
 
-func (s *Server) aggregateWithdrawals(channel *amqp.Channel, withdrawals []*types.Withdrawal, blockHash common.Hash) error {
+func (s *Server) aggregateWithdrawals(channel *amqp.Channel, headers amqp.Table, withdrawals []*types.Withdrawal, blockHash common.Hash) error {
 	for _, withdrawal := range withdrawals {
 		withdrawalMessage := blockchain.ConvertWithdrawalToWithdrawal(withdrawal, blockHash)
 
-		err := s.publisher.PublishMessage(channel, rabbitmq.WithdrawalExchange, rabbitmq.WithdrawalRoute, withdrawalMessage)
+		err := s.publisher.PublishMessage(channel, rabbitmq.WithdrawalExchange, rabbitmq.WithdrawalRoute, headers, withdrawalMessage)
 		if err != nil {
 			s.log.Error("error in publishing withdrawal message to broker", zap.Error(err))
 			return err
@@ -143,9 +149,9 @@ func (s *Server) aggregateWithdrawals(channel *amqp.Channel, withdrawals []*type
 	return nil
 }
 
-func (s *Server) aggregateTokenEvents(channel *amqp.Channel, tokenEvents []*blockchain.TokenEvent) error {
+func (s *Server) aggregateTokenEvents(channel *amqp.Channel, headers amqp.Table, tokenEvents []*blockchain.TokenEvent) error {
 	for _, tokenEvent := range tokenEvents {
-		err := s.publisher.PublishMessage(channel, rabbitmq.TokenEventExchange, rabbitmq.TokenEventRoute, tokenEvent)
+		err := s.publisher.PublishMessage(channel, rabbitmq.TokenEventExchange, rabbitmq.TokenEventRoute, headers, tokenEvent)
 		if err != nil {
 			s.log.Error("error in publishing token event message to broker", zap.Error(err))
 			return err
@@ -157,11 +163,11 @@ func (s *Server) aggregateTokenEvents(channel *amqp.Channel, tokenEvents []*bloc
 	return nil
 }
 
-func (s *Server) aggragateTransactionLogs(channel *amqp.Channel, transactionLogs []*types.Log) error {
+func (s *Server) aggragateTransactionLogs(channel *amqp.Channel, headers amqp.Table, transactionLogs []*types.Log) error {
 	for _, transactionLog := range transactionLogs {
 		transactionLogMessage := blockchain.ConvertTransactionLogToTransactionLog(transactionLog)
 
-		err := s.publisher.PublishMessage(channel, rabbitmq.TransactionLogExchange, rabbitmq.TransactionLogRoute, transactionLogMessage)
+		err := s.publisher.PublishMessage(channel, rabbitmq.TransactionLogExchange, rabbitmq.TransactionLogRoute, headers, transactionLogMessage)
 		if err != nil {
 			s.log.Error("error in publishing transaction log message to broker", zap.Error(err))
 			return err
@@ -173,14 +179,14 @@ func (s *Server) aggragateTransactionLogs(channel *amqp.Channel, transactionLogs
 	return nil
 }
 
-func (s *Server) aggregateReward(channel *amqp.Channel, totalGasFees uint64, block *types.Block) error {
+func (s *Server) aggregateReward(channel *amqp.Channel, headers amqp.Table, totalGasFees uint64, block *types.Block) error {
 	reward := &blockchain.Reward{
 		Address:   block.Coinbase(),
 		Amount:    totalGasFees,
 		BlockHash: block.Hash(),
 	}
 
-	err := s.publisher.PublishMessage(channel, rabbitmq.RewardExchange, rabbitmq.RewardRoute, reward)
+	err := s.publisher.PublishMessage(channel, rabbitmq.RewardExchange, rabbitmq.RewardRoute, headers, reward)
 	if err != nil {
 		s.log.Error("error in publishing reward message to broker", zap.Error(err))
 		return err
@@ -191,7 +197,7 @@ func (s *Server) aggregateReward(channel *amqp.Channel, totalGasFees uint64, blo
 	return nil
 }
 
-func (s *Server) processTransactionTrace(channel *amqp.Channel, block *types.Block, traces []map[string]interface{}) error {
+func (s *Server) processTransactionTrace(channel *amqp.Channel, headers amqp.Table, block *types.Block, traces []map[string]interface{}) error {
 	for index, trace := range traces {
 		internalTransaction, transactionAction, err := s.blockchainProcessor.ProcessTransactionTrace(index, trace, block)
 		if err != nil {
@@ -206,7 +212,7 @@ func (s *Server) processTransactionTrace(channel *amqp.Channel, block *types.Blo
 			return err
 		}
 
-		err = s.publisher.PublishMessage(channel, rabbitmq.InternalTransactionExchange, rabbitmq.InternalTransactionRoute, internalTransactionMessage)
+		err = s.publisher.PublishMessage(channel, rabbitmq.InternalTransactionExchange, rabbitmq.InternalTransactionRoute, headers, internalTransactionMessage)
 		if err != nil {
 			s.log.Error("error in publishing internal transaction message to broker", zap.Error(err))
 			return err
@@ -219,7 +225,7 @@ func (s *Server) processTransactionTrace(channel *amqp.Channel, block *types.Blo
 			return err
 		}
 
-		err = s.publisher.PublishMessage(channel, rabbitmq.TransactionActionExchange, rabbitmq.TransactionActionRoute, transactionActionMessage)
+		err = s.publisher.PublishMessage(channel, rabbitmq.TransactionActionExchange, rabbitmq.TransactionActionRoute, headers, transactionActionMessage)
 		if err != nil {
 			s.log.Error("error in publishing transaction action message to broker", zap.Error(err))
 			return err
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"context"
+	"flag"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/elmiringos/indexer/producer/config"
+	"github.com/elmiringos/indexer/producer/internal/blockchain"
+	"github.com/elmiringos/indexer/producer/pkg/logger"
+	"github.com/elmiringos/indexer/producer/pkg/rabbitmq"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// go test ./internal/server -run TestAggregateBlock -record-rpc=<node url> records the fixtures again from a node
+var recordRPC = flag.String("record-rpc", "", "record RPC fixtures from this node instead of replaying them")
+
+// Fixtures are cut from real chains:
+//   - Sepolia block 175881 comes from the sepolia-00021 era1 archive of go-ethereum's testdata. It holds three
+//     EIP-1559 contract creations that ran out of gas, so their eth_getCode replies are empty.
+//   - Mainnet block 18189758 is the execution payload of go-ethereum's Capella beacon block testdata, stored
+//     as a `geth export` RLP dump. It holds 100 transactions and 16 withdrawals but no receipts.
+//   - Mainnet transaction 0x5e3c77ae is an ERC-20 transfer from go-ethereum's tracer testdata. Its Transfer log
+//     comes from the recorded trace and the eth_call fixtures were recorded by executing the token's code.
+var (
+	sepoliaBlockHash        = common.HexToHash("0x39723cd3caf2b11067d5a95564c802ed6504bb48ed3e70bb7ebff341d181ca13")
+	sepoliaTransactionsRoot = common.HexToHash("0x35ec65e8eb9fb5c1d05922960dfe266d17a766c16b19822e7f0c99c9eb843173")
+	sepoliaReceiptsRoot     = common.HexToHash("0x09e41ef90db5a42e8a4d9a5ccdfe58c208534b3d45111bdcf92f969a3abb1581")
+	sepoliaSender           = common.HexToAddress("0xeA1B261FB7Ec1C4F2BEeA2476f17017537b4B507")
+
+	capellaBlockHash        = common.HexToHash("0x802acf5c350f4252e31d83c431fcb259470250fa0edf49e8391cfee014239820")
+	capellaTransactionsRoot = common.HexToHash("0x1d7757cb83f4a319a23490400ddca36c92685217b4d98c6b86a6fe8929cc8ed7")
+	capellaReceiptsRoot     = common.HexToHash("0x4e30ab0d1b712b4b4b93864f956287dfcd688f3c077dd356d1b78b6d316d1622")
+	capellaWithdrawalsRoot  = common.HexToHash("0x2000a17ef6773049d73297ceffc1d2c67444c02b49681cd5101561af43454b14")
+
+	transferHash     = common.HexToHash("0x5e3c77aeb3418a3e5fabe6cc97ec723e2c5cd36b5d5551984487286dcd2e92fc")
+	transferToken    = common.HexToAddress("0xf4eced2f682ce333f96f2d8966c613ded8fc95dd")
+	transferSender   = common.HexToAddress("0xd1220a0cf47c7b9be7a2e6ba89f429762e7b9adb")
+	transferReceiver = common.HexToAddress("0xdbf03b407c01e7cd3cbea99509d93f8dddc8c6fb")
+)
+
+type publishedMessage struct {
+	exchange rabbitmq.ExchangeName
+	headers  amqp.Table
+	message  interface{}
+}
+
+// capturingPublisher keeps the published messages instead of sending them to a broker
+type capturingPublisher struct {
+	messages []publishedMessage
+}
+
+func (p *capturingPublisher) CreateChannel() *amqp.Channel {
+	return nil
+}
+
+func (p *capturingPublisher) MakeNewQueueAndExchange(rabbitmq.ExchangeName, rabbitmq.RoutingKey, rabbitmq.QueueType) (*amqp.Queue, error) {
+	return &amqp.Queue{}, nil
+}
+
+func (p *capturingPublisher) PublishMessage(_ *amqp.Channel, exchange rabbitmq.ExchangeName, _ rabbitmq.RoutingKey, headers amqp.Table, message interface{}) error {
+	p.messages = append(p.messages, publishedMessage{exchange: exchange, headers: headers, message: message})
+	return nil
+}
+
+func (p *capturingPublisher) exchanges() []rabbitmq.ExchangeName {
+	exchanges := make([]rabbitmq.ExchangeName, len(p.messages))
+	for i, message := range p.messages {
+		exchanges[i] = message.exchange
+	}
+	return exchanges
+}
+
+// newFixtureProcessor replays the RPC fixtures in testdata/rpc/name, or records them when -record-rpc is set
+func newFixtureProcessor(t *testing.T, name string) *blockchain.BlockchainProcessor {
+	t.Helper()
+	logger.SetLogger(zap.NewNop())
+
+	dir := filepath.Join("testdata", "rpc", name)
+	cfg := &config.Config{}
+	cfg.Source.Type = blockchain.SourceRPC
+	if *recordRPC != "" {
+		// an HTTP URL for the websocket client keeps it from dialing, only HTTP calls are recorded
+		cfg.EthNode.HttpURL = *recordRPC
+		cfg.EthNode.WsURL = *recordRPC
+		cfg.EthNode.RecordDir = dir
+	} else {
+		cfg.EthNode.HttpURL = "http://replay.invalid"
+		cfg.EthNode.ReplayDir = dir
+	}
+
+	processor := blockchain.NewBlockchainProcessor(cfg)
+	t.Cleanup(processor.CloseClients)
+
+	return processor
+}
+
+// readChainFile returns the first block at or above number of a chain file, a processor given serves
+// the receipts the file carries
+func readChainFile(t *testing.T, processor *blockchain.BlockchainProcessor, format, path string, number int64) *types.Block {
+	t.Helper()
+	logger.SetLogger(zap.NewNop())
+
+	source, err := blockchain.NewFileBlockSource(format, path)
+	require.NoError(t, err)
+	if processor != nil {
+		processor.SetReceiptSource(source)
+	}
+
+	blocks, err := source.GenerateBlocks(context.Background(), big.NewInt(number))
+	require.NoError(t, err)
+
+	block := <-blocks
+	require.NotNil(t, block)
+	for range blocks {
+	}
+
+	return block
+}
+
+func newTestServer(processor *blockchain.BlockchainProcessor, publisher *capturingPublisher) *Server {
+	return &Server{
+		blockchainProcessor: processor,
+		blockSource:         processor,
+		publisher:           publisher,
+		config:              &config.Config{},
+		log:                 zap.NewNop(),
+	}
+}
+
+func readSepoliaBlock(t *testing.T, processor *blockchain.BlockchainProcessor) *types.Block {
+	t.Helper()
+
+	block := readChainFile(t, processor, blockchain.SourceEra1, "../blockchain/testdata/sepolia-00021-cut.era1", 175881)
+	require.Equal(t, sepoliaBlockHash, block.Hash())
+	require.Equal(t, sepoliaTransactionsRoot, block.TxHash())
+	require.Equal(t, sepoliaReceiptsRoot, block.ReceiptHash())
+
+	return block
+}
+
+func TestAggregateBlock(t *testing.T) {
+	processor := newFixtureProcessor(t, "sepolia_contract_creation")
+	publisher := &capturingPublisher{}
+	server := newTestServer(processor, publisher)
+	server.resetGeneration.Store(3)
+
+	block := readSepoliaBlock(t, processor)
+	require.NoError(t, server.aggregateBlock(nil, block))
+
+	// core drops a block with all its children when they carry a generation older than its last reset
+	for _, message := range publisher.messages {
+		assert.Equal(t, int64(3), message.headers[rabbitmq.ResetGenerationHeader], message.exchange)
+	}
+
+	assert.Equal(t, []rabbitmq.ExchangeName{
+		rabbitmq.BlockExchange,
+		rabbitmq.TransactionExchange,
+		rabbitmq.TransactionExchange,
+		rabbitmq.TransactionExchange,
+		rabbitmq.RewardExchange,
+	}, publisher.exchanges())
+
+	blockMessage := publisher.messages[0].message.(*blockchain.Block)
+	assert.Equal(t, sepoliaBlockHash, blockMessage.Hash)
+	assert.Equal(t, "175881", blockMessage.Number.String())
+	assert.Equal(t, 3, blockMessage.TransactionsCount)
+	assert.Equal(t, 0, blockMessage.WithdrawalsCount)
+	assert.False(t, blockMessage.IsPos)
+	assert.Equal(t, "7", blockMessage.BaseFeePerGas.String())
+	assert.Equal(t, sepoliaTransactionsRoot, blockMessage.TransactionsRoot)
+	assert.Equal(t, sepoliaReceiptsRoot, blockMessage.ReceiptsRoot)
+	assert.False(t, blockMessage.Reindexed)
+
+	expected := []struct {
+		hash    common.Hash
+		nonce   uint64
+		gasUsed uint64
+	}{
+		{hash: common.HexToHash("0x9e588bfd96efb86590963a0158b6dcf8a99101dfee2b97241a247e6ea4a25903"), nonce: 36, gasUsed: 143104},
+		{hash: common.HexToHash("0x46acc720e303f44d4aa26442766a8eec222178efa1db35cfee4b6a6bd32de08a"), nonce: 37, gasUsed: 143088},
+		{hash: common.HexToHash("0xf781ddd0a7714accc027e19da71842301260f86a065a503f3d8cac78f29d9ee7"), nonce: 38, gasUsed: 143087},
+	}
+	var cumulativeGasUsed uint64
+	for i, want := range expected {
+		transaction := publisher.messages[i+1].message.(*blockchain.Transaction)
+		cumulativeGasUsed += want.gasUsed
+
+		assert.Equal(t, want.hash, transaction.Hash)
+		assert.Equal(t, sepoliaBlockHash, transaction.BlockHash)
+		assert.Equal(t, i, transaction.Index)
+		assert.Equal(t, sepoliaSender, transaction.From)
+		assert.Equal(t, common.Address{}, transaction.To, "contract creation")
+		assert.Equal(t, want.nonce, transaction.Nonce)
+		assert.Equal(t, uint8(types.DynamicFeeTxType), transaction.Type)
+		assert.Equal(t, types.ReceiptStatusFailed, transaction.Status, "out of gas")
+		assert.Equal(t, want.gasUsed, transaction.GasUsed)
+		assert.Equal(t, cumulativeGasUsed, transaction.CumulativeGasUsed)
+		assert.Equal(t, 0, transaction.LogsCount)
+	}
+
+	reward := publisher.messages[4].message.(*blockchain.Reward)
+	assert.Equal(t, block.Coinbase(), reward.Address)
+	assert.Equal(t, sepoliaBlockHash, reward.BlockHash)
+}
+
+func TestAggregateBlock_Reindexed(t *testing.T) {
+	processor := newFixtureProcessor(t, "sepolia_contract_creation")
+	publisher := &capturingPublisher{}
+	server := newTestServer(processor, publisher)
+
+	block := readSepoliaBlock(t, processor)
+
+	// reindexBlocks records the block before handing it to the workers
+	server.reindexing.Store(block.Hash(), struct{}{})
+	require.NoError(t, server.aggregateBlock(nil, block))
+
+	// core restarts the counters of the block and of its transactions
+	assert.True(t, publisher.messages[0].message.(*blockchain.Block).Reindexed)
+	for _, message := range publisher.messages[1:4] {
+		assert.True(t, message.message.(*blockchain.Transaction).Reindexed)
+	}
+
+	_, pending := server.reindexing.Load(block.Hash())
+	assert.False(t, pending)
+
+	// the same block coming from the chain head afterwards is a normal one
+	publisher.messages = nil
+	block = readSepoliaBlock(t, processor)
+	require.NoError(t, server.aggregateBlock(nil, block))
+	assert.False(t, publisher.messages[0].message.(*blockchain.Block).Reindexed)
+}
+
+func TestAggregateWithdrawals_Mainnet(t *testing.T) {
+	publisher := &capturingPublisher{}
+	server := newTestServer(nil, publisher)
+
+	block := readChainFile(t, nil, blockchain.SourceRLP, "testdata/mainnet-18189758.rlp", 0)
+	require.Equal(t, capellaBlockHash, block.Hash())
+	require.Equal(t, capellaTransactionsRoot, block.TxHash())
+	require.Equal(t, capellaReceiptsRoot, block.ReceiptHash())
+	require.Equal(t, capellaWithdrawalsRoot, *block.Header().WithdrawalsHash)
+
+	blockMessage := blockchain.ConvertBlockToBlock(block)
+	assert.Equal(t, "18189758", blockMessage.Number.String())
+	assert.True(t, blockMessage.IsPos)
+	assert.Equal(t, 100, blockMessage.TransactionsCount)
+	assert.Equal(t, 16, blockMessage.WithdrawalsCount)
+	assert.Equal(t, capellaTransactionsRoot, blockMessage.TransactionsRoot)
+	assert.Equal(t, capellaReceiptsRoot, blockMessage.ReceiptsRoot)
+
+	require.NoError(t, server.aggregateWithdrawals(nil, amqp.Table{}, block.Withdrawals(), block.Hash()))
+	require.Len(t, publisher.messages, 16)
+
+	first := publisher.messages[0].message.(*blockchain.Withdrawal)
+	assert.Equal(t, &blockchain.Withdrawal{
+		Index:          18476769,
+		BlockHash:      capellaBlockHash,
+		AddressHash:    common.HexToAddress("0xB9D7934878B5FB9610B3fE8A5e441e8fad7E293f"),
+		ValidatorIndex: 711858,
+		Amount:         16008754,
+	}, first)
+
+	for i, message := range publisher.messages {
+		assert.Equal(t, rabbitmq.WithdrawalExchange, message.exchange)
+		assert.Equal(t, uint64(18476769+i), message.message.(*blockchain.Withdrawal).Index)
+	}
+}
+
+func TestGetTokenEvents(t *testing.T) {
+	processor := newFixtureProcessor(t, "mainnet_erc20_transfer")
+
+	// receipt of the transfer in mainnet block 765825 with the log of its trace
+	receipt := &types.Receipt{
+		TxHash: transferHash,
+		Logs: []*types.Log{{
+			Address: transferToken,
+			Topics: []common.Hash{
+				common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+				common.BytesToHash(transferSender.Bytes()),
+				common.BytesToHash(transferReceiver.Bytes()),
+			},
+			Data:  common.FromHex("0x0000000000000000000000000000000000000000000000000000000000989680"),
+			Index: 0,
+		}},
+	}
+
+	events := processor.GetTokenEvents(receipt, transferHash)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	assert.Equal(t, transferToken, event.Address)
+	assert.Equal(t, transferHash, event.TransactionHash)
+	assert.Equal(t, transferSender, event.From)
+	assert.Equal(t, transferReceiver, event.To)
+	assert.Equal(t, "10000000", event.Value.String())
+	assert.False(t, event.IsMint)
+	assert.False(t, event.IsBurn)
+	// the trace prestate holds only the storage slots the transfer touched, executing the token's name,
+	// symbol and decimals on it returns empty values
+	assert.Equal(t, blockchain.TokenMetadata{"name": "", "symbol": "", "decimals": uint8(0)}, event.TokenMetadata)
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/elmiringos/indexer/producer/pkg/logger"
+	"github.com/elmiringos/indexer/producer/pkg/pb"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ControlHandler serves the commands core sends to the producer
+type ControlHandler struct {
+	reindexQueue *ReindexQueue
+	log          *zap.Logger
+	pb.UnimplementedProducerControlServiceServer
+}
+
+func NewControlHandler(reindexQueue *ReindexQueue) *ControlHandler {
+	return &ControlHandler{
+		reindexQueue: reindexQueue,
+		log:          logger.GetLogger(),
+	}
+}
+
+func (h *ControlHandler) ReindexRange(ctx context.Context, req *pb.ReindexRangeRequest) (*pb.ReindexRangeResponse, error) {
+	request := ReindexRequest{
+		From:   new(big.Int).SetBytes(req.FromNumber),
+		To:     new(big.Int).SetBytes(req.ToNumber),
+		Reason: req.Reason,
+	}
+
+	if err := h.reindexQueue.Enqueue(request); err != nil {
+		h.log.Warn("Reindex request rejected", zap.Error(err))
+
+		switch {
+		case errors.Is(err, ErrReindexQueueFull):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		default:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	h.log.Info(
+		"Reindex request queued",
+		zap.String("from", request.From.String()),
+		zap.String("to", request.To.String()),
+		zap.String("reason", request.Reason),
+	)
+
+	return &pb.ReindexRangeResponse{
+		Accepted:     true,
+		QueuedBlocks: request.Size(),
+	}, nil
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"github.com/elmiringos/indexer/producer/pkg/pb"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewGRPCServer creates the producer's gRPC server serving the control and the standard health services
+func NewGRPCServer(healthServer *grpchealth.Server, reindexQueue *ReindexQueue) *grpc.Server {
+	s := grpc.NewServer()
+
+	pb.RegisterProducerControlServiceServer(s, NewControlHandler(reindexQueue))
+	healthpb.RegisterHealthServer(s, healthServer)
+	reflection.Register(s)
+
+	return s
+}
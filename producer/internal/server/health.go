@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/elmiringos/indexer/producer/config"
+	"github.com/elmiringos/indexer/producer/internal/blockchain"
+	"github.com/elmiringos/indexer/producer/pkg/health"
+)
+
+// RegisterHealthChecks registers the producer's readiness checks: node reachability and head freshness
+func RegisterHealthChecks(checker *health.Checker, blockchainProcessor *blockchain.BlockchainProcessor, cfg *config.Config) {
+	checker.Register("eth_node", blockchainProcessor.Ping)
+
+	checker.Register("chain_head", func(ctx context.Context) error {
+		header, err := blockchainProcessor.LatestHeader(ctx)
+		if err != nil {
+			return err
+		}
+
+		age := time.Since(time.Unix(int64(header.Time), 0))
+		if age > cfg.Health.MaxHeadAge {
+			return fmt.Errorf("head block %s is %s old, exceeds %s", header.Number, age.Truncate(time.Second), cfg.Health.MaxHeadAge)
+		}
+
+		return nil
+	})
+}
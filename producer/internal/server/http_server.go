@@ -0,0 +1,25 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/elmiringos/indexer/producer/config"
+	"github.com/elmiringos/indexer/producer/pkg/health"
+	"github.com/elmiringos/indexer/producer/pkg/metrics"
+)
+
+// NewHTTPServer creates the HTTP server exposing the producer's operational endpoints
+func NewHTTPServer(cfg *config.Config, checker *health.Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", checker.LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", cfg.HTTP.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/elmiringos/indexer/producer/pkg/metrics"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidReindexRange  = errors.New("reindex range start is above its end")
+	ErrReindexRangeTooLarge = errors.New("reindex range exceeds the allowed number of blocks")
+	ErrReindexQueueFull     = errors.New("reindex queue is full")
+)
+
+// ReindexRequest asks to fetch and publish an inclusive range of blocks again
+type ReindexRequest struct {
+	From   *big.Int
+	To     *big.Int
+	Reason string
+}
+
+// Size returns the number of blocks in the range
+func (r ReindexRequest) Size() uint64 {
+	return new(big.Int).Sub(r.To, r.From).Uint64() + 1
+}
+
+// ReindexQueue buffers reindex requests between the control service and the block workers
+type ReindexQueue struct {
+	requests chan ReindexRequest
+	maxRange uint64
+}
+
+// NewReindexQueue creates a queue holding up to size requests of at most maxRange blocks each
+func NewReindexQueue(size int, maxRange uint64) *ReindexQueue {
+	return &ReindexQueue{
+		requests: make(chan ReindexRequest, size),
+		maxRange: maxRange,
+	}
+}
+
+// Enqueue validates the request and queues it without blocking
+func (q *ReindexQueue) Enqueue(request ReindexRequest) error {
+	if request.From.Cmp(request.To) > 0 {
+		return fmt.Errorf("%w: %s > %s", ErrInvalidReindexRange, request.From, request.To)
+	}
+
+	if size := new(big.Int).Sub(request.To, request.From); !size.IsUint64() || size.Uint64() >= q.maxRange {
+		return fmt.Errorf("%w: %s-%s, max %d", ErrReindexRangeTooLarge, request.From, request.To, q.maxRange)
+	}
+
+	select {
+	case q.requests <- request:
+		return nil
+	default:
+		return ErrReindexQueueFull
+	}
+}
+
+// mergeReindexedBlocks forwards blocks to the workers together with the blocks fetched for reindex requests.
+// The returned channel is closed once blocks is closed.
+func (s *Server) mergeReindexedBlocks(ctx context.Context, blocks <-chan *types.Block) <-chan *types.Block {
+	out := make(chan *types.Block, cap(blocks))
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.reindexBlocks(ctx, out)
+	}()
+
+	go func() {
+		for block := range blocks {
+			out <- block
+		}
+
+		cancel()
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// reindexBlocks fetches the blocks of every queued reindex request until ctx is done
+func (s *Server) reindexBlocks(ctx context.Context, out chan<- *types.Block) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case request := <-s.reindexQueue.requests:
+			s.log.Info(
+				"Reindexing block range",
+				zap.String("from", request.From.String()),
+				zap.String("to", request.To.String()),
+				zap.String("reason", request.Reason),
+			)
+
+			for number := new(big.Int).Set(request.From); number.Cmp(request.To) <= 0; number.Add(number, big.NewInt(1)) {
+				block, err := s.blockchainProcessor.GetBlockByNumber(ctx, number)
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					s.log.Error("Error in fetching block for reindex", zap.String("blockNumber", number.String()), zap.Error(err))
+					continue
+				}
+
+				s.reindexing.Store(block.Hash(), struct{}{})
+
+				select {
+				case out <- block:
+					metrics.ReindexedBlocks.Inc()
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReindexQueue_Enqueue(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        int64
+		to          int64
+		expectedErr error
+	}{
+		{name: "single block", from: 10, to: 10},
+		{name: "range at the limit", from: 10, to: 19},
+		{name: "reversed range", from: 20, to: 10, expectedErr: ErrInvalidReindexRange},
+		{name: "range above the limit", from: 10, to: 20, expectedErr: ErrReindexRangeTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := NewReindexQueue(1, 10)
+
+			err := queue.Enqueue(ReindexRequest{From: big.NewInt(tt.from), To: big.NewInt(tt.to)})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, queue.requests)
+				return
+			}
+
+			require.NoError(t, err)
+			request := <-queue.requests
+			assert.Equal(t, uint64(tt.to-tt.from+1), request.Size())
+		})
+	}
+}
+
+func TestReindexQueue_EnqueueFull(t *testing.T) {
+	queue := NewReindexQueue(1, 10)
+
+	require.NoError(t, queue.Enqueue(ReindexRequest{From: big.NewInt(1), To: big.NewInt(2)}))
+	assert.ErrorIs(t, queue.Enqueue(ReindexRequest{From: big.NewInt(3), To: big.NewInt(4)}), ErrReindexQueueFull)
+}
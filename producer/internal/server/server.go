@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/elmiringos/indexer/producer/config"
 	"github.com/elmiringos/indexer/producer/internal/blockchain"
 	grpccoreclient "github.com/elmiringos/indexer/producer/pkg/grpc_core_client"
 	"github.com/elmiringos/indexer/producer/pkg/logger"
+	"github.com/elmiringos/indexer/producer/pkg/metrics"
 	"github.com/elmiringos/indexer/producer/pkg/rabbitmq"
 
 	"github.com/ethereum/go-ethereum/core/types"
@@ -18,22 +21,34 @@ import (
 
 type Server struct {
 	blockchainProcessor *blockchain.BlockchainProcessor
+	blockSource         blockchain.BlockSource
 	grpcCoreClient      *grpccoreclient.CoreClient
-	publisher           *rabbitmq.Publisher
+	publisher           rabbitmq.MessagePublisher
+	reindexQueue        *ReindexQueue
 	config              *config.Config
 	log                 *zap.Logger
+	// resetGeneration is the reset generation of core, stamped on every published message
+	resetGeneration atomic.Uint64
+	// reindexing holds the hashes of blocks fetched for a reindex request until they are aggregated
+	reindexing sync.Map
 }
 
 func NewServer(
 	blockhainProcessor *blockchain.BlockchainProcessor,
-	publisher *rabbitmq.Publisher,
+	blockSource blockchain.BlockSource,
+	publisher rabbitmq.MessagePublisher,
 	grpcCoreClient *grpccoreclient.CoreClient,
+	reindexQueue *ReindexQueue,
 	cfg *config.Config,
 ) *Server {
 	if blockhainProcessor == nil {
 		panic("Blockhain processor is nil")
 	}
 
+	if blockSource == nil {
+		panic("Block source is nil")
+	}
+
 	if publisher == nil {
 		panic("Rabbitmq publisher is nil")
 	}
@@ -44,8 +59,10 @@ func NewServer(
 
 	return &Server{
 		blockchainProcessor: blockhainProcessor,
+		blockSource:         blockSource,
 		publisher:           publisher,
 		grpcCoreClient:      grpcCoreClient,
+		reindexQueue:        reindexQueue,
 		config:              cfg,
 		log:                 logger.GetLogger(),
 	}
@@ -93,7 +110,10 @@ func (s *Server) worker(id int, blocks <-chan *types.Block, wg *sync.WaitGroup)
 
 	channel := s.publisher.CreateChannel()
 	s.log.Info("Creating new rabbitmqchannel", zap.Int("worker_id", id))
-	defer channel.Close()
+	// the in-process broker of the all-in-one binary has no channels
+	if channel != nil {
+		defer channel.Close()
+	}
 
 	for block := range blocks {
 		s.log.Info("Worker started processing block", zap.Int("worker", id), zap.Int64("blockHeight", block.Number().Int64()))
@@ -101,6 +121,10 @@ func (s *Server) worker(id int, blocks <-chan *types.Block, wg *sync.WaitGroup)
 		err := s.aggregateBlock(channel, block)
 		if err != nil {
 			s.log.Error("Error aggregating block", zap.Error(err))
+			metrics.BlocksProcessed.WithLabelValues("error").Inc()
+		} else {
+			metrics.BlocksProcessed.WithLabelValues("ok").Inc()
+			metrics.ObservePublishedBlock(block.NumberU64())
 		}
 
 		s.log.Info("Worker finished processing block", zap.Int("worker", id), zap.Int64("blockHeight", block.Number().Int64()))
@@ -114,8 +138,9 @@ func (s *Server) startWorkerPool(numWorkers int, blocks <-chan *types.Block, wg
 	}
 }
 
-func (s *Server) ResetState() {
-	messageState, err := s.grpcCoreClient.ResetState()
+// ResetState makes core drop indexed state, everything when fromBlock is nil or only blocks above it
+func (s *Server) ResetState(fromBlock *big.Int, reason string) {
+	messageState, err := s.grpcCoreClient.ResetState(fromBlock, reason)
 	if err != nil {
 		s.log.Fatal("Error in reseting core service state", zap.Error(err))
 	}
@@ -123,53 +148,128 @@ func (s *Server) ResetState() {
 	if !messageState.Success {
 		s.log.Fatal("Unsuccesful atemt in reseting core service state")
 	}
+
+	s.resetGeneration.Store(messageState.Generation)
+	s.log.Warn("Core service state reset", zap.Uint64("deletedBlocks", messageState.DeletedBlocks), zap.Uint64("generation", messageState.Generation))
 }
 
-func (s *Server) SyncStartingBlock(configBlockStartNumber *big.Int) {
-	currentBlock, err := s.grpcCoreClient.GetCurrentBlock()
+// refreshResetGeneration asks core for its reset generation, blocks aggregated from now on carry it
+func (s *Server) refreshResetGeneration() error {
+	generation, err := s.grpcCoreClient.GetResetGeneration()
 	if err != nil {
-		s.log.Fatal("Error in getting starting block", zap.Error(err))
-	}
-
-	currentBlockNumber := big.NewInt(0).SetBytes(currentBlock.BlockNumber)
-
-	if currentBlockNumber.Cmp(big.NewInt(0)) == 0 {
-		s.log.Info("No starting block found, starting from block that placed in config.yml")
-	} else if currentBlockNumber.Cmp(configBlockStartNumber) < 0 {
-		s.log.Warn(
-			"Current block number in Database is less than config block number. Some data will be lost.",
-			zap.Any("currentBlockNumber", currentBlockNumber),
-			zap.Any("startingBlockNumber", configBlockStartNumber),
-		)
-	} else if currentBlockNumber.Cmp(configBlockStartNumber) > 0 {
-		s.log.Warn(
-			"Current block number is greater than starting block number. This is not possible. Please check the database and the core service.",
-			zap.Any("currentBlockNumber", currentBlockNumber),
-			zap.Any("startingBlockNumber", configBlockStartNumber),
-		)
+		return err
+	}
+
+	if previous := s.resetGeneration.Swap(generation); previous != generation {
+		s.log.Warn("Core state was reset, stamping messages with the new generation", zap.Uint64("previous", previous), zap.Uint64("generation", generation))
+	}
+
+	return nil
+}
+
+// watchResetGeneration polls core for resets done by other clients. Messages published between a reset and
+// the next poll carry the old generation and are dropped by core, the gap detector asks for them again.
+func (s *Server) watchResetGeneration(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refreshResetGeneration(); err != nil {
+				s.log.Warn("Error in getting reset generation from core", zap.Error(err))
+			}
+		}
 	}
 }
 
+// SyncStartingBlock resolves the block to start publishing from against what core has already indexed
+func (s *Server) SyncStartingBlock(configBlockStartNumber *big.Int) *big.Int {
+	var forceStartNumber *big.Int
+	if s.config.Server.ForceStartBlock != "" {
+		var ok bool
+		forceStartNumber, ok = big.NewInt(0).SetString(s.config.Server.ForceStartBlock, 10)
+		if !ok {
+			s.log.Fatal("Error in setting forced start block", zap.String("forceStartBlock", s.config.Server.ForceStartBlock))
+		}
+	}
+
+	response, err := s.grpcCoreClient.GetIndexedRange()
+	if err != nil {
+		s.log.Fatal("Error in getting indexed range from core", zap.Error(err))
+	}
+
+	var indexed *indexedRange
+	if response.HasBlocks {
+		indexed = &indexedRange{
+			lowest: big.NewInt(0).SetBytes(response.LowestNumber),
+			next:   big.NewInt(0).SetBytes(response.NextNumber),
+		}
+	}
+
+	// chain files may be replayed without any node, the head check only applies to the node source
+	var chainHead *big.Int
+	if s.config.Source.Type == blockchain.SourceRPC {
+		header, err := s.blockchainProcessor.LatestHeader(context.Background())
+		if err != nil {
+			s.log.Warn("Error in getting chain head, skipping head check", zap.Error(err))
+		} else {
+			chainHead = header.Number
+		}
+	}
+
+	startNumber, err := resolveStartBlock(configBlockStartNumber, forceStartNumber, indexed, chainHead)
+	if err != nil {
+		s.log.Fatal("Refusing to start, set force_start_block to override", zap.Error(err))
+	}
+
+	switch {
+	case forceStartNumber != nil:
+		s.log.Warn("Starting from forced block", zap.String("startBlock", startNumber.String()))
+		if indexed != nil && startNumber.Cmp(indexed.next) < 0 {
+			s.log.Warn("Forced block is below the first block core is missing, indexed blocks will be published again", zap.String("nextBlock", indexed.next.String()))
+		} else if indexed != nil && startNumber.Cmp(indexed.next) > 0 {
+			s.log.Warn("Forced block is above the first block core is missing, skipped blocks will not be indexed", zap.String("nextBlock", indexed.next.String()))
+		}
+	case indexed == nil:
+		s.log.Info("No indexed blocks found, starting from block that placed in config.yml", zap.String("startBlock", startNumber.String()))
+	default:
+		s.log.Info("Resuming from the first block core has not fully indexed", zap.String("startBlock", startNumber.String()), zap.String("lowestBlock", indexed.lowest.String()))
+	}
+
+	return startNumber
+}
+
 func (s *Server) StartBlockchainDataConsuming() {
-	blockStartNumber, ok := big.NewInt(0).SetString(s.config.Server.BlockStartNumber, 10)
+	configStartNumber, ok := big.NewInt(0).SetString(s.config.Server.BlockStartNumber, 10)
 	if !ok {
 		s.log.Fatal("Error in setting block start number", zap.String("blockStartNumber", s.config.Server.BlockStartNumber))
 	}
 
 	// Sync starting block before starting the workers
-	s.SyncStartingBlock(blockStartNumber)
+	blockStartNumber := s.SyncStartingBlock(configStartNumber)
+
+	if err := s.refreshResetGeneration(); err != nil {
+		s.log.Fatal("Error in getting reset generation from core", zap.Error(err))
+	}
+	go s.watchResetGeneration(context.Background(), s.config.Server.ResetGenerationPoll)
 
 	// Setup all queues (blockQueue, transactionQueue, withdrawalQueue, transactionLogQueue, internalTransactionQueue, transactionActionQueue, tokenEventQueue)
 	s.setupAllQueues()
 
 	var wg sync.WaitGroup
 
-	// Listen for new blocks
-	blocks, err := s.blockchainProcessor.GenerateBlocks(context.Background(), blockStartNumber)
+	// Listen for new blocks, or read them from chain files
+	blocks, err := s.blockSource.GenerateBlocks(context.Background(), blockStartNumber)
 	if err != nil {
 		s.log.Fatal("Error in generating blocks", zap.Error(err))
 	}
 
+	// Blocks requested again by core are processed by the same workers
+	blocks = s.mergeReindexedBlocks(context.Background(), blocks)
+
 	// Start the worker pool
 	s.startWorkerPool(s.config.WorkerCount, blocks, &wg)
 	wg.Wait()
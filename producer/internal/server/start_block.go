@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrStartBlockBelowIndexed = errors.New("configured start block is below the lowest block indexed by core, resuming would never backfill it")
+	ErrStartBlockAboveIndexed = errors.New("configured start block is above the first block core is missing, starting there would leave a gap")
+	ErrIndexedAheadOfChain    = errors.New("core has indexed blocks beyond the chain head, the database likely belongs to another network")
+)
+
+// indexedRange is the contiguous fully indexed range reported by core
+type indexedRange struct {
+	// lowest is the lowest block stored by core
+	lowest *big.Int
+	// next is the first block at or above lowest that core has not fully indexed
+	next *big.Int
+}
+
+// resolveStartBlock decides which block publishing starts from.
+// A forced start always wins. Without indexed data the configured start is used, otherwise
+// indexing resumes from the first block core is missing, provided the configured start lies
+// within the indexed range and the range is not ahead of the chain head.
+// A nil indexed means core has no blocks, a nil chainHead skips the head check.
+func resolveStartBlock(configStart, forceStart *big.Int, indexed *indexedRange, chainHead *big.Int) (*big.Int, error) {
+	if forceStart != nil {
+		return forceStart, nil
+	}
+
+	if indexed == nil {
+		return configStart, nil
+	}
+
+	if chainHead != nil && indexed.next.Cmp(new(big.Int).Add(chainHead, big.NewInt(1))) > 0 {
+		return nil, fmt.Errorf("%w: next block %s, chain head %s", ErrIndexedAheadOfChain, indexed.next, chainHead)
+	}
+
+	if configStart.Cmp(indexed.lowest) < 0 {
+		return nil, fmt.Errorf("%w: configured %s, lowest indexed %s", ErrStartBlockBelowIndexed, configStart, indexed.lowest)
+	}
+
+	if configStart.Cmp(indexed.next) > 0 {
+		return nil, fmt.Errorf("%w: configured %s, next block %s", ErrStartBlockAboveIndexed, configStart, indexed.next)
+	}
+
+	return indexed.next, nil
+}
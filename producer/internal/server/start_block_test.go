@@ -0,0 +1,96 @@
+package server
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveStartBlock(t *testing.T) {
+	indexed := &indexedRange{lowest: big.NewInt(100), next: big.NewInt(150)}
+
+	tests := []struct {
+		name        string
+		configStart int64
+		forceStart  *big.Int
+		indexed     *indexedRange
+		chainHead   *big.Int
+		expected    int64
+		expectedErr error
+	}{
+		{
+			name:        "empty database starts from config",
+			configStart: 100,
+			expected:    100,
+		},
+		{
+			name:        "resumes from first missing block",
+			configStart: 100,
+			indexed:     indexed,
+			chainHead:   big.NewInt(200),
+			expected:    150,
+		},
+		{
+			name:        "config inside indexed range resumes",
+			configStart: 120,
+			indexed:     indexed,
+			expected:    150,
+		},
+		{
+			name:        "config at next block resumes",
+			configStart: 150,
+			indexed:     indexed,
+			expected:    150,
+		},
+		{
+			name:        "fully synced database resumes after head",
+			configStart: 100,
+			indexed:     indexed,
+			chainHead:   big.NewInt(149),
+			expected:    150,
+		},
+		{
+			name:        "force overrides indexed range",
+			configStart: 100,
+			forceStart:  big.NewInt(10),
+			indexed:     indexed,
+			expected:    10,
+		},
+		{
+			name:        "config below indexed range is refused",
+			configStart: 50,
+			indexed:     indexed,
+			expectedErr: ErrStartBlockBelowIndexed,
+		},
+		{
+			name:        "config above first missing block is refused",
+			configStart: 151,
+			indexed:     indexed,
+			expectedErr: ErrStartBlockAboveIndexed,
+		},
+		{
+			name:        "indexed beyond chain head is refused",
+			configStart: 100,
+			indexed:     indexed,
+			chainHead:   big.NewInt(120),
+			expectedErr: ErrIndexedAheadOfChain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, err := resolveStartBlock(big.NewInt(tt.configStart), tt.forceStart, tt.indexed, tt.chainHead)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, start)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, big.NewInt(tt.expected), start)
+		})
+	}
+}
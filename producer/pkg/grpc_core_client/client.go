@@ -2,25 +2,33 @@ package grpccoreclient
 
 import (
 	"context"
+	"math/big"
 
 	pb "github.com/elmiringos/indexer/producer/pkg/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 type CoreClient struct {
 	conn       *grpc.ClientConn
 	grpcClient pb.CoreServiceClient
+	adminToken string
 }
 
-func NewCoreClient(address string) (*CoreClient, error) {
+// NewCoreClient connects to core, adminToken authorizes admin RPCs such as ResetState
+func NewCoreClient(address string, adminToken string) (*CoreClient, error) {
 	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		return nil, err
 	}
 
 	client := pb.NewCoreServiceClient(conn)
-	return &CoreClient{conn: conn, grpcClient: client}, nil
+	return &CoreClient{conn: conn, grpcClient: client, adminToken: adminToken}, nil
+}
+
+func (c *CoreClient) adminContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.adminToken, "x-admin-actor", "producer")
 }
 
 func (c *CoreClient) Close() error {
@@ -36,11 +44,37 @@ func (c *CoreClient) GetCurrentBlock() (*pb.GetCurrentBlockResponse, error) {
 	return response, nil
 }
 
-func (c *CoreClient) ResetState() (*pb.ResetStateResponse, error) {
-	response, err := c.grpcClient.ResetState(context.Background(), &pb.ResetStateRequest{})
+func (c *CoreClient) GetIndexedRange() (*pb.GetIndexedRangeResponse, error) {
+	response, err := c.grpcClient.GetIndexedRange(context.Background(), &pb.GetIndexedRangeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ResetState asks core to drop indexed state, everything when fromBlock is nil or only blocks above it
+func (c *CoreClient) ResetState(fromBlock *big.Int, reason string) (*pb.ResetStateResponse, error) {
+	request := &pb.ResetStateRequest{Reason: reason}
+	if fromBlock != nil {
+		request.HasFromBlock = true
+		request.FromBlockNumber = fromBlock.Bytes()
+	}
+
+	response, err := c.grpcClient.ResetState(c.adminContext(context.Background()), request)
 	if err != nil {
 		return nil, err
 	}
 
 	return response, err
 }
+
+// GetResetGeneration returns the reset generation core expects on new messages
+func (c *CoreClient) GetResetGeneration() (uint64, error) {
+	response, err := c.grpcClient.GetResetGeneration(context.Background(), &pb.GetResetGenerationRequest{})
+	if err != nil {
+		return 0, err
+	}
+
+	return response.Generation, nil
+}
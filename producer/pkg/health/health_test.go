@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestCheck_AllUp(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("first", func(ctx context.Context) error { return nil })
+	checker.Register("second", func(ctx context.Context) error { return nil })
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusUp, report.Checks["first"].Status)
+}
+
+func TestCheck_OneDown(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("ok", func(ctx context.Context) error { return nil })
+	checker.Register("broken", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["ok"].Status)
+	assert.Equal(t, StatusDown, report.Checks["broken"].Status)
+	assert.Equal(t, "connection refused", report.Checks["broken"].Error)
+}
+
+func TestCheck_Timeout(t *testing.T) {
+	checker := NewChecker(10 * time.Millisecond)
+	checker.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := checker.Check(context.Background())
+
+	assert.Equal(t, StatusDown, report.Checks["slow"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestReadinessHandler(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("broken", func(ctx context.Context) error { return errors.New("down") })
+
+	recorder := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	var report Report
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "down", report.Checks["broken"].Error)
+}
+
+func TestLivenessHandler_IgnoresChecks(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("broken", func(ctx context.Context) error { return errors.New("down") })
+
+	recorder := httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestWatchGRPC(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.Register("ok", func(ctx context.Context) error { return nil })
+
+	server := NewGRPCServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.WatchGRPC(ctx, server, time.Hour, "producer.Control")
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		response, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "producer.Control"})
+		return err == nil && response.Status == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -18,6 +18,11 @@ func GetLogger() *zap.Logger {
 	return log
 }
 
+// SetLogger replaces the shared logger, tests use it to run code that logs without creating log files
+func SetLogger(l *zap.Logger) {
+	log = l
+}
+
 func New(cfg *config.Config) *zap.Logger {
 	var core zapcore.Core
 
@@ -0,0 +1,129 @@
+// Package metrics defines the Prometheus collectors exported by the producer
+package metrics
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "producer"
+
+var (
+	// ChainHead is the latest block number reported by the node
+	ChainHead = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "chain_head_block",
+		Help:      "Latest block number seen on the chain.",
+	})
+
+	// PublishedHeight is the highest block number fully published to the broker
+	PublishedHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "published_block",
+		Help:      "Highest block number fully published to the broker.",
+	})
+
+	// BlockLag is the distance between the chain head and the published height
+	BlockLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "block_lag",
+		Help:      "Number of blocks between the chain head and the published height.",
+	})
+
+	// BlocksProcessed counts aggregated blocks, rate() of it gives blocks/sec
+	BlocksProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_processed_total",
+		Help:      "Number of blocks aggregated by workers, by result.",
+	}, []string{"result"})
+
+	// ReindexedBlocks counts blocks fetched again on request of core
+	ReindexedBlocks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reindexed_blocks_total",
+		Help:      "Number of blocks fetched again for reindex requests.",
+	})
+
+	// RPCDuration tracks JSON-RPC call latency by method
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "Latency of JSON-RPC calls to the node, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	// RPCErrors counts failed JSON-RPC calls by method
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_errors_total",
+		Help:      "Number of failed JSON-RPC calls to the node, by method.",
+	}, []string{"method"})
+
+	// PublishDuration tracks AMQP publish latency by exchange
+	PublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "amqp_publish_duration_seconds",
+		Help:      "Latency of publishing a message to the broker, by exchange.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"exchange"})
+
+	// PublishFailures counts failed AMQP publishes by exchange
+	PublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "amqp_publish_failures_total",
+		Help:      "Number of messages that failed to publish, by exchange.",
+	}, []string{"exchange"})
+)
+
+// ObserveRPC records the latency and outcome of a JSON-RPC call started at start
+func ObserveRPC(method string, start time.Time, err error) {
+	RPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// head and published mirror the gauges so the lag can be derived without reading collectors back
+var head, published atomic.Uint64
+
+// ObserveChainHead updates the chain head and the resulting lag
+func ObserveChainHead(number uint64) {
+	raise(&head, number)
+	ChainHead.Set(float64(head.Load()))
+	updateLag()
+}
+
+// ObservePublishedBlock raises the published height if number is above it and updates the lag
+func ObservePublishedBlock(number uint64) {
+	raise(&published, number)
+	PublishedHeight.Set(float64(published.Load()))
+	updateLag()
+}
+
+func raise(value *atomic.Uint64, number uint64) {
+	for {
+		current := value.Load()
+		if number <= current || value.CompareAndSwap(current, number) {
+			return
+		}
+	}
+}
+
+func updateLag() {
+	h, p := head.Load(), published.Load()
+	if h < p {
+		BlockLag.Set(0)
+		return
+	}
+	BlockLag.Set(float64(h - p))
+}
+
+// Handler returns the HTTP handler serving the metrics in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -2,13 +2,23 @@ package rabbitmq
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/elmiringos/indexer/producer/pkg/logger"
+	"github.com/elmiringos/indexer/producer/pkg/metrics"
 
 	ampq "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+// MessagePublisher publishes the aggregated messages to the broker, Publisher implements it on top of
+// RabbitMQ and the all-in-one binary with in-process queues, whose CreateChannel returns nil
+type MessagePublisher interface {
+	CreateChannel() *ampq.Channel
+	MakeNewQueueAndExchange(exchange ExchangeName, routingKey RoutingKey, queueType QueueType) (*ampq.Queue, error)
+	PublishMessage(channel *ampq.Channel, exchange ExchangeName, routingKey RoutingKey, headers ampq.Table, message interface{}) error
+}
+
 type Publisher struct {
 	conn *ampq.Connection
 	log  *zap.Logger
@@ -82,12 +92,20 @@ func (p *Publisher) MakeNewQueueAndExchange(exchange ExchangeName, routingKey Ro
 	return &queue, nil
 }
 
-func (p *Publisher) PublishMessage(channel *ampq.Channel, exchange ExchangeName, routingKey RoutingKey, message interface{}) error {
+func (p *Publisher) PublishMessage(channel *ampq.Channel, exchange ExchangeName, routingKey RoutingKey, headers ampq.Table, message interface{}) error {
 	body, err := json.Marshal(message)
 	if err != nil {
 		return err
 	}
 
+	start := time.Now()
+	defer func() {
+		metrics.PublishDuration.WithLabelValues(string(exchange)).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.PublishFailures.WithLabelValues(string(exchange)).Inc()
+		}
+	}()
+
 	err = channel.Publish(
 		string(exchange),
 		string(routingKey),
@@ -95,6 +113,8 @@ func (p *Publisher) PublishMessage(channel *ampq.Channel, exchange ExchangeName,
 		false,
 		ampq.Publishing{
 			ContentType: "application/json",
+			Timestamp:   time.Now(),
+			Headers:     headers,
 			Body:        body,
 		},
 	)
@@ -42,6 +42,9 @@ const (
 	TransactionActionQueue   QueueType = "transaction_action"
 )
 
+// ResetGenerationHeader carries the reset generation of core a message was published in
+const ResetGenerationHeader = "x-reset-generation"
+
 type BlockStatus int
 
 const (
@@ -0,0 +1,282 @@
+// Package rpcreplay records JSON-RPC traffic to a fixture directory and serves it back offline
+package rpcreplay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrFixtureNotFound = errors.New("no recorded fixture for request")
+	ErrInvalidRequest  = errors.New("invalid JSON-RPC request")
+	ErrInvalidResponse = errors.New("invalid JSON-RPC response")
+)
+
+// call is a single recorded JSON-RPC exchange, request ids are not recorded since they change between runs
+type call struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// fixture is the content of a fixture file, batch requests are recorded as one fixture
+type fixture struct {
+	Batch bool    `json:"batch"`
+	Calls []*call `json:"calls"`
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// parseRequests decodes a single or batch request body
+func parseRequests(body []byte) ([]*rpcRequest, bool, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var requests []*rpcRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			return nil, true, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		return requests, true, nil
+	}
+
+	var request rpcRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	return []*rpcRequest{&request}, false, nil
+}
+
+// fixtureName derives the file name of a request from its methods and params
+func fixtureName(requests []*rpcRequest) (string, error) {
+	hash := sha256.New()
+	for _, request := range requests {
+		var params bytes.Buffer
+		if len(request.Params) > 0 {
+			if err := json.Compact(&params, request.Params); err != nil {
+				return "", fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+			}
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00", request.Method, params.Bytes())
+	}
+
+	prefix := requests[0].Method
+	if len(requests) > 1 {
+		prefix = "batch"
+	}
+	return fmt.Sprintf("%s_%s.json", prefix, hex.EncodeToString(hash.Sum(nil))[:16]), nil
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// RecordingTransport forwards requests to the node and writes every exchange to a fixture file
+type RecordingTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// NewRecordingTransport records into dir, which is created if needed. A nil next uses http.DefaultTransport.
+func NewRecordingTransport(dir string, next http.RoundTripper) (*RecordingTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &RecordingTransport{dir: dir, next: next}, nil
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// only successful exchanges are worth replaying, transport errors are returned as they are
+	if resp.StatusCode == http.StatusOK {
+		if err := t.record(body, respBody); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func (t *RecordingTransport) record(body, respBody []byte) error {
+	requests, batch, err := parseRequests(body)
+	if err != nil || len(requests) == 0 {
+		return err
+	}
+
+	var responses []*rpcResponse
+	if batch {
+		err = json.Unmarshal(respBody, &responses)
+	} else {
+		var response rpcResponse
+		err = json.Unmarshal(respBody, &response)
+		responses = []*rpcResponse{&response}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+
+	byID := make(map[string]*rpcResponse, len(responses))
+	for _, response := range responses {
+		byID[string(response.ID)] = response
+	}
+
+	recorded := &fixture{Batch: batch}
+	for _, request := range requests {
+		response, ok := byID[string(request.ID)]
+		if !ok {
+			return fmt.Errorf("%w: no response for %s", ErrInvalidResponse, request.Method)
+		}
+		recorded.Calls = append(recorded.Calls, &call{
+			Method: request.Method,
+			Params: request.Params,
+			Result: response.Result,
+			Error:  response.Error,
+		})
+	}
+
+	name, err := fixtureName(requests)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.dir, name), append(data, '\n'), 0o644)
+}
+
+// ReplayTransport answers requests from the fixtures written by RecordingTransport without any network access
+type ReplayTransport struct {
+	dir string
+}
+
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return &ReplayTransport{dir: dir}, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, batch, err := parseRequests(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, ErrInvalidRequest
+	}
+
+	name, err := fixtureName(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(t.dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			methods := make([]string, len(requests))
+			for i, request := range requests {
+				methods[i] = request.Method
+			}
+			return nil, fmt.Errorf("%w: %s (%s)", ErrFixtureNotFound, strings.Join(methods, ","), name)
+		}
+		return nil, err
+	}
+
+	var recorded fixture
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", name, err)
+	}
+	if len(recorded.Calls) != len(requests) {
+		return nil, fmt.Errorf("fixture %s: recorded %d calls, request has %d", name, len(recorded.Calls), len(requests))
+	}
+
+	// answer with the ids of this request
+	responses := make([]*rpcResponse, len(requests))
+	for i, request := range requests {
+		responses[i] = &rpcResponse{
+			Version: "2.0",
+			ID:      request.ID,
+			Result:  recorded.Calls[i].Result,
+			Error:   recorded.Calls[i].Error,
+		}
+	}
+
+	var respBody []byte
+	if batch {
+		respBody, err = json.Marshal(responses)
+	} else {
+		respBody, err = json.Marshal(responses[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package rpcreplay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNode answers eth_chainId and echoes the params of echo, counting the requests it serves
+func newNode(t *testing.T, served *int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*served++
+
+		requests, batch, err := parseRequests(mustRead(t, r))
+		require.NoError(t, err)
+
+		responses := make([]map[string]interface{}, len(requests))
+		for i, request := range requests {
+			var result interface{} = "0x1"
+			if request.Method == "echo" {
+				result = request.Params
+			}
+			responses[i] = map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result}
+		}
+
+		if batch {
+			require.NoError(t, json.NewEncoder(w).Encode(responses))
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(responses[0]))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func mustRead(t *testing.T, r *http.Request) []byte {
+	body, err := readBody(r.Body)
+	require.NoError(t, err)
+	return body
+}
+
+func dial(t *testing.T, url string, transport http.RoundTripper) *rpc.Client {
+	t.Helper()
+
+	client, err := rpc.DialOptions(context.Background(), url, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var served int
+	node := newNode(t, &served)
+	dir := t.TempDir()
+
+	recorder, err := NewRecordingTransport(dir, nil)
+	require.NoError(t, err)
+	live := dial(t, node.URL, recorder)
+
+	var chainID string
+	require.NoError(t, live.Call(&chainID, "eth_chainId"))
+	var echoed []string
+	require.NoError(t, live.Call(&echoed, "echo", "a", "b"))
+
+	batch := []rpc.BatchElem{
+		{Method: "eth_chainId", Result: new(string)},
+		{Method: "echo", Args: []interface{}{"c"}, Result: &[]string{}},
+	}
+	require.NoError(t, live.BatchCall(batch))
+	assert.Equal(t, 3, served)
+
+	replayer, err := NewReplayTransport(dir)
+	require.NoError(t, err)
+	offline := dial(t, "http://replay.invalid", replayer)
+
+	// several calls so the request ids differ from the recorded ones
+	for i := 0; i < 3; i++ {
+		var replayedChainID string
+		require.NoError(t, offline.Call(&replayedChainID, "eth_chainId"))
+		assert.Equal(t, chainID, replayedChainID)
+	}
+
+	var replayedEcho []string
+	require.NoError(t, offline.Call(&replayedEcho, "echo", "a", "b"))
+	assert.Equal(t, []string{"a", "b"}, replayedEcho)
+
+	replayedBatch := []rpc.BatchElem{
+		{Method: "eth_chainId", Result: new(string)},
+		{Method: "echo", Args: []interface{}{"c"}, Result: &[]string{}},
+	}
+	require.NoError(t, offline.BatchCall(replayedBatch))
+	assert.Equal(t, "0x1", *replayedBatch[0].Result.(*string))
+	assert.Equal(t, []string{"c"}, *replayedBatch[1].Result.(*[]string))
+
+	assert.Equal(t, 3, served, "replay must not reach the node")
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	replayer, err := NewReplayTransport(t.TempDir())
+	require.NoError(t, err)
+	offline := dial(t, "http://replay.invalid", replayer)
+
+	var result string
+	err = offline.Call(&result, "eth_chainId")
+	assert.ErrorIs(t, err, ErrFixtureNotFound)
+}